@@ -1,6 +1,8 @@
 package clients
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -29,6 +31,18 @@ type Artifact struct {
 	Files       []ArtifactFile `json:"files"`
 }
 
+// UploadAttachmentRequest represents the API request body for uploading an attachment
+type UploadAttachmentRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        string `json:"data"` // Base64-encoded content
+}
+
+// UploadAttachmentResponse represents the API response for uploading an attachment
+type UploadAttachmentResponse struct {
+	ID string `json:"id"`
+}
+
 // TokenResponse represents the API response for token operations
 type TokenResponse struct {
 	Token     string    `json:"token"`
@@ -168,3 +182,52 @@ func (c *AgentsApiClient) FetchArtifacts() ([]Artifact, error) {
 	return artifacts, nil
 }
 
+// UploadAttachment uploads a file to the Claude Control API and returns the new attachment ID
+func (c *AgentsApiClient) UploadAttachment(filename, contentType string, content []byte) (*UploadAttachmentResponse, error) {
+	url := fmt.Sprintf("%s/api/agents/attachments", c.baseURL)
+
+	body, err := json.Marshal(UploadAttachmentRequest{
+		Filename:    filename,
+		ContentType: contentType,
+		Data:        base64.StdEncoding.EncodeToString(content),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add Bearer token authentication header
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	if c.agentID != "" {
+		req.Header.Set("X-AGENT-ID", c.agentID)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for successful response
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	// Parse response
+	var uploadResp UploadAttachmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if uploadResp.ID == "" {
+		return nil, fmt.Errorf("API returned empty attachment ID")
+	}
+
+	return &uploadResp, nil
+}
@@ -48,11 +48,18 @@ func (c *ClaudeClient) StartNewSession(prompt string, options *clients.ClaudeOpt
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	var cmd = c.buildCommand(ctx, options, args)
+	var onOutputLine func(line string)
+	if options != nil {
+		onOutputLine = options.OnOutputLine
+	}
 
 	log.Info("Running Claude command (timeout: %s)", clients.DefaultSessionTimeout)
-	output, err := cmd.CombinedOutput()
+	output, err := clients.CombinedOutputWithLines(cmd, onOutputLine)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			log.Error("⏰ Claude session timed out after %s", clients.DefaultSessionTimeout)
@@ -61,6 +68,13 @@ func (c *ClaudeClient) StartNewSession(prompt string, options *clients.ClaudeOpt
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 Claude session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -101,11 +115,18 @@ func (c *ClaudeClient) ContinueSession(sessionID, prompt string, options *client
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	var cmd = c.buildCommand(ctx, options, args)
+	var onOutputLine func(line string)
+	if options != nil {
+		onOutputLine = options.OnOutputLine
+	}
 
 	log.Info("Running Claude command (timeout: %s)", clients.DefaultSessionTimeout)
-	output, err := cmd.CombinedOutput()
+	output, err := clients.CombinedOutputWithLines(cmd, onOutputLine)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			log.Error("⏰ Claude session timed out after %s", clients.DefaultSessionTimeout)
@@ -114,6 +135,13 @@ func (c *ClaudeClient) ContinueSession(sessionID, prompt string, options *client
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 Claude session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -4,14 +4,17 @@ package clients
 type ClaudeOptions struct {
 	SystemPrompt    string
 	DisallowedTools []string
-	Model           string // Model alias or full name (e.g., "sonnet", "haiku", "opus", "claude-sonnet-4-5-20250929")
-	WorkDir         string // Working directory for the Claude session (e.g., a git worktree path)
+	Model           string            // Model alias or full name (e.g., "sonnet", "haiku", "opus", "claude-sonnet-4-5-20250929")
+	WorkDir         string            // Working directory for the Claude session (e.g., a git worktree path)
+	JobID           string            // Job the session runs for, used to interrupt its process
+	OnOutputLine    func(line string) // Called with each output line while the session runs
 }
 
 // CursorOptions contains optional parameters for Cursor CLI interactions
 type CursorOptions struct {
 	SystemPrompt string
 	Model        string
+	JobID        string // Job the session runs for, used to interrupt its process
 }
 
 // CodexOptions contains optional parameters for Codex CLI interactions
@@ -19,6 +22,7 @@ type CodexOptions struct {
 	Model     string // GPT-5 or other model
 	Sandbox   string // "workspace-write", "danger-full-access", "read-only"
 	WebSearch bool   // Enable --search flag
+	JobID     string // Job the session runs for, used to interrupt its process
 }
 
 // ClaudeClient defines the interface for Claude CLI interactions
@@ -43,6 +47,7 @@ type CodexClient interface {
 type OpenCodeOptions struct {
 	Model   string // Model in provider/model format (e.g., "anthropic/claude-3-5-sonnet")
 	WorkDir string // Working directory for the OpenCode session (e.g., a git worktree path)
+	JobID   string // Job the session runs for, used to interrupt its process
 }
 
 // OpenCodeClient defines the interface for OpenCode CLI interactions
@@ -0,0 +1,289 @@
+package clients
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// CodeHostKind identifies the service hosting the repository
+type CodeHostKind string
+
+const (
+	CodeHostGitHub CodeHostKind = "github"
+	CodeHostGitLab CodeHostKind = "gitlab"
+)
+
+// Pull request states reported by every code host
+const (
+	PullRequestStateOpen   = "open"
+	PullRequestStateMerged = "merged"
+	PullRequestStateClosed = "closed"
+)
+
+// PullRequest is a pull request (GitHub) or merge request (GitLab)
+type PullRequest struct {
+	ID          string // PR number on GitHub, MR IID on GitLab
+	URL         string
+	Title       string
+	Description string
+	State       string // open, merged or closed
+	Draft       bool   // Opened as a draft and not yet marked ready for review
+}
+
+// Buckets a pull request check falls into, as reported by `gh pr checks`
+const (
+	CheckBucketPass     = "pass"
+	CheckBucketFail     = "fail"
+	CheckBucketPending  = "pending"
+	CheckBucketSkipping = "skipping"
+	CheckBucketCancel   = "cancel"
+)
+
+// PullRequestCheck is a CI check run (GitHub) or pipeline job (GitLab) of a pull request
+type PullRequestCheck struct {
+	Name   string
+	Bucket string // pass, fail, pending, skipping or cancel
+	Link   string // Details page of the run, including its log
+}
+
+// PullRequestChecks are the CI checks of the head commit of a pull request
+type PullRequestChecks struct {
+	HeadCommit string
+	Checks     []PullRequestCheck
+}
+
+// Pending reports whether any check has not finished yet
+func (c *PullRequestChecks) Pending() bool {
+	for _, check := range c.Checks {
+		if check.Bucket == CheckBucketPending {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed returns the checks that failed
+func (c *PullRequestChecks) Failed() []PullRequestCheck {
+	var failed []PullRequestCheck
+	for _, check := range c.Checks {
+		if check.Bucket == CheckBucketFail {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// CodeHost creates and inspects pull requests on the service hosting the repository.
+// dir is the repository or worktree the operation runs for; empty means the current directory.
+type CodeHost interface {
+	// Kind returns which service this is
+	Kind() CodeHostKind
+	// MaxTitleLength returns the longest pull request title the service accepts
+	MaxTitleLength() int
+	// CheckAccess verifies the host is reachable with the configured credentials
+	CheckAccess(dir string) error
+	// CreatePullRequest opens a pull request from headBranch into baseBranch and returns its URL.
+	// Draft pull requests do not request reviews until they are marked ready.
+	CreatePullRequest(dir, headBranch, baseBranch, title, description string, draft bool) (string, error)
+	// ListOpenPullRequests returns the open pull requests with branchName as head
+	ListOpenPullRequests(dir, branchName string) ([]PullRequest, error)
+	// GetPullRequestForBranch returns the latest pull request for branchName, preferring an open one
+	GetPullRequestForBranch(dir, branchName string) (*PullRequest, error)
+	// GetPullRequestByID returns the pull request with the given number or IID
+	GetPullRequestByID(dir, id string) (*PullRequest, error)
+	// UpdatePullRequestTitle sets the title of the pull request for branchName
+	UpdatePullRequestTitle(dir, branchName, title string) error
+	// UpdatePullRequestDescription sets the description of the pull request for branchName
+	UpdatePullRequestDescription(dir, branchName, description string) error
+	// MarkPullRequestReady marks a draft pull request as ready for review
+	MarkPullRequestReady(dir, id string) error
+	// GetPullRequestChecks returns the CI checks of the pull request's head commit
+	GetPullRequestChecks(dir, id string) (*PullRequestChecks, error)
+	// FindPullRequestTemplate returns the repository's pull request template, or "" if it has none
+	FindPullRequestTemplate(dir string) (string, error)
+}
+
+// ReviewComment is a comment in a pull request review thread
+type ReviewComment struct {
+	Author    string
+	Body      string
+	DiffHunk  string // Diff context the thread is attached to
+	URL       string
+	CreatedAt time.Time
+}
+
+// ReviewThread is a conversation on a line of the pull request diff
+type ReviewThread struct {
+	ID         string // Node ID used to reply to and resolve the thread
+	Path       string
+	Line       int // Line in the current diff, or the original line for outdated threads
+	IsResolved bool
+	IsOutdated bool
+	Comments   []ReviewComment
+}
+
+// Review is a submitted pull request review
+type Review struct {
+	Author      string
+	State       string // e.g. COMMENTED, CHANGES_REQUESTED, APPROVED
+	Body        string
+	URL         string
+	SubmittedAt time.Time
+}
+
+// PullRequestReviews are the reviews and review threads of a pull request
+type PullRequestReviews struct {
+	Viewer  string // Login eksecd acts as, whose own comments are not review feedback
+	Threads []ReviewThread
+	Reviews []Review
+}
+
+// ReviewThreadHost is implemented by code hosts whose review threads eksecd can read and answer
+type ReviewThreadHost interface {
+	// GetPullRequestReviews returns the reviews and review threads of the pull request
+	GetPullRequestReviews(dir, id string) (*PullRequestReviews, error)
+	// ReplyToReviewThread adds a reply to the review thread
+	ReplyToReviewThread(dir, threadID, body string) error
+	// ResolveReviewThread marks the review thread as resolved
+	ResolveReviewThread(dir, threadID string) error
+}
+
+// PullRequestMetadata are the labels, reviewers and assignees added to a pull request
+type PullRequestMetadata struct {
+	Labels        []string
+	Reviewers     []string // User logins
+	TeamReviewers []string // "org/team-slug"
+	Assignees     []string
+}
+
+// Empty reports whether there is nothing to add
+func (m PullRequestMetadata) Empty() bool {
+	return len(m.Labels) == 0 && len(m.Reviewers) == 0 && len(m.TeamReviewers) == 0 && len(m.Assignees) == 0
+}
+
+// PullRequestMetadataEditor is implemented by code hosts that can label pull requests and
+// request reviewers and assignees
+type PullRequestMetadataEditor interface {
+	// AddPullRequestMetadata adds labels, reviewers and assignees to the pull request
+	AddPullRequestMetadata(dir, id string, metadata PullRequestMetadata) error
+}
+
+// RemoteLocation is the host and repository path parsed from a remote URL
+type RemoteLocation struct {
+	Host string // Hostname, with the port for HTTP(S) remotes
+	Path string // Repository path without ".git", e.g. "owner/repo" or "group/subgroup/project"
+}
+
+// ParseRemoteURL parses scp-like SSH (git@host:path), ssh:// and http(s):// remote URLs.
+// Credentials in the URL are dropped.
+func ParseRemoteURL(remoteURL string) (*RemoteLocation, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		parsed, err := url.Parse(remoteURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL %s: %w", remoteURL, err)
+		}
+		host = parsed.Host
+		if parsed.Scheme == "ssh" || parsed.Scheme == "git+ssh" {
+			// The SSH port says nothing about where the web and API live
+			host = parsed.Hostname()
+		}
+		path = parsed.Path
+	} else if at := strings.Index(remoteURL, "@"); at >= 0 && strings.Contains(remoteURL[at:], ":") {
+		rest := remoteURL[at+1:]
+		colon := strings.Index(rest, ":")
+		host = rest[:colon]
+		path = rest[colon+1:]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return nil, fmt.Errorf("remote URL %s has no host and repository path", remoteURL)
+	}
+	return &RemoteLocation{Host: host, Path: path}, nil
+}
+
+// isSSHRemoteURL reports whether remoteURL is an scp-like or ssh:// remote
+func isSSHRemoteURL(remoteURL string) bool {
+	if strings.HasPrefix(remoteURL, "ssh://") || strings.HasPrefix(remoteURL, "git+ssh://") {
+		return true
+	}
+	return !strings.Contains(remoteURL, "://") && strings.Contains(remoteURL, "@")
+}
+
+// WebURL returns the https URL of the repository
+func (r *RemoteLocation) WebURL() string {
+	return "https://" + r.Host + "/" + r.Path
+}
+
+// DetectCodeHostKind picks the code host from the origin remote URL. Hosts named like
+// gitlab and the host in GITLAB_HOST (for self-hosted GitLab) are GitLab; everything else
+// is treated as GitHub.
+func DetectCodeHostKind(remoteURL string) CodeHostKind {
+	location, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return CodeHostGitHub
+	}
+	if isGitLabHost(location.Host) {
+		return CodeHostGitLab
+	}
+	return CodeHostGitHub
+}
+
+// NewCodeHostForRemote returns the code host client for the origin remote URL
+func NewCodeHostForRemote(remoteURL string) (CodeHost, error) {
+	if DetectCodeHostKind(remoteURL) != CodeHostGitLab {
+		return NewGitHubHost(), nil
+	}
+
+	location, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewGitLabHost(gitLabAPIURL(location.Host), os.Getenv("GITLAB_TOKEN"), location.Path), nil
+}
+
+// isGitLabHost reports whether host is gitlab.com, looks like a GitLab instance, or is the
+// instance configured in GITLAB_HOST
+func isGitLabHost(host string) bool {
+	hostname := strings.ToLower(host)
+	if i := strings.LastIndex(hostname, ":"); i >= 0 {
+		hostname = hostname[:i]
+	}
+	if strings.Contains(hostname, "gitlab") {
+		return true
+	}
+	configured := gitLabConfiguredHost()
+	return configured != nil && strings.EqualFold(configured.Hostname(), hostname)
+}
+
+// gitLabAPIURL returns the REST API base URL of the GitLab instance at host. GITLAB_HOST
+// decides the scheme and port when it names the same instance.
+func gitLabAPIURL(host string) string {
+	base := "https://" + host
+	if configured := gitLabConfiguredHost(); configured != nil && strings.EqualFold(configured.Hostname(), strings.Split(host, ":")[0]) {
+		base = configured.Scheme + "://" + configured.Host
+	}
+	return base + "/api/v4"
+}
+
+// gitLabConfiguredHost parses GITLAB_HOST, which may be a bare hostname or a URL
+func gitLabConfiguredHost() *url.URL {
+	value := strings.TrimSpace(os.Getenv("GITLAB_HOST"))
+	if value == "" {
+		return nil
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	return parsed
+}
@@ -0,0 +1,137 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersLocations are where GitHub looks for a CODEOWNERS file, in order
+var codeOwnersLocations = []string{
+	filepath.Join(".github", "CODEOWNERS"),
+	"CODEOWNERS",
+	filepath.Join("docs", "CODEOWNERS"),
+}
+
+// CodeOwners are the ownership rules of a CODEOWNERS file
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// codeOwnersRule assigns owners to the paths matching a pattern; no owners unassigns them
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Lines with invalid patterns
+// are skipped, as GitHub does.
+func ParseCodeOwners(content string) *CodeOwners {
+	codeOwners := &CodeOwners{}
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return codeOwners
+}
+
+// Owners returns the owners of path, which is relative to the repository root. The last
+// matching rule wins.
+func (c *CodeOwners) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPattern converts a gitignore-style CODEOWNERS pattern into a regular expression.
+// Patterns with a leading or inner slash are relative to the root, others match at any depth,
+// and a pattern matching a directory matches everything in it.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty pattern %q", pattern)
+	}
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			sb.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(trimmed[i : i+1]))
+		}
+	}
+	if dirOnly {
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
+
+// FindCodeOwners reads the CODEOWNERS file of the repository checked out in dir. Returns nil
+// if the repository has none.
+func FindCodeOwners(dir string) (*CodeOwners, error) {
+	for _, location := range codeOwnersLocations {
+		content, err := os.ReadFile(filepath.Join(dir, location))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		return ParseCodeOwners(string(content)), nil
+	}
+	return nil, nil
+}
+
+// CodeOwnerReviewers splits the owners of the changed paths into user and team reviewers.
+// Owners given by email address cannot be requested as reviewers and are skipped.
+func (c *CodeOwners) CodeOwnerReviewers(changedPaths []string) (reviewers, teamReviewers []string) {
+	seen := make(map[string]bool)
+	for _, path := range changedPaths {
+		for _, owner := range c.Owners(path) {
+			if !strings.HasPrefix(owner, "@") || seen[owner] {
+				continue
+			}
+			seen[owner] = true
+			if name := strings.TrimPrefix(owner, "@"); strings.Contains(name, "/") {
+				teamReviewers = append(teamReviewers, name)
+			} else {
+				reviewers = append(reviewers, name)
+			}
+		}
+	}
+	return reviewers, teamReviewers
+}
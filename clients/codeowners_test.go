@@ -0,0 +1,78 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testCodeOwners = `# Default owners
+*                 @org/everyone
+*.go              @gopher
+/docs/            @org/docs-team writer@example.com
+internal/**/db.go @dba
+build/            # no owners
+`
+
+func TestCodeOwners_Owners(t *testing.T) {
+	codeOwners := ParseCodeOwners(testCodeOwners)
+
+	cases := map[string][]string{
+		"README.md":                   {"@org/everyone"},
+		"cmd/main.go":                 {"@gopher"},
+		"docs/guide.md":               {"@org/docs-team", "writer@example.com"},
+		"docs/api/index.md":           {"@org/docs-team", "writer@example.com"},
+		"site/docs/guide.md":          {"@org/everyone"},
+		"internal/store/sql/db.go":    {"@dba"},
+		"internal/db.go":              {"@dba"},
+		"build/Makefile":              {},
+		"tools/build/compile.sh":      {},
+		"internal/store/sql/query.go": {"@gopher"},
+	}
+	for path, expected := range cases {
+		owners := codeOwners.Owners(path)
+		if len(expected) == 0 && len(owners) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(owners, expected) {
+			t.Errorf("%s: expected owners %v, got %v", path, expected, owners)
+		}
+	}
+}
+
+func TestCodeOwners_CodeOwnerReviewers(t *testing.T) {
+	codeOwners := ParseCodeOwners(testCodeOwners)
+
+	reviewers, teamReviewers := codeOwners.CodeOwnerReviewers([]string{
+		"cmd/main.go", "docs/guide.md", "internal/db.go", "clients/git.go", "build/Makefile",
+	})
+	if !reflect.DeepEqual(reviewers, []string{"gopher", "dba"}) {
+		t.Errorf("unexpected reviewers %v", reviewers)
+	}
+	if !reflect.DeepEqual(teamReviewers, []string{"org/docs-team"}) {
+		t.Errorf("unexpected team reviewers %v", teamReviewers)
+	}
+}
+
+func TestFindCodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	codeOwners, err := FindCodeOwners(dir)
+	if err != nil || codeOwners != nil {
+		t.Fatalf("expected no code owners without a file, got %v, %v", codeOwners, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @alice\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	codeOwners, err = FindCodeOwners(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owners := codeOwners.Owners("main.go"); !reflect.DeepEqual(owners, []string{"@alice"}) {
+		t.Errorf("expected @alice, got %v", owners)
+	}
+}
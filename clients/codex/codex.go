@@ -33,6 +33,9 @@ func (c *CodexClient) StartNewSession(prompt string, options *clients.CodexOptio
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	cmd := clients.BuildAgentCommandWithContext(ctx, "codex", args...)
 	if c.workDir != "" {
@@ -49,6 +52,13 @@ func (c *CodexClient) StartNewSession(prompt string, options *clients.CodexOptio
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 Codex session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -75,6 +85,9 @@ func (c *CodexClient) ContinueSession(threadID, prompt string, options *clients.
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	cmd := clients.BuildAgentCommandWithContext(ctx, "codex", args...)
 	if c.workDir != "" {
@@ -91,6 +104,13 @@ func (c *CodexClient) ContinueSession(threadID, prompt string, options *clients.
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 Codex session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit signing formats, the values of git's gpg.format
+const (
+	CommitSigningOpenPGP = "openpgp"
+	CommitSigningSSH     = "ssh"
+	CommitSigningX509    = "x509"
+)
+
+// CommitSigning is the key eksecd signs its commits with
+type CommitSigning struct {
+	Format string // One of the CommitSigning* formats
+	Key    string // user.signingkey: a GPG key ID, or for ssh the path to the key or "key::<public key>"
+}
+
+// NewCommitSigning validates a signing key and format. An empty format is openpgp.
+func NewCommitSigning(key, format string) (*CommitSigning, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("commit signing key is empty")
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "", "gpg":
+		format = CommitSigningOpenPGP
+	case CommitSigningOpenPGP, CommitSigningSSH, CommitSigningX509:
+	default:
+		return nil, fmt.Errorf("unsupported commit signing format %q (expected %s, %s or %s)",
+			format, CommitSigningOpenPGP, CommitSigningSSH, CommitSigningX509)
+	}
+	return &CommitSigning{Format: format, Key: key}, nil
+}
+
+// configArgs are the `git -c` options that make commit and rebase sign the commits they create
+func (s *CommitSigning) configArgs() []string {
+	if s == nil {
+		return nil
+	}
+	return []string{
+		"-c", "gpg.format=" + s.Format,
+		"-c", "user.signingkey=" + s.Key,
+		"-c", "commit.gpgsign=true",
+	}
+}
@@ -48,6 +48,9 @@ func (c *CursorClient) StartNewSession(prompt string, options *clients.CursorOpt
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	cmd := clients.BuildAgentCommandWithContext(ctx, "cursor-agent", args...)
 
@@ -61,6 +64,13 @@ func (c *CursorClient) StartNewSession(prompt string, options *clients.CursorOpt
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 Cursor session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -93,6 +103,9 @@ func (c *CursorClient) ContinueSession(sessionID, prompt string, options *client
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	cmd := clients.BuildAgentCommandWithContext(ctx, "cursor-agent", args...)
 
@@ -106,6 +119,13 @@ func (c *CursorClient) ContinueSession(sessionID, prompt string, options *client
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 Cursor session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
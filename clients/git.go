@@ -6,16 +6,17 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"eksecd/core/log"
-
-	"github.com/cenkalti/backoff/v4"
 )
 
 type GitClient struct {
-	getRepoPath func() string // Function to get repository path (allows lazy evaluation)
+	getRepoPath func() string  // Function to get repository path (allows lazy evaluation)
+	codeHost    CodeHost       // Overrides the code host detected from the origin remote when set
+	signing     *CommitSigning // Signs commits when set
 }
 
 // WorktreeInfo contains information about a git worktree
@@ -31,82 +32,42 @@ func NewGitClient() *GitClient {
 	}
 }
 
-// SetRepoPathProvider sets the function that provides the repository path
-func (g *GitClient) SetRepoPathProvider(provider func() string) {
-	g.getRepoPath = provider
+// SetCommitSigning makes commits and rebases sign the commits they create with the key.
+// nil disables signing.
+func (g *GitClient) SetCommitSigning(signing *CommitSigning) {
+	g.signing = signing
 }
 
-// setWorkDir sets the working directory for a git command if a repo path is configured
-func (g *GitClient) setWorkDir(cmd *exec.Cmd) {
-	if repoPath := g.getRepoPath(); repoPath != "" {
-		cmd.Dir = repoPath
-	}
+// gitCommand builds a git command that creates commits, signing them when configured
+func (g *GitClient) gitCommand(args ...string) *exec.Cmd {
+	return exec.Command("git", append(g.signing.configArgs(), args...)...)
 }
 
-// isRecoverableGHError checks if an error is a recoverable GitHub API error that should be retried
-func isRecoverableGHError(err error, output string) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-	outputStr := strings.ToLower(output)
-
-	recoverablePatterns := []string{
-		"timeout",
-		"i/o timeout",
-		"connection timeout",
-		"dial tcp",
-		"context deadline exceeded",
-	}
-
-	for _, pattern := range recoverablePatterns {
-		if strings.Contains(errStr, pattern) || strings.Contains(outputStr, pattern) {
-			return true
-		}
+// commitArgs builds the arguments of `git commit` with the message and trailers
+func commitArgs(message string, trailers []string) []string {
+	args := []string{"commit", "-m", message}
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
 	}
-
-	return false
+	return args
 }
 
-// executeWithRetry executes a GitHub CLI command with exponential backoff for recoverable errors
-func (g *GitClient) executeWithRetry(cmd *exec.Cmd, operationName string) ([]byte, error) {
-	var output []byte
-	var err error
-
-	retryBackoff := backoff.NewExponentialBackOff()
-	retryBackoff.InitialInterval = 2 * time.Second
-	retryBackoff.MaxInterval = 30 * time.Second
-	retryBackoff.MaxElapsedTime = 2 * time.Minute
-	retryBackoff.Multiplier = 2
-
-	// Preserve original working directory for retries
-	originalDir := cmd.Dir
-
-	retryOperation := func() error {
-		output, err = cmd.CombinedOutput()
-
-		if err != nil && isRecoverableGHError(err, string(output)) {
-			log.Info("⏳ GitHub API recoverable error detected for %s, retrying...", operationName)
-			// Reset command for retry, preserving working directory
-			cmd = exec.Command(cmd.Args[0], cmd.Args[1:]...)
-			cmd.Dir = originalDir
-			return err // This will trigger a retry
-		}
+// SetRepoPathProvider sets the function that provides the repository path
+func (g *GitClient) SetRepoPathProvider(provider func() string) {
+	g.getRepoPath = provider
+}
 
-		return nil // Success or non-recoverable error, stop retrying
-	}
+// SetCodeHost makes every pull request operation use host instead of the one detected
+// from the origin remote
+func (g *GitClient) SetCodeHost(host CodeHost) {
+	g.codeHost = host
+}
 
-	retryErr := backoff.Retry(retryOperation, retryBackoff)
-	if retryErr != nil {
-		// If we still have the original error, use it
-		if err != nil {
-			return output, err
-		}
-		return output, retryErr
+// setWorkDir sets the working directory for a git command if a repo path is configured
+func (g *GitClient) setWorkDir(cmd *exec.Cmd) {
+	if repoPath := g.getRepoPath(); repoPath != "" {
+		cmd.Dir = repoPath
 	}
-
-	return output, err
 }
 
 func (g *GitClient) CheckoutBranch(branchName string) error {
@@ -299,10 +260,11 @@ func (g *GitClient) AddAll() error {
 	return nil
 }
 
-func (g *GitClient) Commit(message string) error {
+// Commit commits the staged changes. trailers are added to the message as "Key: value" lines.
+func (g *GitClient) Commit(message string, trailers []string) error {
 	log.Info("📋 Starting to commit with message: %s", message)
 
-	cmd := exec.Command("git", "commit", "-m", message)
+	cmd := g.gitCommand(commitArgs(message, trailers)...)
 	g.setWorkDir(cmd)
 	output, err := cmd.CombinedOutput()
 
@@ -333,54 +295,17 @@ func (g *GitClient) PushBranch(branchName string) error {
 	return nil
 }
 
-func (g *GitClient) CreatePullRequest(title, body, baseBranch string) (string, error) {
-	log.Info("📋 Starting to create pull request: %s", title)
-
-	// Validate and truncate PR title if necessary
-	validationResult := ValidateAndTruncatePRTitle(title, body)
-
-	// If title was truncated, prepend overflow to description
-	finalBody := body
-	if validationResult.DescriptionPrefix != "" {
-		log.Warn("⚠️ PR title exceeded %d characters, truncating to '%s' and moving overflow to description",
-			MaxGitHubPRTitleLength, validationResult.Title)
-		finalBody = validationResult.DescriptionPrefix + body
-	}
-
-	cmd := exec.Command("gh", "pr", "create", "--title", validationResult.Title, "--body", finalBody, "--base", baseBranch)
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "create pull request")
-
-	if err != nil {
-		log.Error("❌ GitHub PR creation failed for title '%s': %v\nOutput: %s", validationResult.Title, err, string(output))
-		return "", fmt.Errorf("github pr creation failed: %w\nOutput: %s", err, string(output))
-	}
-
-	// The output contains the PR URL
-	prURL := strings.TrimSpace(string(output))
-
-	log.Info("✅ Successfully created pull request: %s", validationResult.Title)
-	log.Info("📋 Completed successfully - created pull request")
-	return prURL, nil
+func (g *GitClient) CreatePullRequest(title, body, baseBranch string, draft bool) (string, error) {
+	return g.createPullRequest(g.getRepoPath(), title, body, baseBranch, draft)
 }
 
 func (g *GitClient) GetPRURL(branchName string) (string, error) {
-	log.Info("📋 Starting to get PR URL for branch: %s", branchName)
-
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "url", "--jq", ".url")
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "get PR URL")
-
-	if err != nil {
-		log.Error("❌ Failed to get PR URL for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return "", fmt.Errorf("failed to get PR URL: %w\nOutput: %s", err, string(output))
-	}
-
-	prURL := strings.TrimSpace(string(output))
+	return g.getPRURL(g.getRepoPath(), branchName)
+}
 
-	log.Info("✅ Successfully got PR URL: %s", prURL)
-	log.Info("📋 Completed successfully - got PR URL")
-	return prURL, nil
+// GetPullRequest returns the latest pull request for branchName, preferring an open one
+func (g *GitClient) GetPullRequest(branchName string) (*PullRequest, error) {
+	return g.getPullRequest(g.getRepoPath(), branchName)
 }
 
 func (g *GitClient) GetCurrentBranch() (string, error) {
@@ -542,32 +467,15 @@ func (g *GitClient) HasRemoteRepository() error {
 	return nil
 }
 
-func (g *GitClient) IsGitHubCLIAvailable() error {
-	log.Info("📋 Starting to check GitHub CLI availability")
-
-	// Check if gh command exists
-	cmd := exec.Command("gh", "--version")
-	g.setWorkDir(cmd)
-	output, err := cmd.CombinedOutput()
-
-	if err != nil {
-		log.Error("❌ GitHub CLI not found: %v\nOutput: %s", err, string(output))
-		return fmt.Errorf("github cli (gh) not found: %w\nOutput: %s", err, string(output))
-	}
-
-	// Check if gh is authenticated
-	cmd = exec.Command("gh", "auth", "status")
-	g.setWorkDir(cmd)
-	output, err = cmd.CombinedOutput()
-
+// ValidateCodeHostAccess checks that pull requests can be managed on the host of the origin
+// remote: an authenticated GitHub CLI for GitHub, a working GITLAB_TOKEN for GitLab
+func (g *GitClient) ValidateCodeHostAccess() error {
+	dir := g.getRepoPath()
+	host, err := g.codeHostForDir(dir)
 	if err != nil {
-		log.Error("❌ GitHub CLI not authenticated: %v\nOutput: %s", err, string(output))
-		return fmt.Errorf("github cli not authenticated (run 'gh auth login'): %w\nOutput: %s", err, string(output))
+		return err
 	}
-
-	log.Info("✅ GitHub CLI is available and authenticated")
-	log.Info("📋 Completed successfully - validated GitHub CLI")
-	return nil
+	return host.CheckAccess(dir)
 }
 
 func (g *GitClient) HasUncommittedChanges() (bool, error) {
@@ -598,30 +506,7 @@ func (g *GitClient) HasUncommittedChanges() (bool, error) {
 }
 
 func (g *GitClient) HasExistingPR(branchName string) (bool, error) {
-	log.Info("📋 Starting to check for existing PR for branch: %s", branchName)
-
-	// Use GitHub CLI to list PRs for the current branch
-	cmd := exec.Command("gh", "pr", "list", "--head", branchName, "--json", "number")
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "check existing PR")
-
-	if err != nil {
-		log.Error("❌ Failed to check for existing PR for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return false, fmt.Errorf("failed to check for existing PR: %w\nOutput: %s", err, string(output))
-	}
-
-	// If output is "[]" (empty JSON array), no PRs exist for this branch
-	outputStr := strings.TrimSpace(string(output))
-	hasPR := outputStr != "[]" && outputStr != ""
-
-	if hasPR {
-		log.Info("✅ Found existing PR for branch: %s", branchName)
-	} else {
-		log.Info("✅ No existing PR found for branch: %s", branchName)
-	}
-
-	log.Info("📋 Completed successfully - checked for existing PR")
-	return hasPR, nil
+	return g.hasExistingPR(g.getRepoPath(), branchName)
 }
 
 func (g *GitClient) GetLatestCommitHash() (string, error) {
@@ -675,11 +560,10 @@ func (g *GitClient) GetRemoteURL() (string, error) {
 
 	remoteURL := strings.TrimSpace(string(output))
 
-	// Convert SSH URL to HTTPS if needed for GitHub links
-	if strings.HasPrefix(remoteURL, "git@github.com:") {
+	// Convert SSH URL to HTTPS if needed for GitHub and GitLab links
+	if location, err := ParseRemoteURL(remoteURL); err == nil && isSSHRemoteURL(remoteURL) {
 		// Convert git@github.com:owner/repo.git to https://github.com/owner/repo
-		remoteURL = strings.Replace(remoteURL, "git@github.com:", "https://github.com/", 1)
-		remoteURL = strings.TrimSuffix(remoteURL, ".git")
+		remoteURL = location.WebURL()
 	} else if strings.HasSuffix(remoteURL, ".git") {
 		// Remove .git suffix from HTTPS URLs
 		remoteURL = strings.TrimSuffix(remoteURL, ".git")
@@ -723,116 +607,38 @@ func (g *GitClient) GetRepositoryIdentifier() (string, error) {
 }
 
 func (g *GitClient) GetPRDescription(branchName string) (string, error) {
-	log.Info("📋 Starting to get PR description for branch: %s", branchName)
-
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "body", "--jq", ".body")
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "get PR description")
-
-	if err != nil {
-		log.Error("❌ Failed to get PR description for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return "", fmt.Errorf("failed to get PR description: %w\nOutput: %s", err, string(output))
-	}
-
-	description := strings.TrimSpace(string(output))
-	log.Info("✅ Successfully got PR description")
-	log.Info("📋 Completed successfully - got PR description")
-	return description, nil
+	return g.getPRDescription(g.getRepoPath(), branchName)
 }
 
 func (g *GitClient) UpdatePRDescription(branchName, newDescription string) error {
-	log.Info("📋 Starting to update PR description for branch: %s", branchName)
-
-	cmd := exec.Command("gh", "pr", "edit", branchName, "--body", newDescription)
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "update PR description")
-
-	if err != nil {
-		log.Error("❌ Failed to update PR description for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return fmt.Errorf("failed to update PR description: %w\nOutput: %s", err, string(output))
-	}
-
-	log.Info("✅ Successfully updated PR description")
-	log.Info("📋 Completed successfully - updated PR description")
-	return nil
+	return g.updatePRDescription(g.getRepoPath(), branchName, newDescription)
 }
 
 func (g *GitClient) GetPRTitle(branchName string) (string, error) {
-	log.Info("📋 Starting to get PR title for branch: %s", branchName)
-
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "title", "--jq", ".title")
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "get PR title")
-
-	if err != nil {
-		log.Error("❌ Failed to get PR title for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return "", fmt.Errorf("failed to get PR title: %w\nOutput: %s", err, string(output))
-	}
-
-	title := strings.TrimSpace(string(output))
-	log.Info("✅ Successfully got PR title: %s", title)
-	log.Info("📋 Completed successfully - got PR title")
-	return title, nil
+	return g.getPRTitle(g.getRepoPath(), branchName)
 }
 
 func (g *GitClient) UpdatePRTitle(branchName, newTitle string) error {
-	log.Info("📋 Starting to update PR title for branch: %s", branchName)
-
-	// Get current description first in case we need to prepend overflow
-	currentDescription, err := g.GetPRDescription(branchName)
-	if err != nil {
-		log.Warn("⚠️ Failed to get current PR description, continuing with title update only: %v", err)
-		currentDescription = ""
-	}
-
-	// Validate and truncate PR title if necessary
-	validationResult := ValidateAndTruncatePRTitle(newTitle, currentDescription)
-
-	// If title was truncated, prepend overflow to description
-	if validationResult.DescriptionPrefix != "" {
-		log.Warn("⚠️ PR title exceeded %d characters, truncating to '%s' and moving overflow to description",
-			MaxGitHubPRTitleLength, validationResult.Title)
-
-		newDescription := validationResult.DescriptionPrefix + currentDescription
-
-		// Update both title and description
-		if err := g.UpdatePRDescription(branchName, newDescription); err != nil {
-			log.Error("❌ Failed to update PR description with overflow text: %v", err)
-			return fmt.Errorf("failed to update PR description with overflow: %w", err)
-		}
-	}
-
-	// Update the title
-	cmd := exec.Command("gh", "pr", "edit", branchName, "--title", validationResult.Title)
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "update PR title")
-
-	if err != nil {
-		log.Error("❌ Failed to update PR title for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return fmt.Errorf("failed to update PR title: %w\nOutput: %s", err, string(output))
-	}
-
-	log.Info("✅ Successfully updated PR title")
-	log.Info("📋 Completed successfully - updated PR title")
-	return nil
+	return g.updatePRTitle(g.getRepoPath(), branchName, newTitle)
 }
 
 func (g *GitClient) GetPRState(branchName string) (string, error) {
 	log.Info("📋 Starting to get PR state for branch: %s", branchName)
 
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "state", "--jq", ".state")
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "get PR state")
-
+	dir := g.getRepoPath()
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return "", err
+	}
+	pr, err := host.GetPullRequestForBranch(dir, branchName)
 	if err != nil {
-		log.Error("❌ Failed to get PR state for branch %s: %v\nOutput: %s", branchName, err, string(output))
-		return "", fmt.Errorf("failed to get PR state: %w\nOutput: %s", err, string(output))
+		log.Error("❌ Failed to get PR state for branch %s: %v", branchName, err)
+		return "", fmt.Errorf("failed to get PR state: %w", err)
 	}
 
-	state := strings.TrimSpace(string(output))
-	log.Info("✅ Retrieved PR state: %s", state)
+	log.Info("✅ Retrieved PR state: %s", pr.State)
 	log.Info("📋 Completed successfully - got PR state")
-	return strings.ToLower(state), nil
+	return pr.State, nil
 }
 
 func (g *GitClient) ExtractPRIDFromURL(prURL string) string {
@@ -852,19 +658,105 @@ func (g *GitClient) ExtractPRIDFromURL(prURL string) string {
 func (g *GitClient) GetPRStateByID(prID string) (string, error) {
 	log.Info("📋 Starting to get PR state by ID: %s", prID)
 
-	cmd := exec.Command("gh", "pr", "view", prID, "--json", "state", "--jq", ".state")
-	g.setWorkDir(cmd)
-	output, err := g.executeWithRetry(cmd, "get PR state by ID")
-
+	dir := g.getRepoPath()
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return "", err
+	}
+	pr, err := host.GetPullRequestByID(dir, prID)
 	if err != nil {
-		log.Error("❌ Failed to get PR state for PR ID %s: %v\nOutput: %s", prID, err, string(output))
-		return "", fmt.Errorf("failed to get PR state by ID: %w\nOutput: %s", err, string(output))
+		log.Error("❌ Failed to get PR state for PR ID %s: %v", prID, err)
+		return "", fmt.Errorf("failed to get PR state by ID: %w", err)
 	}
 
-	state := strings.TrimSpace(string(output))
-	log.Info("✅ Retrieved PR state by ID: %s", state)
+	log.Info("✅ Retrieved PR state by ID: %s", pr.State)
 	log.Info("📋 Completed successfully - got PR state by ID")
-	return strings.ToLower(state), nil
+	return pr.State, nil
+}
+
+// GetPRChecksByID returns the CI checks of the head commit of the pull request
+func (g *GitClient) GetPRChecksByID(prID string) (*PullRequestChecks, error) {
+	dir := g.getRepoPath()
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return host.GetPullRequestChecks(dir, prID)
+}
+
+// MarkPRReadyByID marks a draft pull request as ready for review
+func (g *GitClient) MarkPRReadyByID(prID string) error {
+	dir := g.getRepoPath()
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return err
+	}
+	return host.MarkPullRequestReady(dir, prID)
+}
+
+// AddPRMetadataByID adds labels, reviewers and assignees to the pull request
+func (g *GitClient) AddPRMetadataByID(prID string, metadata PullRequestMetadata) error {
+	dir := g.getRepoPath()
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return err
+	}
+	editor, ok := host.(PullRequestMetadataEditor)
+	if !ok {
+		return fmt.Errorf("%s does not support adding labels, reviewers and assignees", host.Kind())
+	}
+	return editor.AddPullRequestMetadata(dir, prID, metadata)
+}
+
+// GetPRReviewsByID returns the reviews and review threads of the pull request, or nil if the
+// code host has no review threads eksecd can answer
+func (g *GitClient) GetPRReviewsByID(prID string) (*PullRequestReviews, error) {
+	dir := g.getRepoPath()
+	host, err := g.reviewThreadHostForDir(dir)
+	if err != nil || host == nil {
+		return nil, err
+	}
+	return host.GetPullRequestReviews(dir, prID)
+}
+
+// ReplyToPRReviewThread adds a reply to a pull request review thread
+func (g *GitClient) ReplyToPRReviewThread(threadID, body string) error {
+	dir := g.getRepoPath()
+	host, err := g.reviewThreadHostForDir(dir)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("code host does not support review threads")
+	}
+	return host.ReplyToReviewThread(dir, threadID, body)
+}
+
+// ResolvePRReviewThread marks a pull request review thread as resolved
+func (g *GitClient) ResolvePRReviewThread(threadID string) error {
+	dir := g.getRepoPath()
+	host, err := g.reviewThreadHostForDir(dir)
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		return fmt.Errorf("code host does not support review threads")
+	}
+	return host.ResolveReviewThread(dir, threadID)
+}
+
+// reviewThreadHostForDir returns the code host as a ReviewThreadHost, or nil if it is not one
+func (g *GitClient) reviewThreadHostForDir(dir string) (ReviewThreadHost, error) {
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	reviewHost, ok := host.(ReviewThreadHost)
+	if !ok {
+		log.Info("ℹ️ %s does not support review threads", host.Kind())
+		return nil, nil
+	}
+	return reviewHost, nil
 }
 
 func (g *GitClient) GetLocalBranches() ([]string, error) {
@@ -927,6 +819,34 @@ func (g *GitClient) RemoteBranchExists(branchName string) (bool, error) {
 	return exists, nil
 }
 
+// ListRemoteBranchesMergedInto lists the remote-tracking branches whose tip is reachable
+// from ref (git branch -r --merged <ref>), e.g. "origin/eksecd/feature"
+func (g *GitClient) ListRemoteBranchesMergedInto(ref string) ([]string, error) {
+	log.Info("📋 Starting to list remote branches merged into %s", ref)
+
+	cmd := exec.Command("git", "branch", "-r", "--merged", ref)
+	g.setWorkDir(cmd)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to list remote branches merged into %s: %v\nOutput: %s", ref, err, string(output))
+		return nil, fmt.Errorf("failed to list remote branches merged into %s: %w\nOutput: %s", ref, err, string(output))
+	}
+
+	var branches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		branch := strings.TrimSpace(line)
+		// Skip empty lines and symbolic refs like "origin/HEAD -> origin/main"
+		if branch == "" || strings.Contains(branch, " -> ") {
+			continue
+		}
+		branches = append(branches, branch)
+	}
+
+	log.Info("✅ Found %d remote branches merged into %s", len(branches), ref)
+	return branches, nil
+}
+
 func (g *GitClient) CheckoutRemoteBranch(branchName string) error {
 	log.Info("📋 Starting to checkout remote branch: %s", branchName)
 
@@ -974,6 +894,20 @@ func (g *GitClient) DeleteLocalBranch(branchName string) error {
 	return nil
 }
 
+// CheckBranchName returns an error unless git accepts branchName as a branch name
+func (g *GitClient) CheckBranchName(branchName string) error {
+	// --branch would expand @{-N} to a previously checked out branch instead of rejecting it
+	if branchName == "" || strings.HasPrefix(branchName, "-") || strings.Contains(branchName, "@{") {
+		return fmt.Errorf("invalid branch name %q", branchName)
+	}
+	cmd := exec.Command("git", "check-ref-format", "--branch", branchName)
+	g.setWorkDir(cmd)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("invalid branch name %q: %w\nOutput: %s", branchName, err, string(output))
+	}
+	return nil
+}
+
 func (g *GitClient) ValidateRemoteAccess() error {
 	log.Info("📋 Starting to validate remote repository access")
 
@@ -1187,32 +1121,10 @@ func (g *GitClient) FetchOrigin() error {
 	return nil
 }
 
-// FindPRTemplate searches for GitHub PR template in standard locations
+// FindPRTemplate searches for the pull request template of the code host in the repository
 // Returns the template content if found, empty string otherwise
 func (g *GitClient) FindPRTemplate() (string, error) {
-	log.Info("🔍 Searching for GitHub PR template")
-
-	// Standard locations per GitHub docs
-	// https://docs.github.com/en/communities/using-templates-to-encourage-useful-issues-and-pull-requests/creating-a-pull-request-template-for-your-repository
-	templatePaths := []string{
-		"pull_request_template.md",
-		"PULL_REQUEST_TEMPLATE.md",
-		".github/pull_request_template.md",
-		".github/PULL_REQUEST_TEMPLATE.md",
-		"docs/pull_request_template.md",
-		"docs/PULL_REQUEST_TEMPLATE.md",
-		".github/PULL_REQUEST_TEMPLATE/pull_request_template.md",
-	}
-
-	for _, path := range templatePaths {
-		if content, err := os.ReadFile(path); err == nil {
-			log.Info("✅ Found PR template at: %s", path)
-			return strings.TrimSpace(string(content)), nil
-		}
-	}
-
-	log.Info("ℹ️ No PR template found in standard locations")
-	return "", nil
+	return g.findPRTemplate(g.getRepoPath())
 }
 
 // =============================================================================
@@ -1478,11 +1390,12 @@ func (g *GitClient) AddAllInWorktree(worktreePath string) error {
 	return nil
 }
 
-// CommitInWorktree commits changes in the specified worktree
-func (g *GitClient) CommitInWorktree(worktreePath, message string) error {
+// CommitInWorktree commits changes in the specified worktree. trailers are added to the
+// message as "Key: value" lines.
+func (g *GitClient) CommitInWorktree(worktreePath, message string, trailers []string) error {
 	log.Info("📋 Starting to commit in worktree: %s", worktreePath)
 
-	cmd := exec.Command("git", "commit", "-m", message)
+	cmd := g.gitCommand(commitArgs(message, trailers)...)
 	cmd.Dir = worktreePath
 	output, err := cmd.CombinedOutput()
 
@@ -1593,10 +1506,9 @@ func (g *GitClient) GetRemoteURLInWorktree(worktreePath string) (string, error)
 
 	remoteURL := strings.TrimSpace(string(output))
 
-	// Convert SSH URL to HTTPS if needed for GitHub links
-	if strings.HasPrefix(remoteURL, "git@github.com:") {
-		remoteURL = strings.Replace(remoteURL, "git@github.com:", "https://github.com/", 1)
-		remoteURL = strings.TrimSuffix(remoteURL, ".git")
+	// Convert SSH URL to HTTPS if needed for GitHub and GitLab links
+	if location, err := ParseRemoteURL(remoteURL); err == nil && isSSHRemoteURL(remoteURL) {
+		remoteURL = location.WebURL()
 	} else if strings.HasSuffix(remoteURL, ".git") {
 		remoteURL = strings.TrimSuffix(remoteURL, ".git")
 	}
@@ -1607,107 +1519,42 @@ func (g *GitClient) GetRemoteURLInWorktree(worktreePath string) (string, error)
 
 // HasExistingPRInWorktree checks if a PR already exists for a branch from the worktree context
 func (g *GitClient) HasExistingPRInWorktree(worktreePath, branchName string) (bool, error) {
-	log.Info("📋 Starting to check for existing PR for branch %s in worktree: %s", branchName, worktreePath)
-
-	cmd := exec.Command("gh", "pr", "list", "--head", branchName, "--json", "number")
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "check existing PR")
-
-	if err != nil {
-		log.Error("❌ Failed to check for existing PR: %v\nOutput: %s", err, string(output))
-		return false, fmt.Errorf("failed to check for existing PR: %w\nOutput: %s", err, string(output))
-	}
-
-	outputStr := strings.TrimSpace(string(output))
-	hasPR := outputStr != "[]" && outputStr != ""
-
-	if hasPR {
-		log.Info("✅ Found existing PR for branch: %s", branchName)
-	} else {
-		log.Info("✅ No existing PR found for branch: %s", branchName)
-	}
-
-	return hasPR, nil
-}
-
-// executeWithRetryInDir is like executeWithRetry but with explicit working directory
-func (g *GitClient) executeWithRetryInDir(cmd *exec.Cmd, workDir, operationName string) ([]byte, error) {
-	var output []byte
-	var err error
-
-	retryBackoff := backoff.NewExponentialBackOff()
-	retryBackoff.InitialInterval = 2 * time.Second
-	retryBackoff.MaxInterval = 30 * time.Second
-	retryBackoff.MaxElapsedTime = 2 * time.Minute
-	retryBackoff.Multiplier = 2
-
-	retryOperation := func() error {
-		output, err = cmd.CombinedOutput()
-
-		if err != nil && isRecoverableGHError(err, string(output)) {
-			log.Info("⏳ GitHub API recoverable error detected for %s, retrying...", operationName)
-			cmd = exec.Command(cmd.Args[0], cmd.Args[1:]...)
-			cmd.Dir = workDir
-			return err
-		}
-
-		return nil
-	}
-
-	retryErr := backoff.Retry(retryOperation, retryBackoff)
-	if retryErr != nil {
-		if err != nil {
-			return output, err
-		}
-		return output, retryErr
-	}
-
-	return output, err
+	return g.hasExistingPR(worktreePath, branchName)
 }
 
 // CreatePullRequestInWorktree creates a pull request from the specified worktree context
-func (g *GitClient) CreatePullRequestInWorktree(worktreePath, title, body, baseBranch string) (string, error) {
-	log.Info("📋 Starting to create pull request from worktree: %s", worktreePath)
-
-	// Validate and truncate PR title if necessary
-	validationResult := ValidateAndTruncatePRTitle(title, body)
-
-	finalBody := body
-	if validationResult.DescriptionPrefix != "" {
-		log.Warn("⚠️ PR title exceeded %d characters, truncating", MaxGitHubPRTitleLength)
-		finalBody = validationResult.DescriptionPrefix + body
-	}
-
-	cmd := exec.Command("gh", "pr", "create", "--title", validationResult.Title, "--body", finalBody, "--base", baseBranch)
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "create pull request")
-
-	if err != nil {
-		log.Error("❌ GitHub PR creation failed: %v\nOutput: %s", err, string(output))
-		return "", fmt.Errorf("github pr creation failed: %w\nOutput: %s", err, string(output))
-	}
-
-	prURL := strings.TrimSpace(string(output))
-	log.Info("✅ Successfully created pull request: %s", prURL)
-	return prURL, nil
+func (g *GitClient) CreatePullRequestInWorktree(worktreePath, title, body, baseBranch string, draft bool) (string, error) {
+	return g.createPullRequest(worktreePath, title, body, baseBranch, draft)
 }
 
 // GetPRURLInWorktree gets the PR URL for a branch from the worktree context
 func (g *GitClient) GetPRURLInWorktree(worktreePath, branchName string) (string, error) {
-	log.Info("📋 Starting to get PR URL for branch %s from worktree: %s", branchName, worktreePath)
+	return g.getPRURL(worktreePath, branchName)
+}
 
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "url", "--jq", ".url")
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "get PR URL")
+// GetPullRequestInWorktree returns the pull request for a branch from the worktree context
+func (g *GitClient) GetPullRequestInWorktree(worktreePath, branchName string) (*PullRequest, error) {
+	return g.getPullRequest(worktreePath, branchName)
+}
 
+// ListChangedFilesInWorktree lists the files changed on the worktree's branch since it
+// diverged from baseRef
+func (g *GitClient) ListChangedFilesInWorktree(worktreePath, baseRef string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", baseRef+"...HEAD")
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
 	if err != nil {
-		log.Error("❌ Failed to get PR URL: %v\nOutput: %s", err, string(output))
-		return "", fmt.Errorf("failed to get PR URL: %w\nOutput: %s", err, string(output))
+		log.Error("❌ Failed to list files changed since %s: %v\nOutput: %s", baseRef, err, string(output))
+		return nil, fmt.Errorf("failed to list files changed since %s: %w\nOutput: %s", baseRef, err, string(output))
 	}
 
-	prURL := strings.TrimSpace(string(output))
-	log.Info("✅ PR URL: %s", prURL)
-	return prURL, nil
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
 }
 
 // GetDefaultBranchInWorktree gets the default branch from the worktree context
@@ -1743,116 +1590,27 @@ func (g *GitClient) GetDefaultBranchInWorktree(worktreePath string) (string, err
 
 // GetPRTitleInWorktree gets the PR title for a branch from the worktree context
 func (g *GitClient) GetPRTitleInWorktree(worktreePath, branchName string) (string, error) {
-	log.Info("📋 Starting to get PR title for branch %s from worktree: %s", branchName, worktreePath)
-
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "title", "--jq", ".title")
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "get PR title")
-
-	if err != nil {
-		log.Error("❌ Failed to get PR title: %v\nOutput: %s", err, string(output))
-		return "", fmt.Errorf("failed to get PR title: %w\nOutput: %s", err, string(output))
-	}
-
-	title := strings.TrimSpace(string(output))
-	log.Info("✅ PR title: %s", title)
-	return title, nil
+	return g.getPRTitle(worktreePath, branchName)
 }
 
 // GetPRDescriptionInWorktree gets the PR description for a branch from the worktree context
 func (g *GitClient) GetPRDescriptionInWorktree(worktreePath, branchName string) (string, error) {
-	log.Info("📋 Starting to get PR description for branch %s from worktree: %s", branchName, worktreePath)
-
-	cmd := exec.Command("gh", "pr", "view", branchName, "--json", "body", "--jq", ".body")
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "get PR description")
-
-	if err != nil {
-		log.Error("❌ Failed to get PR description: %v\nOutput: %s", err, string(output))
-		return "", fmt.Errorf("failed to get PR description: %w\nOutput: %s", err, string(output))
-	}
-
-	description := strings.TrimSpace(string(output))
-	log.Info("✅ Got PR description")
-	return description, nil
+	return g.getPRDescription(worktreePath, branchName)
 }
 
 // UpdatePRTitleInWorktree updates the PR title for a branch from the worktree context
 func (g *GitClient) UpdatePRTitleInWorktree(worktreePath, branchName, newTitle string) error {
-	log.Info("📋 Starting to update PR title for branch %s from worktree: %s", branchName, worktreePath)
-
-	// Get current description first in case we need to prepend overflow
-	currentDescription, err := g.GetPRDescriptionInWorktree(worktreePath, branchName)
-	if err != nil {
-		log.Warn("⚠️ Failed to get current PR description: %v", err)
-		currentDescription = ""
-	}
-
-	// Validate and truncate PR title if necessary
-	validationResult := ValidateAndTruncatePRTitle(newTitle, currentDescription)
-
-	if validationResult.DescriptionPrefix != "" {
-		log.Warn("⚠️ PR title exceeded %d characters, truncating", MaxGitHubPRTitleLength)
-		newDescription := validationResult.DescriptionPrefix + currentDescription
-		if err := g.UpdatePRDescriptionInWorktree(worktreePath, branchName, newDescription); err != nil {
-			return fmt.Errorf("failed to update PR description with overflow: %w", err)
-		}
-	}
-
-	cmd := exec.Command("gh", "pr", "edit", branchName, "--title", validationResult.Title)
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "update PR title")
-
-	if err != nil {
-		log.Error("❌ Failed to update PR title: %v\nOutput: %s", err, string(output))
-		return fmt.Errorf("failed to update PR title: %w\nOutput: %s", err, string(output))
-	}
-
-	log.Info("✅ Successfully updated PR title")
-	return nil
+	return g.updatePRTitle(worktreePath, branchName, newTitle)
 }
 
 // UpdatePRDescriptionInWorktree updates the PR description for a branch from the worktree context
 func (g *GitClient) UpdatePRDescriptionInWorktree(worktreePath, branchName, newDescription string) error {
-	log.Info("📋 Starting to update PR description for branch %s from worktree: %s", branchName, worktreePath)
-
-	cmd := exec.Command("gh", "pr", "edit", branchName, "--body", newDescription)
-	cmd.Dir = worktreePath
-	output, err := g.executeWithRetryInDir(cmd, worktreePath, "update PR description")
-
-	if err != nil {
-		log.Error("❌ Failed to update PR description: %v\nOutput: %s", err, string(output))
-		return fmt.Errorf("failed to update PR description: %w\nOutput: %s", err, string(output))
-	}
-
-	log.Info("✅ Successfully updated PR description")
-	return nil
+	return g.updatePRDescription(worktreePath, branchName, newDescription)
 }
 
-// FindPRTemplateInWorktree searches for GitHub PR template in the worktree
+// FindPRTemplateInWorktree searches for the pull request template of the code host in the worktree
 func (g *GitClient) FindPRTemplateInWorktree(worktreePath string) (string, error) {
-	log.Info("🔍 Searching for GitHub PR template in worktree: %s", worktreePath)
-
-	templatePaths := []string{
-		"pull_request_template.md",
-		"PULL_REQUEST_TEMPLATE.md",
-		".github/pull_request_template.md",
-		".github/PULL_REQUEST_TEMPLATE.md",
-		"docs/pull_request_template.md",
-		"docs/PULL_REQUEST_TEMPLATE.md",
-		".github/PULL_REQUEST_TEMPLATE/pull_request_template.md",
-	}
-
-	for _, path := range templatePaths {
-		fullPath := filepath.Join(worktreePath, path)
-		if content, err := os.ReadFile(fullPath); err == nil {
-			log.Info("✅ Found PR template at: %s", fullPath)
-			return strings.TrimSpace(string(content)), nil
-		}
-	}
-
-	log.Info("ℹ️ No PR template found in worktree")
-	return "", nil
+	return g.findPRTemplate(worktreePath)
 }
 
 // =============================================================================
@@ -1928,3 +1686,525 @@ func (g *GitClient) MoveWorktree(oldPath, newPath string) error {
 	log.Info("✅ Successfully moved worktree to %s", newPath)
 	return nil
 }
+
+// =============================================================================
+// Job Export and Import Support
+// =============================================================================
+
+// ResolveCommitInDir returns the commit hash ref points to in the repository or worktree at dir
+func (g *GitClient) ResolveCommitInDir(dir, ref string) (string, error) {
+	log.Info("📋 Starting to resolve %s in %s", ref, dir)
+
+	cmd := exec.Command("git", "rev-parse", "--verify", ref+"^{commit}")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to resolve %s: %v\nOutput: %s", ref, err, string(output))
+		return "", fmt.Errorf("failed to resolve %s: %w\nOutput: %s", ref, err, string(output))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CountCommitsNotOnOrigin counts the commits of branchName that no origin ref contains
+func (g *GitClient) CountCommitsNotOnOrigin(dir, branchName string) (int, error) {
+	log.Info("📋 Starting to count commits of %s not on origin", branchName)
+
+	cmd := exec.Command("git", "rev-list", "--count", "refs/heads/"+branchName, "--not", "--remotes=origin")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to count commits not on origin: %v\nOutput: %s", err, string(output))
+		return 0, fmt.Errorf("failed to count commits not on origin: %w\nOutput: %s", err, string(output))
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse commit count %q: %w", strings.TrimSpace(string(output)), err)
+	}
+
+	log.Info("✅ Branch %s has %d commits not on origin", branchName, count)
+	return count, nil
+}
+
+// CreateBranchBundle writes a git bundle with the commits of branchName that are not on
+// origin. The repository importing it must have fetched origin first.
+func (g *GitClient) CreateBranchBundle(dir, branchName, bundlePath string) error {
+	log.Info("📋 Starting to bundle branch %s to %s", branchName, bundlePath)
+
+	cmd := exec.Command("git", "bundle", "create", bundlePath, "refs/heads/"+branchName, "--not", "--remotes=origin")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to create git bundle: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to create git bundle: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully bundled branch %s", branchName)
+	return nil
+}
+
+// DiffWorkingTree returns a binary patch of every uncommitted change in dir against HEAD,
+// untracked files included. A temporary index is used, so the real index is not touched.
+func (g *GitClient) DiffWorkingTree(dir string) ([]byte, error) {
+	log.Info("📋 Starting to diff working tree: %s", dir)
+
+	tmpDir, err := os.MkdirTemp("", "eksecd-index-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary index directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	indexEnv := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmpDir, "index"))
+
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "-A"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = indexEnv
+		if output, err := cmd.CombinedOutput(); err != nil {
+			log.Error("❌ Git %s failed: %v\nOutput: %s", args[0], err, string(output))
+			return nil, fmt.Errorf("git %s failed: %w\nOutput: %s", args[0], err, string(output))
+		}
+	}
+
+	cmd := exec.Command("git", "diff", "--cached", "--binary", "HEAD")
+	cmd.Dir = dir
+	cmd.Env = indexEnv
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		log.Error("❌ Git diff failed: %v\nOutput: %s", err, stderr.String())
+		return nil, fmt.Errorf("git diff failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	log.Info("✅ Diffed working tree (%d bytes)", len(output))
+	return output, nil
+}
+
+// FetchBranchFromBundle creates branchName in the repository from the same branch in a git bundle
+func (g *GitClient) FetchBranchFromBundle(bundlePath, branchName string) error {
+	log.Info("📋 Starting to fetch branch %s from bundle %s", branchName, bundlePath)
+
+	ref := "refs/heads/" + branchName
+	cmd := exec.Command("git", "fetch", bundlePath, ref+":"+ref)
+	g.setWorkDir(cmd)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to fetch branch from bundle: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to fetch branch from bundle: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully fetched branch %s from bundle", branchName)
+	return nil
+}
+
+// CreateBranchAt creates branchName at commit without checking it out
+func (g *GitClient) CreateBranchAt(branchName, commit string) error {
+	log.Info("📋 Starting to create branch %s at %s", branchName, commit)
+
+	cmd := exec.Command("git", "branch", branchName, commit)
+	g.setWorkDir(cmd)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to create branch %s: %v\nOutput: %s", branchName, err, string(output))
+		return fmt.Errorf("failed to create branch %s: %w\nOutput: %s", branchName, err, string(output))
+	}
+
+	log.Info("✅ Successfully created branch %s", branchName)
+	return nil
+}
+
+// AddWorktreeForBranch creates a worktree at worktreePath with the existing branchName checked out
+func (g *GitClient) AddWorktreeForBranch(worktreePath, branchName string) error {
+	log.Info("📋 Starting to add worktree at %s for existing branch %s", worktreePath, branchName)
+
+	cmd := exec.Command("git", "worktree", "add", worktreePath, branchName)
+	g.setWorkDir(cmd)
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to add worktree: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to add worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully added worktree at %s for branch %s", worktreePath, branchName)
+	return nil
+}
+
+// SetUpstreamInWorktree sets origin/<branchName> as the upstream of branchName
+func (g *GitClient) SetUpstreamInWorktree(worktreePath, branchName string) error {
+	log.Info("📋 Starting to set upstream of %s in worktree %s", branchName, worktreePath)
+
+	cmd := exec.Command("git", "branch", "--set-upstream-to=origin/"+branchName, branchName)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to set upstream: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to set upstream: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully set upstream of %s", branchName)
+	return nil
+}
+
+// ApplyPatchInWorktree applies a binary patch to the working tree of the worktree
+func (g *GitClient) ApplyPatchInWorktree(worktreePath, patchPath string) error {
+	log.Info("📋 Starting to apply patch %s in worktree %s", patchPath, worktreePath)
+
+	cmd := exec.Command("git", "apply", "--binary", "--whitespace=nowarn", patchPath)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to apply patch: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to apply patch: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully applied patch in worktree")
+	return nil
+}
+
+// =============================================================================
+// Code Host Operations
+// =============================================================================
+
+// codeHostForDir returns the code host of the origin remote of the repository at dir
+func (g *GitClient) codeHostForDir(dir string) (CodeHost, error) {
+	if g.codeHost != nil {
+		return g.codeHost, nil
+	}
+
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Error("❌ Failed to get remote URL to detect code host: %v\nOutput: %s", err, string(output))
+		return nil, fmt.Errorf("failed to get remote URL to detect code host: %w\nOutput: %s", err, string(output))
+	}
+
+	return NewCodeHostForRemote(strings.TrimSpace(string(output)))
+}
+
+// createPullRequest opens a pull request for the branch checked out in dir
+func (g *GitClient) createPullRequest(dir, title, body, baseBranch string, draft bool) (string, error) {
+	log.Info("📋 Starting to create pull request: %s", title)
+
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "branch", "--show-current")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Error("❌ Failed to get current branch: %v\nOutput: %s", err, string(output))
+		return "", fmt.Errorf("failed to get current branch: %w\nOutput: %s", err, string(output))
+	}
+	headBranch := strings.TrimSpace(string(output))
+	if headBranch == "" {
+		return "", fmt.Errorf("cannot create pull request from a detached HEAD")
+	}
+
+	// Validate and truncate PR title if necessary
+	validationResult := ValidateAndTruncateTitle(title, body, host.MaxTitleLength())
+
+	// If title was truncated, prepend overflow to description
+	finalBody := body
+	if validationResult.DescriptionPrefix != "" {
+		log.Warn("⚠️ PR title exceeded %d characters, truncating to '%s' and moving overflow to description",
+			host.MaxTitleLength(), validationResult.Title)
+		finalBody = validationResult.DescriptionPrefix + body
+	}
+
+	prURL, err := host.CreatePullRequest(dir, headBranch, baseBranch, validationResult.Title, finalBody, draft)
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("📋 Completed successfully - created pull request")
+	return prURL, nil
+}
+
+// getPRURL gets the URL of the pull request for branchName
+func (g *GitClient) getPRURL(dir, branchName string) (string, error) {
+	pr, err := g.getPullRequest(dir, branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR URL: %w", err)
+	}
+	return pr.URL, nil
+}
+
+// getPRTitle gets the title of the pull request for branchName
+func (g *GitClient) getPRTitle(dir, branchName string) (string, error) {
+	pr, err := g.getPullRequest(dir, branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR title: %w", err)
+	}
+	return strings.TrimSpace(pr.Title), nil
+}
+
+// getPRDescription gets the description of the pull request for branchName
+func (g *GitClient) getPRDescription(dir, branchName string) (string, error) {
+	pr, err := g.getPullRequest(dir, branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR description: %w", err)
+	}
+	return strings.TrimSpace(pr.Description), nil
+}
+
+// getPullRequest gets the pull request for branchName from the code host of dir
+func (g *GitClient) getPullRequest(dir, branchName string) (*PullRequest, error) {
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return host.GetPullRequestForBranch(dir, branchName)
+}
+
+// hasExistingPR checks if an open pull request exists for branchName
+func (g *GitClient) hasExistingPR(dir, branchName string) (bool, error) {
+	log.Info("📋 Starting to check for existing PR for branch: %s", branchName)
+
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return false, err
+	}
+	pullRequests, err := host.ListOpenPullRequests(dir, branchName)
+	if err != nil {
+		log.Error("❌ Failed to check for existing PR for branch %s: %v", branchName, err)
+		return false, fmt.Errorf("failed to check for existing PR: %w", err)
+	}
+
+	hasPR := len(pullRequests) > 0
+	if hasPR {
+		log.Info("✅ Found existing PR for branch: %s", branchName)
+	} else {
+		log.Info("✅ No existing PR found for branch: %s", branchName)
+	}
+	return hasPR, nil
+}
+
+// updatePRTitle updates the title of the pull request for branchName. A title over the
+// host's limit is truncated and the overflow is moved to the top of the description.
+func (g *GitClient) updatePRTitle(dir, branchName, newTitle string) error {
+	log.Info("📋 Starting to update PR title for branch: %s", branchName)
+
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// Get current description first in case we need to prepend overflow
+	currentDescription, err := g.getPRDescription(dir, branchName)
+	if err != nil {
+		log.Warn("⚠️ Failed to get current PR description, continuing with title update only: %v", err)
+		currentDescription = ""
+	}
+
+	validationResult := ValidateAndTruncateTitle(newTitle, currentDescription, host.MaxTitleLength())
+	if validationResult.DescriptionPrefix != "" {
+		log.Warn("⚠️ PR title exceeded %d characters, truncating to '%s' and moving overflow to description",
+			host.MaxTitleLength(), validationResult.Title)
+
+		newDescription := validationResult.DescriptionPrefix + currentDescription
+		if err := host.UpdatePullRequestDescription(dir, branchName, newDescription); err != nil {
+			log.Error("❌ Failed to update PR description with overflow text: %v", err)
+			return fmt.Errorf("failed to update PR description with overflow: %w", err)
+		}
+	}
+
+	if err := host.UpdatePullRequestTitle(dir, branchName, validationResult.Title); err != nil {
+		log.Error("❌ Failed to update PR title for branch %s: %v", branchName, err)
+		return fmt.Errorf("failed to update PR title: %w", err)
+	}
+
+	log.Info("✅ Successfully updated PR title")
+	log.Info("📋 Completed successfully - updated PR title")
+	return nil
+}
+
+// updatePRDescription updates the description of the pull request for branchName
+func (g *GitClient) updatePRDescription(dir, branchName, newDescription string) error {
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return err
+	}
+	if err := host.UpdatePullRequestDescription(dir, branchName, newDescription); err != nil {
+		log.Error("❌ Failed to update PR description for branch %s: %v", branchName, err)
+		return fmt.Errorf("failed to update PR description: %w", err)
+	}
+	return nil
+}
+
+// findPRTemplate returns the pull request template of the repository at dir
+func (g *GitClient) findPRTemplate(dir string) (string, error) {
+	host, err := g.codeHostForDir(dir)
+	if err != nil {
+		return "", err
+	}
+	return host.FindPullRequestTemplate(dir)
+}
+
+// =============================================================================
+// Rebase Support
+// =============================================================================
+
+// IsAncestorInWorktree reports whether ancestor is reachable from ref in the worktree
+func (g *GitClient) IsAncestorInWorktree(worktreePath, ancestor, ref string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, ref)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+
+	log.Error("❌ Failed to check if %s is an ancestor of %s: %v\nOutput: %s", ancestor, ref, err, string(output))
+	return false, fmt.Errorf("failed to check if %s is an ancestor of %s: %w\nOutput: %s", ancestor, ref, err, string(output))
+}
+
+// FetchBranchInWorktree updates origin/<branchName> from the worktree
+func (g *GitClient) FetchBranchInWorktree(worktreePath, branchName string) error {
+	log.Info("📋 Starting to fetch origin/%s in worktree: %s", branchName, worktreePath)
+
+	cmd := exec.Command("git", "fetch", "origin", branchName)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Git fetch failed in worktree: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("git fetch failed in worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully fetched origin/%s", branchName)
+	return nil
+}
+
+// RebaseInWorktree rebases the branch checked out in the worktree onto ref. Returns true
+// if the rebase stopped on a conflict; the rebase is then left in progress.
+func (g *GitClient) RebaseInWorktree(worktreePath, ref string) (bool, error) {
+	log.Info("📋 Starting to rebase worktree %s onto %s", worktreePath, ref)
+
+	cmd := g.gitCommand("rebase", ref)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+	return g.rebaseStepResult(worktreePath, "rebase", output, err)
+}
+
+// ContinueRebaseInWorktree stages all changes and continues the rebase in progress. Returns
+// true if the rebase stopped on a conflict in a later commit.
+func (g *GitClient) ContinueRebaseInWorktree(worktreePath string) (bool, error) {
+	log.Info("📋 Starting to continue rebase in worktree: %s", worktreePath)
+
+	if err := g.AddAllInWorktree(worktreePath); err != nil {
+		return false, err
+	}
+
+	// Keep the original commit messages instead of opening an editor
+	cmd := g.gitCommand("-c", "core.editor=true", "rebase", "--continue")
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+	return g.rebaseStepResult(worktreePath, "rebase --continue", output, err)
+}
+
+// rebaseStepResult turns the outcome of a rebase command into (stopped on conflict, error)
+func (g *GitClient) rebaseStepResult(worktreePath, operation string, output []byte, err error) (bool, error) {
+	if err == nil {
+		log.Info("✅ Successfully completed git %s in worktree: %s", operation, worktreePath)
+		return false, nil
+	}
+
+	inProgress, checkErr := g.IsRebaseInProgressInWorktree(worktreePath)
+	if checkErr == nil && inProgress {
+		log.Info("⚠️ git %s stopped on a conflict in worktree: %s", operation, worktreePath)
+		return true, nil
+	}
+
+	log.Error("❌ git %s failed in worktree: %v\nOutput: %s", operation, err, string(output))
+	return false, fmt.Errorf("git %s failed: %w\nOutput: %s", operation, err, string(output))
+}
+
+// IsRebaseInProgressInWorktree reports whether the worktree has a rebase in progress
+func (g *GitClient) IsRebaseInProgressInWorktree(worktreePath string) (bool, error) {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		cmd := exec.Command("git", "rev-parse", "--git-path", name)
+		cmd.Dir = worktreePath
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return false, fmt.Errorf("failed to locate %s: %w\nOutput: %s", name, err, string(output))
+		}
+
+		path := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(worktreePath, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AbortRebaseInWorktree aborts the rebase in progress, restoring the branch
+func (g *GitClient) AbortRebaseInWorktree(worktreePath string) error {
+	log.Info("📋 Starting to abort rebase in worktree: %s", worktreePath)
+
+	cmd := exec.Command("git", "rebase", "--abort")
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to abort rebase in worktree: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to abort rebase: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully aborted rebase in worktree: %s", worktreePath)
+	return nil
+}
+
+// ListConflictedFilesInWorktree lists the files with unresolved merge conflicts
+func (g *GitClient) ListConflictedFilesInWorktree(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Failed to list conflicted files in worktree: %v\nOutput: %s", err, string(output))
+		return nil, fmt.Errorf("failed to list conflicted files: %w\nOutput: %s", err, string(output))
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if file := strings.TrimSpace(line); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// ForcePushWithLeaseFromWorktree pushes the branch from the worktree, replacing the remote
+// branch only if it is still at the commit last fetched
+func (g *GitClient) ForcePushWithLeaseFromWorktree(worktreePath, branchName string) error {
+	log.Info("📋 Starting to force-push branch %s with lease from worktree: %s", branchName, worktreePath)
+
+	cmd := exec.Command("git", "push", "--force-with-lease", "origin", branchName)
+	cmd.Dir = worktreePath
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ Force-push with lease failed: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("git push --force-with-lease failed: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully force-pushed branch %s", branchName)
+	return nil
+}
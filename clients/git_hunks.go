@@ -0,0 +1,202 @@
+package clients
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"eksecd/core/log"
+)
+
+// DiffUnit is the smallest part of the uncommitted changes that can be committed on its
+// own: one hunk of a modified text file, or a whole file when it is added, deleted, binary
+// or only changed its mode.
+type DiffUnit struct {
+	ID         string // "U1", "U2", ... in diff order
+	Path       string
+	WholeFile  bool
+	FileHeader string // "diff --git" header lines of the file, empty for whole files
+	Hunk       string // "@@" line and body of the hunk, empty for whole files
+	Summary    string // Short description of a whole-file change
+}
+
+// ListDiffUnitsInDir splits the changes of the checkout in dir since HEAD, staged or not,
+// and its untracked files into diff units
+func (g *GitClient) ListDiffUnitsInDir(dir string) ([]DiffUnit, error) {
+	log.Info("📋 Starting to list diff units in: %s", dir)
+
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff", "--no-renames", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		log.Error("❌ Failed to diff changes: %v", err)
+		return nil, fmt.Errorf("failed to diff changes: %w", err)
+	}
+	units := parseDiffUnits(string(output))
+
+	cmd = exec.Command("git", "ls-files", "--others", "--exclude-standard", "-z")
+	cmd.Dir = dir
+	output, err = cmd.Output()
+	if err != nil {
+		log.Error("❌ Failed to list untracked files: %v", err)
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			units = append(units, DiffUnit{Path: path, WholeFile: true, Summary: "new file"})
+		}
+	}
+
+	for i := range units {
+		units[i].ID = fmt.Sprintf("U%d", i+1)
+	}
+	log.Info("✅ Found %d diff units", len(units))
+	return units, nil
+}
+
+// parseDiffUnits splits `git diff` output into one unit per hunk, or per file for changes
+// that cannot be applied hunk by hunk
+func parseDiffUnits(diff string) []DiffUnit {
+	var units []DiffUnit
+	for _, section := range splitDiffSections(diff) {
+		lines := strings.SplitAfter(section, "\n")
+		path := diffSectionPath(strings.TrimSuffix(lines[0], "\n"))
+
+		headerEnd := len(lines)
+		for i, line := range lines {
+			if strings.HasPrefix(line, "@@") {
+				headerEnd = i
+				break
+			}
+		}
+		header := strings.Join(lines[:headerEnd], "")
+
+		if summary := wholeFileChange(header, headerEnd == len(lines)); summary != "" {
+			units = append(units, DiffUnit{Path: path, WholeFile: true, Summary: summary})
+			continue
+		}
+
+		var hunk strings.Builder
+		for _, line := range lines[headerEnd:] {
+			if strings.HasPrefix(line, "@@") && hunk.Len() > 0 {
+				units = append(units, DiffUnit{Path: path, FileHeader: header, Hunk: hunk.String()})
+				hunk.Reset()
+			}
+			hunk.WriteString(line)
+		}
+		if hunk.Len() > 0 {
+			units = append(units, DiffUnit{Path: path, FileHeader: header, Hunk: hunk.String()})
+		}
+	}
+	return units
+}
+
+// splitDiffSections splits `git diff` output at each "diff --git" line
+func splitDiffSections(diff string) []string {
+	var sections []string
+	start := -1
+	for offset := 0; offset < len(diff); {
+		end := strings.IndexByte(diff[offset:], '\n')
+		if end < 0 {
+			end = len(diff)
+		} else {
+			end += offset + 1
+		}
+		if strings.HasPrefix(diff[offset:], "diff --git ") {
+			if start >= 0 {
+				sections = append(sections, diff[start:offset])
+			}
+			start = offset
+		}
+		offset = end
+	}
+	if start >= 0 {
+		sections = append(sections, diff[start:])
+	}
+	return sections
+}
+
+// diffSectionPath extracts the path from a "diff --git a/<path> b/<path>" line. Without
+// renames both paths are the same.
+func diffSectionPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasPrefix(rest, `"`) {
+		if quoted, err := strconv.QuotedPrefix(rest); err == nil {
+			if path, err := strconv.Unquote(quoted); err == nil {
+				return strings.TrimPrefix(path, "a/")
+			}
+		}
+	}
+	if len(rest) < 6 {
+		return rest
+	}
+	return strings.TrimPrefix(rest[:(len(rest)-1)/2], "a/")
+}
+
+// wholeFileChange describes a file change that must be committed as a whole, or returns ""
+// for a text modification that can be split into hunks
+func wholeFileChange(header string, noHunks bool) string {
+	switch {
+	case strings.Contains(header, "\nnew file mode"):
+		return "new file"
+	case strings.Contains(header, "\ndeleted file mode"):
+		return "deleted file"
+	case strings.Contains(header, "\nBinary files ") || strings.Contains(header, "\nGIT binary patch"):
+		return "binary file"
+	case noHunks:
+		return "mode change"
+	}
+	return ""
+}
+
+// StageDiffUnitsInDir stages the given units of the checkout in dir: whole files with
+// `git add` and hunks with `git apply --cached`. Hunks of a file must be in diff order.
+func (g *GitClient) StageDiffUnitsInDir(dir string, units []DiffUnit) error {
+	var paths []string
+	var patch strings.Builder
+	lastHeader := ""
+	for _, unit := range units {
+		if unit.WholeFile {
+			paths = append(paths, unit.Path)
+			continue
+		}
+		if unit.FileHeader != lastHeader {
+			patch.WriteString(unit.FileHeader)
+			lastHeader = unit.FileHeader
+		}
+		patch.WriteString(unit.Hunk)
+	}
+
+	if len(paths) > 0 {
+		cmd := exec.Command("git", append([]string{"add", "-A", "--"}, paths...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			log.Error("❌ Failed to stage files: %v\nOutput: %s", err, string(output))
+			return fmt.Errorf("failed to stage files: %w\nOutput: %s", err, string(output))
+		}
+	}
+
+	if patch.Len() > 0 {
+		cmd := exec.Command("git", "apply", "--cached", "-")
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(patch.String())
+		if output, err := cmd.CombinedOutput(); err != nil {
+			log.Error("❌ Failed to stage hunks: %v\nOutput: %s", err, string(output))
+			return fmt.Errorf("failed to stage hunks: %w\nOutput: %s", err, string(output))
+		}
+	}
+	return nil
+}
+
+// ResetIndexInDir unstages all changes of the checkout in dir, keeping the working tree
+func (g *GitClient) ResetIndexInDir(dir string) error {
+	cmd := exec.Command("git", "reset", "-q")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Error("❌ Failed to reset index: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("failed to reset index: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
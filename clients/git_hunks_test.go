@@ -0,0 +1,69 @@
+package clients
+
+import (
+	"testing"
+)
+
+func TestParseDiffUnits(t *testing.T) {
+	diff := `diff --git a/dir name/app.go b/dir name/app.go
+index 1111111..2222222 100644
+--- a/dir name/app.go
++++ b/dir name/app.go
+@@ -1,3 +1,3 @@
+ package app
+-var a = 1
++var a = 2
+@@ -20,3 +20,4 @@ func run() {
+ 	start()
++	wait()
+ }
+diff --git a/logo.png b/logo.png
+index 3333333..4444444 100644
+Binary files a/logo.png and b/logo.png differ
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 5555555..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-old
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"
+index 6666666..7777777 100644
+--- "a/caf\303\251.txt"
++++ "b/caf\303\251.txt"
+@@ -1 +1 @@
+-a
++b
+`
+	units := parseDiffUnits(diff)
+	expected := []struct {
+		path      string
+		wholeFile bool
+		summary   string
+	}{
+		{"dir name/app.go", false, ""},
+		{"dir name/app.go", false, ""},
+		{"logo.png", true, "binary file"},
+		{"old.txt", true, "deleted file"},
+		{"run.sh", true, "mode change"},
+		{"café.txt", false, ""},
+	}
+	if len(units) != len(expected) {
+		t.Fatalf("expected %d units, got %d: %+v", len(expected), len(units), units)
+	}
+	for i, e := range expected {
+		if units[i].Path != e.path || units[i].WholeFile != e.wholeFile || units[i].Summary != e.summary {
+			t.Errorf("unit %d: expected %+v, got %+v", i, e, units[i])
+		}
+	}
+
+	if units[1].Hunk != "@@ -20,3 +20,4 @@ func run() {\n \tstart()\n+\twait()\n }\n" {
+		t.Errorf("unexpected second hunk %q", units[1].Hunk)
+	}
+	if units[0].FileHeader != units[1].FileHeader || units[0].FileHeader[:len("diff --git")] != "diff --git" {
+		t.Errorf("expected both hunks to share the file header, got %q and %q", units[0].FileHeader, units[1].FileHeader)
+	}
+}
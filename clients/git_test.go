@@ -527,7 +527,7 @@ func TestWorktreeGitOperations(t *testing.T) {
 	}
 
 	// Test CommitInWorktree
-	if err := client.CommitInWorktree(worktreePath, "Test commit"); err != nil {
+	if err := client.CommitInWorktree(worktreePath, "Test commit", nil); err != nil {
 		t.Fatalf("Failed to commit in worktree: %v", err)
 	}
 
@@ -565,3 +565,68 @@ func TestWorktreeGitOperations(t *testing.T) {
 		t.Error("Expected uncommitted changes after modifying file")
 	}
 }
+
+func TestCommit_TrailersAndSSHSigning(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	repoPath, cleanup := setupTestGitRepo(t)
+	defer cleanup()
+
+	keyPath := filepath.Join(t.TempDir(), "signing_key")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to generate signing key: %v\n%s", err, output)
+	}
+	signing, err := NewCommitSigning(keyPath, "ssh")
+	if err != nil {
+		t.Fatalf("Failed to configure signing: %v", err)
+	}
+
+	client := NewGitClient()
+	client.SetRepoPathProvider(func() string { return repoPath })
+	client.SetCommitSigning(signing)
+
+	if err := os.WriteFile(filepath.Join(repoPath, "signed.txt"), []byte("signed"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := client.AddAll(); err != nil {
+		t.Fatalf("Failed to add changes: %v", err)
+	}
+	trailers := []string{"Co-authored-by: Jane Doe <jane@example.com>", "Eksecd-Thread: https://example.slack.com/archives/C1/p1"}
+	if err := client.Commit("Add signed file", trailers); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%B")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to get commit message: %v", err)
+	}
+	expected := "Add signed file\n\n" + strings.Join(trailers, "\n")
+	if strings.TrimSpace(string(output)) != expected {
+		t.Errorf("Expected commit message %q, got %q", expected, string(output))
+	}
+
+	cmd = exec.Command("git", "cat-file", "commit", "HEAD")
+	cmd.Dir = repoPath
+	output, err = cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if !strings.Contains(string(output), "-----BEGIN SSH SIGNATURE-----") {
+		t.Errorf("Expected an SSH signature on the commit, got:\n%s", output)
+	}
+}
+
+func TestNewCommitSigning(t *testing.T) {
+	if signing, err := NewCommitSigning("ABCDEF12", ""); err != nil || signing.Format != CommitSigningOpenPGP {
+		t.Errorf("Expected openpgp by default, got %+v, %v", signing, err)
+	}
+	if _, err := NewCommitSigning("ABCDEF12", "pgp2"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+	if _, err := NewCommitSigning(" ", "ssh"); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+}
@@ -0,0 +1,372 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"eksecd/core/log"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// githubPullRequestFields are the `gh pr` JSON fields parsed into a PullRequest
+const githubPullRequestFields = "number,url,title,body,state,isDraft"
+
+// GitHubHost manages pull requests on GitHub through the GitHub CLI (gh)
+type GitHubHost struct{}
+
+func NewGitHubHost() *GitHubHost {
+	return &GitHubHost{}
+}
+
+// githubPullRequest is a pull request as printed by `gh pr view --json`
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	IsDraft bool   `json:"isDraft"`
+}
+
+func (p githubPullRequest) toPullRequest() PullRequest {
+	return PullRequest{
+		ID:          fmt.Sprintf("%d", p.Number),
+		URL:         p.URL,
+		Title:       p.Title,
+		Description: p.Body,
+		State:       strings.ToLower(p.State),
+		Draft:       p.IsDraft,
+	}
+}
+
+func (h *GitHubHost) Kind() CodeHostKind {
+	return CodeHostGitHub
+}
+
+func (h *GitHubHost) MaxTitleLength() int {
+	return MaxGitHubPRTitleLength
+}
+
+func (h *GitHubHost) CheckAccess(dir string) error {
+	log.Info("📋 Starting to check GitHub CLI availability")
+
+	// Check if gh command exists
+	cmd := exec.Command("gh", "--version")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ GitHub CLI not found: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("github cli (gh) not found: %w\nOutput: %s", err, string(output))
+	}
+
+	// Check if gh is authenticated
+	cmd = exec.Command("gh", "auth", "status")
+	cmd.Dir = dir
+	output, err = cmd.CombinedOutput()
+
+	if err != nil {
+		log.Error("❌ GitHub CLI not authenticated: %v\nOutput: %s", err, string(output))
+		return fmt.Errorf("github cli not authenticated (run 'gh auth login'): %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ GitHub CLI is available and authenticated")
+	log.Info("📋 Completed successfully - validated GitHub CLI")
+	return nil
+}
+
+func (h *GitHubHost) CreatePullRequest(dir, headBranch, baseBranch, title, description string, draft bool) (string, error) {
+	log.Info("📋 Starting to create GitHub pull request: %s", title)
+
+	args := []string{"pr", "create", "--title", title, "--body", description, "--base", baseBranch, "--head", headBranch}
+	if draft {
+		args = append(args, "--draft")
+	}
+	cmd := exec.Command("gh", args...)
+	output, err := executeGHWithRetry(cmd, dir, "create pull request")
+
+	if err != nil {
+		log.Error("❌ GitHub PR creation failed for title '%s': %v\nOutput: %s", title, err, string(output))
+		return "", fmt.Errorf("github pr creation failed: %w\nOutput: %s", err, string(output))
+	}
+
+	// The output contains the PR URL
+	prURL := strings.TrimSpace(string(output))
+
+	log.Info("✅ Successfully created pull request: %s", prURL)
+	return prURL, nil
+}
+
+func (h *GitHubHost) ListOpenPullRequests(dir, branchName string) ([]PullRequest, error) {
+	log.Info("📋 Starting to list GitHub PRs for branch: %s", branchName)
+
+	cmd := exec.Command("gh", "pr", "list", "--head", branchName, "--json", githubPullRequestFields)
+	output, err := executeGHWithRetry(cmd, dir, "list pull requests")
+
+	if err != nil {
+		log.Error("❌ Failed to list PRs for branch %s: %v\nOutput: %s", branchName, err, string(output))
+		return nil, fmt.Errorf("failed to list pull requests: %w\nOutput: %s", err, string(output))
+	}
+
+	var listed []githubPullRequest
+	if err := json.Unmarshal(output, &listed); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request list: %w\nOutput: %s", err, string(output))
+	}
+
+	pullRequests := make([]PullRequest, 0, len(listed))
+	for _, pr := range listed {
+		pullRequests = append(pullRequests, pr.toPullRequest())
+	}
+	return pullRequests, nil
+}
+
+func (h *GitHubHost) GetPullRequestForBranch(dir, branchName string) (*PullRequest, error) {
+	return h.viewPullRequest(dir, branchName)
+}
+
+func (h *GitHubHost) GetPullRequestByID(dir, id string) (*PullRequest, error) {
+	return h.viewPullRequest(dir, id)
+}
+
+// viewPullRequest runs `gh pr view` for a branch name or PR number
+func (h *GitHubHost) viewPullRequest(dir, ref string) (*PullRequest, error) {
+	log.Info("📋 Starting to view GitHub PR: %s", ref)
+
+	cmd := exec.Command("gh", "pr", "view", ref, "--json", githubPullRequestFields)
+	output, err := executeGHWithRetry(cmd, dir, "view pull request")
+
+	if err != nil {
+		log.Error("❌ Failed to view PR %s: %v\nOutput: %s", ref, err, string(output))
+		return nil, fmt.Errorf("failed to view pull request %s: %w\nOutput: %s", ref, err, string(output))
+	}
+
+	var viewed githubPullRequest
+	if err := json.Unmarshal(output, &viewed); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request %s: %w\nOutput: %s", ref, err, string(output))
+	}
+
+	pr := viewed.toPullRequest()
+	log.Info("✅ Viewed PR #%s (%s)", pr.ID, pr.State)
+	return &pr, nil
+}
+
+func (h *GitHubHost) UpdatePullRequestTitle(dir, branchName, title string) error {
+	return h.editPullRequest(dir, branchName, "--title", title)
+}
+
+func (h *GitHubHost) UpdatePullRequestDescription(dir, branchName, description string) error {
+	return h.editPullRequest(dir, branchName, "--body", description)
+}
+
+func (h *GitHubHost) MarkPullRequestReady(dir, id string) error {
+	log.Info("📋 Starting to mark GitHub PR #%s ready for review", id)
+
+	cmd := exec.Command("gh", "pr", "ready", id)
+	output, err := executeGHWithRetry(cmd, dir, "mark pull request ready")
+	if err != nil {
+		log.Error("❌ Failed to mark PR #%s ready for review: %v\nOutput: %s", id, err, string(output))
+		return fmt.Errorf("failed to mark pull request %s ready for review: %w\nOutput: %s", id, err, string(output))
+	}
+
+	log.Info("✅ Marked PR #%s ready for review", id)
+	return nil
+}
+
+func (h *GitHubHost) AddPullRequestMetadata(dir, id string, metadata PullRequestMetadata) error {
+	log.Info("📋 Starting to add labels, reviewers and assignees to GitHub PR #%s", id)
+
+	// GitHub rejects review requests for the pull request's author
+	cmd := exec.Command("gh", "pr", "view", id, "--json", "author", "--jq", ".author.login")
+	output, err := executeGHWithRetry(cmd, dir, "get pull request author")
+	if err != nil {
+		log.Error("❌ Failed to get author of PR #%s: %v\nOutput: %s", id, err, string(output))
+		return fmt.Errorf("failed to get author of pull request %s: %w\nOutput: %s", id, err, string(output))
+	}
+	author := strings.TrimSpace(string(output))
+
+	var reviewers []string
+	for _, reviewer := range metadata.Reviewers {
+		if !strings.EqualFold(reviewer, author) {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+	reviewers = append(reviewers, metadata.TeamReviewers...)
+
+	args := []string{"pr", "edit", id}
+	if len(metadata.Labels) > 0 {
+		args = append(args, "--add-label", strings.Join(metadata.Labels, ","))
+	}
+	if len(reviewers) > 0 {
+		args = append(args, "--add-reviewer", strings.Join(reviewers, ","))
+	}
+	if len(metadata.Assignees) > 0 {
+		args = append(args, "--add-assignee", strings.Join(metadata.Assignees, ","))
+	}
+	if len(args) == 3 {
+		log.Info("ℹ️ Nothing to add to PR #%s", id)
+		return nil
+	}
+
+	cmd = exec.Command("gh", args...)
+	output, err = executeGHWithRetry(cmd, dir, "edit pull request")
+	if err != nil {
+		log.Error("❌ Failed to edit PR #%s: %v\nOutput: %s", id, err, string(output))
+		return fmt.Errorf("failed to add labels, reviewers and assignees to pull request %s: %w\nOutput: %s", id, err, string(output))
+	}
+
+	log.Info("✅ Added labels, reviewers and assignees to PR #%s", id)
+	return nil
+}
+
+// editPullRequest sets one field of the pull request for branchName with `gh pr edit`
+func (h *GitHubHost) editPullRequest(dir, branchName, flag, value string) error {
+	log.Info("📋 Starting to update GitHub PR for branch %s (%s)", branchName, flag)
+
+	cmd := exec.Command("gh", "pr", "edit", branchName, flag, value)
+	output, err := executeGHWithRetry(cmd, dir, "update pull request")
+
+	if err != nil {
+		log.Error("❌ Failed to update PR for branch %s: %v\nOutput: %s", branchName, err, string(output))
+		return fmt.Errorf("failed to update pull request: %w\nOutput: %s", err, string(output))
+	}
+
+	log.Info("✅ Successfully updated PR for branch %s", branchName)
+	return nil
+}
+
+// githubCheck is a check as printed by `gh pr checks --json`
+type githubCheck struct {
+	Name   string `json:"name"`
+	Bucket string `json:"bucket"`
+	Link   string `json:"link"`
+}
+
+func (h *GitHubHost) GetPullRequestChecks(dir, id string) (*PullRequestChecks, error) {
+	log.Info("📋 Starting to get GitHub checks for PR #%s", id)
+
+	cmd := exec.Command("gh", "pr", "view", id, "--json", "headRefOid", "--jq", ".headRefOid")
+	output, err := executeGHWithRetry(cmd, dir, "view pull request head")
+	if err != nil {
+		log.Error("❌ Failed to get head commit of PR #%s: %v\nOutput: %s", id, err, string(output))
+		return nil, fmt.Errorf("failed to get head commit of pull request %s: %w\nOutput: %s", id, err, string(output))
+	}
+	checks := &PullRequestChecks{HeadCommit: strings.TrimSpace(string(output))}
+
+	// Older gh versions exit non-zero while checks are failing or pending, even with --json
+	cmd = exec.Command("gh", "pr", "checks", id, "--json", "name,bucket,link")
+	output, err = executeGHWithRetry(cmd, dir, "list pull request checks")
+	var listed []githubCheck
+	if parseErr := json.Unmarshal(output, &listed); parseErr != nil {
+		if err == nil {
+			return nil, fmt.Errorf("failed to parse checks for pull request %s: %w\nOutput: %s", id, parseErr, string(output))
+		}
+		if strings.Contains(strings.ToLower(string(output)), "no checks reported") {
+			log.Info("ℹ️ No checks reported for PR #%s", id)
+			return checks, nil
+		}
+		log.Error("❌ Failed to list checks for PR #%s: %v\nOutput: %s", id, err, string(output))
+		return nil, fmt.Errorf("failed to list checks for pull request %s: %w\nOutput: %s", id, err, string(output))
+	}
+	for _, check := range listed {
+		checks.Checks = append(checks.Checks, PullRequestCheck(check))
+	}
+
+	log.Info("✅ Found %d checks for PR #%s", len(checks.Checks), id)
+	return checks, nil
+}
+
+// FindPullRequestTemplate searches the standard GitHub PR template locations
+// https://docs.github.com/en/communities/using-templates-to-encourage-useful-issues-and-pull-requests/creating-a-pull-request-template-for-your-repository
+func (h *GitHubHost) FindPullRequestTemplate(dir string) (string, error) {
+	log.Info("🔍 Searching for GitHub PR template")
+
+	templatePaths := []string{
+		"pull_request_template.md",
+		"PULL_REQUEST_TEMPLATE.md",
+		".github/pull_request_template.md",
+		".github/PULL_REQUEST_TEMPLATE.md",
+		"docs/pull_request_template.md",
+		"docs/PULL_REQUEST_TEMPLATE.md",
+		".github/PULL_REQUEST_TEMPLATE/pull_request_template.md",
+	}
+
+	for _, path := range templatePaths {
+		fullPath := filepath.Join(dir, path)
+		if content, err := os.ReadFile(fullPath); err == nil {
+			log.Info("✅ Found PR template at: %s", fullPath)
+			return strings.TrimSpace(string(content)), nil
+		}
+	}
+
+	log.Info("ℹ️ No PR template found in standard locations")
+	return "", nil
+}
+
+// executeGHWithRetry runs a GitHub CLI command in dir with exponential backoff for recoverable errors
+func executeGHWithRetry(cmd *exec.Cmd, dir, operationName string) ([]byte, error) {
+	var output []byte
+	var err error
+
+	retryBackoff := backoff.NewExponentialBackOff()
+	retryBackoff.InitialInterval = 2 * time.Second
+	retryBackoff.MaxInterval = 30 * time.Second
+	retryBackoff.MaxElapsedTime = 2 * time.Minute
+	retryBackoff.Multiplier = 2
+
+	cmd.Dir = dir
+	retryOperation := func() error {
+		output, err = cmd.CombinedOutput()
+
+		if err != nil && isRecoverableGHError(err, string(output)) {
+			log.Info("⏳ GitHub API recoverable error detected for %s, retrying...", operationName)
+			// Reset command for retry, preserving working directory
+			cmd = exec.Command(cmd.Args[0], cmd.Args[1:]...)
+			cmd.Dir = dir
+			return err // This will trigger a retry
+		}
+
+		return nil // Success or non-recoverable error, stop retrying
+	}
+
+	retryErr := backoff.Retry(retryOperation, retryBackoff)
+	if retryErr != nil {
+		if err != nil {
+			return output, err
+		}
+		return output, retryErr
+	}
+
+	return output, err
+}
+
+// isRecoverableGHError checks if an error is a recoverable GitHub API error that should be retried
+func isRecoverableGHError(err error, output string) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := strings.ToLower(err.Error())
+	outputStr := strings.ToLower(output)
+
+	recoverablePatterns := []string{
+		"timeout",
+		"i/o timeout",
+		"connection timeout",
+		"dial tcp",
+		"context deadline exceeded",
+	}
+
+	for _, pattern := range recoverablePatterns {
+		if strings.Contains(errStr, pattern) || strings.Contains(outputStr, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
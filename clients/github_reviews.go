@@ -0,0 +1,182 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"eksecd/core/log"
+)
+
+// githubReviewsQuery fetches the review threads and reviews of a pull request. {owner} and
+// {repo} are filled in by `gh api` from the repository in the working directory.
+const githubReviewsQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  viewer { login }
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100) {
+        nodes {
+          id
+          path
+          line
+          originalLine
+          isResolved
+          isOutdated
+          comments(first: 50) {
+            nodes { author { login } body diffHunk url createdAt }
+          }
+        }
+      }
+      reviews(first: 100) {
+        nodes { author { login } state body url submittedAt }
+      }
+    }
+  }
+}`
+
+// githubReviewsResponse is the response to githubReviewsQuery
+type githubReviewsResponse struct {
+	Data struct {
+		Viewer     githubActor `json:"viewer"`
+		Repository struct {
+			PullRequest *struct {
+				ReviewThreads struct {
+					Nodes []struct {
+						ID           string `json:"id"`
+						Path         string `json:"path"`
+						Line         *int   `json:"line"`
+						OriginalLine *int   `json:"originalLine"`
+						IsResolved   bool   `json:"isResolved"`
+						IsOutdated   bool   `json:"isOutdated"`
+						Comments     struct {
+							Nodes []struct {
+								Author    githubActor `json:"author"`
+								Body      string      `json:"body"`
+								DiffHunk  string      `json:"diffHunk"`
+								URL       string      `json:"url"`
+								CreatedAt time.Time   `json:"createdAt"`
+							} `json:"nodes"`
+						} `json:"comments"`
+					} `json:"nodes"`
+				} `json:"reviewThreads"`
+				Reviews struct {
+					Nodes []struct {
+						Author      githubActor `json:"author"`
+						State       string      `json:"state"`
+						Body        string      `json:"body"`
+						URL         string      `json:"url"`
+						SubmittedAt time.Time   `json:"submittedAt"`
+					} `json:"nodes"`
+				} `json:"reviews"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	} `json:"data"`
+}
+
+// githubActor is a user or bot; deleted accounts come back as null
+type githubActor struct {
+	Login string `json:"login"`
+}
+
+// parseGitHubReviews converts the response to githubReviewsQuery into PullRequestReviews
+func parseGitHubReviews(output []byte) (*PullRequestReviews, error) {
+	var response githubReviewsResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request reviews: %w", err)
+	}
+	pr := response.Data.Repository.PullRequest
+	if pr == nil {
+		return nil, fmt.Errorf("pull request not found in reviews response")
+	}
+
+	reviews := &PullRequestReviews{Viewer: response.Data.Viewer.Login}
+	for _, node := range pr.ReviewThreads.Nodes {
+		thread := ReviewThread{
+			ID:         node.ID,
+			Path:       node.Path,
+			IsResolved: node.IsResolved,
+			IsOutdated: node.IsOutdated,
+		}
+		if node.Line != nil {
+			thread.Line = *node.Line
+		} else if node.OriginalLine != nil {
+			thread.Line = *node.OriginalLine
+		}
+		for _, comment := range node.Comments.Nodes {
+			thread.Comments = append(thread.Comments, ReviewComment{
+				Author:    comment.Author.Login,
+				Body:      comment.Body,
+				DiffHunk:  comment.DiffHunk,
+				URL:       comment.URL,
+				CreatedAt: comment.CreatedAt,
+			})
+		}
+		reviews.Threads = append(reviews.Threads, thread)
+	}
+	for _, node := range pr.Reviews.Nodes {
+		reviews.Reviews = append(reviews.Reviews, Review{
+			Author:      node.Author.Login,
+			State:       node.State,
+			Body:        node.Body,
+			URL:         node.URL,
+			SubmittedAt: node.SubmittedAt,
+		})
+	}
+	return reviews, nil
+}
+
+func (h *GitHubHost) GetPullRequestReviews(dir, id string) (*PullRequestReviews, error) {
+	log.Info("📋 Starting to get GitHub reviews for PR #%s", id)
+
+	cmd := exec.Command("gh", "api", "graphql",
+		"-F", "owner={owner}", "-F", "repo={repo}", "-F", "number="+id,
+		"-f", "query="+githubReviewsQuery)
+	output, err := executeGHWithRetry(cmd, dir, "get pull request reviews")
+	if err != nil {
+		log.Error("❌ Failed to get reviews for PR #%s: %v\nOutput: %s", id, err, string(output))
+		return nil, fmt.Errorf("failed to get reviews for pull request %s: %w\nOutput: %s", id, err, string(output))
+	}
+
+	reviews, err := parseGitHubReviews(output)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("✅ Found %d review threads and %d reviews for PR #%s", len(reviews.Threads), len(reviews.Reviews), id)
+	return reviews, nil
+}
+
+func (h *GitHubHost) ReplyToReviewThread(dir, threadID, body string) error {
+	log.Info("📋 Starting to reply to review thread %s", threadID)
+
+	const mutation = `mutation($threadId: ID!, $body: String!) {
+  addPullRequestReviewThreadReply(input: {pullRequestReviewThreadId: $threadId, body: $body}) { comment { id } }
+}`
+	cmd := exec.Command("gh", "api", "graphql", "-f", "threadId="+threadID, "-f", "body="+body, "-f", "query="+mutation)
+	output, err := executeGHWithRetry(cmd, dir, "reply to review thread")
+	if err != nil {
+		log.Error("❌ Failed to reply to review thread %s: %v\nOutput: %s", threadID, err, string(output))
+		return fmt.Errorf("failed to reply to review thread %s: %w\nOutput: %s", threadID, err, string(output))
+	}
+
+	log.Info("✅ Replied to review thread %s", threadID)
+	return nil
+}
+
+func (h *GitHubHost) ResolveReviewThread(dir, threadID string) error {
+	log.Info("📋 Starting to resolve review thread %s", threadID)
+
+	const mutation = `mutation($threadId: ID!) {
+  resolveReviewThread(input: {threadId: $threadId}) { thread { isResolved } }
+}`
+	cmd := exec.Command("gh", "api", "graphql", "-f", "threadId="+threadID, "-f", "query="+mutation)
+	output, err := executeGHWithRetry(cmd, dir, "resolve review thread")
+	if err != nil {
+		log.Error("❌ Failed to resolve review thread %s: %v\nOutput: %s", threadID, err, string(output))
+		return fmt.Errorf("failed to resolve review thread %s: %w\nOutput: %s", threadID, err, string(output))
+	}
+
+	log.Info("✅ Resolved review thread %s", threadID)
+	return nil
+}
@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGitHubReviews(t *testing.T) {
+	output := []byte(`{"data": {
+  "viewer": {"login": "eksecd-bot"},
+  "repository": {"pullRequest": {
+    "reviewThreads": {"nodes": [
+      {"id": "PRRT_1", "path": "main.go", "line": 42, "originalLine": 40, "isResolved": false, "isOutdated": false,
+       "comments": {"nodes": [
+         {"author": {"login": "alice"}, "body": "Handle the error", "diffHunk": "@@ -1,3 +1,4 @@", "url": "https://github.com/o/r/pull/7#discussion_r1", "createdAt": "2026-10-01T10:00:00Z"},
+         {"author": null, "body": "+1", "diffHunk": "", "url": "https://github.com/o/r/pull/7#discussion_r2", "createdAt": "2026-10-01T11:00:00Z"}
+       ]}},
+      {"id": "PRRT_2", "path": "old.go", "line": null, "originalLine": 7, "isResolved": true, "isOutdated": true,
+       "comments": {"nodes": []}}
+    ]},
+    "reviews": {"nodes": [
+      {"author": {"login": "bob"}, "state": "CHANGES_REQUESTED", "body": "Needs tests", "url": "https://github.com/o/r/pull/7#pullrequestreview-1", "submittedAt": "2026-10-01T12:00:00Z"}
+    ]}
+  }}
+}}`)
+
+	reviews, err := parseGitHubReviews(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reviews.Viewer != "eksecd-bot" {
+		t.Errorf("expected viewer eksecd-bot, got %q", reviews.Viewer)
+	}
+	if len(reviews.Threads) != 2 {
+		t.Fatalf("expected 2 threads, got %d", len(reviews.Threads))
+	}
+
+	thread := reviews.Threads[0]
+	if thread.ID != "PRRT_1" || thread.Path != "main.go" || thread.Line != 42 || thread.IsResolved {
+		t.Errorf("unexpected thread %+v", thread)
+	}
+	if len(thread.Comments) != 2 || thread.Comments[0].Author != "alice" || thread.Comments[1].Author != "" {
+		t.Errorf("unexpected comments %+v", thread.Comments)
+	}
+	if !thread.Comments[0].CreatedAt.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected comment time %v", thread.Comments[0].CreatedAt)
+	}
+
+	// Outdated threads have no current line and fall back to the original one
+	if outdated := reviews.Threads[1]; outdated.Line != 7 || !outdated.IsOutdated || !outdated.IsResolved {
+		t.Errorf("unexpected outdated thread %+v", outdated)
+	}
+
+	if len(reviews.Reviews) != 1 || reviews.Reviews[0].Author != "bob" || reviews.Reviews[0].State != "CHANGES_REQUESTED" {
+		t.Errorf("unexpected reviews %+v", reviews.Reviews)
+	}
+}
+
+func TestParseGitHubReviews_MissingPullRequest(t *testing.T) {
+	output := []byte(`{"data": {"viewer": {"login": "eksecd-bot"}, "repository": {"pullRequest": null}}}`)
+	if _, err := parseGitHubReviews(output); err == nil {
+		t.Error("expected an error for a missing pull request")
+	}
+}
@@ -0,0 +1,460 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"eksecd/core/log"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	// MaxGitLabMRTitleLength is the maximum character limit for GitLab merge request titles
+	MaxGitLabMRTitleLength = 255
+
+	gitLabRequestTimeout = 30 * time.Second
+
+	// gitLabDraftPrefix marks a merge request as a draft; GitLab has no separate draft field
+	gitLabDraftPrefix = "Draft: "
+)
+
+// gitLabDraftTitlePattern matches the title prefixes GitLab treats as marking a draft
+var gitLabDraftTitlePattern = regexp.MustCompile(`(?i)^\s*(\[draft\]|\(draft\)|draft:)\s*`)
+
+// GitLabHost manages merge requests through the GitLab REST API (v4). It authenticates with
+// a personal, project or group access token that has the api scope.
+type GitLabHost struct {
+	apiURL      string // e.g. https://gitlab.com/api/v4
+	token       string
+	projectPath string // e.g. group/subgroup/project
+	httpClient  *http.Client
+}
+
+func NewGitLabHost(apiURL, token, projectPath string) *GitLabHost {
+	return &GitLabHost{
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		token:       token,
+		projectPath: projectPath,
+		httpClient:  &http.Client{Timeout: gitLabRequestTimeout},
+	}
+}
+
+// gitLabMergeRequest is a merge request as returned by the GitLab API
+type gitLabMergeRequest struct {
+	IID         int    `json:"iid"`
+	WebURL      string `json:"web_url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"` // opened, closed, locked or merged
+	SHA         string `json:"sha"`   // Head commit of the source branch
+	Draft       bool   `json:"draft"`
+}
+
+func (m gitLabMergeRequest) toPullRequest() PullRequest {
+	state := m.State
+	switch m.State {
+	case "opened", "locked":
+		state = PullRequestStateOpen
+	}
+	title := m.Title
+	if m.Draft {
+		title = gitLabDraftTitlePattern.ReplaceAllString(title, "")
+	}
+	return PullRequest{
+		ID:          strconv.Itoa(m.IID),
+		URL:         m.WebURL,
+		Title:       title,
+		Description: m.Description,
+		State:       state,
+		Draft:       m.Draft,
+	}
+}
+
+// gitLabDraftTitle prefixes title with the draft marker, shortening it to stay within the
+// title length limit
+func gitLabDraftTitle(title string) string {
+	runes := []rune(title)
+	if maxLength := MaxGitLabMRTitleLength - len(gitLabDraftPrefix); len(runes) > maxLength {
+		runes = runes[:maxLength]
+	}
+	return gitLabDraftPrefix + string(runes)
+}
+
+// gitLabAPIError is a non-2xx response from the GitLab API
+type gitLabAPIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *gitLabAPIError) Error() string {
+	return fmt.Sprintf("gitlab api %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+func (h *GitLabHost) Kind() CodeHostKind {
+	return CodeHostGitLab
+}
+
+func (h *GitLabHost) MaxTitleLength() int {
+	return MaxGitLabMRTitleLength
+}
+
+func (h *GitLabHost) CheckAccess(dir string) error {
+	log.Info("📋 Starting to check GitLab API access for %s", h.projectPath)
+
+	if h.token == "" {
+		log.Error("❌ GITLAB_TOKEN is not set")
+		return fmt.Errorf("GITLAB_TOKEN must be set to a token with the api scope to manage merge requests")
+	}
+
+	if err := h.do(http.MethodGet, h.projectURL(""), nil, nil); err != nil {
+		log.Error("❌ GitLab API access check failed: %v", err)
+		return fmt.Errorf("failed to access gitlab project %s: %w", h.projectPath, err)
+	}
+
+	log.Info("✅ GitLab API is reachable and authenticated")
+	log.Info("📋 Completed successfully - validated GitLab API access")
+	return nil
+}
+
+func (h *GitLabHost) CreatePullRequest(dir, headBranch, baseBranch, title, description string, draft bool) (string, error) {
+	log.Info("📋 Starting to create GitLab merge request: %s", title)
+
+	if draft {
+		title = gitLabDraftTitle(title)
+	}
+	request := map[string]string{
+		"source_branch": headBranch,
+		"target_branch": baseBranch,
+		"title":         title,
+		"description":   description,
+	}
+	var created gitLabMergeRequest
+	if err := h.do(http.MethodPost, h.projectURL("/merge_requests"), request, &created); err != nil {
+		// The branch already has an open merge request, e.g. from an earlier attempt whose
+		// response was lost
+		var apiErr *gitLabAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			if existing, listErr := h.listMergeRequests(headBranch, "opened"); listErr == nil && len(existing) > 0 {
+				log.Info("✅ Merge request for %s already exists: %s", headBranch, existing[0].WebURL)
+				return existing[0].WebURL, nil
+			}
+		}
+		log.Error("❌ GitLab MR creation failed for title '%s': %v", title, err)
+		return "", fmt.Errorf("gitlab merge request creation failed: %w", err)
+	}
+
+	log.Info("✅ Successfully created merge request: %s", created.WebURL)
+	return created.WebURL, nil
+}
+
+func (h *GitLabHost) ListOpenPullRequests(dir, branchName string) ([]PullRequest, error) {
+	log.Info("📋 Starting to list GitLab MRs for branch: %s", branchName)
+
+	mergeRequests, err := h.listMergeRequests(branchName, "opened")
+	if err != nil {
+		log.Error("❌ Failed to list MRs for branch %s: %v", branchName, err)
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	pullRequests := make([]PullRequest, 0, len(mergeRequests))
+	for _, mr := range mergeRequests {
+		pullRequests = append(pullRequests, mr.toPullRequest())
+	}
+	return pullRequests, nil
+}
+
+func (h *GitLabHost) GetPullRequestForBranch(dir, branchName string) (*PullRequest, error) {
+	log.Info("📋 Starting to get GitLab MR for branch: %s", branchName)
+
+	// Newest first; like `gh pr view <branch>`, an open MR wins over merged or closed ones
+	mergeRequests, err := h.listMergeRequests(branchName, "all")
+	if err != nil {
+		log.Error("❌ Failed to get MR for branch %s: %v", branchName, err)
+		return nil, fmt.Errorf("failed to get merge request for branch %s: %w", branchName, err)
+	}
+	if len(mergeRequests) == 0 {
+		return nil, fmt.Errorf("no merge request found for branch %s", branchName)
+	}
+
+	selected := mergeRequests[0]
+	for _, mr := range mergeRequests {
+		if mr.State == "opened" {
+			selected = mr
+			break
+		}
+	}
+
+	pr := selected.toPullRequest()
+	log.Info("✅ Found MR !%s (%s) for branch %s", pr.ID, pr.State, branchName)
+	return &pr, nil
+}
+
+func (h *GitLabHost) GetPullRequestByID(dir, id string) (*PullRequest, error) {
+	log.Info("📋 Starting to get GitLab MR: !%s", id)
+
+	var mr gitLabMergeRequest
+	if err := h.do(http.MethodGet, h.projectURL("/merge_requests/"+url.PathEscape(id)), nil, &mr); err != nil {
+		log.Error("❌ Failed to get MR !%s: %v", id, err)
+		return nil, fmt.Errorf("failed to get merge request %s: %w", id, err)
+	}
+
+	pr := mr.toPullRequest()
+	return &pr, nil
+}
+
+func (h *GitLabHost) UpdatePullRequestTitle(dir, branchName, title string) error {
+	log.Info("📋 Starting to update GitLab MR title for branch: %s", branchName)
+
+	pr, err := h.GetPullRequestForBranch(dir, branchName)
+	if err != nil {
+		return err
+	}
+	// Keep drafts drafts; the marker is part of the title
+	if pr.Draft {
+		title = gitLabDraftTitle(title)
+	}
+	return h.updateMergeRequest(pr, map[string]string{"title": title})
+}
+
+func (h *GitLabHost) UpdatePullRequestDescription(dir, branchName, description string) error {
+	log.Info("📋 Starting to update GitLab MR description for branch: %s", branchName)
+
+	pr, err := h.GetPullRequestForBranch(dir, branchName)
+	if err != nil {
+		return err
+	}
+	return h.updateMergeRequest(pr, map[string]string{"description": description})
+}
+
+func (h *GitLabHost) MarkPullRequestReady(dir, id string) error {
+	log.Info("📋 Starting to mark GitLab MR !%s ready", id)
+
+	pr, err := h.GetPullRequestByID(dir, id)
+	if err != nil {
+		return err
+	}
+	if !pr.Draft {
+		log.Info("ℹ️ MR !%s is not a draft", id)
+		return nil
+	}
+	// GetPullRequestByID strips the draft marker from the title
+	return h.updateMergeRequest(pr, map[string]string{"title": pr.Title})
+}
+
+// updateMergeRequest changes fields of the merge request
+func (h *GitLabHost) updateMergeRequest(pr *PullRequest, fields map[string]string) error {
+	if err := h.do(http.MethodPut, h.projectURL("/merge_requests/"+pr.ID), fields, nil); err != nil {
+		log.Error("❌ Failed to update MR !%s: %v", pr.ID, err)
+		return fmt.Errorf("failed to update merge request %s: %w", pr.ID, err)
+	}
+
+	log.Info("✅ Successfully updated MR !%s", pr.ID)
+	return nil
+}
+
+// gitLabPipeline is a merge request pipeline as returned by the GitLab API
+type gitLabPipeline struct {
+	ID  int    `json:"id"`
+	SHA string `json:"sha"`
+}
+
+// gitLabJob is a pipeline job as returned by the GitLab API
+type gitLabJob struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	WebURL       string `json:"web_url"`
+	AllowFailure bool   `json:"allow_failure"`
+}
+
+// checkBucket maps the job status to the bucket `gh pr checks` would report
+func (j gitLabJob) checkBucket() string {
+	switch j.Status {
+	case "success":
+		return CheckBucketPass
+	case "failed":
+		if j.AllowFailure {
+			return CheckBucketPass
+		}
+		return CheckBucketFail
+	case "canceled", "canceling":
+		return CheckBucketCancel
+	case "skipped", "manual":
+		return CheckBucketSkipping
+	default:
+		return CheckBucketPending
+	}
+}
+
+// GetPullRequestChecks returns the jobs of the latest pipeline for the merge request's head
+// commit. Without such a pipeline there are no checks yet.
+func (h *GitLabHost) GetPullRequestChecks(dir, id string) (*PullRequestChecks, error) {
+	log.Info("📋 Starting to get GitLab pipeline jobs for MR !%s", id)
+
+	var mr gitLabMergeRequest
+	mrPath := h.projectURL("/merge_requests/" + url.PathEscape(id))
+	if err := h.do(http.MethodGet, mrPath, nil, &mr); err != nil {
+		log.Error("❌ Failed to get MR !%s: %v", id, err)
+		return nil, fmt.Errorf("failed to get merge request %s: %w", id, err)
+	}
+	checks := &PullRequestChecks{HeadCommit: mr.SHA}
+
+	// Newest first
+	var pipelines []gitLabPipeline
+	if err := h.do(http.MethodGet, mrPath+"/pipelines", nil, &pipelines); err != nil {
+		log.Error("❌ Failed to list pipelines for MR !%s: %v", id, err)
+		return nil, fmt.Errorf("failed to list pipelines for merge request %s: %w", id, err)
+	}
+	pipelineID := 0
+	for _, pipeline := range pipelines {
+		if pipeline.SHA == mr.SHA {
+			pipelineID = pipeline.ID
+			break
+		}
+	}
+	if pipelineID == 0 {
+		log.Info("ℹ️ No pipeline for the head commit of MR !%s", id)
+		return checks, nil
+	}
+
+	var jobs []gitLabJob
+	jobsPath := h.projectURL(fmt.Sprintf("/pipelines/%d/jobs?per_page=100", pipelineID))
+	if err := h.do(http.MethodGet, jobsPath, nil, &jobs); err != nil {
+		log.Error("❌ Failed to list jobs of pipeline %d: %v", pipelineID, err)
+		return nil, fmt.Errorf("failed to list jobs of pipeline %d: %w", pipelineID, err)
+	}
+	for _, job := range jobs {
+		checks.Checks = append(checks.Checks, PullRequestCheck{Name: job.Name, Bucket: job.checkBucket(), Link: job.WebURL})
+	}
+
+	log.Info("✅ Found %d pipeline jobs for MR !%s", len(checks.Checks), id)
+	return checks, nil
+}
+
+// FindPullRequestTemplate returns the default merge request template. GitLab prefills new
+// merge requests with .gitlab/merge_request_templates/Default.md.
+func (h *GitLabHost) FindPullRequestTemplate(dir string) (string, error) {
+	log.Info("🔍 Searching for GitLab MR template")
+
+	templatePaths := []string{
+		".gitlab/merge_request_templates/Default.md",
+		".gitlab/merge_request_templates/default.md",
+	}
+
+	for _, path := range templatePaths {
+		fullPath := filepath.Join(dir, path)
+		if content, err := os.ReadFile(fullPath); err == nil {
+			log.Info("✅ Found MR template at: %s", fullPath)
+			return strings.TrimSpace(string(content)), nil
+		}
+	}
+
+	log.Info("ℹ️ No default MR template found")
+	return "", nil
+}
+
+// listMergeRequests lists the merge requests with branchName as source branch, newest first
+func (h *GitLabHost) listMergeRequests(branchName, state string) ([]gitLabMergeRequest, error) {
+	query := url.Values{}
+	query.Set("source_branch", branchName)
+	query.Set("state", state)
+	query.Set("order_by", "created_at")
+	query.Set("sort", "desc")
+
+	var mergeRequests []gitLabMergeRequest
+	if err := h.do(http.MethodGet, h.projectURL("/merge_requests?"+query.Encode()), nil, &mergeRequests); err != nil {
+		return nil, err
+	}
+	return mergeRequests, nil
+}
+
+// projectURL returns the API URL of path under the project
+func (h *GitLabHost) projectURL(path string) string {
+	return h.apiURL + "/projects/" + url.PathEscape(h.projectPath) + path
+}
+
+// do sends a JSON request and decodes the response into result. Rate limits are retried
+// with exponential backoff. Network errors and server errors are only retried for GET and
+// PUT, since a POST that failed that way may still have been applied.
+func (h *GitLabHost) do(method, requestURL string, body any, result any) error {
+	idempotent := method == http.MethodGet || method == http.MethodPut
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to marshal gitlab request: %w", err)
+		}
+	}
+
+	retryBackoff := backoff.NewExponentialBackOff()
+	retryBackoff.InitialInterval = 2 * time.Second
+	retryBackoff.MaxInterval = 30 * time.Second
+	retryBackoff.MaxElapsedTime = 2 * time.Minute
+	retryBackoff.Multiplier = 2
+
+	var responseBody []byte
+	operation := func() error {
+		req, err := http.NewRequest(method, requestURL, bytes.NewReader(payload))
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("failed to create gitlab request: %w", err))
+		}
+		req.Header.Set("PRIVATE-TOKEN", h.token)
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := h.httpClient.Do(req)
+		if err != nil {
+			if !idempotent {
+				return backoff.Permanent(fmt.Errorf("gitlab api %s %s failed: %w", method, req.URL.Path, err))
+			}
+			log.Info("⏳ GitLab API request failed for %s %s, retrying: %v", method, req.URL.Path, err)
+			return err
+		}
+		defer resp.Body.Close()
+
+		responseBody, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read gitlab response: %w", err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			apiErr := &gitLabAPIError{
+				Method:     method,
+				Path:       req.URL.EscapedPath(),
+				StatusCode: resp.StatusCode,
+				Body:       strings.TrimSpace(string(responseBody)),
+			}
+			if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= 500 && idempotent) {
+				log.Info("⏳ GitLab API returned %d for %s %s, retrying...", resp.StatusCode, method, apiErr.Path)
+				return apiErr
+			}
+			return backoff.Permanent(apiErr)
+		}
+		return nil
+	}
+
+	if err := backoff.Retry(operation, retryBackoff); err != nil {
+		return err
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(responseBody, result); err != nil {
+			return fmt.Errorf("failed to parse gitlab response: %w", err)
+		}
+	}
+	return nil
+}
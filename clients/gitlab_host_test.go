@@ -0,0 +1,475 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitLab is an in-memory stand-in for the merge request endpoints of the GitLab REST API
+type fakeGitLab struct {
+	t       *testing.T
+	server  *httptest.Server
+	token   string
+	project string
+
+	mu            sync.Mutex
+	mergeRequests []*fakeMergeRequest
+	pipelines     map[int][]gitLabPipeline // Merge request IID → pipelines, newest first
+	jobs          map[int][]gitLabJob      // Pipeline ID → jobs
+	failNext      int                      // Number of upcoming requests answered with 502
+}
+
+type fakeMergeRequest struct {
+	gitLabMergeRequest
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+}
+
+func newFakeGitLab(t *testing.T, token, project string) *fakeGitLab {
+	t.Helper()
+	f := &fakeGitLab{t: t, token: token, project: project}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGitLab) apiURL() string {
+	return f.server.URL + "/api/v4"
+}
+
+func (f *fakeGitLab) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failNext > 0 {
+		f.failNext--
+		http.Error(w, `{"message":"502 Bad Gateway"}`, http.StatusBadGateway)
+		return
+	}
+	if r.Header.Get("PRIVATE-TOKEN") != f.token {
+		http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	prefix := "/api/v4/projects/" + url.PathEscape(f.project)
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, prefix) {
+		http.Error(w, `{"message":"404 Project Not Found"}`, http.StatusNotFound)
+		return
+	}
+	path = strings.TrimPrefix(path, prefix)
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		f.writeJSON(w, http.StatusOK, map[string]any{"id": 1, "path_with_namespace": f.project})
+	case path == "/merge_requests" && r.Method == http.MethodGet:
+		f.list(w, r.URL.Query())
+	case path == "/merge_requests" && r.Method == http.MethodPost:
+		f.create(w, r)
+	case strings.HasPrefix(path, "/pipelines/") && strings.HasSuffix(path, "/jobs"):
+		pipelineID, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path, "/pipelines/"), "/jobs"))
+		f.writeJSON(w, http.StatusOK, append([]gitLabJob{}, f.jobs[pipelineID]...))
+	case strings.HasPrefix(path, "/merge_requests/"):
+		rest, pipelines := strings.CutSuffix(strings.TrimPrefix(path, "/merge_requests/"), "/pipelines")
+		iid, _ := strconv.Atoi(rest)
+		mr := f.find(iid)
+		if mr == nil {
+			http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+			return
+		}
+		if pipelines {
+			f.writeJSON(w, http.StatusOK, append([]gitLabPipeline{}, f.pipelines[iid]...))
+			return
+		}
+		if r.Method == http.MethodPut {
+			var fields map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if title, ok := fields["title"]; ok {
+				mr.Title = title
+				mr.Draft = strings.HasPrefix(title, "Draft:")
+			}
+			if description, ok := fields["description"]; ok {
+				mr.Description = description
+			}
+		}
+		f.writeJSON(w, http.StatusOK, mr)
+	default:
+		http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+	}
+}
+
+func (f *fakeGitLab) list(w http.ResponseWriter, query url.Values) {
+	matches := []*fakeMergeRequest{}
+	for _, mr := range f.mergeRequests {
+		if mr.SourceBranch != query.Get("source_branch") {
+			continue
+		}
+		if state := query.Get("state"); state != "" && state != "all" && mr.State != state {
+			continue
+		}
+		matches = append(matches, mr)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].IID > matches[j].IID })
+	f.writeJSON(w, http.StatusOK, matches)
+}
+
+func (f *fakeGitLab) create(w http.ResponseWriter, r *http.Request) {
+	var request map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(request["title"]) > MaxGitLabMRTitleLength {
+		http.Error(w, `{"message":{"title":["is too long (maximum is 255 characters)"]}}`, http.StatusBadRequest)
+		return
+	}
+	for _, mr := range f.mergeRequests {
+		if mr.SourceBranch == request["source_branch"] && mr.State == "opened" {
+			http.Error(w, `{"message":["Another open merge request already exists for this source branch"]}`, http.StatusConflict)
+			return
+		}
+	}
+
+	iid := len(f.mergeRequests) + 1
+	mr := &fakeMergeRequest{
+		gitLabMergeRequest: gitLabMergeRequest{
+			IID:         iid,
+			WebURL:      fmt.Sprintf("https://gitlab.example.com/%s/-/merge_requests/%d", f.project, iid),
+			Title:       request["title"],
+			Description: request["description"],
+			State:       "opened",
+			Draft:       strings.HasPrefix(request["title"], "Draft:"),
+		},
+		SourceBranch: request["source_branch"],
+		TargetBranch: request["target_branch"],
+	}
+	f.mergeRequests = append(f.mergeRequests, mr)
+	f.writeJSON(w, http.StatusCreated, mr)
+}
+
+func (f *fakeGitLab) find(iid int) *fakeMergeRequest {
+	for _, mr := range f.mergeRequests {
+		if mr.IID == iid {
+			return mr
+		}
+	}
+	return nil
+}
+
+// setState changes the state of a merge request as if it was merged or closed on GitLab
+func (f *fakeGitLab) setState(iid int, state string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.find(iid).State = state
+}
+
+func (f *fakeGitLab) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		f.t.Errorf("failed to encode response: %v", err)
+	}
+}
+
+func TestGitLabHost_MergeRequestFlow(t *testing.T) {
+	fake := newFakeGitLab(t, "glpat-test", "group/subgroup/project")
+	host := NewGitLabHost(fake.apiURL(), "glpat-test", "group/subgroup/project")
+
+	if err := host.CheckAccess(""); err != nil {
+		t.Fatalf("expected access check to pass: %v", err)
+	}
+
+	open, err := host.ListOpenPullRequests("", "eksecd/feature")
+	if err != nil || len(open) != 0 {
+		t.Fatalf("expected no open merge requests, got %v (%v)", open, err)
+	}
+	if _, err := host.GetPullRequestForBranch("", "eksecd/feature"); err == nil {
+		t.Errorf("expected an error for a branch without merge requests")
+	}
+
+	mrURL, err := host.CreatePullRequest("", "eksecd/feature", "main", "Add feature", "Description", false)
+	if err != nil {
+		t.Fatalf("failed to create merge request: %v", err)
+	}
+	if mrURL != "https://gitlab.example.com/group/subgroup/project/-/merge_requests/1" {
+		t.Errorf("unexpected merge request URL %s", mrURL)
+	}
+	if created := fake.find(1); created.SourceBranch != "eksecd/feature" || created.TargetBranch != "main" {
+		t.Errorf("unexpected branches %s -> %s", created.SourceBranch, created.TargetBranch)
+	}
+
+	if err := host.UpdatePullRequestTitle("", "eksecd/feature", "Add a better feature"); err != nil {
+		t.Fatalf("failed to update title: %v", err)
+	}
+	if err := host.UpdatePullRequestDescription("", "eksecd/feature", "Better description"); err != nil {
+		t.Fatalf("failed to update description: %v", err)
+	}
+
+	pr, err := host.GetPullRequestForBranch("", "eksecd/feature")
+	if err != nil {
+		t.Fatalf("failed to get merge request: %v", err)
+	}
+	if pr.ID != "1" || pr.Title != "Add a better feature" || pr.Description != "Better description" || pr.State != PullRequestStateOpen {
+		t.Errorf("unexpected merge request %+v", pr)
+	}
+
+	fake.setState(1, "merged")
+	pr, err = host.GetPullRequestByID("", "1")
+	if err != nil {
+		t.Fatalf("failed to get merge request by ID: %v", err)
+	}
+	if pr.State != PullRequestStateMerged {
+		t.Errorf("expected merged state, got %s", pr.State)
+	}
+	if open, _ := host.ListOpenPullRequests("", "eksecd/feature"); len(open) != 0 {
+		t.Errorf("expected merged merge request not to be listed as open")
+	}
+}
+
+func TestGitLabHost_PrefersOpenMergeRequestForBranch(t *testing.T) {
+	fake := newFakeGitLab(t, "token", "group/project")
+	host := NewGitLabHost(fake.apiURL(), "token", "group/project")
+
+	if _, err := host.CreatePullRequest("", "eksecd/feature", "main", "First", "", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := host.CreatePullRequest("", "eksecd/other", "main", "Other", "", false); err != nil {
+		t.Fatal(err)
+	}
+	fake.setState(1, "closed")
+	if _, err := host.CreatePullRequest("", "eksecd/feature", "main", "Second", "", false); err != nil {
+		t.Fatal(err)
+	}
+	fake.setState(3, "locked")
+
+	pr, err := host.GetPullRequestForBranch("", "eksecd/feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.ID != "3" || pr.State != PullRequestStateOpen {
+		t.Errorf("expected open merge request !3, got %+v", pr)
+	}
+}
+
+func TestGitLabHost_Errors(t *testing.T) {
+	fake := newFakeGitLab(t, "token", "group/project")
+
+	if err := NewGitLabHost(fake.apiURL(), "", "group/project").CheckAccess(""); err == nil || !strings.Contains(err.Error(), "GITLAB_TOKEN") {
+		t.Errorf("expected missing token error, got %v", err)
+	}
+	if err := NewGitLabHost(fake.apiURL(), "wrong", "group/project").CheckAccess(""); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+
+	host := NewGitLabHost(fake.apiURL(), "token", "group/project")
+	mrURL, err := host.CreatePullRequest("", "eksecd/feature", "main", "Title", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second create for the same branch returns the open merge request
+	if got, err := host.CreatePullRequest("", "eksecd/feature", "main", "Title", "", false); err != nil || got != mrURL {
+		t.Errorf("expected existing merge request %s, got %q (%v)", mrURL, got, err)
+	}
+
+	// Server errors on creation are not retried, since the merge request may exist already
+	fake.mu.Lock()
+	fake.failNext = 2
+	fake.mu.Unlock()
+	if _, err := host.CreatePullRequest("", "eksecd/other", "main", "Other", "", false); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("expected bad gateway error, got %v", err)
+	}
+	fake.mu.Lock()
+	remaining := fake.failNext
+	fake.failNext = 0
+	fake.mu.Unlock()
+	if remaining != 1 {
+		t.Errorf("expected a single creation attempt, got %d", 2-remaining)
+	}
+}
+
+func TestGitLabHost_DraftMergeRequest(t *testing.T) {
+	fake := newFakeGitLab(t, "token", "group/project")
+	host := NewGitLabHost(fake.apiURL(), "token", "group/project")
+
+	if _, err := host.CreatePullRequest("", "eksecd/feature", "main", strings.Repeat("a", MaxGitLabMRTitleLength), "", true); err != nil {
+		t.Fatalf("failed to create draft merge request: %v", err)
+	}
+	if title := fake.find(1).Title; len(title) != MaxGitLabMRTitleLength || !strings.HasPrefix(title, "Draft: ") {
+		t.Errorf("expected a draft title within the length limit, got %q", title)
+	}
+
+	// Retitling keeps the draft marker, which GetPullRequestForBranch hides
+	if err := host.UpdatePullRequestTitle("", "eksecd/feature", "Add feature"); err != nil {
+		t.Fatal(err)
+	}
+	pr, err := host.GetPullRequestForBranch("", "eksecd/feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pr.Draft || pr.Title != "Add feature" || fake.find(1).Title != "Draft: Add feature" {
+		t.Errorf("expected draft titled Add feature, got %+v (stored %q)", pr, fake.find(1).Title)
+	}
+
+	if err := host.MarkPullRequestReady("", "1"); err != nil {
+		t.Fatalf("failed to mark merge request ready: %v", err)
+	}
+	pr, err = host.GetPullRequestByID("", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Draft || pr.Title != "Add feature" {
+		t.Errorf("expected ready merge request titled Add feature, got %+v", pr)
+	}
+	// Marking a ready merge request again changes nothing
+	if err := host.MarkPullRequestReady("", "1"); err != nil {
+		t.Errorf("expected no error for a ready merge request, got %v", err)
+	}
+}
+
+func TestGitLabHost_PullRequestChecks(t *testing.T) {
+	fake := newFakeGitLab(t, "token", "group/project")
+	host := NewGitLabHost(fake.apiURL(), "token", "group/project")
+	if _, err := host.CreatePullRequest("", "eksecd/feature", "main", "Title", "", false); err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	fake.find(1).SHA = "bbbb"
+	fake.mu.Unlock()
+
+	// No pipeline for the head commit yet
+	checks, err := host.GetPullRequestChecks("", "1")
+	if err != nil {
+		t.Fatalf("failed to get checks: %v", err)
+	}
+	if checks.HeadCommit != "bbbb" || len(checks.Checks) != 0 {
+		t.Errorf("expected no checks at head bbbb, got %+v", checks)
+	}
+
+	fake.mu.Lock()
+	fake.pipelines = map[int][]gitLabPipeline{1: {{ID: 11, SHA: "bbbb"}, {ID: 10, SHA: "aaaa"}}}
+	fake.jobs = map[int][]gitLabJob{
+		10: {{Name: "old", Status: "failed"}},
+		11: {
+			{Name: "build", Status: "success", WebURL: "https://gitlab.example.com/jobs/1"},
+			{Name: "test", Status: "failed", WebURL: "https://gitlab.example.com/jobs/2"},
+			{Name: "lint", Status: "failed", AllowFailure: true},
+			{Name: "deploy", Status: "manual"},
+		},
+	}
+	fake.mu.Unlock()
+
+	checks, err = host.GetPullRequestChecks("", "1")
+	if err != nil {
+		t.Fatalf("failed to get checks: %v", err)
+	}
+	if len(checks.Checks) != 4 || checks.Pending() {
+		t.Fatalf("expected four finished checks, got %+v", checks)
+	}
+	failed := checks.Failed()
+	if len(failed) != 1 || failed[0].Name != "test" || failed[0].Link != "https://gitlab.example.com/jobs/2" {
+		t.Errorf("expected only test to fail, got %+v", failed)
+	}
+
+	fake.mu.Lock()
+	fake.jobs[11][0].Status = "running"
+	fake.mu.Unlock()
+	if checks, _ := host.GetPullRequestChecks("", "1"); !checks.Pending() {
+		t.Errorf("expected running job to be pending")
+	}
+}
+
+func TestGitClient_PullRequestsOnGitLab(t *testing.T) {
+	repoPath, cleanup := setupTestGitRepo(t)
+	defer cleanup()
+	runTestGit(t, repoPath, "checkout", "-b", "eksecd/feature")
+	templateDir := filepath.Join(repoPath, ".gitlab", "merge_request_templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "Default.md"), []byte("## What does this MR do?\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := newFakeGitLab(t, "token", "group/project")
+	client := NewGitClient()
+	client.SetCodeHost(NewGitLabHost(fake.apiURL(), "token", "group/project"))
+
+	template, err := client.FindPRTemplateInWorktree(repoPath)
+	if err != nil || template != "## What does this MR do?" {
+		t.Errorf("expected GitLab template, got %q (%v)", template, err)
+	}
+
+	hasPR, err := client.HasExistingPRInWorktree(repoPath, "eksecd/feature")
+	if err != nil || hasPR {
+		t.Fatalf("expected no merge request yet, got %v (%v)", hasPR, err)
+	}
+
+	// The title is cut to GitLab's limit and the overflow moves into the description
+	longTitle := strings.Repeat("a", MaxGitLabMRTitleLength+5)
+	mrURL, err := client.CreatePullRequestInWorktree(repoPath, longTitle, "Body", "main", false)
+	if err != nil {
+		t.Fatalf("failed to create merge request: %v", err)
+	}
+	created := fake.find(1)
+	if created.SourceBranch != "eksecd/feature" || len(created.Title) != MaxGitLabMRTitleLength {
+		t.Errorf("unexpected merge request %+v", created)
+	}
+	if !strings.HasSuffix(created.Description, "Body") || !strings.HasPrefix(created.Description, "aaaaaaaa") {
+		t.Errorf("expected title overflow in description, got %q", created.Description)
+	}
+	if id := client.ExtractPRIDFromURL(mrURL); id != "1" {
+		t.Errorf("expected ID 1 from %s, got %s", mrURL, id)
+	}
+
+	if hasPR, _ := client.HasExistingPRInWorktree(repoPath, "eksecd/feature"); !hasPR {
+		t.Errorf("expected merge request to exist")
+	}
+	if got, _ := client.GetPRURLInWorktree(repoPath, "eksecd/feature"); got != mrURL {
+		t.Errorf("expected URL %s, got %s", mrURL, got)
+	}
+
+	if err := client.UpdatePRTitleInWorktree(repoPath, "eksecd/feature", "Short title"); err != nil {
+		t.Fatalf("failed to update title: %v", err)
+	}
+	if title, _ := client.GetPRTitleInWorktree(repoPath, "eksecd/feature"); title != "Short title" {
+		t.Errorf("expected updated title, got %q", title)
+	}
+
+	// Transient server errors are retried
+	fake.mu.Lock()
+	fake.failNext = 1
+	fake.mu.Unlock()
+	client.SetRepoPathProvider(func() string { return repoPath })
+	if state, err := client.GetPRState("eksecd/feature"); err != nil || state != "open" {
+		t.Errorf("expected open state after retry, got %q (%v)", state, err)
+	}
+
+	fake.setState(1, "closed")
+	if state, err := client.GetPRStateByID("1"); err != nil || state != "closed" {
+		t.Errorf("expected closed state, got %q (%v)", state, err)
+	}
+}
+
+// runTestGit runs a git command in dir and fails the test on error
+func runTestGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\nOutput: %s", strings.Join(args, " "), err, string(output))
+	}
+}
@@ -41,6 +41,9 @@ func (c *OpenCodeClient) StartNewSession(prompt string, options *clients.OpenCod
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	var cmd = buildCommand(ctx, options, args)
 
@@ -54,6 +57,13 @@ func (c *OpenCodeClient) StartNewSession(prompt string, options *clients.OpenCod
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 OpenCode session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -89,6 +99,9 @@ func (c *OpenCodeClient) ContinueSession(sessionID, prompt string, options *clie
 
 	ctx, cancel := context.WithTimeout(context.Background(), clients.DefaultSessionTimeout)
 	defer cancel()
+	if options != nil {
+		defer clients.TrackAgentTurn(options.JobID, cancel)()
+	}
 
 	var cmd = buildCommand(ctx, options, args)
 
@@ -102,6 +115,13 @@ func (c *OpenCodeClient) ContinueSession(sessionID, prompt string, options *clie
 				Output: string(output),
 			}
 		}
+		if ctx.Err() == context.Canceled {
+			log.Info("🛑 OpenCode session interrupted")
+			return "", &core.ErrClaudeCommandErr{
+				Err:    &core.ErrAgentInterrupted{},
+				Output: string(output),
+			}
+		}
 		return "", &core.ErrClaudeCommandErr{
 			Err:    err,
 			Output: string(output),
@@ -27,8 +27,14 @@ type PRTitleValidationResult struct {
 // Returns:
 //   - PRTitleValidationResult with validated title and optional description prefix
 func ValidateAndTruncatePRTitle(title, description string) PRTitleValidationResult {
+	return ValidateAndTruncateTitle(title, description, MaxGitHubPRTitleLength)
+}
+
+// ValidateAndTruncateTitle is ValidateAndTruncatePRTitle for a code host whose title limit
+// is maxLength characters
+func ValidateAndTruncateTitle(title, description string, maxLength int) PRTitleValidationResult {
 	// If title is within limit, return as-is
-	if len(title) <= MaxGitHubPRTitleLength {
+	if len(title) <= maxLength {
 		return PRTitleValidationResult{
 			Title:             title,
 			DescriptionPrefix: "",
@@ -37,7 +43,7 @@ func ValidateAndTruncatePRTitle(title, description string) PRTitleValidationResu
 
 	// Title exceeds limit - need to truncate
 	// Reserve 3 characters for "..."
-	truncateAt := MaxGitHubPRTitleLength - 3
+	truncateAt := maxLength - 3
 
 	// Truncate at the last complete character before the limit
 	truncatedTitle := title[:truncateAt] + "..."
@@ -2,11 +2,14 @@
 package clients
 
 import (
+	"bytes"
 	"context"
 	"log"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -14,6 +17,10 @@ import (
 // before being killed. This prevents hung processes from blocking the worker pool.
 const DefaultSessionTimeout = 1 * time.Hour
 
+// agentWaitDelay bounds how long we wait for an agent's output to close after its process
+// was cancelled, since tool subprocesses spawned by the agent may keep the pipes open
+const agentWaitDelay = 10 * time.Second
+
 // BlockedEnvVars lists environment variables that should never be passed to agent processes.
 // These contain sensitive credentials that agents should not have access to.
 var BlockedEnvVars = map[string]bool{
@@ -38,6 +45,93 @@ func AgentHTTPProxy() string {
 	return os.Getenv("AGENT_HTTP_PROXY")
 }
 
+// agentTurn is a handle to a job's running agent process
+type agentTurn struct {
+	cancel context.CancelFunc
+}
+
+// agentTurns tracks the running agent process of each job so a turn can be interrupted
+var agentTurns = struct {
+	sync.Mutex
+	byJob map[string]*agentTurn
+}{byJob: make(map[string]*agentTurn)}
+
+// TrackAgentTurn registers the cancel function of a job's running agent process.
+// The returned function unregisters it and must be called once the process exits.
+// Empty job IDs are not tracked.
+func TrackAgentTurn(jobID string, cancel context.CancelFunc) func() {
+	if jobID == "" {
+		return func() {}
+	}
+
+	turn := &agentTurn{cancel: cancel}
+	agentTurns.Lock()
+	agentTurns.byJob[jobID] = turn
+	agentTurns.Unlock()
+
+	return func() {
+		agentTurns.Lock()
+		defer agentTurns.Unlock()
+		if agentTurns.byJob[jobID] == turn {
+			delete(agentTurns.byJob, jobID)
+		}
+	}
+}
+
+// InterruptAgentTurn terminates the running agent process of a job.
+// Returns false if the job has no running agent process.
+func InterruptAgentTurn(jobID string) bool {
+	agentTurns.Lock()
+	turn, exists := agentTurns.byJob[jobID]
+	agentTurns.Unlock()
+	if !exists {
+		return false
+	}
+
+	log.Printf("[InterruptAgentTurn] Terminating agent process for job %s", jobID)
+	turn.cancel()
+	return true
+}
+
+// lineTapWriter collects a command's output and passes each complete line to onLine as it
+// is written
+type lineTapWriter struct {
+	output  bytes.Buffer
+	pending []byte
+	onLine  func(line string)
+}
+
+func (w *lineTapWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(string(w.pending[:i]))
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// CombinedOutputWithLines runs cmd like cmd.CombinedOutput, additionally passing each
+// output line to onLine while the command runs. onLine may be nil.
+func CombinedOutputWithLines(cmd *exec.Cmd, onLine func(line string)) ([]byte, error) {
+	if onLine == nil {
+		return cmd.CombinedOutput()
+	}
+	// The same writer for both streams makes exec call Write from one goroutine at a time
+	w := &lineTapWriter{onLine: onLine}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	if len(w.pending) > 0 {
+		w.onLine(string(w.pending))
+	}
+	return w.output.Bytes(), err
+}
+
 // BuildAgentCommandWithContext creates an exec.Cmd bound to a context for timeout/cancellation.
 // When the context expires, the process is killed automatically.
 func BuildAgentCommandWithContext(ctx context.Context, name string, args ...string) *exec.Cmd {
@@ -54,6 +148,7 @@ func BuildAgentCommandWithContext(ctx context.Context, name string, args ...stri
 		log.Printf("[BuildAgentCommandWithContext] Self-hosted mode: running %s as current user", name)
 		cmd := exec.CommandContext(ctx, name, args...)
 		cmd.Env = filteredEnv
+		cmd.WaitDelay = agentWaitDelay
 		return cmd
 	}
 
@@ -71,6 +166,12 @@ func BuildAgentCommandWithContext(ctx context.Context, name string, args ...stri
 
 	log.Printf("[BuildAgentCommandWithContext] Managed mode: running sudo -u %s bash -c '...' (cmd=%s)", execUser, name)
 	cmd := exec.CommandContext(ctx, "sudo", sudoArgs...)
+	// sudo relays SIGTERM to the agent but cannot relay SIGKILL, so terminate gracefully
+	// and let WaitDelay escalate to a kill if the agent doesn't exit
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = agentWaitDelay
 	return cmd
 }
 
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
 )
@@ -482,3 +483,76 @@ func TestBuildAgentCommandWithContext_InjectsProxy(t *testing.T) {
 		t.Error("HTTPS_PROXY not injected into command environment")
 	}
 }
+
+func TestInterruptAgentTurn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	untrack := TrackAgentTurn("job-1", cancel)
+
+	if InterruptAgentTurn("job-2") {
+		t.Error("Expected no interrupt for a job without a running turn")
+	}
+	if !InterruptAgentTurn("job-1") {
+		t.Fatal("Expected running turn to be interrupted")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("Expected context to be cancelled, got %v", ctx.Err())
+	}
+
+	untrack()
+	if InterruptAgentTurn("job-1") {
+		t.Error("Expected no interrupt after the turn was untracked")
+	}
+}
+
+func TestTrackAgentTurn_StaleUntrackKeepsNewerTurn(t *testing.T) {
+	_, cancelOld := context.WithCancel(context.Background())
+	defer cancelOld()
+	newCtx, cancelNew := context.WithCancel(context.Background())
+	defer cancelNew()
+
+	untrackOld := TrackAgentTurn("job-1", cancelOld)
+	untrackNew := TrackAgentTurn("job-1", cancelNew)
+	defer untrackNew()
+
+	untrackOld()
+	if !InterruptAgentTurn("job-1") {
+		t.Fatal("Expected newer turn to still be tracked")
+	}
+	if newCtx.Err() != context.Canceled {
+		t.Errorf("Expected newer turn to be cancelled, got %v", newCtx.Err())
+	}
+}
+
+func TestTrackAgentTurn_EmptyJobID(t *testing.T) {
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	untrack := TrackAgentTurn("", cancel)
+	defer untrack()
+
+	if InterruptAgentTurn("") {
+		t.Error("Expected empty job IDs not to be tracked")
+	}
+}
+
+func TestCombinedOutputWithLines(t *testing.T) {
+	var lines []string
+	cmd := exec.Command("sh", "-c", "printf 'first\\nsecond\\n'; printf 'error\\n' >&2; printf 'last'")
+	output, err := CombinedOutputWithLines(cmd, func(line string) { lines = append(lines, line) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(output) != "first\nsecond\nerror\nlast" {
+		t.Errorf("unexpected output %q", output)
+	}
+	if strings.Join(lines, "|") != "first|second|error|last" {
+		t.Errorf("unexpected lines %q", lines)
+	}
+
+	output, err = CombinedOutputWithLines(exec.Command("sh", "-c", "echo out; exit 3"), nil)
+	if err == nil || string(output) != "out\n" {
+		t.Errorf("expected the output and error without a line callback, got %q, %v", output, err)
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/core/env"
+	"eksecd/handlers"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+// JobsCommand groups the subcommands that inspect jobs handled by this agent
+type JobsCommand struct {
+	History JobsHistoryCommand `command:"history" description:"List finished jobs from the job history"`
+	Export  JobsExportCommand  `command:"export" description:"Export a job so another machine can continue it (stop the agent first)"`
+	Import  JobsImportCommand  `command:"import" description:"Import a job exported with jobs export (stop the agent first)"`
+}
+
+// setOptions gives the subcommands access to the global options, e.g. --repo
+func (c *JobsCommand) setOptions(opts *Options) {
+	c.Export.opts = opts
+	c.Import.opts = opts
+}
+
+// JobsExportCommand writes an active job to a bundle file
+type JobsExportCommand struct {
+	Output string `long:"output" short:"o" description:"Bundle file to write (default: <job-id>.tar.gz)"`
+	Args   struct {
+		JobID string `positional-arg-name:"job-id" required:"yes"`
+	} `positional-args:"yes"`
+
+	opts *Options
+}
+
+// JobsImportCommand recreates a job from a bundle file
+type JobsImportCommand struct {
+	Args struct {
+		Bundle string `positional-arg-name:"bundle" required:"yes"`
+	} `positional-args:"yes"`
+
+	opts *Options
+}
+
+// JobsHistoryCommand lists archived jobs, optionally filtered and exported as CSV or JSON
+type JobsHistoryCommand struct {
+	Status  []string `long:"status" description:"Only list jobs with this outcome (merged, closed, inactive, failed, abandoned). Can be repeated"`
+	Since   string   `long:"since" description:"Only list jobs finished on or after this date (YYYY-MM-DD or RFC 3339)"`
+	Until   string   `long:"until" description:"Only list jobs finished on or before this date (YYYY-MM-DD or RFC 3339)"`
+	PRState []string `long:"pr-state" description:"Only list jobs whose pull request was in this state when they finished (open, merged, closed, no_pr). Can be repeated"`
+	Format  string   `long:"format" description:"Output format" choice:"table" choice:"csv" choice:"json" default:"table"`
+	Output  string   `long:"output" short:"o" description:"Write to this file instead of stdout"`
+}
+
+// jobHistoryFilter selects job history entries. Empty fields match everything.
+type jobHistoryFilter struct {
+	outcomes map[models.JobOutcome]bool
+	prStates map[string]bool
+	since    time.Time
+	until    time.Time
+}
+
+var validJobOutcomes = map[models.JobOutcome]bool{
+	models.JobOutcomeMerged:    true,
+	models.JobOutcomeClosed:    true,
+	models.JobOutcomeInactive:  true,
+	models.JobOutcomeFailed:    true,
+	models.JobOutcomeAbandoned: true,
+}
+
+var validPRStates = map[string]bool{"open": true, "merged": true, "closed": true, "no_pr": true}
+
+// Execute prints the job history of the agent using the current config directory
+func (c *JobsHistoryCommand) Execute(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+	}
+
+	filter, err := c.filter()
+	if err != nil {
+		return err
+	}
+
+	configDir, err := env.GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	entries, err := models.ReadJobHistory(filepath.Join(configDir, models.JobHistoryFileName))
+	if err != nil {
+		return err
+	}
+	entries = filter.apply(entries)
+
+	out := io.Writer(os.Stdout)
+	if c.Output != "" {
+		file, err := os.Create(c.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	return writeJobHistory(out, entries, c.Format)
+}
+
+// filter builds the entry filter from the command line options
+func (c *JobsHistoryCommand) filter() (jobHistoryFilter, error) {
+	var filter jobHistoryFilter
+
+	for _, status := range c.Status {
+		outcome := models.JobOutcome(strings.ToLower(status))
+		if !validJobOutcomes[outcome] {
+			return filter, fmt.Errorf("invalid status %q: expected merged, closed, inactive, failed or abandoned", status)
+		}
+		if filter.outcomes == nil {
+			filter.outcomes = make(map[models.JobOutcome]bool)
+		}
+		filter.outcomes[outcome] = true
+	}
+
+	for _, state := range c.PRState {
+		state = strings.ToLower(state)
+		if !validPRStates[state] {
+			return filter, fmt.Errorf("invalid PR state %q: expected open, merged, closed or no_pr", state)
+		}
+		if filter.prStates == nil {
+			filter.prStates = make(map[string]bool)
+		}
+		filter.prStates[state] = true
+	}
+
+	var err error
+	if c.Since != "" {
+		if filter.since, err = parseHistoryDate(c.Since, false); err != nil {
+			return filter, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if c.Until != "" {
+		if filter.until, err = parseHistoryDate(c.Until, true); err != nil {
+			return filter, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseHistoryDate parses a YYYY-MM-DD date in local time or an RFC 3339 timestamp.
+// With endOfDay, a plain date covers the whole day.
+func parseHistoryDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date or RFC 3339 timestamp", value)
+	}
+	return t, nil
+}
+
+// apply returns the entries matching the filter
+func (f jobHistoryFilter) apply(entries []models.JobHistoryEntry) []models.JobHistoryEntry {
+	result := make([]models.JobHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if f.outcomes != nil && !f.outcomes[entry.Outcome] {
+			continue
+		}
+		if f.prStates != nil && !f.prStates[entry.PullRequestState] {
+			continue
+		}
+		if !f.since.IsZero() && entry.FinishedAt.Before(f.since) {
+			continue
+		}
+		if !f.until.IsZero() && entry.FinishedAt.After(f.until) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// jobHistoryCSVHeader lists the CSV columns written by writeJobHistory
+var jobHistoryCSVHeader = []string{
+	"job_id", "outcome", "finished_at", "created_at", "last_activity_at", "repository", "branch_name",
+	"pull_request_id", "pull_request_url", "pull_request_state", "turn_count", "message_link",
+	"mode", "schedule_name", "error",
+}
+
+// writeJobHistory writes entries to w as a table, CSV or JSON
+func writeJobHistory(w io.Writer, entries []models.JobHistoryEntry, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
+		}
+		return nil
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(jobHistoryCSVHeader); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		for _, entry := range entries {
+			record := []string{
+				entry.JobID,
+				string(entry.Outcome),
+				formatHistoryTime(entry.FinishedAt),
+				formatHistoryTime(entry.CreatedAt),
+				formatHistoryTime(entry.LastActivityAt),
+				entry.Repository,
+				entry.BranchName,
+				entry.PullRequestID,
+				entry.PullRequestURL,
+				entry.PullRequestState,
+				strconv.Itoa(entry.TurnCount),
+				entry.MessageLink,
+				string(entry.Mode),
+				entry.ScheduleName,
+				entry.Error,
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	case "table", "":
+		if len(entries) == 0 {
+			_, err := fmt.Fprintln(w, "No finished jobs found")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FINISHED\tOUTCOME\tPR STATE\tTURNS\tBRANCH\tPULL REQUEST\tJOB")
+		for _, entry := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				entry.FinishedAt.Local().Format("2006-01-02 15:04"),
+				entry.Outcome,
+				valueOrDash(entry.PullRequestState),
+				entry.TurnCount,
+				valueOrDash(entry.BranchName),
+				valueOrDash(entry.PullRequestURL),
+				entry.JobID,
+			)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// formatHistoryTime formats t as RFC 3339, or an empty string for the zero time
+func formatHistoryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// valueOrDash returns value, or "-" when it is empty
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// Execute exports the job from the stopped agent's state
+func (c *JobsExportCommand) Execute(args []string) error {
+	statePath, err := jobsStatePath()
+	if err != nil {
+		return err
+	}
+	loadedState, err := models.LoadState(statePath)
+	if err != nil {
+		return stateAccessError(err)
+	}
+	job, exists := loadedState.Jobs[c.Args.JobID]
+	if !exists {
+		return fmt.Errorf("job %s not found in %s", c.Args.JobID, statePath)
+	}
+
+	bundler, err := newJobBundler(c.opts)
+	if err != nil {
+		return err
+	}
+	output := c.Output
+	if output == "" {
+		output = c.Args.JobID + ".tar.gz"
+	}
+	manifest, err := bundler.Export(*job, output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported job %s to %s\n", job.JobID, output)
+	if job.BranchName != "" {
+		fmt.Printf("  branch: %s at %s (unpushed commits: %t, uncommitted changes: %t)\n",
+			job.BranchName, manifest.HeadCommit, manifest.HasBranchBundle, manifest.HasChanges)
+	}
+	fmt.Printf("  session files: %d\n", len(manifest.SessionFiles))
+	return nil
+}
+
+// Execute imports the bundle into the stopped agent's state
+func (c *JobsImportCommand) Execute(args []string) error {
+	statePath, err := jobsStatePath()
+	if err != nil {
+		return err
+	}
+	appState, _, err := handlers.RestoreAppState(statePath)
+	if err != nil {
+		return stateAccessError(err)
+	}
+	defer appState.Close()
+
+	// Check the job before creating its worktree and branch
+	manifest, err := usecases.ReadJobBundleManifest(c.Args.Bundle)
+	if err != nil {
+		return err
+	}
+	if _, exists := appState.GetJobData(manifest.Job.JobID); exists {
+		return fmt.Errorf("job %s already exists on this agent", manifest.Job.JobID)
+	}
+
+	bundler, err := newJobBundler(c.opts)
+	if err != nil {
+		return err
+	}
+	job, err := bundler.Import(c.Args.Bundle, func(job models.JobData) error {
+		if err := appState.UpdateJobData(job.JobID, job); err != nil {
+			return fmt.Errorf("failed to store imported job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported job %s\n", job.JobID)
+	if job.WorktreePath != "" {
+		fmt.Printf("  worktree: %s (branch %s)\n", job.WorktreePath, job.BranchName)
+	}
+	fmt.Println("Start the agent to continue the conversation")
+	return nil
+}
+
+// jobsStatePath returns the state database of the agent using the current config directory
+func jobsStatePath() (string, error) {
+	configDir, err := env.GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, models.StateFileName), nil
+}
+
+// stateAccessError explains that the agent has to be stopped when its state is locked
+func stateAccessError(err error) error {
+	if errors.Is(err, models.ErrStateLocked) {
+		return fmt.Errorf("the agent is running, stop it before exporting or importing jobs: %w", err)
+	}
+	return err
+}
+
+// newJobBundler creates a JobBundler for the repository selected like the agent does, with
+// --repo or the current directory
+func newJobBundler(opts *Options) (*usecases.JobBundler, error) {
+	repoPath := ""
+	if opts != nil {
+		repoPath = opts.Repo
+	}
+	gitClient := clients.NewGitClient()
+	repoContext, err := resolveRepositoryContext(repoPath, gitClient)
+	if err != nil {
+		return nil, err
+	}
+	gitClient.SetRepoPathProvider(func() string { return repoContext.RepoPath })
+
+	worktreeBasePath, err := usecases.WorktreeBasePath()
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := usecases.AgentHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return usecases.NewJobBundler(gitClient, repoContext.RepoPath, worktreeBasePath, homeDir, workDir), nil
+}
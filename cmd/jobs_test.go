@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"eksecd/models"
+)
+
+func testJobHistory() []models.JobHistoryEntry {
+	return []models.JobHistoryEntry{
+		{JobID: "job-1", Outcome: models.JobOutcomeMerged, PullRequestState: "merged", PullRequestURL: "https://github.com/acme/widgets/pull/1", TurnCount: 3, FinishedAt: time.Date(2024, 3, 4, 10, 0, 0, 0, time.Local)},
+		{JobID: "job-2", Outcome: models.JobOutcomeInactive, PullRequestState: "open", TurnCount: 1, FinishedAt: time.Date(2024, 3, 6, 23, 30, 0, 0, time.Local)},
+		{JobID: "job-3", Outcome: models.JobOutcomeFailed, PullRequestState: "no_pr", Error: "boom, \"quoted\"", FinishedAt: time.Date(2024, 3, 12, 9, 0, 0, 0, time.Local)},
+	}
+}
+
+func jobIDs(entries []models.JobHistoryEntry) string {
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.JobID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestJobsHistoryCommand_Filter(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  JobsHistoryCommand
+		expected string
+	}{
+		{"No filters", JobsHistoryCommand{}, "job-1,job-2,job-3"},
+		{"Status", JobsHistoryCommand{Status: []string{"merged", "FAILED"}}, "job-1,job-3"},
+		{"PR state", JobsHistoryCommand{PRState: []string{"open"}}, "job-2"},
+		{"Week by date", JobsHistoryCommand{Since: "2024-03-04", Until: "2024-03-06"}, "job-1,job-2"},
+		{"Since timestamp", JobsHistoryCommand{Since: time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local).Format(time.RFC3339)}, "job-2,job-3"},
+		{"Combined", JobsHistoryCommand{Status: []string{"inactive"}, Since: "2024-03-07"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.command.filter()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := jobIDs(filter.apply(testJobHistory())); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJobsHistoryCommand_InvalidFilters(t *testing.T) {
+	for _, command := range []JobsHistoryCommand{
+		{Status: []string{"shipped"}},
+		{PRState: []string{"draft"}},
+		{Since: "last week"},
+		{Until: "2024-13-01"},
+	} {
+		if _, err := command.filter(); err == nil {
+			t.Errorf("expected error for %+v", command)
+		}
+	}
+}
+
+func TestWriteJobHistory_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJobHistory(&buf, testJobHistory(), "csv"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected header and 3 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(jobHistoryCSVHeader, ",") {
+		t.Errorf("unexpected header %v", records[0])
+	}
+	if records[1][0] != "job-1" || records[1][1] != "merged" || records[1][8] != "https://github.com/acme/widgets/pull/1" || records[1][10] != "3" {
+		t.Errorf("unexpected first row %v", records[1])
+	}
+	if records[3][14] != "boom, \"quoted\"" {
+		t.Errorf("expected error to survive CSV quoting, got %q", records[3][14])
+	}
+}
+
+func TestWriteJobHistory_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJobHistory(&buf, testJobHistory(), "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entries []models.JobHistoryEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if jobIDs(entries) != "job-1,job-2,job-3" || entries[0].TurnCount != 3 {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	// An empty history is an empty array rather than null
+	buf.Reset()
+	if err := writeJobHistory(&buf, []models.JobHistoryEntry{}, "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected empty array, got %q", buf.String())
+	}
+}
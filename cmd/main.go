@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -59,11 +60,38 @@ type CmdRunner struct {
 	// Job dispatcher for per-job message sequencing
 	dispatcher *handlers.JobDispatcher
 
+	// Runtime-adjustable job concurrency (MAX_CONCURRENCY, set_concurrency_v1, auto mode)
+	concurrency *handlers.ConcurrencyController
+
+	// Locally scheduled recurring jobs
+	scheduledJobs *handlers.ScheduledJobRunner
+
+	// Reports CI results of open job PRs to their threads
+	ciWatcher *handlers.CIWatcher
+
 	// Worktree pool for fast worktree acquisition
 	poolCtx    context.Context
 	poolCancel context.CancelFunc
+
+	// Graceful drain requested via SIGTERM or drain_v1
+	drainRequests chan models.DrainPayload
+	drainTimedOut bool
+
+	// Artifact deployment settings, reused when hot reloading artifacts
+	agentType       string
+	workDir         string
+	targetHomeDir   string
+	artifactReloads chan string
 }
 
+// defaultDrainTimeout is how long in-flight turns may run after a drain is requested.
+// Can be overridden via the DRAIN_TIMEOUT_SECONDS environment variable.
+const defaultDrainTimeout = 5 * time.Minute
+
+// drainInterruptGracePeriod is how long interrupted turns may take to stop at the drain
+// deadline before their work is committed
+const drainInterruptGracePeriod = 30 * time.Second
+
 // validateModelForAgent checks if the specified model is compatible with the chosen agent
 func validateModelForAgent(agentType, model string) error {
 	// If no model specified, it's valid for all agents (they'll use defaults)
@@ -133,56 +161,75 @@ func fetchAndSetToken(agentsApiClient *clients.AgentsApiClient, envManager *env.
 	return nil
 }
 
-// fetchAndStoreArtifacts fetches agent artifacts from API and stores them locally
+// fetchAndStoreArtifacts fetches agent artifacts from API and stores them locally.
+// All artifact files are downloaded before anything on disk is touched and the stored set
+// is then replaced by rename, so a failed fetch or write leaves the previous artifacts in place.
 func fetchAndStoreArtifacts(agentsApiClient *clients.AgentsApiClient) error {
 	log.Info("📦 Fetching agent artifacts from API...")
 
-	// Clean up existing rules, MCP configs, and skills before downloading new ones
-	// This ensures stale items deleted on the server are removed locally
-	if err := utils.CleanCcagentRulesDir(); err != nil {
-		return fmt.Errorf("failed to clean eksecd rules directory: %w", err)
-	}
-
-	if err := utils.CleanCcagentMCPDir(); err != nil {
-		return fmt.Errorf("failed to clean eksecd MCP directory: %w", err)
-	}
-
-	if err := utils.CleanCcagentSkillsDir(); err != nil {
-		return fmt.Errorf("failed to clean eksecd skills directory: %w", err)
-	}
-
 	artifacts, err := agentsApiClient.FetchArtifacts()
 	if err != nil {
 		return fmt.Errorf("failed to fetch artifacts: %w", err)
 	}
 
-	// Handle empty artifacts list
-	if len(artifacts) == 0 {
-		log.Info("📦 No artifacts configured for this agent")
-		return nil
-	}
-
 	log.Info("📦 Found %d artifact(s) to download", len(artifacts))
 
-	// Download and store each artifact file
+	// Download every artifact file into memory first
+	var downloaded []utils.ArtifactFile
 	for _, artifact := range artifacts {
 		log.Info("📦 Processing %s artifact: %s (%s)", artifact.Type, artifact.Title, artifact.Description)
 
 		for _, file := range artifact.Files {
-			log.Info("📥 Downloading artifact file to: %s", file.Location)
+			log.Info("📥 Downloading artifact file for: %s", file.Location)
 
-			if err := utils.FetchAndStoreArtifact(agentsApiClient, file.AttachmentID, file.Location); err != nil {
+			content, err := utils.FetchArtifactContent(agentsApiClient, file.AttachmentID)
+			if err != nil {
 				return fmt.Errorf("failed to download artifact file %s: %w", file.Location, err)
 			}
-
-			log.Info("✅ Successfully saved artifact file: %s", file.Location)
+			downloaded = append(downloaded, utils.ArtifactFile{Location: file.Location, Content: content})
 		}
 	}
 
+	// Swap in the new rules, MCP configs and skills as a whole, which also removes items
+	// deleted on the server
+	if err := utils.ReplaceStoredArtifacts(downloaded); err != nil {
+		return fmt.Errorf("failed to store artifacts: %w", err)
+	}
+	if len(downloaded) == 0 {
+		log.Info("📦 No artifacts configured for this agent")
+		return nil
+	}
+
 	log.Info("✅ Successfully downloaded all artifacts")
 	return nil
 }
 
+// applyArtifacts deploys the stored rules, MCP configs, skills and permissions
+// for the given agent type
+func applyArtifacts(agentType, workDir, targetHomeDir string) error {
+	// Process rules based on agent type
+	if err := processAgentRules(agentType, workDir, targetHomeDir); err != nil {
+		return fmt.Errorf("failed to process agent rules: %w", err)
+	}
+
+	// Process MCP configs based on agent type
+	if err := processMCPConfigs(agentType, workDir, targetHomeDir); err != nil {
+		return fmt.Errorf("failed to process MCP configs: %w", err)
+	}
+
+	// Process skills based on agent type
+	if err := processSkills(agentType, targetHomeDir); err != nil {
+		return fmt.Errorf("failed to process skills: %w", err)
+	}
+
+	// Process permissions based on agent type (enables yolo mode for OpenCode)
+	if err := processPermissions(agentType, workDir, targetHomeDir); err != nil {
+		return fmt.Errorf("failed to process permissions: %w", err)
+	}
+
+	return nil
+}
+
 // processAgentRules processes rules from eksecd directory based on agent type
 // targetHomeDir specifies the home directory to deploy rules to.
 // If empty, uses the current user's home directory.
@@ -345,7 +392,7 @@ func resolveAutoDetectedRepoContext(gitClient *clients.GitClient) (*models.Repos
 	}, nil
 }
 
-func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner, error) {
+func NewCmdRunner(agentType, permissionMode, model, repoPath string, pushOnly, draftPRs, conventionalCommits bool) (*CmdRunner, error) {
 	log.Info("📋 Starting to initialize CmdRunner with agent: %s", agentType)
 
 	// Validate model compatibility with agent
@@ -413,24 +460,9 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 		log.Info("🏠 Agent exec user configured: %s, deploying artifacts to %s", execUser, targetHomeDir)
 	}
 
-	// Process rules based on agent type
-	if err := processAgentRules(agentType, workDir, targetHomeDir); err != nil {
-		return nil, fmt.Errorf("failed to process agent rules: %w", err)
-	}
-
-	// Process MCP configs based on agent type
-	if err := processMCPConfigs(agentType, workDir, targetHomeDir); err != nil {
-		return nil, fmt.Errorf("failed to process MCP configs: %w", err)
-	}
-
-	// Process skills based on agent type
-	if err := processSkills(agentType, targetHomeDir); err != nil {
-		return nil, fmt.Errorf("failed to process skills: %w", err)
-	}
-
-	// Process permissions based on agent type (enables yolo mode for OpenCode)
-	if err := processPermissions(agentType, workDir, targetHomeDir); err != nil {
-		return nil, fmt.Errorf("failed to process permissions: %w", err)
+	// Process rules, MCP configs, skills and permissions based on agent type
+	if err := applyArtifacts(agentType, workDir, targetHomeDir); err != nil {
+		return nil, err
 	}
 
 	// Create the appropriate CLI agent service (now with all dependencies available)
@@ -447,9 +479,17 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 	}
 
 	gitClient := clients.NewGitClient()
+	if signingKey := envManager.Get("COMMIT_SIGNING_KEY"); signingKey != "" {
+		signing, err := clients.NewCommitSigning(signingKey, envManager.Get("COMMIT_SIGNING_FORMAT"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit signing configuration: %w", err)
+		}
+		log.Info("🔏 Signing agent commits with %s key", signing.Format)
+		gitClient.SetCommitSigning(signing)
+	}
 
 	// Determine state file path
-	statePath := filepath.Join(configDir, "state.json")
+	statePath := filepath.Join(configDir, models.StateFileName)
 
 	// Restore app state from persisted data
 	appState, agentID, err := handlers.RestoreAppState(statePath)
@@ -462,6 +502,18 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 	if err != nil {
 		return nil, err
 	}
+	if pushOnly && repoContext.IsRepoMode {
+		log.Info("📦 Push-only mode: job branches are pushed without opening pull requests")
+		repoContext.PushOnly = true
+	}
+	if draftPRs && repoContext.IsRepoMode && !repoContext.PushOnly {
+		log.Info("📝 Draft-first mode: job pull requests are opened as drafts")
+		repoContext.DraftPRs = true
+	}
+	if conventionalCommits && repoContext.IsRepoMode {
+		log.Info("📝 Conventional Commits mode: each turn's changes are split into logical commits")
+		repoContext.ConventionalCommits = true
+	}
 
 	// Set repository context in app state
 	appState.SetRepositoryContext(repoContext)
@@ -477,6 +529,21 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 	messageSender := handlers.NewMessageSender(connectionState)
 
 	gitUseCase := usecases.NewGitUseCase(gitClient, cliAgent, appState)
+	if configPath := envManager.Get("COMMITLINT_CONFIG"); configPath != "" {
+		rules, err := usecases.LoadCommitLintRules(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commitlint configuration: %w", err)
+		}
+		gitUseCase.SetCommitLintRules(rules)
+	}
+	if branchTemplate := envManager.Get("BRANCH_NAME_TEMPLATE"); branchTemplate != "" {
+		tmpl, err := usecases.ParseBranchNameTemplate(branchTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BRANCH_NAME_TEMPLATE: %w", err)
+		}
+		log.Info("🌿 Naming job branches with template %s", branchTemplate)
+		gitUseCase.SetBranchNameTemplate(tmpl)
+	}
 
 	messageHandler := handlers.NewMessageHandler(cliAgent, gitUseCase, appState, envManager, messageSender, agentsApiClient)
 
@@ -492,6 +559,11 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 		agentsApiClient:  agentsApiClient,
 		wsURL:            wsURL,
 		eksecAPIKey:    eksecAPIKey,
+		drainRequests:    make(chan models.DrainPayload, 1),
+		agentType:        agentType,
+		workDir:          workDir,
+		targetHomeDir:    targetHomeDir,
+		artifactReloads:  make(chan string, 1),
 	}
 
 	// Initialize dual worker pools that persist for the app lifetime
@@ -504,8 +576,11 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 			log.Info("🔧 MAX_CONCURRENCY set to %d (concurrent job processing enabled)", maxConcurrency)
 		}
 	}
-	cr.blockingWorkerPool = workerpool.New(maxConcurrency) // concurrent conversation processing
-	cr.instantWorkerPool = workerpool.New(5)               // parallel PR status checks
+	// Reserve worker slots up to the ceiling so concurrency can be raised at runtime;
+	// the dispatcher's scheduler enforces the actual limit
+	workerSlots := max(maxConcurrency, handlers.MaxConcurrencyCeiling)
+	cr.blockingWorkerPool = workerpool.New(workerSlots) // concurrent conversation processing
+	cr.instantWorkerPool = workerpool.New(5)            // parallel PR status checks
 
 	// Initialize job dispatcher for per-job message sequencing
 	cr.dispatcher = handlers.NewJobDispatcher(
@@ -518,15 +593,45 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 	// Wire up the job evictor so MessageHandler can signal dispatcher to stop failed jobs
 	cr.messageHandler.SetJobEvictor(cr.dispatcher)
 
+	// Wire up the dispatcher so PR review comments can start turns on their jobs
+	cr.messageHandler.SetMessageDispatcher(cr.dispatcher)
+
+	// Pin the checkout mode so later concurrency changes never switch between worktrees and
+	// the main checkout while jobs run. Without worktrees, jobs in a repository share one
+	// checkout and must run one at a time until eksecd is restarted with MAX_CONCURRENCY > 1.
+	useWorktrees := gitUseCase.PinWorktreeMode()
+	maxAllowedConcurrency := workerSlots
+	maxAllowedReason := ""
+	if appState.GetRepositoryContext().IsRepoMode && !useWorktrees {
+		maxAllowedConcurrency = 1
+		maxAllowedReason = "jobs share the main checkout, restart eksecd with MAX_CONCURRENCY > 1 to run jobs in worktrees"
+	}
+	cr.concurrency = handlers.NewConcurrencyController(cr.dispatcher, maxAllowedConcurrency, maxAllowedReason)
+	envManager.RegisterReloadHook(cr.concurrency.EnvReloadHook)
+	log.Info("🎚️ Job concurrency set to %d (max %d)", cr.concurrency.Effective(), maxAllowedConcurrency)
+
+	// Recurring jobs are read from SCHEDULED_JOBS_FILE, defaulting to schedules.json in the config dir
+	scheduleConfigPath := envManager.Get("SCHEDULED_JOBS_FILE")
+	if scheduleConfigPath == "" {
+		scheduleConfigPath = filepath.Join(configDir, "schedules.json")
+	}
+	cr.scheduledJobs = handlers.NewScheduledJobRunner(scheduleConfigPath, cr.dispatcher, appState, func(model string) error {
+		return validateModelForAgent(agentType, model)
+	})
+
+	cr.ciWatcher = handlers.NewCIWatcher(cr.messageHandler, gitUseCase, appState, envManager, cr.dispatcher)
+
 	// Initialize worktree pool if concurrency enabled and in repo mode
 	// Note: repoContext is already set above, we just refresh it here
 	repoContext = appState.GetRepositoryContext()
 	if gitUseCase.ShouldUseWorktrees() && repoContext.IsRepoMode {
 		// Get pool size from environment, default to MAX_CONCURRENCY
 		poolSize := maxConcurrency
+		poolSizePinned := false
 		if envVal := envManager.Get("WORKTREE_POOL_SIZE"); envVal != "" {
 			if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
 				poolSize = val
+				poolSizePinned = true
 			}
 		}
 
@@ -541,6 +646,7 @@ func NewCmdRunner(agentType, permissionMode, model, repoPath string) (*CmdRunner
 			poolSize,
 		)
 		gitUseCase.SetWorktreePool(worktreePool)
+		cr.concurrency.SetWorktreePool(worktreePool, !poolSizePinned)
 
 		// Create context for pool lifecycle
 		cr.poolCtx, cr.poolCancel = context.WithCancel(context.Background())
@@ -616,16 +722,24 @@ func createCLIAgent(
 
 type Options struct {
 	//nolint
-	Agent             string `long:"agent" description:"CLI agent to use (claude, cursor, codex, or opencode)" choice:"claude" choice:"cursor" choice:"codex" choice:"opencode" default:"claude"`
-	BypassPermissions bool   `long:"claude-bypass-permissions" description:"Use bypassPermissions mode for Claude/Codex (only applies when --agent=claude or --agent=codex) (WARNING: Only use in controlled sandbox environments)"`
-	Model             string `long:"model" description:"Model to use (agent-specific: claude: sonnet/haiku/opus or full model name, cursor: gpt-5/sonnet-4/sonnet-4-thinking, codex: any model string, opencode: provider/model format)"`
-	Repo              string `long:"repo" description:"Path to git repository (absolute or relative). If not provided, eksecd runs in no-repo mode with git operations disabled"`
-	Version           bool   `long:"version" short:"v" description:"Show version information"`
+	Agent               string `long:"agent" description:"CLI agent to use (claude, cursor, codex, or opencode)" choice:"claude" choice:"cursor" choice:"codex" choice:"opencode" default:"claude"`
+	BypassPermissions   bool   `long:"claude-bypass-permissions" description:"Use bypassPermissions mode for Claude/Codex (only applies when --agent=claude or --agent=codex) (WARNING: Only use in controlled sandbox environments)"`
+	Model               string `long:"model" description:"Model to use (agent-specific: claude: sonnet/haiku/opus or full model name, cursor: gpt-5/sonnet-4/sonnet-4-thinking, codex: any model string, opencode: provider/model format)"`
+	Repo                string `long:"repo" description:"Path to git repository (absolute or relative). If not provided, eksecd runs in no-repo mode with git operations disabled"`
+	NoPR                bool   `long:"no-pr" description:"Push job branches without opening pull requests (for git servers without pull requests); jobs finish when their branch is merged"`
+	DraftPRs            bool   `long:"draft-prs" description:"Open job pull requests as drafts; they are marked ready for review on request or once their CI checks pass"`
+	ConventionalCommits bool   `long:"conventional-commits" description:"Split each turn's changes into Conventional Commits grouped by the agent and checked against commitlint rules"`
+	Version             bool   `long:"version" short:"v" description:"Show version information"`
+
+	Jobs JobsCommand `command:"jobs" description:"Inspect jobs handled by this agent"`
 }
 
 func main() {
 	var opts Options
 	parser := flags.NewParser(&opts, flags.Default)
+	// Without a subcommand eksecd runs the agent
+	parser.SubcommandsOptional = true
+	opts.Jobs.setOptions(&opts)
 
 	_, err := parser.Parse()
 	if err != nil {
@@ -636,6 +750,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Subcommands run during parsing
+	if parser.Active != nil {
+		os.Exit(0)
+	}
+
 	// Handle version flag
 	if opts.Version {
 		fmt.Printf("%s\n", core.GetVersion())
@@ -699,7 +818,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	cmdRunner, err := NewCmdRunner(opts.Agent, permissionMode, opts.Model, opts.Repo)
+	cmdRunner, err := NewCmdRunner(opts.Agent, permissionMode, opts.Model, opts.Repo, opts.NoPR, opts.DraftPRs, opts.ConventionalCommits)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing CmdRunner: %v\n", err)
 		os.Exit(1)
@@ -768,6 +887,16 @@ func main() {
 	log.Info("🌐 WebSocket URL: %s", cmdRunner.wsURL)
 	log.Info("🔑 Agent ID: %s", cmdRunner.agentID)
 
+	// Start artifact hot reload routine (triggered by SIGHUP or artifacts_updated_v1)
+	reloadCtx, reloadCancel := context.WithCancel(context.Background())
+	defer reloadCancel()
+	cmdRunner.startArtifactsReloadRoutine(reloadCtx)
+
+	// Start concurrency auto-adjust routine (only acts when AUTO_CONCURRENCY is enabled)
+	concurrencyCtx, concurrencyCancel := context.WithCancel(context.Background())
+	defer concurrencyCancel()
+	cmdRunner.concurrency.StartAutoAdjust(concurrencyCtx)
+
 	// Start periodic cleanup routine (runs every 10 minutes) - only in repo mode
 	if repoCtx.IsRepoMode {
 		cleanupCtx, cleanupCancel := context.WithCancel(context.Background())
@@ -775,6 +904,11 @@ func main() {
 		cmdRunner.startCleanupRoutine(cleanupCtx)
 	}
 
+	// Start scheduled jobs routine (checks schedules at the start of every minute)
+	scheduleCtx, scheduleCancel := context.WithCancel(context.Background())
+	defer scheduleCancel()
+	cmdRunner.startScheduledJobsRoutine(scheduleCtx)
+
 	// Set up deferred cleanup
 	defer func() {
 		// Stop worktree pool first (before worker pools to ensure no new acquisitions)
@@ -807,14 +941,28 @@ func main() {
 		}
 
 		// Stop persistent worker pools on shutdown
-		if cmdRunner.blockingWorkerPool != nil {
+		// After a timed-out drain, turns still running would block shutdown, so don't wait for them
+		if cmdRunner.blockingWorkerPool != nil && !cmdRunner.drainTimedOut {
 			cmdRunner.blockingWorkerPool.StopWait()
 		}
 		if cmdRunner.instantWorkerPool != nil {
 			cmdRunner.instantWorkerPool.StopWait()
 		}
+
+		// Close the state store once no job can write to it anymore
+		if err := cmdRunner.appState.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to close state store: %v\n", err)
+		}
 	}()
 
+	// Start CI watcher routine (polls checks of open job PRs) - only in repo mode.
+	// Started after the cleanup above is deferred so it stops before the worker pools do.
+	if repoCtx.IsRepoMode {
+		ciWatchCtx, ciWatchCancel := context.WithCancel(context.Background())
+		defer ciWatchCancel()
+		cmdRunner.startCIWatcherRoutine(ciWatchCtx)
+	}
+
 	// Start Socket.IO client with backoff retry
 	err = cmdRunner.startSocketIOClientWithRetry(cmdRunner.wsURL, cmdRunner.eksecAPIKey)
 	if err != nil {
@@ -864,6 +1012,11 @@ func (cr *CmdRunner) startSocketIOClient(serverURLStr, apiKey string) error {
 	signal.Notify(interrupt, os.Interrupt)
 	defer signal.Stop(interrupt)
 
+	// SIGTERM (e.g. container stop) triggers a graceful drain instead of an immediate exit
+	terminate := make(chan os.Signal, 1)
+	signal.Notify(terminate, syscall.SIGTERM)
+	defer signal.Stop(terminate)
+
 	// Set up Socket.IO client options
 	opts := socket.DefaultOptions()
 	opts.SetTransports(types.NewSet(socket.Polling, socket.WebSocket))
@@ -969,6 +1122,14 @@ func (cr *CmdRunner) startSocketIOClient(serverURLStr, apiKey string) error {
 		// Route messages to appropriate handler
 		switch msg.Type {
 		case models.MessageTypeStartConversation, models.MessageTypeUserMessage:
+			// New conversations are not accepted while draining
+			if msg.Type == models.MessageTypeStartConversation && cr.dispatcher.IsDraining() {
+				instantWorkerPool.Submit(func() {
+					cr.messageHandler.RejectStartConversation(msg)
+				})
+				return
+			}
+
 			// Persist message to queue BEFORE submitting for crash recovery
 			if err := cr.messageHandler.PersistQueuedMessage(msg); err != nil {
 				log.Error("❌ Failed to persist queued message: %v", err)
@@ -976,11 +1137,41 @@ func (cr *CmdRunner) startSocketIOClient(serverURLStr, apiKey string) error {
 
 			// Route through dispatcher for per-job sequential processing
 			cr.dispatcher.Dispatch(msg)
-		case models.MessageTypeCheckIdleJobs:
-			// PR status checks can run in parallel without blocking conversations
+		case models.MessageTypeCheckIdleJobs, models.MessageTypeJobStatusRequest, models.MessageTypeMarkReady:
+			// PR status checks, status queries and marking PRs ready can run in parallel without blocking conversations
 			instantWorkerPool.Submit(func() {
 				cr.messageHandler.HandleMessage(msg)
 			})
+		case models.MessageTypeSetConcurrency:
+			err := cr.concurrency.HandleSetConcurrency(msg)
+			if err != nil {
+				log.Error("❌ Failed to handle set concurrency message: %v", err)
+			}
+			response := models.BaseMessage{
+				ID:      core.NewID("msg"),
+				Type:    models.MessageTypeSetConcurrencyResponse,
+				Payload: cr.concurrency.SetConcurrencyResponse(err),
+			}
+			// QueueMessage blocks until the sender picks the message up
+			instantWorkerPool.Submit(func() {
+				cr.messageSender.QueueMessage("cc_message", response)
+			})
+		case models.MessageTypeArtifactsUpdated:
+			// Reload runs on its own routine since it waits for in-flight turns to finish
+			cr.requestArtifactsReload("artifacts updated on server")
+		case models.MessageTypeDrain:
+			// Drain is orchestrated by the connection loop, which owns the socket lifecycle
+			var payload models.DrainPayload
+			if payloadBytes, err := json.Marshal(msg.Payload); err == nil {
+				if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+					log.Error("❌ Failed to unmarshal drain payload, using defaults: %v", err)
+				}
+			}
+			select {
+			case cr.drainRequests <- payload:
+			default:
+				log.Info("🚰 Drain already requested, ignoring duplicate drain message")
+			}
 		default:
 			// Route other message types through dispatcher
 			cr.dispatcher.Dispatch(msg)
@@ -1011,12 +1202,25 @@ func (cr *CmdRunner) startSocketIOClient(serverURLStr, apiKey string) error {
 	defer pingCancel()
 	cr.startPingRoutine(pingCtx, socketClient, runtimeErrorChan)
 
-	// Wait for interrupt signal or runtime error
+	// Wait for interrupt signal, drain request or runtime error
 	select {
 	case <-interrupt:
 		log.Info("🔌 Interrupt received, closing Socket.IO connection...")
 		socketClient.Disconnect()
 		return nil
+	case <-terminate:
+		log.Info("🚰 SIGTERM received, draining before shutdown...")
+		cr.drain(models.DrainPayload{Reason: "SIGTERM received"})
+		socketClient.Disconnect()
+		return nil
+	case payload := <-cr.drainRequests:
+		log.Info("🚰 Drain requested by server, draining before shutdown...")
+		if payload.Reason == "" {
+			payload.Reason = "drain requested by server"
+		}
+		cr.drain(payload)
+		socketClient.Disconnect()
+		return nil
 	case err := <-runtimeErrorChan:
 		log.Error("❌ Runtime error occurred: %v", err)
 		socketClient.Disconnect()
@@ -1024,6 +1228,58 @@ func (cr *CmdRunner) startSocketIOClient(serverURLStr, apiKey string) error {
 	}
 }
 
+// getDrainTimeout returns how long in-flight turns may run during a drain
+func (cr *CmdRunner) getDrainTimeout(payload models.DrainPayload) time.Duration {
+	if payload.DeadlineSeconds > 0 {
+		return time.Duration(payload.DeadlineSeconds) * time.Second
+	}
+	if envVal := cr.envManager.Get("DRAIN_TIMEOUT_SECONDS"); envVal != "" {
+		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
+			return time.Duration(val) * time.Second
+		}
+		log.Warn("⚠️ Invalid DRAIN_TIMEOUT_SECONDS value %q, using default %s", envVal, defaultDrainTimeout)
+	}
+	return defaultDrainTimeout
+}
+
+// drain stops accepting new conversations, notifies the server, waits for in-flight
+// turns to finish (up to the deadline), interrupts the rest, commits and pushes their
+// unfinished work and persists state so that the agent can exit cleanly
+func (cr *CmdRunner) drain(payload models.DrainPayload) {
+	log.Info("📋 Starting to drain agent: %s", payload.Reason)
+
+	cr.dispatcher.StartDraining()
+	deadline := time.Now().Add(cr.getDrainTimeout(payload))
+
+	// Don't let a lost connection block the drain - the server will notice the disconnect anyway
+	notified := make(chan struct{})
+	go func() {
+		cr.messageHandler.SendAgentDrainingMessage(payload.Reason, deadline, cr.dispatcher.ActiveJobIDs())
+		close(notified)
+	}()
+	select {
+	case <-notified:
+	case <-time.After(10 * time.Second):
+		log.Warn("⚠️ Timed out notifying server about drain, continuing")
+	}
+
+	log.Info("🚰 Waiting for in-flight turns to finish (deadline: %s)", deadline.Format(time.RFC3339))
+	if !cr.dispatcher.WaitForActiveJobs(deadline) {
+		// Stop the turns first so the agents no longer write to the checkouts being committed
+		cr.messageHandler.InterruptRunningTurns(cr.dispatcher.ActiveJobIDs())
+		if !cr.dispatcher.WaitForActiveJobs(time.Now().Add(drainInterruptGracePeriod)) {
+			cr.drainTimedOut = true
+		}
+		cr.messageHandler.CommitInFlightWork(cr.dispatcher.ActiveJobIDs())
+	}
+
+	if err := cr.appState.PersistState(); err != nil {
+		log.Error("❌ Failed to persist state during drain: %v", err)
+	}
+
+	log.Info("📋 Completed successfully - drained agent")
+}
+
 func (cr *CmdRunner) setupProgramLogging() (string, error) {
 	// Get config directory
 	configDir, err := env.GetConfigDir()
@@ -1113,3 +1369,97 @@ func (cr *CmdRunner) startCleanupRoutine(ctx context.Context) {
 		}
 	}()
 }
+
+func (cr *CmdRunner) startCIWatcherRoutine(ctx context.Context) {
+	log.Info("🚦 Starting CI watcher routine")
+	go func() {
+		for {
+			// Re-read the interval every time so env reloads apply
+			timer := time.NewTimer(cr.ciWatcher.PollInterval())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Info("🚦 CI watcher routine stopped")
+				return
+			case <-timer.C:
+				if cr.ciWatcher.Enabled() {
+					cr.ciWatcher.CheckAll(cr.instantWorkerPool.Submit)
+				}
+			}
+		}
+	}()
+}
+
+func (cr *CmdRunner) startScheduledJobsRoutine(ctx context.Context) {
+	log.Info("⏰ Starting scheduled jobs routine (config: %s)", cr.scheduledJobs.ConfigPath())
+	go func() {
+		for {
+			// Wake up at the start of each minute
+			now := time.Now()
+			timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Info("⏰ Scheduled jobs routine stopped")
+				return
+			case firedAt := <-timer.C:
+				cr.scheduledJobs.RunDue(firedAt)
+			}
+		}
+	}()
+}
+
+// requestArtifactsReload schedules an artifact reload. Requests that arrive while a
+// reload is already pending are coalesced into it.
+func (cr *CmdRunner) requestArtifactsReload(reason string) {
+	select {
+	case cr.artifactReloads <- reason:
+		log.Info("📦 Scheduled artifact reload: %s", reason)
+	default:
+		log.Info("📦 Artifact reload already pending, coalescing request: %s", reason)
+	}
+}
+
+func (cr *CmdRunner) startArtifactsReloadRoutine(ctx context.Context) {
+	log.Info("📦 Starting artifact reload routine (SIGHUP or artifacts_updated_v1)")
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("📦 Artifact reload routine stopped")
+				return
+			case <-hangup:
+				cr.requestArtifactsReload("SIGHUP received")
+			case reason := <-cr.artifactReloads:
+				if err := cr.reloadArtifacts(reason); err != nil {
+					log.Error("❌ Failed to reload artifacts: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// reloadArtifacts re-fetches artifacts and re-runs the rules, MCP, skills and permissions
+// processors. It waits until no agent turn is in flight and holds new turns back until
+// the reload is done, so every turn sees a consistent set of artifacts.
+func (cr *CmdRunner) reloadArtifacts(reason string) error {
+	log.Info("📋 Starting to reload artifacts: %s", reason)
+
+	err := cr.messageHandler.RunWithTurnsPaused(func() error {
+		if err := fetchAndStoreArtifacts(cr.agentsApiClient); err != nil {
+			return fmt.Errorf("failed to fetch and store artifacts: %w", err)
+		}
+		return applyArtifacts(cr.agentType, cr.workDir, cr.targetHomeDir)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("📋 Completed successfully - reloaded artifacts")
+	return nil
+}
@@ -67,3 +67,23 @@ func IsClaudeCLISuccessfulResponse(err error) (*ErrClaudeCLISuccessfulResponse,
 	}
 	return nil, false
 }
+
+// ErrAgentInterrupted represents an agent turn that was terminated on request so a new
+// message can take over. It is not a failure: partial changes are kept and the session
+// can be resumed.
+type ErrAgentInterrupted struct {
+	SessionID string // The session ID recovered from the partial output, empty if unknown
+}
+
+func (e *ErrAgentInterrupted) Error() string {
+	return "agent turn was interrupted"
+}
+
+// IsAgentInterrupted checks if an error is caused by an interrupted agent turn
+func IsAgentInterrupted(err error) (*ErrAgentInterrupted, bool) {
+	var interruptedErr *ErrAgentInterrupted
+	if errors.As(err, &interruptedErr) {
+		return interruptedErr, true
+	}
+	return nil, false
+}
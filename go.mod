@@ -13,6 +13,7 @@ require (
 	github.com/oklog/ulid/v2 v2.1.1
 	github.com/zishang520/socket.io/clients/socket/v3 v3.0.0-rc.11
 	github.com/zishang520/socket.io/v3 v3.0.0-rc.11
+	go.etcd.io/bbolt v1.4.3
 )
 
 require (
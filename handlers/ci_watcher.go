@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/core"
+	"eksecd/core/env"
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+const (
+	ciWatchEnvVar              = "CI_WATCH"                  // Set to false to stop watching CI checks
+	ciWatchIntervalEnvVar      = "CI_WATCH_INTERVAL_SECONDS" // How often open job PRs are polled
+	ciAutoFixEnvVar            = "CI_AUTO_FIX"               // Start a fix turn when checks fail
+	ciAutoFixMaxAttemptsEnvVar = "CI_AUTO_FIX_MAX_ATTEMPTS"  // Fix turns per job until checks pass again
+
+	defaultCIWatchInterval      = 2 * time.Minute
+	defaultCIAutoFixMaxAttempts = 2
+)
+
+// CIWatcher polls the CI checks of open job PRs. Once the checks of a new head commit have
+// finished with failures, it reports them to the job's thread and, with CI_AUTO_FIX enabled,
+// starts a follow-up turn asking the agent to fix them. In draft-first mode, a draft PR whose
+// checks pass is marked ready for review. Checks run on the instant worker pool.
+type CIWatcher struct {
+	appState   *models.AppState
+	envManager *env.EnvManager
+	dispatcher *JobDispatcher
+	getChecks  func(prID string) (*clients.PullRequestChecks, error)
+	notify     func(message, processedMessageID, jobID string) error
+	dispatch   func(msg models.BaseMessage)
+	markReady  func(jobID, processedMessageID, reason string) error
+
+	mutex    sync.Mutex
+	checking map[string]bool // Jobs with a check in flight
+}
+
+// NewCIWatcher creates a watcher that reports to threads through messageHandler and starts
+// fix turns through dispatcher
+func NewCIWatcher(
+	messageHandler *MessageHandler,
+	gitUseCase *usecases.GitUseCase,
+	appState *models.AppState,
+	envManager *env.EnvManager,
+	dispatcher *JobDispatcher,
+) *CIWatcher {
+	return &CIWatcher{
+		appState:   appState,
+		envManager: envManager,
+		dispatcher: dispatcher,
+		getChecks:  gitUseCase.GetPRChecks,
+		notify:     messageHandler.sendSystemMessage,
+		dispatch:   dispatcher.Dispatch,
+		markReady:  messageHandler.markPullRequestReady,
+		checking:   make(map[string]bool),
+	}
+}
+
+// Enabled reports whether CI checks are watched. On by default; push-only mode has no PRs.
+func (w *CIWatcher) Enabled() bool {
+	if w.appState.GetRepositoryContext().PushOnly {
+		return false
+	}
+	return w.boolSetting(ciWatchEnvVar, true)
+}
+
+// PollInterval returns how long to wait between polls, from CI_WATCH_INTERVAL_SECONDS
+func (w *CIWatcher) PollInterval() time.Duration {
+	seconds := w.intSetting(ciWatchIntervalEnvVar, 0)
+	if seconds <= 0 {
+		return defaultCIWatchInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// CheckAll submits a check for every job with a PR that is not running a turn
+func (w *CIWatcher) CheckAll(submit func(func())) {
+	for jobID, jobData := range w.appState.GetAllJobs() {
+		if jobData.PullRequestID == "" || jobData.Status == models.JobStatusInProgress {
+			continue
+		}
+
+		w.mutex.Lock()
+		if w.checking[jobID] {
+			w.mutex.Unlock()
+			continue
+		}
+		w.checking[jobID] = true
+		w.mutex.Unlock()
+
+		submit(func() {
+			defer func() {
+				w.mutex.Lock()
+				delete(w.checking, jobID)
+				w.mutex.Unlock()
+			}()
+			if err := w.CheckJob(jobID); err != nil {
+				log.Warn("⚠️ Failed to check CI for job %s: %v", jobID, err)
+			}
+		})
+	}
+}
+
+// CheckJob reports the finished checks of the job PR's head commit once per commit
+func (w *CIWatcher) CheckJob(jobID string) error {
+	jobData, exists := w.appState.GetJobData(jobID)
+	if !exists || jobData.PullRequestID == "" || jobData.Status == models.JobStatusInProgress {
+		return nil
+	}
+
+	checks, err := w.getChecks(jobData.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if checks == nil || checks.HeadCommit == "" || checks.HeadCommit == jobData.CIReportedCommit {
+		return nil
+	}
+	if len(checks.Checks) == 0 || checks.Pending() {
+		log.Info("ℹ️ CI checks for job %s are not finished yet", jobID)
+		return nil
+	}
+
+	failed := checks.Failed()
+	if len(failed) == 0 {
+		log.Info("✅ CI checks passed for job %s at %s", jobID, shortCommitHash(checks.HeadCommit))
+		if err := w.appState.SetJobCIState(jobID, checks.HeadCommit, 0); err != nil {
+			return err
+		}
+		// In draft-first mode, passing checks take the draft out of draft
+		if jobData.PullRequestDraft && w.appState.GetRepositoryContext().DraftPRs {
+			return w.markReady(jobID, jobData.ProcessedMessageID, "CI checks passed")
+		}
+		return nil
+	}
+
+	log.Info("❌ %d CI checks failed for job %s at %s", len(failed), jobID, shortCommitHash(checks.HeadCommit))
+	fixAttempts := jobData.CIFixAttempts
+	startFix := false
+	message := ciFailureMessage(checks.HeadCommit, failed)
+	if w.boolSetting(ciAutoFixEnvVar, false) {
+		maxAttempts := w.intSetting(ciAutoFixMaxAttemptsEnvVar, defaultCIAutoFixMaxAttempts)
+		switch {
+		case w.dispatcher != nil && w.dispatcher.IsDraining():
+			message += "\nNot starting an automatic fix while eksecd is shutting down."
+		case fixAttempts < maxAttempts:
+			fixAttempts++
+			startFix = true
+			message += fmt.Sprintf("\nStarting automatic fix attempt %d of %d.", fixAttempts, maxAttempts)
+		default:
+			message += fmt.Sprintf("\nAutomatic fix limit reached (%d of %d attempts used).", fixAttempts, maxAttempts)
+		}
+	}
+
+	// Record the commit first so a slow thread or dispatch never reports it twice
+	if err := w.appState.SetJobCIState(jobID, checks.HeadCommit, fixAttempts); err != nil {
+		return fmt.Errorf("failed to record CI state: %w", err)
+	}
+	if err := w.notify(message, jobData.ProcessedMessageID, jobID); err != nil {
+		log.Error("❌ Failed to report CI failure for job %s: %v", jobID, err)
+	}
+
+	if startFix {
+		log.Info("🔧 Starting CI fix attempt %d for job %s", fixAttempts, jobID)
+		w.dispatch(models.BaseMessage{
+			ID:   core.NewID("msg"),
+			Type: models.MessageTypeUserMessage,
+			Payload: models.UserMessagePayload{
+				JobID:              jobID,
+				Message:            ciFixPrompt(failed),
+				ProcessedMessageID: core.NewID("msg"),
+				MessageLink:        jobData.MessageLink,
+				Author:             "CI",
+			},
+		})
+	}
+	return nil
+}
+
+// ciFailureMessage lists the failed checks of commit with links to their logs
+func ciFailureMessage(commit string, failed []clients.PullRequestCheck) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CI failed for commit `%s`:", shortCommitHash(commit))
+	for _, check := range failed {
+		fmt.Fprintf(&sb, "\n- `%s`", check.Name)
+		if check.Link != "" {
+			fmt.Fprintf(&sb, " ([logs](%s))", check.Link)
+		}
+	}
+	return sb.String()
+}
+
+// ciFixPrompt asks the agent to fix the failed checks
+func ciFixPrompt(failed []clients.PullRequestCheck) string {
+	names := make([]string, len(failed))
+	for i, check := range failed {
+		names[i] = check.Name
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CI failed with: %s, please fix.\n\nFailed checks:", strings.Join(names, ", "))
+	for _, check := range failed {
+		fmt.Fprintf(&sb, "\n- %s", check.Name)
+		if check.Link != "" {
+			fmt.Fprintf(&sb, ": %s", check.Link)
+		}
+	}
+	sb.WriteString("\n\nRead the logs of the failed checks to find the cause (for GitHub Actions, `gh run view <run-id> --log-failed`), fix it, and keep the changes focused on making CI pass.")
+	return sb.String()
+}
+
+// boolSetting reads a boolean environment setting, falling back to defaultValue
+func (w *CIWatcher) boolSetting(key string, defaultValue bool) bool {
+	envVal := w.envManager.Get(key)
+	if envVal == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(envVal)
+	if err != nil {
+		log.Warn("⚠️ Invalid %s value %q, using %t", key, envVal, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// intSetting reads an integer environment setting, falling back to defaultValue
+func (w *CIWatcher) intSetting(key string, defaultValue int) int {
+	envVal := w.envManager.Get(key)
+	if envVal == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(envVal)
+	if err != nil || value < 0 {
+		log.Warn("⚠️ Invalid %s value %q, using %d", key, envVal, defaultValue)
+		return defaultValue
+	}
+	return value
+}
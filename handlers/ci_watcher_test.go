@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"eksecd/clients"
+	"eksecd/core/env"
+	"eksecd/models"
+)
+
+// testCIWatcher is a CIWatcher with stubbed checks that records thread messages and fix turns
+type testCIWatcher struct {
+	*CIWatcher
+	checks     *clients.PullRequestChecks
+	messages   []string
+	dispatched []models.UserMessagePayload
+	readied    []string // Reasons PRs were marked ready for review
+}
+
+func newTestCIWatcher(t *testing.T) *testCIWatcher {
+	t.Helper()
+	t.Setenv("EKSEC_CONFIG_DIR", t.TempDir())
+	envManager, err := env.NewEnvManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{IsRepoMode: true})
+	if err := appState.UpdateJobData("job-1", models.JobData{
+		JobID:              "job-1",
+		BranchName:         "eksecd/feature",
+		PullRequestID:      "12",
+		ProcessedMessageID: "msg-thread",
+		MessageLink:        "https://team.slack.com/archives/C1/p1",
+		Status:             models.JobStatusCompleted,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &testCIWatcher{}
+	w.CIWatcher = &CIWatcher{
+		appState:   appState,
+		envManager: envManager,
+		getChecks:  func(prID string) (*clients.PullRequestChecks, error) { return w.checks, nil },
+		notify: func(message, processedMessageID, jobID string) error {
+			if processedMessageID != "msg-thread" || jobID != "job-1" {
+				t.Errorf("unexpected message target %s/%s", processedMessageID, jobID)
+			}
+			w.messages = append(w.messages, message)
+			return nil
+		},
+		dispatch: func(msg models.BaseMessage) {
+			w.dispatched = append(w.dispatched, msg.Payload.(models.UserMessagePayload))
+		},
+		markReady: func(jobID, processedMessageID, reason string) error {
+			w.readied = append(w.readied, reason)
+			return appState.SetJobPullRequestDraft(jobID, false)
+		},
+		checking: make(map[string]bool),
+	}
+	return w
+}
+
+func (w *testCIWatcher) check(t *testing.T) {
+	t.Helper()
+	if err := w.CheckJob("job-1"); err != nil {
+		t.Fatalf("failed to check job: %v", err)
+	}
+}
+
+func failingChecks(commit string) *clients.PullRequestChecks {
+	return &clients.PullRequestChecks{
+		HeadCommit: commit,
+		Checks: []clients.PullRequestCheck{
+			{Name: "lint", Bucket: clients.CheckBucketPass, Link: "https://github.com/o/r/actions/runs/1/job/1"},
+			{Name: "test", Bucket: clients.CheckBucketFail, Link: "https://github.com/o/r/actions/runs/1/job/2"},
+		},
+	}
+}
+
+func TestCIWatcher_ReportsFailedChecksOncePerCommit(t *testing.T) {
+	w := newTestCIWatcher(t)
+
+	// Nothing is reported while checks run
+	w.checks = &clients.PullRequestChecks{
+		HeadCommit: "0123456789abcdef",
+		Checks:     []clients.PullRequestCheck{{Name: "test", Bucket: clients.CheckBucketPending}},
+	}
+	w.check(t)
+	if len(w.messages) != 0 {
+		t.Fatalf("expected no message for pending checks, got %v", w.messages)
+	}
+
+	w.checks = failingChecks("0123456789abcdef")
+	w.check(t)
+	w.check(t)
+	expected := "CI failed for commit `0123456`:\n- `test` ([logs](https://github.com/o/r/actions/runs/1/job/2))"
+	if len(w.messages) != 1 || w.messages[0] != expected {
+		t.Fatalf("expected one failure report %q, got %v", expected, w.messages)
+	}
+	if len(w.dispatched) != 0 {
+		t.Errorf("expected no fix turn without CI_AUTO_FIX, got %v", w.dispatched)
+	}
+
+	// A new failing commit is reported again
+	w.checks = failingChecks("fedcba9876543210")
+	w.check(t)
+	if len(w.messages) != 2 {
+		t.Errorf("expected a report for the new commit, got %v", w.messages)
+	}
+}
+
+func TestCIWatcher_SkipsJobsRunningATurn(t *testing.T) {
+	w := newTestCIWatcher(t)
+	jobData, _ := w.appState.GetJobData("job-1")
+	jobData.Status = models.JobStatusInProgress
+	if err := w.appState.UpdateJobData("job-1", *jobData); err != nil {
+		t.Fatal(err)
+	}
+
+	w.checks = failingChecks("0123456789abcdef")
+	w.check(t)
+	if len(w.messages) != 0 {
+		t.Errorf("expected no report while a turn runs, got %v", w.messages)
+	}
+}
+
+func TestCIWatcher_AutoFixUpToMaxAttempts(t *testing.T) {
+	t.Setenv("CI_AUTO_FIX", "true")
+	t.Setenv("CI_AUTO_FIX_MAX_ATTEMPTS", "1")
+	w := newTestCIWatcher(t)
+
+	w.checks = failingChecks("0123456789abcdef")
+	w.check(t)
+	if len(w.dispatched) != 1 {
+		t.Fatalf("expected one fix turn, got %d", len(w.dispatched))
+	}
+	fix := w.dispatched[0]
+	if fix.JobID != "job-1" || fix.MessageLink != "https://team.slack.com/archives/C1/p1" || fix.ProcessedMessageID == "" {
+		t.Errorf("unexpected fix turn payload %+v", fix)
+	}
+	if !strings.HasPrefix(fix.Message, "CI failed with: test, please fix.") || !strings.Contains(fix.Message, "runs/1/job/2") {
+		t.Errorf("unexpected fix prompt %q", fix.Message)
+	}
+	if !strings.HasSuffix(w.messages[0], "Starting automatic fix attempt 1 of 1.") {
+		t.Errorf("expected fix attempt note, got %q", w.messages[0])
+	}
+
+	// The fix turn pushed a commit that still fails
+	w.checks = failingChecks("fedcba9876543210")
+	w.check(t)
+	if len(w.dispatched) != 1 {
+		t.Errorf("expected no fix turn beyond the limit, got %d", len(w.dispatched))
+	}
+	if !strings.HasSuffix(w.messages[1], "Automatic fix limit reached (1 of 1 attempts used).") {
+		t.Errorf("expected stop note, got %q", w.messages[1])
+	}
+
+	// Passing checks reset the attempts
+	w.checks = &clients.PullRequestChecks{
+		HeadCommit: "1111111111111111",
+		Checks:     []clients.PullRequestCheck{{Name: "test", Bucket: clients.CheckBucketPass}},
+	}
+	w.check(t)
+	if jobData, _ := w.appState.GetJobData("job-1"); jobData.CIFixAttempts != 0 || jobData.CIReportedCommit != "1111111111111111" {
+		t.Errorf("expected attempts reset at the passing commit, got %+v", jobData)
+	}
+	if len(w.messages) != 2 {
+		t.Errorf("expected passing checks not to be reported, got %v", w.messages)
+	}
+
+	w.checks = failingChecks("2222222222222222")
+	w.check(t)
+	if len(w.dispatched) != 2 {
+		t.Errorf("expected a new fix turn after checks passed, got %d", len(w.dispatched))
+	}
+}
+
+func TestCIWatcher_MarksDraftReadyWhenChecksPass(t *testing.T) {
+	w := newTestCIWatcher(t)
+	w.appState.SetRepositoryContext(&models.RepositoryContext{IsRepoMode: true, DraftPRs: true})
+	if err := w.appState.SetJobPullRequestDraft("job-1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	w.checks = failingChecks("0123456789abcdef")
+	w.check(t)
+	if len(w.readied) != 0 {
+		t.Fatalf("expected a failing draft to stay a draft, got %v", w.readied)
+	}
+
+	passing := &clients.PullRequestChecks{
+		HeadCommit: "1111111111111111",
+		Checks:     []clients.PullRequestCheck{{Name: "test", Bucket: clients.CheckBucketPass}},
+	}
+	w.checks = passing
+	w.check(t)
+	if len(w.readied) != 1 || w.readied[0] != "CI checks passed" {
+		t.Fatalf("expected the draft marked ready once checks pass, got %v", w.readied)
+	}
+
+	// Ready PRs are left alone on later passing commits
+	passing.HeadCommit = "2222222222222222"
+	w.check(t)
+	if len(w.readied) != 1 {
+		t.Errorf("expected no second ready transition, got %v", w.readied)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"eksecd/core/log"
+	"eksecd/models"
+)
+
+// coalesceFollowUpsEnvVar enables merging queued follow-up messages into a single turn
+const coalesceFollowUpsEnvVar = "COALESCE_FOLLOW_UP_MESSAGES"
+
+// coalesceFollowUpsEnabled reports whether queued follow-up messages for a job should be
+// merged into one prompt instead of running one turn per message
+func (mh *MessageHandler) coalesceFollowUpsEnabled() bool {
+	if mh == nil || mh.envManager == nil {
+		return false
+	}
+	envVal := mh.envManager.Get(coalesceFollowUpsEnvVar)
+	if envVal == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(envVal)
+	if err != nil {
+		log.Warn("⚠️ Invalid %s value %q, coalescing disabled", coalesceFollowUpsEnvVar, envVal)
+		return false
+	}
+	return enabled
+}
+
+// collectFollowUps takes a user message and drains any further user messages already
+// buffered in the job's channel without blocking. Draining stops at the first message of
+// another type, which is returned as carried so the caller processes it next.
+func collectFollowUps(first models.BaseMessage, ch chan models.BaseMessage) (batch []models.BaseMessage, carried *models.BaseMessage) {
+	batch = []models.BaseMessage{first}
+	for {
+		select {
+		case next, ok := <-ch:
+			if !ok {
+				return batch, nil
+			}
+			if next.Type != models.MessageTypeUserMessage {
+				return batch, &next
+			}
+			batch = append(batch, next)
+		default:
+			return batch, nil
+		}
+	}
+}
+
+// coalesceUserMessages merges several follow-up messages into one. The merged prompt keeps
+// the original order and authors, attachments are concatenated in order, and the last
+// message's ID and link are used for replies. Earlier IDs are kept in CoalescedMessageIDs
+// so each of them is acknowledged.
+func coalesceUserMessages(batch []models.BaseMessage) (models.BaseMessage, error) {
+	if len(batch) == 1 {
+		return batch[0], nil
+	}
+
+	payloads := make([]models.UserMessagePayload, 0, len(batch))
+	for _, msg := range batch {
+		var payload models.UserMessagePayload
+		if err := unmarshalPayload(msg.Payload, &payload); err != nil {
+			return models.BaseMessage{}, fmt.Errorf("failed to unmarshal user message payload: %w", err)
+		}
+		payloads = append(payloads, payload)
+	}
+
+	last := payloads[len(payloads)-1]
+	merged := models.UserMessagePayload{
+		JobID:              last.JobID,
+		ProcessedMessageID: last.ProcessedMessageID,
+		MessageLink:        last.MessageLink,
+		Author:             last.Author,
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "%d messages arrived while you were working. Address all of them, in order:\n", len(payloads))
+	for i, payload := range payloads {
+		author := payload.Author
+		if author == "" {
+			author = "user"
+		}
+		fmt.Fprintf(&prompt, "\n[%d] From %s:\n%s\n", i+1, author, payload.Message)
+
+		merged.Attachments = append(merged.Attachments, payload.Attachments...)
+		merged.Interrupt = merged.Interrupt || payload.Interrupt
+		merged.ReviewThreadIDs = append(merged.ReviewThreadIDs, payload.ReviewThreadIDs...)
+		merged.CoalescedMessageIDs = append(merged.CoalescedMessageIDs, payload.CoalescedMessageIDs...)
+		if i < len(payloads)-1 {
+			merged.CoalescedMessageIDs = append(merged.CoalescedMessageIDs, payload.ProcessedMessageID)
+		}
+	}
+	merged.Message = strings.TrimRight(prompt.String(), "\n")
+
+	return models.BaseMessage{
+		ID:      batch[len(batch)-1].ID,
+		Type:    models.MessageTypeUserMessage,
+		Payload: merged,
+	}, nil
+}
+
+// acknowledgedMessageIDs returns every ProcessedMessageID a user message stands for
+func acknowledgedMessageIDs(payload models.UserMessagePayload) []string {
+	ids := make([]string, 0, len(payload.CoalescedMessageIDs)+1)
+	ids = append(ids, payload.CoalescedMessageIDs...)
+	return append(ids, payload.ProcessedMessageID)
+}
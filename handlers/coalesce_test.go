@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"eksecd/models"
+)
+
+func createTestUserMessage(jobID, processedMsgID, author, text string, attachmentIDs ...string) models.BaseMessage {
+	var attachments []models.MessageAttachment
+	for _, id := range attachmentIDs {
+		attachments = append(attachments, models.MessageAttachment{AttachmentID: id})
+	}
+	return models.BaseMessage{
+		ID:   "msg-" + processedMsgID,
+		Type: models.MessageTypeUserMessage,
+		Payload: models.UserMessagePayload{
+			JobID:              jobID,
+			Message:            text,
+			ProcessedMessageID: processedMsgID,
+			MessageLink:        "https://team.slack.com/archives/C1/p" + processedMsgID,
+			Author:             author,
+			Attachments:        attachments,
+		},
+	}
+}
+
+func TestCollectFollowUps_StopsAtOtherMessageType(t *testing.T) {
+	ch := make(chan models.BaseMessage, 10)
+	ch <- createTestUserMessage("job-1", "pm-2", "bob", "second")
+	ch <- createTestMessage(models.MessageTypeStartConversation, "job-1")
+	ch <- createTestUserMessage("job-1", "pm-3", "alice", "third")
+
+	batch, carried := collectFollowUps(createTestUserMessage("job-1", "pm-1", "alice", "first"), ch)
+
+	if len(batch) != 2 {
+		t.Fatalf("expected 2 messages in batch, got %d", len(batch))
+	}
+	if carried == nil || carried.Type != models.MessageTypeStartConversation {
+		t.Fatalf("expected start conversation message to be carried, got %+v", carried)
+	}
+	if len(ch) != 1 {
+		t.Errorf("expected 1 message left in channel, got %d", len(ch))
+	}
+}
+
+func TestCollectFollowUps_EmptyChannel(t *testing.T) {
+	ch := make(chan models.BaseMessage, 10)
+
+	batch, carried := collectFollowUps(createTestUserMessage("job-1", "pm-1", "alice", "first"), ch)
+
+	if len(batch) != 1 || carried != nil {
+		t.Errorf("expected single message and nothing carried, got %d messages, carried %+v", len(batch), carried)
+	}
+}
+
+func TestCoalesceUserMessages(t *testing.T) {
+	batch := []models.BaseMessage{
+		createTestUserMessage("job-1", "pm-1", "alice", "please add tests", "att-1"),
+		createTestUserMessage("job-1", "pm-2", "", "also fix the lint"),
+		createTestUserMessage("job-1", "pm-3", "bob", "and update the README", "att-2", "att-3"),
+	}
+
+	merged, err := coalesceUserMessages(batch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payload, ok := merged.Payload.(models.UserMessagePayload)
+	if !ok {
+		t.Fatalf("expected UserMessagePayload, got %T", merged.Payload)
+	}
+	if payload.ProcessedMessageID != "pm-3" {
+		t.Errorf("expected last ProcessedMessageID pm-3, got %s", payload.ProcessedMessageID)
+	}
+	if payload.MessageLink != "https://team.slack.com/archives/C1/ppm-3" {
+		t.Errorf("expected last message link, got %s", payload.MessageLink)
+	}
+
+	acked := acknowledgedMessageIDs(payload)
+	if strings.Join(acked, ",") != "pm-1,pm-2,pm-3" {
+		t.Errorf("expected all messages acknowledged in order, got %v", acked)
+	}
+
+	var attachmentIDs []string
+	for _, att := range payload.Attachments {
+		attachmentIDs = append(attachmentIDs, att.AttachmentID)
+	}
+	if strings.Join(attachmentIDs, ",") != "att-1,att-2,att-3" {
+		t.Errorf("expected attachments merged in order, got %v", attachmentIDs)
+	}
+
+	first := strings.Index(payload.Message, "[1] From alice:\nplease add tests")
+	second := strings.Index(payload.Message, "[2] From user:\nalso fix the lint")
+	third := strings.Index(payload.Message, "[3] From bob:\nand update the README")
+	if first < 0 || second < 0 || third < 0 || !(first < second && second < third) {
+		t.Errorf("expected messages with authors in order, got:\n%s", payload.Message)
+	}
+}
+
+func TestCoalesceUserMessages_SingleMessageUnchanged(t *testing.T) {
+	msg := createTestUserMessage("job-1", "pm-1", "alice", "hello")
+
+	merged, err := coalesceUserMessages([]models.BaseMessage{msg})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload := merged.Payload.(models.UserMessagePayload)
+	if payload.Message != "hello" || len(payload.CoalescedMessageIDs) != 0 {
+		t.Errorf("expected single message to pass through unchanged, got %+v", payload)
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+	"eksecd/utils"
+)
+
+const (
+	// MaxConcurrencyCeiling is the number of worker slots reserved for conversation
+	// processing. Concurrency can be raised at runtime up to this many jobs.
+	MaxConcurrencyCeiling = 32
+
+	// memoryCheckInterval is how often memory pressure is checked in auto mode
+	memoryCheckInterval = 30 * time.Second
+
+	// defaultMemoryPressurePercent is the memory usage at which auto mode lowers
+	// concurrency. Can be overridden via MEMORY_PRESSURE_THRESHOLD_PERCENT.
+	defaultMemoryPressurePercent = 85
+
+	// memoryRecoveryMarginPercent is how far usage must drop below the threshold
+	// before auto mode raises concurrency again, so it doesn't flap
+	memoryRecoveryMarginPercent = 10
+)
+
+// ConcurrencyController keeps the number of concurrently processed jobs and the worktree
+// pool target in line with MAX_CONCURRENCY, set_concurrency_v1 overrides and, in auto
+// mode, memory pressure. Lowering concurrency never stops running jobs; they finish and
+// no new ones start until the running count drops below the new limit.
+type ConcurrencyController struct {
+	mutex                  sync.Mutex
+	dispatcher             *JobDispatcher
+	worktreePool           *usecases.WorktreePool // nil when worktrees are not pooled
+	poolFollowsConcurrency bool                   // false when WORKTREE_POOL_SIZE pins the pool size
+	maxAllowed             int                    // Upper bound from the worker pool size and checkout mode
+	maxAllowedReason       string                 // Explains maxAllowed when a request exceeds it
+	configured             int                    // From MAX_CONCURRENCY or the latest set_concurrency_v1
+	lastEnvValue           string                 // Last seen MAX_CONCURRENCY, so overrides survive unrelated reloads
+	auto                   bool                   // Lower concurrency under memory pressure
+	autoOverridden         bool                   // auto was set by the server and ignores AUTO_CONCURRENCY
+	memoryLimit            int                    // Limit imposed by memory pressure, 0 when not limiting
+	effective              int
+	readMemory             func() (utils.MemoryUsage, error)
+}
+
+// NewConcurrencyController creates a controller that applies MAX_CONCURRENCY to the
+// dispatcher right away. maxAllowed caps concurrency regardless of configuration, and
+// maxAllowedReason is reported to the server when it asks for more.
+func NewConcurrencyController(dispatcher *JobDispatcher, maxAllowed int, maxAllowedReason string) *ConcurrencyController {
+	if maxAllowed < 1 {
+		maxAllowed = 1
+	}
+	c := &ConcurrencyController{
+		dispatcher:       dispatcher,
+		maxAllowed:       maxAllowed,
+		maxAllowedReason: maxAllowedReason,
+		configured:       1,
+		readMemory:       utils.ReadMemoryUsage,
+	}
+	c.EnvReloadHook()
+	return c
+}
+
+// SetWorktreePool makes the controller adjust the pool's target size along with
+// concurrency, unless the pool size is pinned by WORKTREE_POOL_SIZE
+func (c *ConcurrencyController) SetWorktreePool(pool *usecases.WorktreePool, followsConcurrency bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.worktreePool = pool
+	c.poolFollowsConcurrency = followsConcurrency
+	if pool != nil && followsConcurrency {
+		pool.SetTargetSize(c.effective)
+	}
+}
+
+// EnvReloadHook re-reads MAX_CONCURRENCY and AUTO_CONCURRENCY. A changed MAX_CONCURRENCY
+// replaces any earlier server override. Reads the process environment directly since
+// EnvManager holds its lock while running reload hooks.
+func (c *ConcurrencyController) EnvReloadHook() {
+	envValue := os.Getenv("MAX_CONCURRENCY")
+	autoValue := os.Getenv("AUTO_CONCURRENCY")
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if envValue != c.lastEnvValue {
+		c.lastEnvValue = envValue
+		configured := 1
+		if envValue != "" {
+			val, err := strconv.Atoi(envValue)
+			if err != nil || val < 1 {
+				log.Warn("⚠️ Invalid MAX_CONCURRENCY value %q, using 1", envValue)
+			} else {
+				configured = val
+			}
+		}
+		c.configured = configured
+	}
+
+	if !c.autoOverridden {
+		auto, _ := strconv.ParseBool(autoValue)
+		c.setAutoLocked(auto)
+	}
+
+	c.applyLocked()
+}
+
+// HandleSetConcurrency applies a set_concurrency_v1 message from the server. A request
+// above the agent's maximum is rejected as a whole and changes nothing.
+func (c *ConcurrencyController) HandleSetConcurrency(msg models.BaseMessage) error {
+	var payload models.SetConcurrencyPayload
+	if err := unmarshalPayload(msg.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal set concurrency payload: %w", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if payload.MaxConcurrency > c.maxAllowed {
+		err := fmt.Errorf("max_concurrency %d exceeds the maximum of %d for this agent", payload.MaxConcurrency, c.maxAllowed)
+		if c.maxAllowedReason != "" {
+			err = fmt.Errorf("%w: %s", err, c.maxAllowedReason)
+		}
+		return err
+	}
+
+	if payload.MaxConcurrency > 0 {
+		c.configured = payload.MaxConcurrency
+	}
+	if payload.AutoConcurrency != nil {
+		c.autoOverridden = true
+		c.setAutoLocked(*payload.AutoConcurrency)
+	}
+	log.Info("🎚️ Server set concurrency to %d (auto: %t)", c.configured, c.auto)
+
+	c.applyLocked()
+	return nil
+}
+
+// SetConcurrencyResponse reports the outcome of HandleSetConcurrency back to the server
+func (c *ConcurrencyController) SetConcurrencyResponse(err error) models.SetConcurrencyResponsePayload {
+	response := models.SetConcurrencyResponsePayload{
+		Applied:     err == nil,
+		Concurrency: c.Effective(),
+	}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	return response
+}
+
+// Effective returns the number of jobs currently allowed to run at a time
+func (c *ConcurrencyController) Effective() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.effective
+}
+
+// CheckMemoryPressure lowers concurrency by one while memory usage is above the threshold
+// and raises it back step by step once usage has recovered. Does nothing outside auto mode.
+func (c *ConcurrencyController) CheckMemoryPressure() {
+	c.mutex.Lock()
+	auto := c.auto
+	c.mutex.Unlock()
+	if !auto {
+		return
+	}
+
+	usage, err := c.readMemory()
+	if err != nil {
+		log.Warn("⚠️ Failed to read memory usage: %v", err)
+		return
+	}
+	usedPercent := usage.UsedFraction() * 100
+	threshold := float64(c.memoryPressurePercent())
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch {
+	case usedPercent >= threshold && c.effective > 1:
+		c.memoryLimit = c.effective - 1
+		log.Warn("⚠️ Memory usage at %.0f%% (%s), lowering concurrency to %d", usedPercent, usage.Source, c.memoryLimit)
+	case c.memoryLimit > 0 && usedPercent < threshold-memoryRecoveryMarginPercent:
+		c.memoryLimit++
+		if c.memoryLimit >= c.configured {
+			c.memoryLimit = 0
+		}
+		log.Info("🎚️ Memory usage recovered to %.0f%% (%s), raising concurrency", usedPercent, usage.Source)
+	default:
+		return
+	}
+
+	c.applyLocked()
+}
+
+// StartAutoAdjust checks memory pressure periodically until ctx is cancelled
+func (c *ConcurrencyController) StartAutoAdjust(ctx context.Context) {
+	log.Info("🎚️ Starting concurrency auto-adjust routine (every %s)", memoryCheckInterval)
+	go func() {
+		ticker := time.NewTicker(memoryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info("🎚️ Concurrency auto-adjust routine stopped")
+				return
+			case <-ticker.C:
+				c.CheckMemoryPressure()
+			}
+		}
+	}()
+}
+
+// setAutoLocked switches auto mode, dropping any memory limit when it is turned off.
+// Must be called with mutex held.
+func (c *ConcurrencyController) setAutoLocked(auto bool) {
+	c.auto = auto
+	if !auto {
+		c.memoryLimit = 0
+	}
+}
+
+// applyLocked pushes the effective concurrency to the dispatcher and worktree pool.
+// Must be called with mutex held.
+func (c *ConcurrencyController) applyLocked() {
+	effective := c.configured
+	if effective > c.maxAllowed {
+		log.Warn("⚠️ Concurrency %d exceeds the maximum of %d for this agent, using %d", effective, c.maxAllowed, c.maxAllowed)
+		effective = c.maxAllowed
+	}
+	if c.memoryLimit > 0 && c.memoryLimit < effective {
+		effective = c.memoryLimit
+	}
+	if effective < 1 {
+		effective = 1
+	}
+
+	if effective == c.effective {
+		return
+	}
+
+	log.Info("🎚️ Concurrency changed from %d to %d", c.effective, effective)
+	c.effective = effective
+	c.dispatcher.SetConcurrency(effective)
+	if c.worktreePool != nil && c.poolFollowsConcurrency {
+		c.worktreePool.SetTargetSize(effective)
+	}
+}
+
+// memoryPressurePercent returns the memory usage percentage that counts as pressure
+func (c *ConcurrencyController) memoryPressurePercent() int {
+	envVal := os.Getenv("MEMORY_PRESSURE_THRESHOLD_PERCENT")
+	if envVal == "" {
+		return defaultMemoryPressurePercent
+	}
+	val, err := strconv.Atoi(envVal)
+	if err != nil || val < 1 || val > 100 {
+		log.Warn("⚠️ Invalid MEMORY_PRESSURE_THRESHOLD_PERCENT value %q, using default %d", envVal, defaultMemoryPressurePercent)
+		return defaultMemoryPressurePercent
+	}
+	return val
+}
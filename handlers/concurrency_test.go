@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gammazero/workerpool"
+
+	"eksecd/models"
+	"eksecd/utils"
+)
+
+func newTestConcurrencyController(t *testing.T, maxAllowed int) (*ConcurrencyController, *JobDispatcher) {
+	t.Helper()
+	wp := workerpool.New(maxAllowed)
+	t.Cleanup(wp.StopWait)
+	dispatcher := NewJobDispatcher(nil, wp, createTestAppStateNoPath())
+	return NewConcurrencyController(dispatcher, maxAllowed, ""), dispatcher
+}
+
+func TestConcurrencyController_EnvReload(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "2")
+	t.Setenv("AUTO_CONCURRENCY", "")
+	c, dispatcher := newTestConcurrencyController(t, 8)
+
+	if dispatcher.Concurrency() != 2 {
+		t.Fatalf("expected initial concurrency 2, got %d", dispatcher.Concurrency())
+	}
+
+	t.Setenv("MAX_CONCURRENCY", "4")
+	c.EnvReloadHook()
+	if dispatcher.Concurrency() != 4 {
+		t.Errorf("expected concurrency 4 after reload, got %d", dispatcher.Concurrency())
+	}
+
+	t.Setenv("MAX_CONCURRENCY", "20")
+	c.EnvReloadHook()
+	if dispatcher.Concurrency() != 8 {
+		t.Errorf("expected concurrency clamped to 8, got %d", dispatcher.Concurrency())
+	}
+
+	t.Setenv("MAX_CONCURRENCY", "nope")
+	c.EnvReloadHook()
+	if dispatcher.Concurrency() != 1 {
+		t.Errorf("expected invalid value to fall back to 1, got %d", dispatcher.Concurrency())
+	}
+}
+
+func TestConcurrencyController_ServerOverride(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "2")
+	t.Setenv("AUTO_CONCURRENCY", "")
+	c, dispatcher := newTestConcurrencyController(t, 8)
+
+	auto := true
+	err := c.HandleSetConcurrency(models.BaseMessage{
+		Type:    models.MessageTypeSetConcurrency,
+		Payload: models.SetConcurrencyPayload{MaxConcurrency: 5, AutoConcurrency: &auto},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dispatcher.Concurrency() != 5 {
+		t.Errorf("expected concurrency 5, got %d", dispatcher.Concurrency())
+	}
+
+	// Unrelated reloads keep the override; only a changed MAX_CONCURRENCY replaces it
+	c.EnvReloadHook()
+	if dispatcher.Concurrency() != 5 {
+		t.Errorf("expected override to survive unrelated reload, got %d", dispatcher.Concurrency())
+	}
+	if !c.auto {
+		t.Errorf("expected server-set auto mode to ignore AUTO_CONCURRENCY")
+	}
+
+	t.Setenv("MAX_CONCURRENCY", "3")
+	c.EnvReloadHook()
+	if dispatcher.Concurrency() != 3 {
+		t.Errorf("expected changed MAX_CONCURRENCY to replace override, got %d", dispatcher.Concurrency())
+	}
+}
+
+func TestConcurrencyController_RejectsOverrideAboveMaximum(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "1")
+	t.Setenv("AUTO_CONCURRENCY", "")
+	wp := workerpool.New(1)
+	t.Cleanup(wp.StopWait)
+	dispatcher := NewJobDispatcher(nil, wp, createTestAppStateNoPath())
+	c := NewConcurrencyController(dispatcher, 1, "jobs share the main checkout")
+
+	auto := true
+	err := c.HandleSetConcurrency(models.BaseMessage{
+		Type:    models.MessageTypeSetConcurrency,
+		Payload: models.SetConcurrencyPayload{MaxConcurrency: 4, AutoConcurrency: &auto},
+	})
+	if err == nil {
+		t.Fatal("expected a request above the maximum to be rejected")
+	}
+	if c.auto {
+		t.Errorf("expected a rejected request to change nothing")
+	}
+
+	response := c.SetConcurrencyResponse(err)
+	if response.Applied || response.Concurrency != 1 || !strings.Contains(response.Error, "jobs share the main checkout") {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestConcurrencyController_MemoryPressure(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "3")
+	t.Setenv("AUTO_CONCURRENCY", "true")
+	t.Setenv("MEMORY_PRESSURE_THRESHOLD_PERCENT", "80")
+	c, dispatcher := newTestConcurrencyController(t, 8)
+
+	used := uint64(90)
+	c.readMemory = func() (utils.MemoryUsage, error) {
+		return utils.MemoryUsage{UsedBytes: used, LimitBytes: 100, Source: "test"}, nil
+	}
+
+	c.CheckMemoryPressure()
+	c.CheckMemoryPressure()
+	if dispatcher.Concurrency() != 1 {
+		t.Fatalf("expected concurrency lowered to 1, got %d", dispatcher.Concurrency())
+	}
+	c.CheckMemoryPressure()
+	if dispatcher.Concurrency() != 1 {
+		t.Errorf("expected concurrency to never drop below 1, got %d", dispatcher.Concurrency())
+	}
+
+	// Within the recovery margin nothing changes
+	used = 75
+	c.CheckMemoryPressure()
+	if dispatcher.Concurrency() != 1 {
+		t.Errorf("expected concurrency to stay at 1 within recovery margin, got %d", dispatcher.Concurrency())
+	}
+
+	used = 50
+	c.CheckMemoryPressure()
+	if dispatcher.Concurrency() != 2 {
+		t.Errorf("expected concurrency raised to 2, got %d", dispatcher.Concurrency())
+	}
+	c.CheckMemoryPressure()
+	if dispatcher.Concurrency() != 3 || c.memoryLimit != 0 {
+		t.Errorf("expected concurrency back at 3 with no memory limit, got %d (limit %d)", dispatcher.Concurrency(), c.memoryLimit)
+	}
+}
+
+func TestConcurrencyController_MemoryPressureIgnoredWithoutAuto(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "3")
+	t.Setenv("AUTO_CONCURRENCY", "")
+	c, dispatcher := newTestConcurrencyController(t, 8)
+	c.readMemory = func() (utils.MemoryUsage, error) {
+		return utils.MemoryUsage{UsedBytes: 99, LimitBytes: 100}, nil
+	}
+
+	c.CheckMemoryPressure()
+	if dispatcher.Concurrency() != 3 {
+		t.Errorf("expected concurrency unchanged outside auto mode, got %d", dispatcher.Concurrency())
+	}
+}
@@ -16,6 +16,8 @@ const (
 	seenMessageTTL = 5 * time.Minute
 	// cleanupInterval is how often we run cleanup of old seen messages
 	cleanupInterval = 5 * time.Minute
+	// drainPollInterval is how often we check for in-flight jobs while draining
+	drainPollInterval = 500 * time.Millisecond
 )
 
 // JobDispatcher routes messages to per-job channels to ensure sequential processing
@@ -28,6 +30,9 @@ type JobDispatcher struct {
 	handler      *MessageHandler
 	workerPool   *workerpool.WorkerPool
 	appState     *models.AppState
+	draining     bool // When true, no new job processors are started
+	scheduler    *JobScheduler
+	jobInfo      map[string]JobSchedulingInfo // JobID → scheduling info from its start message
 }
 
 // NewJobDispatcher creates a new JobDispatcher instance
@@ -43,6 +48,8 @@ func NewJobDispatcher(
 		handler:      handler,
 		workerPool:   workerPool,
 		appState:     appState,
+		scheduler:    NewJobScheduler(workerPool, workerPool.Size()),
+		jobInfo:      make(map[string]JobSchedulingInfo),
 	}
 }
 
@@ -61,9 +68,18 @@ func (d *JobDispatcher) Dispatch(msg models.BaseMessage) {
 		}
 		d.seenMessages[processedMsgID] = time.Now()
 		d.mutex.Unlock()
+
+		if d.replayIfProcessed(processedMsgID) {
+			return
+		}
 	}
 
 	jobID := d.extractJobID(msg)
+	if jobID != "" && d.IsDraining() {
+		// Message is already persisted in the queue and will be picked up by recovery on restart
+		log.Info("🚰 Draining, leaving message for job %s queued for recovery", jobID)
+		return
+	}
 	if jobID == "" {
 		// No job ID - process directly via worker pool (e.g., CheckIdleJobs)
 		d.workerPool.Submit(func() {
@@ -83,14 +99,31 @@ func (d *JobDispatcher) Dispatch(msg models.BaseMessage) {
 	}
 	d.mutex.Unlock()
 
-	// Submit worker outside of lock to avoid blocking other dispatchers
+	// Schedule worker outside of lock to avoid blocking other dispatchers
 	if !exists {
-		d.workerPool.Submit(func() {
+		run := func() {
 			d.processJobMessages(jobID, ch)
-		})
+		}
+		if d.scheduler != nil {
+			d.scheduler.Schedule(jobID, d.schedulingInfo(jobID, msg), run)
+		} else {
+			d.workerPool.Submit(run)
+		}
+	}
+
+	// An interrupt goes ahead of the messages already queued, and then stops the running turn
+	// so it is processed next
+	if d.isInterrupt(msg) {
+		d.queueFirst(jobID, ch, msg)
+		if d.handler != nil {
+			d.handler.InterruptJob(jobID)
+		}
+		return
 	}
 
 	// Send message to the job's channel (non-blocking since buffer is 100)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 	select {
 	case ch <- msg:
 		log.Info("📥 Queued message to job %s channel", jobID)
@@ -99,6 +132,33 @@ func (d *JobDispatcher) Dispatch(msg models.BaseMessage) {
 	}
 }
 
+// queueFirst puts msg at the front of the job's channel. The lock keeps other messages from
+// being queued in between; the job's processor only reads between turns.
+func (d *JobDispatcher) queueFirst(jobID string, ch chan models.BaseMessage, msg models.BaseMessage) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var queued []models.BaseMessage
+drain:
+	for {
+		select {
+		case earlier := <-ch:
+			queued = append(queued, earlier)
+		default:
+			break drain
+		}
+	}
+
+	for _, next := range append([]models.BaseMessage{msg}, queued...) {
+		select {
+		case ch <- next:
+		default:
+			log.Error("❌ Job %s channel is full, dropping message", jobID)
+		}
+	}
+	log.Info("📥 Queued interrupting message to job %s channel ahead of %d queued messages", jobID, len(queued))
+}
+
 // processJobMessages processes messages sequentially for a specific job
 func (d *JobDispatcher) processJobMessages(jobID string, ch chan models.BaseMessage) {
 	log.Info("🔄 Started message processor for job %s", jobID)
@@ -106,10 +166,56 @@ func (d *JobDispatcher) processJobMessages(jobID string, ch chan models.BaseMess
 	// Ensure channel is cleaned up when we exit
 	defer d.cleanup(jobID)
 
-	for msg := range ch {
+	// carried holds a message read from the channel while coalescing follow-ups
+	// that still needs to be processed
+	var carried *models.BaseMessage
+	for {
+		var msg models.BaseMessage
+		if carried != nil {
+			msg, carried = *carried, nil
+		} else {
+			next, ok := <-ch
+			if !ok {
+				break
+			}
+			msg = next
+		}
+
+		// A job that was waiting for a slot when draining started doesn't start a new turn
+		if d.IsDraining() {
+			log.Info("🚰 Draining, exiting processor for job %s before next turn", jobID)
+			return
+		}
+
+		if msg.Type == models.MessageTypeUserMessage && d.handler.coalesceFollowUpsEnabled() {
+			var batch []models.BaseMessage
+			batch, carried = collectFollowUps(msg, ch)
+			if len(batch) > 1 {
+				merged, err := coalesceUserMessages(batch)
+				if err != nil {
+					// Fall back to one turn per message so nothing is lost
+					log.Error("❌ Failed to coalesce %d messages for job %s: %v", len(batch), jobID, err)
+					for _, earlier := range batch[:len(batch)-1] {
+						d.handler.HandleMessage(earlier)
+					}
+					msg = batch[len(batch)-1]
+				} else {
+					log.Info("🧩 Coalesced %d follow-up messages for job %s into one turn", len(batch), jobID)
+					msg = merged
+				}
+			}
+		}
+
 		log.Info("🔧 Processing message for job %s", jobID)
 		d.handler.HandleMessage(msg)
 
+		// When draining, stop after the in-flight turn. Remaining messages stay
+		// persisted in the queue and are recovered on restart.
+		if d.IsDraining() {
+			log.Info("🚰 Draining, exiting processor for job %s after in-flight turn", jobID)
+			return
+		}
+
 		// Check if job was removed from AppState
 		jobData, exists := d.appState.GetJobData(jobID)
 		if !exists {
@@ -119,7 +225,7 @@ func (d *JobDispatcher) processJobMessages(jobID string, ch chan models.BaseMess
 
 		// If job is completed or failed AND no more messages buffered, exit
 		// This ensures we process all queued messages before exiting
-		if (jobData.Status == models.JobStatusCompleted || jobData.Status == models.JobStatusFailed) && len(ch) == 0 {
+		if (jobData.Status == models.JobStatusCompleted || jobData.Status == models.JobStatusFailed) && len(ch) == 0 && carried == nil {
 			log.Info("✅ Job %s %s and channel empty, exiting processor", jobID, jobData.Status)
 			return
 		}
@@ -136,6 +242,9 @@ func (d *JobDispatcher) cleanup(jobID string) {
 		// Remove from map first to prevent new messages being sent
 		delete(d.activeJobs, jobID)
 	}
+	if _, stillTracked := d.appState.GetJobData(jobID); !stillTracked {
+		delete(d.jobInfo, jobID)
+	}
 	d.mutex.Unlock()
 
 	if exists {
@@ -154,6 +263,115 @@ func (d *JobDispatcher) EvictJob(jobID string) {
 	d.cleanup(jobID)
 }
 
+// SetConcurrency changes how many jobs are processed at a time. The worker pool must be
+// at least this large, otherwise the extra jobs wait for a free worker.
+func (d *JobDispatcher) SetConcurrency(maxConcurrency int) {
+	if d.scheduler == nil {
+		return
+	}
+	d.scheduler.SetMaxRunning(maxConcurrency)
+}
+
+// Concurrency returns how many jobs are processed at a time
+func (d *JobDispatcher) Concurrency() int {
+	if d.scheduler == nil {
+		return d.workerPool.Size()
+	}
+	return d.scheduler.MaxRunning()
+}
+
+// StartDraining puts the dispatcher in drain mode. In-flight turns are allowed to finish,
+// but no further messages are processed until the agent restarts.
+func (d *JobDispatcher) StartDraining() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.draining = true
+	log.Info("🚰 Dispatcher entered drain mode with %d active jobs", len(d.activeJobs))
+}
+
+// IsDraining reports whether the dispatcher is in drain mode
+func (d *JobDispatcher) IsDraining() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.draining
+}
+
+// ActiveJobIDs returns the IDs of jobs that currently have a message processor running
+func (d *JobDispatcher) ActiveJobIDs() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	jobIDs := make([]string, 0, len(d.activeJobs))
+	for jobID := range d.activeJobs {
+		jobIDs = append(jobIDs, jobID)
+	}
+	return jobIDs
+}
+
+// WaitForActiveJobs blocks until no job processors are running or the deadline passes.
+// Returns true if all jobs finished before the deadline.
+func (d *JobDispatcher) WaitForActiveJobs(deadline time.Time) bool {
+	for {
+		remaining := len(d.ActiveJobIDs())
+		if remaining == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			log.Warn("⚠️ Drain deadline reached with %d jobs still in flight", remaining)
+			return false
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
+
+// schedulingInfo determines how a job is ordered by the scheduler. Start messages carry
+// the priority and requester; follow-up messages reuse what the start message provided,
+// falling back to the persisted job data (e.g. after a restart).
+func (d *JobDispatcher) schedulingInfo(jobID string, msg models.BaseMessage) JobSchedulingInfo {
+	if msg.Type == models.MessageTypeStartConversation {
+		var payload models.StartConversationPayload
+		if err := d.unmarshalPayload(msg.Payload, &payload); err != nil {
+			return JobSchedulingInfo{}
+		}
+
+		fairnessKey := payload.RequesterID
+		if fairnessKey == "" {
+			fairnessKey = payload.ChannelID
+		}
+		if fairnessKey == "" {
+			fairnessKey = fairnessKeyFromLink(payload.MessageLink)
+		}
+
+		info := JobSchedulingInfo{
+			Priority:    payload.Priority,
+			FairnessKey: fairnessKey,
+			AskMode:     payload.Mode == models.AgentModeAsk,
+		}
+		d.mutex.Lock()
+		if d.jobInfo != nil {
+			d.jobInfo[jobID] = info
+		}
+		d.mutex.Unlock()
+		return info
+	}
+
+	d.mutex.Lock()
+	info, known := d.jobInfo[jobID]
+	d.mutex.Unlock()
+	if known {
+		return info
+	}
+
+	jobData, exists := d.appState.GetJobData(jobID)
+	if !exists {
+		return JobSchedulingInfo{}
+	}
+	return JobSchedulingInfo{
+		FairnessKey: fairnessKeyFromLink(jobData.MessageLink),
+		AskMode:     jobData.Mode == models.AgentModeAsk,
+	}
+}
+
 // extractJobID extracts the job ID from a message based on its type
 func (d *JobDispatcher) extractJobID(msg models.BaseMessage) string {
 	switch msg.Type {
@@ -179,6 +397,18 @@ func (d *JobDispatcher) extractJobID(msg models.BaseMessage) string {
 	}
 }
 
+// isInterrupt reports whether a message asks to interrupt the job's running turn
+func (d *JobDispatcher) isInterrupt(msg models.BaseMessage) bool {
+	if msg.Type != models.MessageTypeUserMessage {
+		return false
+	}
+	var payload models.UserMessagePayload
+	if err := d.unmarshalPayload(msg.Payload, &payload); err != nil {
+		return false
+	}
+	return payload.Interrupt
+}
+
 // unmarshalPayload unmarshals a message payload into the target struct
 func (d *JobDispatcher) unmarshalPayload(payload any, target any) error {
 	if payload == nil {
@@ -215,6 +445,26 @@ func (d *JobDispatcher) extractProcessedMessageID(msg models.BaseMessage) string
 	}
 }
 
+// replayIfProcessed checks the persisted processed messages, which survive restarts, and
+// answers a redelivered message with its original result. Returns true if the message was
+// already processed and must not run again.
+func (d *JobDispatcher) replayIfProcessed(processedMsgID string) bool {
+	processed, ok := d.appState.GetProcessedMessage(processedMsgID)
+	if !ok {
+		return false
+	}
+
+	log.Info("🔁 Message %s was already processed (%s), replaying original result", processedMsgID, processed.Outcome)
+	// The redelivered message was persisted to the queue before dispatch
+	if err := d.appState.RemoveQueuedMessage(processedMsgID); err != nil {
+		log.Warn("⚠️ Failed to remove redelivered queued message %s: %v", processedMsgID, err)
+	}
+	if d.handler != nil {
+		d.handler.ReplayProcessedMessage(*processed)
+	}
+	return true
+}
+
 // maybeCleanupSeenMessages removes old entries from seenMessages if enough time has passed.
 // Must be called with mutex held.
 func (d *JobDispatcher) maybeCleanupSeenMessages() {
@@ -17,7 +17,9 @@ func createTestAppState(t *testing.T) *models.AppState {
 	t.Helper()
 	tmpDir := t.TempDir()
 	statePath := filepath.Join(tmpDir, "test_state.json")
-	return models.NewAppState("test-agent", statePath)
+	appState := models.NewAppState("test-agent", statePath)
+	t.Cleanup(func() { appState.Close() })
+	return appState
 }
 
 // createTestAppStateNoPath creates an AppState without persistence (for simple tests)
@@ -810,3 +812,70 @@ func TestDispatcher_EmptyProcessedMessageID(t *testing.T) {
 		t.Error("Empty ProcessedMessageID should not be stored in seenMessages")
 	}
 }
+
+func TestDispatcher_DrainingLeavesJobMessagesQueued(t *testing.T) {
+	appState := createTestAppStateNoPath()
+	wp := workerpool.New(2)
+	defer wp.StopWait()
+
+	dispatcher := NewJobDispatcher(nil, wp, appState)
+
+	ch := make(chan models.BaseMessage, 100)
+	dispatcher.mutex.Lock()
+	dispatcher.activeJobs["job-123"] = ch
+	dispatcher.mutex.Unlock()
+
+	dispatcher.StartDraining()
+	if !dispatcher.IsDraining() {
+		t.Fatal("Expected dispatcher to be draining")
+	}
+
+	dispatcher.Dispatch(createTestMessageWithProcessedID(models.MessageTypeUserMessage, "job-123", "processed-msg-drain"))
+	dispatcher.Dispatch(createTestMessage(models.MessageTypeStartConversation, "job-new"))
+
+	if len(ch) != 0 {
+		t.Errorf("Expected no messages routed while draining, got %d", len(ch))
+	}
+
+	dispatcher.mutex.Lock()
+	_, created := dispatcher.activeJobs["job-new"]
+	dispatcher.mutex.Unlock()
+	if created {
+		t.Error("Expected no new job processor while draining")
+	}
+}
+
+func TestDispatcher_WaitForActiveJobs(t *testing.T) {
+	appState := createTestAppStateNoPath()
+	wp := workerpool.New(2)
+	defer wp.StopWait()
+
+	dispatcher := NewJobDispatcher(nil, wp, appState)
+
+	// No active jobs returns immediately
+	if !dispatcher.WaitForActiveJobs(time.Now().Add(time.Second)) {
+		t.Error("Expected WaitForActiveJobs to succeed with no active jobs")
+	}
+
+	dispatcher.mutex.Lock()
+	dispatcher.activeJobs["job-123"] = make(chan models.BaseMessage, 100)
+	dispatcher.mutex.Unlock()
+
+	if ids := dispatcher.ActiveJobIDs(); len(ids) != 1 || ids[0] != "job-123" {
+		t.Errorf("Expected active job IDs [job-123], got %v", ids)
+	}
+
+	// Deadline passes while the job is still active
+	if dispatcher.WaitForActiveJobs(time.Now().Add(100 * time.Millisecond)) {
+		t.Error("Expected WaitForActiveJobs to time out while job is active")
+	}
+
+	// Job finishes before the deadline
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		dispatcher.cleanup("job-123")
+	}()
+	if !dispatcher.WaitForActiveJobs(time.Now().Add(5 * time.Second)) {
+		t.Error("Expected WaitForActiveJobs to succeed once job finished")
+	}
+}
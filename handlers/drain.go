@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"slices"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/core"
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+// SendAgentDrainingMessage tells the server that this agent is draining so it stops
+// routing new conversations here
+func (mh *MessageHandler) SendAgentDrainingMessage(reason string, deadlineAt time.Time, inFlightJobIDs []string) {
+	if inFlightJobIDs == nil {
+		inFlightJobIDs = []string{}
+	}
+
+	drainingMsg := models.BaseMessage{
+		ID:   core.NewID("msg"),
+		Type: models.MessageTypeAgentDraining,
+		Payload: models.AgentDrainingPayload{
+			Reason:         reason,
+			DeadlineAt:     deadlineAt,
+			InFlightJobIDs: inFlightJobIDs,
+		},
+	}
+	mh.messageSender.QueueMessage("cc_message", drainingMsg)
+	log.Info("📤 Queued agent draining message (message ID: %s)", drainingMsg.ID)
+}
+
+// RejectStartConversation tells the thread that a new conversation cannot be started
+// because the agent is shutting down. The message is dropped from the persisted queue.
+func (mh *MessageHandler) RejectStartConversation(msg models.BaseMessage) {
+	var payload models.StartConversationPayload
+	if err := unmarshalPayload(msg.Payload, &payload); err != nil {
+		log.Error("❌ Failed to unmarshal start conversation payload for rejection: %v", err)
+		return
+	}
+
+	if err := mh.appState.RemoveQueuedMessage(payload.ProcessedMessageID); err != nil {
+		log.Warn("⚠️ Failed to remove rejected queued message %s: %v", payload.ProcessedMessageID, err)
+	}
+
+	if err := mh.sendSystemMessage(
+		"eksecd is shutting down and is not accepting new conversations. Please try again shortly.",
+		payload.ProcessedMessageID,
+		payload.JobID,
+	); err != nil {
+		log.Error("❌ Failed to send drain rejection message for job %s: %v", payload.JobID, err)
+	}
+	log.Info("🚰 Rejected new conversation for job %s while draining", payload.JobID)
+}
+
+// InterruptRunningTurns stops the running agent processes of the jobs, so nothing writes to
+// their checkouts while the unfinished work is committed
+func (mh *MessageHandler) InterruptRunningTurns(jobIDs []string) {
+	for _, jobID := range jobIDs {
+		if clients.InterruptAgentTurn(jobID) {
+			log.Info("🚰 Interrupted running turn of job %s for shutdown", jobID)
+		}
+	}
+}
+
+// CommitInFlightWork commits and pushes any uncommitted changes for jobs whose turns did
+// not finish before the drain deadline, so no agent work is lost on shutdown. Their turns
+// must have been stopped with InterruptRunningTurns; jobs in stillRunning are skipped since
+// their processes may still write to the checkout. The jobs stay in_progress and are
+// resumed by recovery on restart.
+func (mh *MessageHandler) CommitInFlightWork(stillRunning []string) {
+	repoContext := mh.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		log.Info("🚰 No-repo mode: skipping commit of in-flight work")
+		return
+	}
+
+	for jobID, jobData := range mh.appState.GetAllJobs() {
+		if jobData.Status != models.JobStatusInProgress || jobData.Mode == models.AgentModeAsk {
+			continue
+		}
+		if slices.Contains(stillRunning, jobID) {
+			log.Warn("⚠️ Job %s is still running after its turn was interrupted, not committing its work", jobID)
+			continue
+		}
+
+		commitHash, err := mh.gitUseCase.CommitInFlightChanges(jobData.WorktreePath, jobData.MessageLink, usecases.JobRequester(jobData))
+		if err != nil {
+			log.Error("❌ Failed to commit in-flight work for job %s: %v", jobID, err)
+			continue
+		}
+		if commitHash == "" {
+			continue
+		}
+
+		if err := mh.sendSystemMessage(
+			"eksecd is restarting. Work in progress has been saved and this job will resume once eksecd is back.",
+			jobData.ProcessedMessageID,
+			jobID,
+		); err != nil {
+			log.Error("❌ Failed to send drain notice for job %s: %v", jobID, err)
+		}
+	}
+}
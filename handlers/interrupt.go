@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/core/log"
+	"eksecd/models"
+)
+
+// interruptedTurnNote is prepended to the message that resumes an interrupted turn
+const interruptedTurnNote = "Note: your previous turn was interrupted by the user before it finished. " +
+	"Any changes you made so far are still in the working tree. " +
+	"Follow the new message below instead of continuing the previous task."
+
+// interruptedRequestPrompt asks a new session to follow the message that interrupted the
+// first turn of a job, which was stopped before the agent started its session
+func interruptedRequestPrompt(interruptedRequest, message string) string {
+	return fmt.Sprintf("Note: an earlier request in this thread was interrupted by the user before you started on it. "+
+		"Any changes already made are in the working tree.\n\nThe interrupted request was:\n%s\n\n"+
+		"Follow the new message below instead of the interrupted request.\n\n%s", interruptedRequest, message)
+}
+
+// InterruptJob terminates the job's running agent process so an interrupting message can
+// take over. Partial changes stay in the worktree. Returns false if no agent was running.
+func (mh *MessageHandler) InterruptJob(jobID string) bool {
+	if !clients.InterruptAgentTurn(jobID) {
+		log.Info("🛑 No running agent turn to interrupt for job %s", jobID)
+		return false
+	}
+	log.Info("🛑 Interrupted running agent turn for job %s", jobID)
+	return true
+}
+
+// handleInterruptedTurn keeps an interrupted job in progress with sessionID so the
+// interrupting message can resume it. Uncommitted changes are left in place. An empty
+// sessionID means the agent was stopped before it started a session.
+func (mh *MessageHandler) handleInterruptedTurn(jobID, sessionID string) error {
+	jobData := mh.jobDataForUpdate(jobID)
+	jobData.ClaudeSessionID = sessionID
+	jobData.Status = models.JobStatusInProgress
+	jobData.UpdatedAt = time.Now()
+	if err := mh.appState.UpdateJobData(jobID, jobData); err != nil {
+		log.Error("❌ Failed to persist interrupted job state: %v", err)
+		return fmt.Errorf("failed to persist interrupted job state: %w", err)
+	}
+
+	mh.activity.RecordActivity(jobID, activityInterrupted)
+	log.Info("🛑 Job %s turn interrupted, keeping session %s for the next message", jobID, sessionID)
+	return nil
+}
+
+// resumesInterruptedTurn reports whether the job's previous turn was interrupted
+func (mh *MessageHandler) resumesInterruptedTurn(jobID string) bool {
+	activity, exists := mh.activity.GetActivity(jobID)
+	return exists && activity.LastActivity == activityInterrupted
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gammazero/workerpool"
+
+	"eksecd/clients"
+	"eksecd/models"
+)
+
+func TestHandleInterruptedTurn_KeepsJobInProgress(t *testing.T) {
+	mh := newTestStatusHandler(t)
+
+	if err := mh.appState.UpdateJobData("job-1", models.JobData{
+		JobID:            "job-1",
+		BranchName:       "eksecd/test-branch",
+		Status:           models.JobStatusFailed,
+		TurnCount:        2,
+		PullRequestURL:   "https://github.com/owner/repo/pull/1",
+		PullRequestDraft: true,
+		CIFixAttempts:    1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mh.handleInterruptedTurn("job-1", "session-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jobData, exists := mh.appState.GetJobData("job-1")
+	if !exists {
+		t.Fatalf("expected job data to be persisted")
+	}
+	if jobData.Status != models.JobStatusInProgress {
+		t.Errorf("expected status %s, got %s", models.JobStatusInProgress, jobData.Status)
+	}
+	if jobData.ClaudeSessionID != "session-1" {
+		t.Errorf("expected session to be kept, got %q", jobData.ClaudeSessionID)
+	}
+	if jobData.BranchName != "eksecd/test-branch" || jobData.TurnCount != 2 || jobData.PullRequestURL == "" ||
+		!jobData.PullRequestDraft || jobData.CIFixAttempts != 1 {
+		t.Errorf("expected the rest of the job to be kept, got %+v", jobData)
+	}
+	if !mh.resumesInterruptedTurn("job-1") {
+		t.Errorf("expected next turn to resume an interrupted turn")
+	}
+
+	mh.activity.RecordActivity("job-1", activityPreparingGit)
+	if mh.resumesInterruptedTurn("job-1") {
+		t.Errorf("expected interrupted state to clear once the next turn starts")
+	}
+}
+
+func TestDispatcher_IsInterrupt(t *testing.T) {
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+	dispatcher := NewJobDispatcher(nil, wp, createTestAppStateNoPath())
+
+	interrupt := models.BaseMessage{
+		Type:    models.MessageTypeUserMessage,
+		Payload: models.UserMessagePayload{JobID: "job-1", Message: "stop", Interrupt: true},
+	}
+	if !dispatcher.isInterrupt(interrupt) {
+		t.Errorf("expected interrupt message to be detected")
+	}
+	if dispatcher.isInterrupt(createTestMessage(models.MessageTypeUserMessage, "job-1")) {
+		t.Errorf("expected regular user message not to interrupt")
+	}
+	if dispatcher.isInterrupt(createTestMessage(models.MessageTypeStartConversation, "job-1")) {
+		t.Errorf("expected start conversation not to interrupt")
+	}
+}
+
+func TestInterruptRunningTurns(t *testing.T) {
+	mh := &MessageHandler{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer clients.TrackAgentTurn("job-running", cancel)()
+
+	mh.InterruptRunningTurns([]string{"job-running", "job-without-turn"})
+	if ctx.Err() == nil {
+		t.Error("expected the running turn to be interrupted")
+	}
+}
+
+func TestDispatcher_InterruptGoesAheadOfQueuedMessages(t *testing.T) {
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+	dispatcher := NewJobDispatcher(nil, wp, createTestAppStateNoPath())
+
+	// The job's processor is busy with a turn, so its channel is not read
+	ch := make(chan models.BaseMessage, 100)
+	dispatcher.activeJobs["job-1"] = ch
+	queued := func(text string) models.BaseMessage {
+		return models.BaseMessage{
+			Type:    models.MessageTypeUserMessage,
+			Payload: models.UserMessagePayload{JobID: "job-1", Message: text},
+		}
+	}
+	dispatcher.Dispatch(queued("first"))
+	dispatcher.Dispatch(queued("second"))
+	dispatcher.Dispatch(models.BaseMessage{
+		Type:    models.MessageTypeUserMessage,
+		Payload: models.UserMessagePayload{JobID: "job-1", Message: "stop", Interrupt: true},
+	})
+
+	var order []string
+	for len(ch) > 0 {
+		order = append(order, (<-ch).Payload.(models.UserMessagePayload).Message)
+	}
+	if len(order) != 3 || order[0] != "stop" || order[1] != "first" || order[2] != "second" {
+		t.Errorf("expected the interrupt ahead of the queued messages, got %v", order)
+	}
+}
+
+func TestInterruptedRequestPrompt(t *testing.T) {
+	prompt := interruptedRequestPrompt("Add a login page", "Use OAuth instead")
+	if !strings.Contains(prompt, "The interrupted request was:\nAdd a login page") || !strings.HasSuffix(prompt, "\n\nUse OAuth instead") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// Job activity phases reported in job status responses
+const (
+	activityPreparingGit = "preparing git environment"
+	activityRunningAgent = "running agent"
+	activityCommitting   = "committing and pushing changes"
+	activitySendingReply = "sending reply"
+	activityIdle         = "idle"
+	activityAgentErrored = "agent failed"
+	activityInterrupted  = "interrupted"
+	activityVerifying    = "verifying changes"
+)
+
+// JobActivity is a point-in-time snapshot of what a job is doing
+type JobActivity struct {
+	AgentRunning     bool
+	AgentStartedAt   time.Time
+	LastActivity     string
+	LastToolActivity string
+	LastActivityAt   time.Time
+}
+
+// JobActivityTracker keeps in-memory runtime information about jobs that is not
+// worth persisting, such as whether an agent process is currently running and
+// what the job was last doing. It is safe for concurrent use.
+type JobActivityTracker struct {
+	mutex      sync.RWMutex
+	activities map[string]*JobActivity
+}
+
+// NewJobActivityTracker creates an empty activity tracker
+func NewJobActivityTracker() *JobActivityTracker {
+	return &JobActivityTracker{
+		activities: make(map[string]*JobActivity),
+	}
+}
+
+// getOrCreateLocked returns the activity entry for a job, creating it if needed.
+// Must be called with the write lock held.
+func (t *JobActivityTracker) getOrCreateLocked(jobID string) *JobActivity {
+	activity, exists := t.activities[jobID]
+	if !exists {
+		activity = &JobActivity{}
+		t.activities[jobID] = activity
+	}
+	return activity
+}
+
+// RecordActivity records the current processing phase of a job
+func (t *JobActivityTracker) RecordActivity(jobID, activity string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry := t.getOrCreateLocked(jobID)
+	entry.LastActivity = activity
+	entry.LastActivityAt = time.Now()
+}
+
+// MarkAgentStarted records that an agent process has started for the job
+func (t *JobActivityTracker) MarkAgentStarted(jobID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	entry := t.getOrCreateLocked(jobID)
+	entry.AgentRunning = true
+	entry.AgentStartedAt = now
+	entry.LastActivity = activityRunningAgent
+	entry.LastActivityAt = now
+}
+
+// MarkAgentFinished records that the agent process for the job has exited.
+// lastToolActivity is kept from the previous turn when empty.
+func (t *JobActivityTracker) MarkAgentFinished(jobID, lastToolActivity string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry := t.getOrCreateLocked(jobID)
+	entry.AgentRunning = false
+	if lastToolActivity != "" {
+		entry.LastToolActivity = lastToolActivity
+	}
+	entry.LastActivityAt = time.Now()
+}
+
+// RecordToolActivity records the tool the job's running agent is using
+func (t *JobActivityTracker) RecordToolActivity(jobID, toolActivity string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	entry := t.getOrCreateLocked(jobID)
+	entry.LastToolActivity = toolActivity
+	entry.LastActivityAt = time.Now()
+}
+
+// GetActivity returns a copy of the job's activity and whether any was recorded
+func (t *JobActivityTracker) GetActivity(jobID string) (JobActivity, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	activity, exists := t.activities[jobID]
+	if !exists {
+		return JobActivity{}, false
+	}
+	return *activity, true
+}
+
+// RemoveJob drops all tracked activity for a job
+func (t *JobActivityTracker) RemoveJob(jobID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.activities, jobID)
+}
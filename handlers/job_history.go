@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"eksecd/core/log"
+)
+
+// recordJobError keeps the error of a failed turn on the job so it ends up in the job
+// history if the job finishes without another successful turn
+func (mh *MessageHandler) recordJobError(jobID string, err error) {
+	if jobID == "" || err == nil {
+		return
+	}
+	if setErr := mh.appState.SetJobError(jobID, err.Error()); setErr != nil {
+		log.Warn("⚠️ Failed to record error for job %s: %v", jobID, setErr)
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eksecd/models"
+)
+
+func TestArchiveJob_RecordsTurnsErrorAndOutcome(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), models.StateFileName)
+	appState := models.NewAppState("test-agent", statePath)
+	t.Cleanup(func() { appState.Close() })
+	mh := &MessageHandler{appState: appState, activity: NewJobActivityTracker()}
+	mh.appState.SetRepositoryContext(&models.RepositoryContext{IsRepoMode: true, RepositoryIdentifier: "acme/widgets"})
+
+	job := mh.jobDataForUpdate("job-1")
+	job.BranchName = "eksecd/feature"
+	job.MessageLink = "https://chat.example.com/thread/1"
+	job.Mode = models.AgentModeExecute
+	job.Status = models.JobStatusInProgress
+	job.UpdatedAt = time.Now()
+	if err := mh.appState.UpdateJobData("job-1", job); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	if err := mh.appState.IncrementTurnCount("job-1"); err != nil {
+		t.Fatalf("failed to count turn: %v", err)
+	}
+
+	// First turn completes and opens a PR
+	job = mh.jobDataForUpdate("job-1")
+	job.Status = models.JobStatusCompleted
+	job.PullRequestID = "42"
+	job.PullRequestURL = "https://github.com/acme/widgets/pull/42"
+	if err := mh.appState.UpdateJobData("job-1", job); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+
+	// Second turn fails; its updates start from the stored job
+	job = mh.jobDataForUpdate("job-1")
+	job.Status = models.JobStatusInProgress
+	if err := mh.appState.UpdateJobData("job-1", job); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	if err := mh.appState.IncrementTurnCount("job-1"); err != nil {
+		t.Fatalf("failed to count turn: %v", err)
+	}
+	mh.recordJobError("job-1", errors.New("auto-commit failed"))
+
+	jobData, _ := mh.appState.GetJobData("job-1")
+	if jobData.TurnCount != 2 {
+		t.Errorf("expected 2 turns, got %d", jobData.TurnCount)
+	}
+	if jobData.CreatedAt.IsZero() {
+		t.Errorf("expected CreatedAt to be set")
+	}
+	if jobData.PullRequestURL != "https://github.com/acme/widgets/pull/42" {
+		t.Errorf("expected PR URL to be kept, got %q", jobData.PullRequestURL)
+	}
+
+	if err := mh.appState.ArchiveJob("job-1", models.JobOutcomeMerged, "merged"); err != nil {
+		t.Fatalf("failed to archive job: %v", err)
+	}
+	if _, exists := mh.appState.GetJobData("job-1"); exists {
+		t.Errorf("expected archived job to be removed from state")
+	}
+	// Archiving an unknown job is a no-op
+	if err := mh.appState.ArchiveJob("job-1", models.JobOutcomeMerged, "merged"); err != nil {
+		t.Fatalf("unexpected error archiving unknown job: %v", err)
+	}
+
+	entries, err := models.ReadJobHistory(models.JobHistoryPath(statePath))
+	if err != nil {
+		t.Fatalf("failed to read job history: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Outcome != models.JobOutcomeMerged || entry.PullRequestState != "merged" {
+		t.Errorf("unexpected outcome %s / %s", entry.Outcome, entry.PullRequestState)
+	}
+	if entry.Repository != "acme/widgets" || entry.BranchName != "eksecd/feature" || entry.PullRequestID != "42" {
+		t.Errorf("unexpected job details: %+v", entry)
+	}
+	if entry.PullRequestURL != "https://github.com/acme/widgets/pull/42" {
+		t.Errorf("unexpected PR URL %q", entry.PullRequestURL)
+	}
+	if entry.TurnCount != 2 || entry.Error != "auto-commit failed" {
+		t.Errorf("expected 2 turns and the last error, got %d / %q", entry.TurnCount, entry.Error)
+	}
+	if entry.MessageLink != "https://chat.example.com/thread/1" {
+		t.Errorf("unexpected message link %q", entry.MessageLink)
+	}
+	if entry.CreatedAt.IsZero() || entry.FinishedAt.Before(entry.CreatedAt) {
+		t.Errorf("unexpected timestamps: created %s, finished %s", entry.CreatedAt, entry.FinishedAt)
+	}
+}
+
+func TestIncrementTurnCount(t *testing.T) {
+	appState := createTestAppState(t)
+
+	// Updates within a turn, such as resuming an interrupted one, do not count turns
+	job := models.JobData{JobID: "job-1", Status: models.JobStatusInProgress, UpdatedAt: time.Now()}
+	for i := 0; i < 3; i++ {
+		if err := appState.UpdateJobData("job-1", job); err != nil {
+			t.Fatalf("failed to update job: %v", err)
+		}
+	}
+	if err := appState.IncrementTurnCount("job-1"); err != nil {
+		t.Fatalf("failed to count turn: %v", err)
+	}
+
+	jobData, _ := appState.GetJobData("job-1")
+	if jobData.TurnCount != 1 {
+		t.Errorf("expected 1 turn, got %d", jobData.TurnCount)
+	}
+
+	// Unknown jobs are ignored
+	if err := appState.IncrementTurnCount("job-2"); err != nil {
+		t.Errorf("unexpected error for unknown job: %v", err)
+	}
+}
+
+func TestReadJobHistory_KeepsLatestEntryAndSkipsTruncatedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), models.JobHistoryFileName)
+	content := `{"job_id":"job-1","outcome":"inactive","finished_at":"2024-03-04T10:00:00Z"}
+{"job_id":"job-2","outcome":"merged","finished_at":"2024-03-03T10:00:00Z"}
+{"job_id":"job-1","outcome":"merged","finished_at":"2024-03-05T10:00:00Z"}
+{"job_id":"job-3","outco`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	entries, err := models.ReadJobHistory(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].JobID != "job-2" || entries[1].JobID != "job-1" {
+		t.Fatalf("expected job-2 then job-1, got %+v", entries)
+	}
+	if entries[1].Outcome != models.JobOutcomeMerged {
+		t.Errorf("expected latest entry for job-1, got %s", entries[1].Outcome)
+	}
+
+	missing, err := models.ReadJobHistory(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("expected empty history for missing file, got %v / %v", missing, err)
+	}
+}
+
+func TestRestoreAppState_KeepsTurnCountOfInProgressJobs(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), models.StateFileName)
+	appState := models.NewAppState("test-agent", statePath)
+	job := models.JobData{JobID: "job-1", Status: models.JobStatusInProgress, UpdatedAt: time.Now()}
+	if err := appState.UpdateJobData("job-1", job); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	if err := appState.IncrementTurnCount("job-1"); err != nil {
+		t.Fatalf("failed to count turn: %v", err)
+	}
+	if err := appState.Close(); err != nil {
+		t.Fatalf("failed to close state store: %v", err)
+	}
+
+	restored, _, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { restored.Close() })
+
+	jobData, _ := restored.GetJobData("job-1")
+	if jobData.TurnCount != 1 {
+		t.Errorf("expected restored job to keep 1 turn, got %d", jobData.TurnCount)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"eksecd/core"
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/services"
+)
+
+// lastToolActivity returns the last tool activity from an agent result, tolerating nil results
+func lastToolActivity(result *services.CLIAgentResult) string {
+	if result == nil {
+		return ""
+	}
+
+	return result.LastToolActivity
+}
+
+// agentForJob returns the agent for a job's turn, reporting the tools it uses to the job's
+// activity while the turn runs when the agent supports it
+func (mh *MessageHandler) agentForJob(jobID string) services.CLIAgent {
+	agent := mh.claudeService.ForJob(jobID)
+	if reporter, ok := agent.(services.ToolActivityReporter); ok {
+		agent = reporter.WithToolActivityCallback(func(activity string) {
+			mh.activity.RecordToolActivity(jobID, activity)
+		})
+	}
+	return agent
+}
+
+// handleJobStatusRequest answers a job status query. It only reads in-memory state,
+// so it is safe to run on the instant worker pool while the job's turn is in flight.
+func (mh *MessageHandler) handleJobStatusRequest(msg models.BaseMessage) error {
+	log.Info("📋 Starting to handle job status request message")
+	var payload models.JobStatusRequestPayload
+	if err := unmarshalPayload(msg.Payload, &payload); err != nil {
+		log.Info("❌ Failed to unmarshal job status request payload: %v", err)
+		return fmt.Errorf("failed to unmarshal job status request payload: %w", err)
+	}
+
+	if payload.JobID == "" {
+		return fmt.Errorf("job status request is missing job_id")
+	}
+
+	response := mh.buildJobStatusResponse(payload.JobID, time.Now())
+
+	statusMsg := models.BaseMessage{
+		ID:      core.NewID("msg"),
+		Type:    models.MessageTypeJobStatusResponse,
+		Payload: response,
+	}
+	mh.messageSender.QueueMessage("cc_message", statusMsg)
+	log.Info("📤 Queued job status response for job: %s (message ID: %s)", payload.JobID, statusMsg.ID)
+
+	log.Info("📋 Completed successfully - handled job status request for job %s", payload.JobID)
+	return nil
+}
+
+// buildJobStatusResponse assembles the status of a job from persisted job data,
+// queued messages and in-memory activity tracking
+func (mh *MessageHandler) buildJobStatusResponse(jobID string, now time.Time) models.JobStatusResponsePayload {
+	response := models.JobStatusResponsePayload{
+		JobID: jobID,
+	}
+
+	if jobData, exists := mh.appState.GetJobData(jobID); exists {
+		updatedAt := jobData.UpdatedAt
+		response.Found = true
+		response.BranchName = jobData.BranchName
+		response.WorktreePath = jobData.WorktreePath
+		response.PullRequestID = jobData.PullRequestID
+		response.Status = jobData.Status
+		response.Mode = jobData.Mode
+		response.UpdatedAt = &updatedAt
+	}
+
+	for _, queued := range mh.appState.GetAllQueuedMessages() {
+		if queued.JobID == jobID {
+			response.QueuedMessageCount++
+		}
+	}
+	if response.QueuedMessageCount > 0 {
+		// A job whose start message is still queued is known to the agent even without job data
+		response.Found = true
+	}
+
+	if activity, exists := mh.activity.GetActivity(jobID); exists {
+		response.AgentRunning = activity.AgentRunning
+		response.LastActivity = activity.LastActivity
+		response.LastToolActivity = activity.LastToolActivity
+		if !activity.LastActivityAt.IsZero() {
+			lastActivityAt := activity.LastActivityAt
+			response.LastActivityAt = &lastActivityAt
+		}
+		if activity.AgentRunning {
+			startedAt := activity.AgentStartedAt
+			response.AgentStartedAt = &startedAt
+			response.AgentRunningSeconds = int64(now.Sub(startedAt).Seconds())
+		}
+	}
+
+	return response
+}
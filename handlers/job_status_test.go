@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eksecd/models"
+	"eksecd/services"
+)
+
+func newTestStatusHandler(t *testing.T) *MessageHandler {
+	t.Helper()
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), "state.json"))
+	t.Cleanup(func() { appState.Close() })
+	return &MessageHandler{
+		appState: appState,
+		activity: NewJobActivityTracker(),
+	}
+}
+
+func TestBuildJobStatusResponse_UnknownJob(t *testing.T) {
+	mh := newTestStatusHandler(t)
+
+	response := mh.buildJobStatusResponse("job-missing", time.Now())
+	if response.Found {
+		t.Errorf("expected Found=false for unknown job")
+	}
+	if response.JobID != "job-missing" {
+		t.Errorf("expected JobID job-missing, got %s", response.JobID)
+	}
+	if response.AgentRunning {
+		t.Errorf("expected AgentRunning=false for unknown job")
+	}
+}
+
+func TestBuildJobStatusResponse_RunningJob(t *testing.T) {
+	mh := newTestStatusHandler(t)
+
+	updatedAt := time.Now().Add(-time.Minute)
+	if err := mh.appState.UpdateJobData("job-1", models.JobData{
+		JobID:         "job-1",
+		BranchName:    "eksecd/test-branch",
+		WorktreePath:  "/tmp/worktree",
+		PullRequestID: "42",
+		Status:        models.JobStatusInProgress,
+		Mode:          models.AgentModeExecute,
+		UpdatedAt:     updatedAt,
+	}); err != nil {
+		t.Fatalf("failed to update job data: %v", err)
+	}
+	for _, id := range []string{"msg-1", "msg-2"} {
+		if err := mh.appState.AddQueuedMessage(models.QueuedMessage{
+			ProcessedMessageID: id,
+			JobID:              "job-1",
+			MessageType:        models.MessageTypeUserMessage,
+		}); err != nil {
+			t.Fatalf("failed to add queued message: %v", err)
+		}
+	}
+	if err := mh.appState.AddQueuedMessage(models.QueuedMessage{
+		ProcessedMessageID: "msg-other",
+		JobID:              "job-2",
+		MessageType:        models.MessageTypeUserMessage,
+	}); err != nil {
+		t.Fatalf("failed to add queued message: %v", err)
+	}
+
+	mh.activity.MarkAgentFinished("job-1", "Edit main.go")
+	mh.activity.MarkAgentStarted("job-1")
+	started, _ := mh.activity.GetActivity("job-1")
+
+	response := mh.buildJobStatusResponse("job-1", started.AgentStartedAt.Add(90*time.Second))
+
+	if !response.Found {
+		t.Fatalf("expected Found=true")
+	}
+	if response.BranchName != "eksecd/test-branch" || response.WorktreePath != "/tmp/worktree" || response.PullRequestID != "42" {
+		t.Errorf("unexpected job data fields: %+v", response)
+	}
+	if response.Status != models.JobStatusInProgress || response.Mode != models.AgentModeExecute {
+		t.Errorf("unexpected status/mode: %s/%s", response.Status, response.Mode)
+	}
+	if response.UpdatedAt == nil || !response.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("expected UpdatedAt %v, got %v", updatedAt, response.UpdatedAt)
+	}
+	if response.QueuedMessageCount != 2 {
+		t.Errorf("expected 2 queued messages, got %d", response.QueuedMessageCount)
+	}
+	if !response.AgentRunning {
+		t.Errorf("expected AgentRunning=true")
+	}
+	if response.AgentRunningSeconds != 90 {
+		t.Errorf("expected AgentRunningSeconds=90, got %d", response.AgentRunningSeconds)
+	}
+	if response.LastActivity != activityRunningAgent {
+		t.Errorf("expected LastActivity %q, got %q", activityRunningAgent, response.LastActivity)
+	}
+	if response.LastToolActivity != "Edit main.go" {
+		t.Errorf("expected LastToolActivity to be kept from previous turn, got %q", response.LastToolActivity)
+	}
+}
+
+func TestJobActivityTracker_Lifecycle(t *testing.T) {
+	tracker := NewJobActivityTracker()
+
+	if _, exists := tracker.GetActivity("job-1"); exists {
+		t.Fatalf("expected no activity for new tracker")
+	}
+
+	tracker.RecordActivity("job-1", activityPreparingGit)
+	tracker.MarkAgentStarted("job-1")
+	tracker.MarkAgentFinished("job-1", "Bash go test ./...")
+	tracker.RecordActivity("job-1", activityIdle)
+
+	activity, exists := tracker.GetActivity("job-1")
+	if !exists {
+		t.Fatalf("expected activity to exist")
+	}
+	if activity.AgentRunning {
+		t.Errorf("expected AgentRunning=false after finish")
+	}
+	if activity.LastActivity != activityIdle {
+		t.Errorf("expected LastActivity %q, got %q", activityIdle, activity.LastActivity)
+	}
+	if activity.LastToolActivity != "Bash go test ./..." {
+		t.Errorf("unexpected LastToolActivity %q", activity.LastToolActivity)
+	}
+
+	tracker.RemoveJob("job-1")
+	if _, exists := tracker.GetActivity("job-1"); exists {
+		t.Errorf("expected activity to be removed")
+	}
+}
+
+// fakeReportingAgent is a CLIAgent that reports a tool while its turn runs
+type fakeReportingAgent struct {
+	services.CLIAgent
+	onToolActivity func(activity string)
+	during         func()
+}
+
+func (f *fakeReportingAgent) ForJob(jobID string) services.CLIAgent {
+	return f
+}
+
+func (f *fakeReportingAgent) WithToolActivityCallback(onToolActivity func(activity string)) services.CLIAgent {
+	return &fakeReportingAgent{onToolActivity: onToolActivity, during: f.during}
+}
+
+func (f *fakeReportingAgent) ContinueConversation(sessionID, prompt string) (*services.CLIAgentResult, error) {
+	f.onToolActivity("Edit main.go")
+	f.during()
+	return &services.CLIAgentResult{SessionID: sessionID}, nil
+}
+
+func TestAgentForJob_ReportsToolActivityDuringTurn(t *testing.T) {
+	mh := newTestStatusHandler(t)
+	agent := &fakeReportingAgent{}
+	agent.during = func() {
+		activity, _ := mh.activity.GetActivity("job-1")
+		if !activity.AgentRunning || activity.LastToolActivity != "Edit main.go" {
+			t.Errorf("expected the current tool while the agent runs, got %+v", activity)
+		}
+	}
+	mh.claudeService = agent
+
+	mh.activity.MarkAgentStarted("job-1")
+	if _, err := mh.agentForJob("job-1").ContinueConversation("session", "prompt"); err != nil {
+		t.Fatal(err)
+	}
+	mh.activity.MarkAgentFinished("job-1", "")
+
+	if activity, _ := mh.activity.GetActivity("job-1"); activity.LastToolActivity != "Edit main.go" {
+		t.Errorf("expected the tool to be kept after the turn, got %q", activity.LastToolActivity)
+	}
+}
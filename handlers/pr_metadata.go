@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"eksecd/clients"
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+const (
+	prLabelsEnvVar             = "PR_LABELS"              // Comma-separated labels added to new PRs
+	prReviewersEnvVar          = "PR_REVIEWERS"           // Comma-separated logins asked to review new PRs
+	prTeamReviewersEnvVar      = "PR_TEAM_REVIEWERS"      // Comma-separated org/team-slug teams asked to review new PRs
+	prAssigneesEnvVar          = "PR_ASSIGNEES"           // Comma-separated logins assigned to new PRs
+	prCodeOwnerReviewersEnvVar = "PR_CODEOWNER_REVIEWERS" // Ask the CODEOWNERS of the changed paths to review new PRs
+)
+
+// pullRequestMetadataSettings returns the labels, reviewers and assignees for a new PR from
+// the environment defaults and the job's overrides, and whether code owners are asked to review
+func pullRequestMetadataSettings(get func(string) string, overrides *models.PullRequestOverrides) (clients.PullRequestMetadata, bool) {
+	metadata := clients.PullRequestMetadata{
+		Labels:        splitListSetting(get(prLabelsEnvVar)),
+		Reviewers:     splitListSetting(get(prReviewersEnvVar)),
+		TeamReviewers: splitListSetting(get(prTeamReviewersEnvVar)),
+		Assignees:     splitListSetting(get(prAssigneesEnvVar)),
+	}
+
+	codeOwnerReviewers := false
+	if envVal := get(prCodeOwnerReviewersEnvVar); envVal != "" {
+		enabled, err := strconv.ParseBool(envVal)
+		if err != nil {
+			log.Warn("⚠️ Invalid %s value %q, code owner reviewers disabled", prCodeOwnerReviewersEnvVar, envVal)
+		}
+		codeOwnerReviewers = enabled
+	}
+
+	if overrides != nil {
+		if overrides.Labels != nil {
+			metadata.Labels = overrides.Labels
+		}
+		if overrides.Reviewers != nil {
+			metadata.Reviewers = overrides.Reviewers
+		}
+		if overrides.TeamReviewers != nil {
+			metadata.TeamReviewers = overrides.TeamReviewers
+		}
+		if overrides.Assignees != nil {
+			metadata.Assignees = overrides.Assignees
+		}
+		if overrides.CodeOwnerReviewers != nil {
+			codeOwnerReviewers = *overrides.CodeOwnerReviewers
+		}
+	}
+	return metadata, codeOwnerReviewers
+}
+
+// splitListSetting splits a comma-separated setting, dropping empty entries and leading @
+func splitListSetting(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimPrefix(strings.TrimSpace(item), "@"); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// addPullRequestMetadata labels the PR the turn just opened and requests its reviewers and
+// assignees, then tells the thread who was asked to review. Reviewers of a draft PR are
+// held back until it is marked ready.
+func (mh *MessageHandler) addPullRequestMetadata(
+	jobID, processedMessageID, worktreePath string,
+	commitResult *usecases.AutoCommitResult,
+) {
+	if commitResult == nil || !commitResult.JustCreatedPR || commitResult.PullRequestID == "" {
+		return
+	}
+
+	var overrides *models.PullRequestOverrides
+	scheduled := false
+	if jobData, exists := mh.appState.GetJobData(jobID); exists {
+		overrides = jobData.PullRequestOverrides
+		scheduled = jobData.ScheduleName != ""
+	}
+	metadata, codeOwnerReviewers := pullRequestMetadataSettings(mh.envManager.Get, overrides)
+	if commitResult.Draft {
+		if len(metadata.Reviewers) > 0 || len(metadata.TeamReviewers) > 0 || codeOwnerReviewers {
+			pending := &models.PendingReviewers{
+				Reviewers:          metadata.Reviewers,
+				TeamReviewers:      metadata.TeamReviewers,
+				CodeOwnerReviewers: codeOwnerReviewers,
+			}
+			if err := mh.appState.SetJobPendingReviewers(jobID, pending); err != nil {
+				log.Error("❌ Failed to record pending reviewers for job %s: %v", jobID, err)
+			}
+		}
+		metadata.Reviewers, metadata.TeamReviewers = nil, nil
+		codeOwnerReviewers = false
+	}
+	if metadata.Empty() && !codeOwnerReviewers {
+		return
+	}
+
+	applied, err := mh.gitUseCase.AddPRMetadata(commitResult.PullRequestID, worktreePath, metadata, codeOwnerReviewers)
+	message := pullRequestMetadataMessage(applied)
+	if err != nil {
+		log.Error("❌ Failed to add labels, reviewers and assignees for job %s: %v", jobID, err)
+		message = fmt.Sprintf("Failed to add labels, reviewers and assignees to the pull request: %v", err)
+	}
+	// Scheduled runs have no thread to report to
+	if message == "" || scheduled {
+		return
+	}
+	if err := mh.sendSystemMessage(message, processedMessageID, jobID); err != nil {
+		log.Error("❌ Failed to send pull request metadata message: %v", err)
+	}
+}
+
+// requestPendingReviewers asks the reviewers held back while the job's PR was a draft to
+// review it. Returns the thread message describing the request, or "" if there was none.
+func (mh *MessageHandler) requestPendingReviewers(jobData *models.JobData) string {
+	pending := jobData.PendingReviewers
+	if pending == nil {
+		return ""
+	}
+	// The PR left draft, so the reviewers are only requested once
+	if err := mh.appState.SetJobPendingReviewers(jobData.JobID, nil); err != nil {
+		log.Error("❌ Failed to clear pending reviewers for job %s: %v", jobData.JobID, err)
+	}
+
+	metadata := clients.PullRequestMetadata{Reviewers: pending.Reviewers, TeamReviewers: pending.TeamReviewers}
+	applied, err := mh.gitUseCase.AddPRMetadata(jobData.PullRequestID, jobData.WorktreePath, metadata, pending.CodeOwnerReviewers)
+	if err != nil {
+		log.Error("❌ Failed to request reviewers for job %s: %v", jobData.JobID, err)
+		return fmt.Sprintf("Failed to request reviewers for the pull request: %v", err)
+	}
+	return pullRequestMetadataMessage(applied)
+}
+
+// pullRequestMetadataMessage describes the labels, reviewers and assignees added to a PR
+func pullRequestMetadataMessage(metadata clients.PullRequestMetadata) string {
+	var parts []string
+	reviewers := append(append([]string{}, metadata.Reviewers...), metadata.TeamReviewers...)
+	if len(reviewers) > 0 {
+		parts = append(parts, "requested reviews from "+mentionList(reviewers))
+	}
+	if len(metadata.Assignees) > 0 {
+		parts = append(parts, "assigned "+mentionList(metadata.Assignees))
+	}
+	if len(metadata.Labels) > 0 {
+		parts = append(parts, "labeled `"+strings.Join(metadata.Labels, "`, `")+"`")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Pull request: " + strings.Join(parts, "; ")
+}
+
+// mentionList formats logins and teams as a list of @-mentions
+func mentionList(names []string) string {
+	mentions := make([]string, len(names))
+	for i, name := range names {
+		mentions[i] = "@" + name
+	}
+	return strings.Join(mentions, ", ")
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"eksecd/clients"
+	"eksecd/core/env"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+func TestPullRequestMetadataSettings(t *testing.T) {
+	settings := map[string]string{
+		prLabelsEnvVar:             "agent, needs-review,",
+		prReviewersEnvVar:          "@alice,bob",
+		prTeamReviewersEnvVar:      "org/backend",
+		prCodeOwnerReviewersEnvVar: "true",
+	}
+	get := func(key string) string { return settings[key] }
+
+	metadata, codeOwnerReviewers := pullRequestMetadataSettings(get, nil)
+	expected := clients.PullRequestMetadata{
+		Labels:        []string{"agent", "needs-review"},
+		Reviewers:     []string{"alice", "bob"},
+		TeamReviewers: []string{"org/backend"},
+	}
+	if !reflect.DeepEqual(metadata, expected) || !codeOwnerReviewers {
+		t.Errorf("expected defaults %+v with code owners, got %+v, %v", expected, metadata, codeOwnerReviewers)
+	}
+
+	disabled := false
+	metadata, codeOwnerReviewers = pullRequestMetadataSettings(get, &models.PullRequestOverrides{
+		Reviewers:          []string{},
+		Assignees:          []string{"carol"},
+		CodeOwnerReviewers: &disabled,
+	})
+	expected = clients.PullRequestMetadata{
+		Labels:        []string{"agent", "needs-review"},
+		Reviewers:     []string{},
+		TeamReviewers: []string{"org/backend"},
+		Assignees:     []string{"carol"},
+	}
+	if !reflect.DeepEqual(metadata, expected) || codeOwnerReviewers {
+		t.Errorf("expected overrides %+v without code owners, got %+v, %v", expected, metadata, codeOwnerReviewers)
+	}
+}
+
+func TestPullRequestMetadataMessage(t *testing.T) {
+	message := pullRequestMetadataMessage(clients.PullRequestMetadata{
+		Labels:        []string{"agent", "bug"},
+		Reviewers:     []string{"alice"},
+		TeamReviewers: []string{"org/backend"},
+		Assignees:     []string{"bob"},
+	})
+	expected := "Pull request: requested reviews from @alice, @org/backend; assigned @bob; labeled `agent`, `bug`"
+	if message != expected {
+		t.Errorf("expected %q, got %q", expected, message)
+	}
+
+	if message := pullRequestMetadataMessage(clients.PullRequestMetadata{}); message != "" {
+		t.Errorf("expected no message without metadata, got %q", message)
+	}
+}
+
+// fakeMetadataHost is a CodeHost that records the metadata added to pull requests and the
+// pull requests marked ready
+type fakeMetadataHost struct {
+	clients.CodeHost
+	added map[string]clients.PullRequestMetadata
+	ready []string
+}
+
+func (f *fakeMetadataHost) Kind() clients.CodeHostKind {
+	return clients.CodeHostGitHub
+}
+
+func (f *fakeMetadataHost) AddPullRequestMetadata(dir, id string, metadata clients.PullRequestMetadata) error {
+	f.added[id] = metadata
+	return nil
+}
+
+func (f *fakeMetadataHost) MarkPullRequestReady(dir, id string) error {
+	f.ready = append(f.ready, id)
+	return nil
+}
+
+func TestAddPullRequestMetadata_HoldsBackReviewersOfDraftPRs(t *testing.T) {
+	t.Setenv("EKSEC_CONFIG_DIR", t.TempDir())
+	t.Setenv(prLabelsEnvVar, "eksecd")
+	t.Setenv(prReviewersEnvVar, "alice")
+	envManager, err := env.NewEnvManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := &fakeMetadataHost{added: map[string]clients.PullRequestMetadata{}}
+	repo := t.TempDir()
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{RepoPath: repo, IsRepoMode: true})
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return repo })
+	gitClient.SetCodeHost(host)
+	messages := make(chan OutgoingMessage, 10)
+	mh := &MessageHandler{
+		gitUseCase:    usecases.NewGitUseCase(gitClient, nil, appState),
+		appState:      appState,
+		envManager:    envManager,
+		messageSender: &MessageSender{messageQueue: messages},
+		activity:      NewJobActivityTracker(),
+	}
+
+	for _, job := range []models.JobData{
+		{JobID: "job-draft", PullRequestID: "1", PullRequestDraft: true},
+		{JobID: "job-ready", PullRequestID: "2"},
+	} {
+		if err := appState.UpdateJobData(job.JobID, job); err != nil {
+			t.Fatal(err)
+		}
+		mh.addPullRequestMetadata(job.JobID, "msg-1", "", &usecases.AutoCommitResult{
+			JustCreatedPR: true,
+			PullRequestID: job.PullRequestID,
+			Draft:         job.PullRequestDraft,
+		})
+	}
+
+	// The draft only gets its labels; its reviewers wait until it is marked ready
+	if got := host.added["1"]; !reflect.DeepEqual(got, clients.PullRequestMetadata{Labels: []string{"eksecd"}}) {
+		t.Errorf("expected only labels on the draft PR, got %+v", got)
+	}
+	if jobData, _ := appState.GetJobData("job-draft"); jobData.PendingReviewers == nil ||
+		!reflect.DeepEqual(jobData.PendingReviewers.Reviewers, []string{"alice"}) {
+		t.Errorf("expected alice as pending reviewer, got %+v", jobData.PendingReviewers)
+	}
+	if got := host.added["2"]; !reflect.DeepEqual(got.Reviewers, []string{"alice"}) {
+		t.Errorf("expected reviewers on the ready PR, got %+v", got)
+	}
+	if jobData, _ := appState.GetJobData("job-ready"); jobData.PendingReviewers != nil {
+		t.Errorf("expected no pending reviewers for the ready PR, got %+v", jobData.PendingReviewers)
+	}
+	for len(messages) > 0 {
+		<-messages
+	}
+
+	delete(host.added, "1")
+	if err := mh.markPullRequestReady("job-draft", "msg-2", "requested"); err != nil {
+		t.Fatalf("failed to mark PR ready: %v", err)
+	}
+	if !reflect.DeepEqual(host.ready, []string{"1"}) {
+		t.Errorf("expected PR 1 marked ready, got %v", host.ready)
+	}
+	if got := host.added["1"]; !reflect.DeepEqual(got, clients.PullRequestMetadata{Reviewers: []string{"alice"}}) {
+		t.Errorf("expected reviewers requested once the PR is ready, got %+v", got)
+	}
+	if jobData, _ := appState.GetJobData("job-draft"); jobData.PendingReviewers != nil || jobData.PullRequestDraft {
+		t.Errorf("expected a ready PR without pending reviewers, got %+v", jobData)
+	}
+	message := (<-messages).Data.(models.BaseMessage).Payload.(models.SystemMessagePayload).Message
+	if !strings.Contains(message, "marked ready for review") || !strings.Contains(message, "@alice") {
+		t.Errorf("expected ready message naming the reviewers, got %q", message)
+	}
+}
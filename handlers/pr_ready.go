@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+// markReadyPhrases are thread messages that mark the job's draft PR ready instead of
+// starting a turn. The whole message must match, ignoring case and trailing punctuation.
+var markReadyPhrases = []string{
+	"ready for review",
+	"mark ready",
+	"mark as ready",
+	"mark ready for review",
+	"mark as ready for review",
+	"mark pr ready",
+	"mark the pr ready",
+	"mark the pr as ready",
+	"mark the pr as ready for review",
+}
+
+// isMarkReadyPhrase reports whether a thread message asks to mark the PR ready for review
+func isMarkReadyPhrase(message string) bool {
+	normalized := strings.ToLower(strings.TrimRight(strings.TrimSpace(message), ".!"))
+	normalized = strings.Join(strings.Fields(normalized), " ")
+	for _, phrase := range markReadyPhrases {
+		if normalized == phrase {
+			return true
+		}
+	}
+	return false
+}
+
+// asksToMarkReady reports whether a thread message is only a request to mark the job's
+// draft PR ready. Jobs without a draft PR get a regular turn for such messages.
+func asksToMarkReady(jobData *models.JobData, payload models.UserMessagePayload) bool {
+	return jobData.PullRequestID != "" && jobData.PullRequestDraft &&
+		len(payload.CoalescedMessageIDs) == 0 && isMarkReadyPhrase(payload.Message)
+}
+
+func (mh *MessageHandler) handleMarkReady(msg models.BaseMessage) error {
+	log.Info("📋 Starting to handle mark ready message")
+	var payload models.MarkReadyPayload
+	if err := unmarshalPayload(msg.Payload, &payload); err != nil {
+		log.Info("❌ Failed to unmarshal mark ready payload: %v", err)
+		return fmt.Errorf("failed to unmarshal mark ready payload: %w", err)
+	}
+	if payload.JobID == "" {
+		return fmt.Errorf("mark ready request is missing job_id")
+	}
+
+	if err := mh.markPullRequestReady(payload.JobID, payload.ProcessedMessageID, "requested"); err != nil {
+		if payload.ProcessedMessageID != "" {
+			if sendErr := mh.sendErrorMessage(err, payload.ProcessedMessageID, payload.JobID); sendErr != nil {
+				log.Error("Failed to send error message: %v", sendErr)
+			}
+		}
+		return err
+	}
+
+	log.Info("📋 Completed successfully - handled mark ready message for job %s", payload.JobID)
+	return nil
+}
+
+// markPullRequestReady marks the job's draft PR ready for review, requests the reviewers held
+// back while it was a draft and tells the thread why.
+// processedMessageID is the thread message to reply to; empty uses the job's latest message.
+func (mh *MessageHandler) markPullRequestReady(jobID, processedMessageID, reason string) error {
+	jobData, exists := mh.appState.GetJobData(jobID)
+	if !exists {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+	if processedMessageID == "" {
+		processedMessageID = jobData.ProcessedMessageID
+	}
+
+	if jobData.PullRequestID == "" {
+		log.Info("ℹ️ Job %s has no pull request to mark ready", jobID)
+		if err := mh.sendSystemMessage("There is no pull request to mark ready for review yet.", processedMessageID, jobID); err != nil {
+			log.Error("❌ Failed to send mark ready message: %v", err)
+		}
+		return nil
+	}
+
+	if err := mh.gitUseCase.MarkPRReady(jobData.PullRequestID); err != nil {
+		log.Error("❌ Failed to mark PR %s ready for job %s: %v", jobData.PullRequestID, jobID, err)
+		return fmt.Errorf("failed to mark pull request ready: %w", err)
+	}
+	if err := mh.appState.SetJobPullRequestDraft(jobID, false); err != nil {
+		log.Error("❌ Failed to record ready state for job %s: %v", jobID, err)
+	}
+	log.Info("✅ Marked PR %s of job %s ready for review (%s)", jobData.PullRequestID, jobID, reason)
+
+	message := markReadyMessage(jobData.PullRequestURL, reason)
+	if reviewersMessage := mh.requestPendingReviewers(jobData); reviewersMessage != "" {
+		message += "\n" + reviewersMessage
+	}
+	if err := mh.sendSystemMessage(message, processedMessageID, jobID); err != nil {
+		log.Error("❌ Failed to send mark ready message: %v", err)
+	}
+	return nil
+}
+
+// markReadyMessage tells the thread the pull request is ready for review and why
+func markReadyMessage(prURL, reason string) string {
+	pullRequest := "Pull request"
+	if prURL != "" {
+		pullRequest = fmt.Sprintf("[Pull request](%s)", prURL)
+	}
+	return fmt.Sprintf("%s marked ready for review (%s)", pullRequest, reason)
+}
+
+// recordPullRequestDraft remembers whether the job's PR is a draft after a turn pushed to it
+func (mh *MessageHandler) recordPullRequestDraft(jobID string, commitResult *usecases.AutoCommitResult) {
+	if commitResult == nil || commitResult.PullRequestLink == "" {
+		return
+	}
+	if err := mh.appState.SetJobPullRequestDraft(jobID, commitResult.Draft); err != nil {
+		log.Error("❌ Failed to record draft state for job %s: %v", jobID, err)
+	}
+}
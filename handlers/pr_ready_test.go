@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"testing"
+
+	"eksecd/models"
+)
+
+func TestIsMarkReadyPhrase(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"ready for review", true},
+		{"  Mark as ready!", true},
+		{"Mark  the PR as ready for review.", true},
+		{"MARK READY", true},
+		{"ready for review once the tests pass", false},
+		{"is this ready for review?", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isMarkReadyPhrase(tt.message); got != tt.expected {
+			t.Errorf("isMarkReadyPhrase(%q) = %v, want %v", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestAsksToMarkReady(t *testing.T) {
+	draft := &models.JobData{PullRequestID: "12", PullRequestDraft: true}
+	ready := &models.JobData{PullRequestID: "12"}
+	message := models.UserMessagePayload{Message: "ready for review"}
+
+	if !asksToMarkReady(draft, message) {
+		t.Errorf("expected the phrase to mark a draft PR ready")
+	}
+	if asksToMarkReady(ready, message) {
+		t.Errorf("expected the phrase to start a turn for a PR that is not a draft")
+	}
+	if asksToMarkReady(&models.JobData{PullRequestDraft: true}, message) {
+		t.Errorf("expected the phrase to start a turn for a job without a PR")
+	}
+	coalesced := models.UserMessagePayload{Message: "ready for review", CoalescedMessageIDs: []string{"pm-1"}}
+	if asksToMarkReady(draft, coalesced) {
+		t.Errorf("expected coalesced messages to start a turn")
+	}
+}
+
+func TestMarkReadyMessage(t *testing.T) {
+	if got := markReadyMessage("https://github.com/o/r/pull/12", "CI checks passed"); got != "[Pull request](https://github.com/o/r/pull/12) marked ready for review (CI checks passed)" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := markReadyMessage("", "requested"); got != "Pull request marked ready for review (requested)" {
+		t.Errorf("unexpected message without URL %q", got)
+	}
+}
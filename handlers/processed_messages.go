@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"time"
+
+	"eksecd/core/log"
+	"eksecd/models"
+)
+
+// recordProcessedMessages persists the outcome of processing the given messages so a
+// redelivery after a restart is answered from the record instead of running again.
+// A failure reported after the reply was already sent does not replace the reply.
+func (mh *MessageHandler) recordProcessedMessages(
+	processedMessageIDs []string,
+	jobID, messageType, messageLink string,
+	outcome models.ProcessedMessageOutcome,
+	reply, errText string,
+) {
+	now := time.Now()
+	for _, processedMessageID := range processedMessageIDs {
+		if processedMessageID == "" {
+			continue
+		}
+		if outcome == models.ProcessedMessageOutcomeFailed {
+			if existing, ok := mh.appState.GetProcessedMessage(processedMessageID); ok &&
+				existing.Outcome == models.ProcessedMessageOutcomeCompleted {
+				continue
+			}
+		}
+		if err := mh.appState.RecordProcessedMessage(models.ProcessedMessage{
+			ProcessedMessageID: processedMessageID,
+			JobID:              jobID,
+			MessageType:        messageType,
+			MessageLink:        messageLink,
+			Outcome:            outcome,
+			Reply:              reply,
+			Error:              errText,
+			ProcessedAt:        now,
+		}); err != nil {
+			log.Warn("⚠️ Failed to record processed message %s: %v", processedMessageID, err)
+		}
+	}
+}
+
+// ReplayProcessedMessage answers a redelivered message with the result recorded when it
+// was first processed
+func (mh *MessageHandler) ReplayProcessedMessage(processed models.ProcessedMessage) {
+	switch processed.Outcome {
+	case models.ProcessedMessageOutcomeCompleted:
+		mh.sendAssistantReply(processed.JobID, processed.Reply, processed.MessageLink, processed.ProcessedMessageID)
+	case models.ProcessedMessageOutcomeFailed:
+		if err := mh.sendSystemMessage(
+			"eksecd encountered error: "+processed.Error,
+			processed.ProcessedMessageID,
+			processed.JobID,
+		); err != nil {
+			log.Error("❌ Failed to replay error for message %s: %v", processed.ProcessedMessageID, err)
+		}
+	default:
+		log.Warn("⚠️ Unknown outcome %q for processed message %s, not replaying", processed.Outcome, processed.ProcessedMessageID)
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gammazero/workerpool"
+
+	"eksecd/models"
+)
+
+func TestRecordProcessedMessages_FailureKeepsEarlierReply(t *testing.T) {
+	mh := newTestStatusHandler(t)
+
+	mh.recordProcessedMessages(
+		[]string{"pm-1", "pm-2"}, "job-1", models.MessageTypeUserMessage, "https://example.com/thread",
+		models.ProcessedMessageOutcomeCompleted, "done", "",
+	)
+	mh.recordProcessedMessages(
+		[]string{"pm-2", "pm-3"}, "job-1", models.MessageTypeUserMessage, "https://example.com/thread",
+		models.ProcessedMessageOutcomeFailed, "", "footer validation failed",
+	)
+
+	for id, want := range map[string]models.ProcessedMessageOutcome{
+		"pm-1": models.ProcessedMessageOutcomeCompleted,
+		"pm-2": models.ProcessedMessageOutcomeCompleted,
+		"pm-3": models.ProcessedMessageOutcomeFailed,
+	} {
+		processed, ok := mh.appState.GetProcessedMessage(id)
+		if !ok {
+			t.Fatalf("expected %s to be recorded", id)
+		}
+		if processed.Outcome != want {
+			t.Errorf("expected %s outcome %s, got %s", id, want, processed.Outcome)
+		}
+	}
+}
+
+func TestDispatch_SkipsMessageProcessedBeforeRestart(t *testing.T) {
+	appState := createTestAppState(t)
+	if err := appState.RecordProcessedMessage(models.ProcessedMessage{
+		ProcessedMessageID: "pm-1",
+		JobID:              "job-1",
+		MessageType:        models.MessageTypeStartConversation,
+		Outcome:            models.ProcessedMessageOutcomeCompleted,
+		Reply:              "done",
+		ProcessedAt:        time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to record processed message: %v", err)
+	}
+
+	// A new dispatcher has no in-memory history, as after a restart
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+	dispatcher := NewJobDispatcher(nil, wp, appState)
+
+	dispatcher.Dispatch(createTestMessageWithProcessedID(models.MessageTypeStartConversation, "job-1", "pm-1"))
+
+	dispatcher.mutex.Lock()
+	defer dispatcher.mutex.Unlock()
+	if _, exists := dispatcher.activeJobs["job-1"]; exists {
+		t.Errorf("expected already processed message not to start a job processor")
+	}
+}
+
+func TestRecoverJobs_SkipsProcessedMessages(t *testing.T) {
+	appState := createTestAppState(t)
+	now := time.Now()
+	if err := appState.UpdateJobData("job-1", models.JobData{
+		JobID:              "job-1",
+		ClaudeSessionID:    "session-1",
+		ProcessedMessageID: "pm-1",
+		Status:             models.JobStatusInProgress,
+		UpdatedAt:          now,
+	}); err != nil {
+		t.Fatalf("failed to add job: %v", err)
+	}
+	if err := appState.AddQueuedMessage(models.QueuedMessage{
+		ProcessedMessageID: "pm-2",
+		JobID:              "job-2",
+		MessageType:        models.MessageTypeUserMessage,
+		QueuedAt:           now,
+	}); err != nil {
+		t.Fatalf("failed to add queued message: %v", err)
+	}
+	for _, processed := range []models.ProcessedMessage{
+		{ProcessedMessageID: "pm-1", JobID: "job-1", Outcome: models.ProcessedMessageOutcomeCompleted, ProcessedAt: now},
+		{ProcessedMessageID: "pm-2", JobID: "job-2", Outcome: models.ProcessedMessageOutcomeFailed, ProcessedAt: now},
+	} {
+		if err := appState.RecordProcessedMessage(processed); err != nil {
+			t.Fatalf("failed to record processed message: %v", err)
+		}
+	}
+
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+	dispatcher := NewJobDispatcher(nil, wp, appState)
+
+	RecoverJobs(appState, nil, dispatcher, nil)
+
+	jobData, exists := appState.GetJobData("job-1")
+	if !exists || jobData.Status != models.JobStatusCompleted {
+		t.Errorf("expected job-1 to be marked completed, got %+v", jobData)
+	}
+	if queued := appState.GetAllQueuedMessages(); len(queued) != 0 {
+		t.Errorf("expected processed queued message to be removed, got %+v", queued)
+	}
+	if len(dispatcher.ActiveJobIDs()) != 0 {
+		t.Errorf("expected nothing to be dispatched, got %v", dispatcher.ActiveJobIDs())
+	}
+}
+
+func TestProcessedMessages_Retention(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	appState := models.NewAppState("test-agent", statePath)
+	now := time.Now()
+
+	restored := map[string]*models.ProcessedMessage{
+		"expired": {ProcessedMessageID: "expired", ProcessedAt: now.Add(-models.ProcessedMessageRetention - time.Hour)},
+		"recent":  {ProcessedMessageID: "recent", ProcessedAt: now.Add(-time.Hour)},
+	}
+	if err := appState.RestoreProcessedMessages(restored); err != nil {
+		t.Fatalf("failed to restore processed messages: %v", err)
+	}
+
+	if _, ok := appState.GetProcessedMessage("expired"); ok {
+		t.Errorf("expected expired processed message to be dropped")
+	}
+	if _, ok := appState.GetProcessedMessage("recent"); !ok {
+		t.Errorf("expected recent processed message to be kept")
+	}
+
+	// Persisted records survive a reload
+	if err := appState.Close(); err != nil {
+		t.Fatalf("failed to close state store: %v", err)
+	}
+	loaded, err := models.LoadState(statePath)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if _, ok := loaded.ProcessedMessages["recent"]; !ok || len(loaded.ProcessedMessages) != 1 {
+		t.Errorf("expected only the recent processed message on disk, got %v", loaded.ProcessedMessages)
+	}
+}
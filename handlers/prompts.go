@@ -175,3 +175,11 @@ MODE: You are in ASK mode.
 
 	return basePrompt
 }
+
+// systemPrompt returns the system prompt for the configured agent
+func (mh *MessageHandler) systemPrompt(mode models.AgentMode, repoContext *models.RepositoryContext, workspacePath string) string {
+	if mh.claudeService.AgentName() == "cursor" {
+		return GetCursorSystemPrompt(mode, repoContext, workspacePath)
+	}
+	return GetClaudeSystemPrompt(mode, repoContext, workspacePath)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"eksecd/core/log"
+	"eksecd/usecases"
+)
+
+// autoRebaseEnvVar enables rebasing job branches onto the default branch before each turn
+const autoRebaseEnvVar = "AUTO_REBASE"
+
+// autoRebaseEnabled reports whether worktree job branches should be rebased onto the
+// default branch before each user message turn
+func (mh *MessageHandler) autoRebaseEnabled() bool {
+	if mh == nil || mh.envManager == nil {
+		return false
+	}
+	envVal := mh.envManager.Get(autoRebaseEnvVar)
+	if envVal == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(envVal)
+	if err != nil {
+		log.Warn("⚠️ Invalid %s value %q, rebasing disabled", autoRebaseEnvVar, envVal)
+		return false
+	}
+	return enabled
+}
+
+// rebaseStatusMessage returns the thread message describing a job branch rebase, or "" if
+// there is nothing to report
+func rebaseStatusMessage(result *usecases.WorktreeRebaseResult) string {
+	if result == nil {
+		return ""
+	}
+
+	if result.Aborted {
+		return fmt.Sprintf("Could not rebase branch `%s` onto `%s` (%s). The rebase was aborted and the branch is unchanged; it may need a manual rebase before it can be merged.",
+			result.BranchName, result.Onto, result.AbortReason)
+	}
+	if !result.Rebased {
+		return ""
+	}
+
+	message := fmt.Sprintf("Rebased branch `%s` onto `%s`", result.BranchName, result.Onto)
+	if len(result.ResolvedConflicts) > 0 {
+		message += fmt.Sprintf(", resolving conflicts in %s", formatFileList(result.ResolvedConflicts))
+	}
+	if result.Pushed {
+		message += " and force-pushed it"
+	}
+	return message + "."
+}
+
+// formatFileList renders file paths as inline code, separated by commas
+func formatFileList(files []string) string {
+	quoted := make([]string, len(files))
+	for i, file := range files {
+		quoted[i] = "`" + file + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+
+	"eksecd/usecases"
+)
+
+func TestRebaseStatusMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *usecases.WorktreeRebaseResult
+		expected string
+	}{
+		{
+			name:     "No rebase attempted",
+			result:   nil,
+			expected: "",
+		},
+		{
+			name: "Clean rebase of a pushed branch",
+			result: &usecases.WorktreeRebaseResult{
+				BranchName: "eksecd/feature",
+				Onto:       "origin/main",
+				Rebased:    true,
+				Pushed:     true,
+			},
+			expected: "Rebased branch `eksecd/feature` onto `origin/main` and force-pushed it.",
+		},
+		{
+			name: "Resolved conflicts on an unpushed branch",
+			result: &usecases.WorktreeRebaseResult{
+				BranchName:        "eksecd/feature",
+				Onto:              "origin/main",
+				Rebased:           true,
+				ResolvedConflicts: []string{"go.mod", "main.go"},
+			},
+			expected: "Rebased branch `eksecd/feature` onto `origin/main`, resolving conflicts in `go.mod`, `main.go`.",
+		},
+		{
+			name: "Aborted rebase",
+			result: &usecases.WorktreeRebaseResult{
+				BranchName:  "eksecd/feature",
+				Onto:        "origin/main",
+				Aborted:     true,
+				AbortReason: "conflict markers left in main.go",
+			},
+			expected: "Could not rebase branch `eksecd/feature` onto `origin/main` (conflict markers left in main.go). The rebase was aborted and the branch is unchanged; it may need a manual rebase before it can be merged.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebaseStatusMessage(tt.result); got != tt.expected {
+				t.Errorf("rebaseStatusMessage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"time"
 
@@ -57,7 +61,7 @@ func RecoverJobs(
 					log.Warn("⚠️ Failed to cleanup worktree for stale job %s: %v", jobID, err)
 				}
 			}
-			if err := appState.RemoveJob(jobID); err != nil {
+			if err := appState.ArchiveJob(jobID, models.JobOutcomeAbandoned, ""); err != nil {
 				log.Error("❌ Failed to remove stale job %s: %v", jobID, err)
 			} else {
 				removedJobsCount++
@@ -71,7 +75,7 @@ func RecoverJobs(
 			if jobData.WorktreePath != "" {
 				if !gitUseCase.WorktreeExists(jobData.WorktreePath) {
 					log.Warn("⚠️ Worktree %s for job %s no longer exists, removing job", jobData.WorktreePath, jobID)
-					if err := appState.RemoveJob(jobID); err != nil {
+					if err := appState.ArchiveJob(jobID, models.JobOutcomeAbandoned, ""); err != nil {
 						log.Error("❌ Failed to remove job with missing worktree %s: %v", jobID, err)
 					} else {
 						removedJobsCount++
@@ -87,7 +91,7 @@ func RecoverJobs(
 				}
 				if !branchExists {
 					log.Warn("⚠️ Branch %s for job %s no longer exists, removing job", jobData.BranchName, jobID)
-					if err := appState.RemoveJob(jobID); err != nil {
+					if err := appState.ArchiveJob(jobID, models.JobOutcomeAbandoned, ""); err != nil {
 						log.Error("❌ Failed to remove job with missing branch %s: %v", jobID, err)
 					} else {
 						removedJobsCount++
@@ -97,6 +101,21 @@ func RecoverJobs(
 			}
 		}
 
+		// The turn finished before the restart but the job status was not updated yet;
+		// running it again would duplicate the reply and any commits
+		if processed, ok := appState.GetProcessedMessage(jobData.ProcessedMessageID); ok {
+			log.Info("🔁 Job %s already finished message %s (%s), not recovering it", jobID, processed.ProcessedMessageID, processed.Outcome)
+			jobData.Status = models.JobStatusCompleted
+			if processed.Outcome == models.ProcessedMessageOutcomeFailed {
+				jobData.Status = models.JobStatusFailed
+			}
+			jobData.UpdatedAt = now
+			if err := appState.UpdateJobData(jobID, jobData); err != nil {
+				log.Error("❌ Failed to update status of already processed job %s: %v", jobID, err)
+			}
+			continue
+		}
+
 		// Determine message type based on ClaudeSessionID
 		var msg models.BaseMessage
 		if jobData.ClaudeSessionID == "" {
@@ -110,6 +129,7 @@ func RecoverJobs(
 					Message:            jobData.LastMessage,
 					ProcessedMessageID: jobData.ProcessedMessageID,
 					MessageLink:        jobData.MessageLink,
+					ScheduleName:       jobData.ScheduleName,
 				},
 			}
 		} else {
@@ -153,6 +173,17 @@ func RecoverJobs(
 				continue
 			}
 
+			// Messages that were fully processed before the restart were already answered
+			if _, processed := appState.GetProcessedMessage(queuedMsg.ProcessedMessageID); processed {
+				log.Info("🔁 Queued message %s was already processed, removing from queue", queuedMsg.ProcessedMessageID)
+				if err := appState.RemoveQueuedMessage(queuedMsg.ProcessedMessageID); err != nil {
+					log.Error("❌ Failed to remove processed queued message %s: %v", queuedMsg.ProcessedMessageID, err)
+				} else {
+					removedQueuedCount++
+				}
+				continue
+			}
+
 			// Check staleness - remove messages older than 24h (same as jobs)
 			msgAge := now.Sub(queuedMsg.QueuedAt)
 			if msgAge > 24*time.Hour {
@@ -189,6 +220,7 @@ func RecoverJobs(
 						Message:            queuedMsg.Message,
 						ProcessedMessageID: queuedMsg.ProcessedMessageID,
 						MessageLink:        queuedMsg.MessageLink,
+						Author:             queuedMsg.Author,
 					},
 				}
 			} else {
@@ -217,10 +249,15 @@ func RecoverJobs(
 // RestoreAppState loads persisted state from disk and restores jobs and queued messages
 // Returns the initialized AppState and agent ID
 func RestoreAppState(statePath string) (*models.AppState, string, error) {
-	// Try to load existing state
-	loadedState, loadErr := models.LoadState(statePath)
-	if loadErr != nil {
-		log.Warn("⚠️ Failed to load persisted state: %v (will start fresh)", loadErr)
+	// One-time migration from the JSON state file used before the state store
+	legacyPath := filepath.Join(filepath.Dir(statePath), models.LegacyStateFileName)
+	if err := migrateLegacyState(legacyPath, statePath); err != nil {
+		return nil, "", err
+	}
+
+	loadedState, err := loadPersistedState(statePath)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// Determine agent ID (use loaded or generate new)
@@ -258,5 +295,106 @@ func RestoreAppState(statePath string) (*models.AppState, string, error) {
 		}
 	}
 
+	// Restore processed message IDs so redeliveries after a restart are not processed twice
+	if loadedState.Loaded && len(loadedState.ProcessedMessages) > 0 {
+		if err := appState.RestoreProcessedMessages(loadedState.ProcessedMessages); err != nil {
+			log.Warn("⚠️ Failed to restore processed messages: %v", err)
+		} else {
+			log.Info("📥 Restored %d processed message IDs", len(loadedState.ProcessedMessages))
+		}
+	}
+
 	return appState, agentID, nil
 }
+
+// loadPersistedState migrates the state at statePath to the current schema and loads it.
+// A store that cannot be read is moved aside and replaced by the newest usable backup;
+// without one the agent starts with empty state. A good store is backed up after loading.
+func loadPersistedState(statePath string) (*models.LoadedState, error) {
+	loadedState, err := migrateAndLoadState(statePath)
+	if errors.Is(err, models.ErrStateLocked) || errors.Is(err, models.ErrStateSchemaTooNew) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("🚨 ==================================================================")
+		log.Error("🚨 Persisted state at %s is unreadable: %v", statePath, err)
+		if err := recoverState(statePath); err != nil {
+			return nil, err
+		}
+
+		if loadedState, err = migrateAndLoadState(statePath); err != nil {
+			return nil, fmt.Errorf("failed to load recovered state: %w", err)
+		}
+	}
+
+	if loadedState.Loaded {
+		if err := models.BackupState(statePath); err != nil {
+			log.Warn("⚠️ Failed to back up persisted state: %v", err)
+		}
+	}
+	return loadedState, nil
+}
+
+// migrateLegacyState moves the JSON state file used before the state store into the
+// store. A file that cannot be parsed is moved aside and the state is recovered from
+// backups; any other failure stops startup, so the file is not skipped for good.
+func migrateLegacyState(legacyPath, statePath string) error {
+	migrated, err := models.MigrateJSONState(legacyPath, statePath)
+	switch {
+	case errors.Is(err, models.ErrLegacyStateCorrupt):
+		quarantinePath := fmt.Sprintf("%s.corrupt-%s", legacyPath, time.Now().Format("20060102-150405"))
+		if renameErr := os.Rename(legacyPath, quarantinePath); renameErr != nil {
+			return fmt.Errorf("failed to move unreadable legacy state file aside: %w", renameErr)
+		}
+		log.Error("🚨 ==================================================================")
+		log.Error("🚨 Legacy state file %s is unreadable: %v", legacyPath, err)
+		log.Error("🚨 Moved the unreadable legacy state to %s", quarantinePath)
+		return recoverState(statePath)
+	case err != nil && migrated:
+		// The store holds the migrated state, so the file is not migrated again
+		log.Warn("⚠️ Migrated state from %s but failed to rename it: %v", legacyPath, err)
+	case err != nil:
+		return fmt.Errorf("failed to migrate legacy state file %s: %w", legacyPath, err)
+	case migrated:
+		log.Info("📦 Migrated state from %s to %s", legacyPath, statePath)
+	}
+	return nil
+}
+
+// recoverState replaces unreadable state with the newest usable backup, or with empty
+// state when there is none, and reports what was lost. Closes the banner opened by the
+// caller's description of the failure.
+func recoverState(statePath string) error {
+	quarantinePath, restoredFrom, err := models.RecoverState(statePath)
+	if err != nil {
+		return fmt.Errorf("failed to recover persisted state: %w", err)
+	}
+	if _, err := os.Stat(quarantinePath); err == nil {
+		log.Error("🚨 Moved the unreadable state to %s", quarantinePath)
+	}
+	if restoredFrom != "" {
+		log.Error("🚨 Restored the last good state from backup %s", restoredFrom)
+		log.Error("🚨 Jobs and messages changed since that backup are lost")
+	} else {
+		log.Error("🚨 No usable state backup found, starting with empty state")
+		log.Error("🚨 Jobs and queued messages from before the restart are lost")
+	}
+	log.Error("🚨 ==================================================================")
+	return nil
+}
+
+// migrateAndLoadState runs pending state migrations and loads the state
+func migrateAndLoadState(statePath string) (*models.LoadedState, error) {
+	from, applied, err := models.MigrateState(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate persisted state: %w", err)
+	}
+	for _, migration := range applied {
+		log.Info("📦 Migrated state to schema version %d: %s", migration.Version, migration.Description)
+	}
+	if len(applied) > 0 {
+		log.Info("📦 Migrated state from schema version %d to %d", from, models.CurrentStateSchemaVersion)
+	}
+
+	return models.LoadState(statePath)
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eksecd/models"
+)
+
+func TestRestoreAppState_MigratesLegacyJSONState(t *testing.T) {
+	configDir := t.TempDir()
+	legacyPath := filepath.Join(configDir, models.LegacyStateFileName)
+	statePath := filepath.Join(configDir, models.StateFileName)
+
+	legacy := models.PersistedState{
+		AgentID: "ccaid_legacy",
+		Jobs: map[string]*models.JobData{
+			"job-1": {JobID: "job-1", BranchName: "eksecd/legacy", Status: models.JobStatusCompleted, UpdatedAt: time.Now()},
+		},
+		QueuedMessages: map[string]*models.QueuedMessage{
+			"pm-1": {ProcessedMessageID: "pm-1", JobID: "job-2", MessageType: models.MessageTypeUserMessage, QueuedAt: time.Now()},
+		},
+	}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatalf("failed to marshal legacy state: %v", err)
+	}
+	if err := os.WriteFile(legacyPath, data, 0644); err != nil {
+		t.Fatalf("failed to write legacy state: %v", err)
+	}
+
+	appState, agentID, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if agentID != "ccaid_legacy" {
+		t.Errorf("expected migrated agent ID, got %s", agentID)
+	}
+	if jobData, exists := appState.GetJobData("job-1"); !exists || jobData.BranchName != "eksecd/legacy" {
+		t.Errorf("expected migrated job, got %+v", jobData)
+	}
+	if queued := appState.GetAllQueuedMessages(); len(queued) != 1 || queued[0].ProcessedMessageID != "pm-1" {
+		t.Errorf("expected migrated queued message, got %+v", queued)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("expected legacy state file to be renamed after migration")
+	}
+	if _, err := os.Stat(legacyPath + ".migrated"); err != nil {
+		t.Errorf("expected renamed legacy state file: %v", err)
+	}
+
+	// Changes after the migration land in the store only
+	if err := appState.RemoveJob("job-1"); err != nil {
+		t.Fatalf("failed to remove job: %v", err)
+	}
+	if err := appState.Close(); err != nil {
+		t.Fatalf("failed to close state store: %v", err)
+	}
+
+	restored, agentID, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer restored.Close()
+	if agentID != "ccaid_legacy" {
+		t.Errorf("expected agent ID to survive restart, got %s", agentID)
+	}
+	if _, exists := restored.GetJobData("job-1"); exists {
+		t.Errorf("expected removed job to stay removed after restart")
+	}
+	if queued := restored.GetAllQueuedMessages(); len(queued) != 1 {
+		t.Errorf("expected queued message to survive restart, got %+v", queued)
+	}
+}
+
+func TestAppState_PersistsRecordsAcrossReopen(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), models.StateFileName)
+	appState := models.NewAppState("ccaid_test", statePath)
+
+	for _, jobID := range []string{"job-1", "job-2"} {
+		if err := appState.UpdateJobData(jobID, models.JobData{JobID: jobID, Status: models.JobStatusInProgress}); err != nil {
+			t.Fatalf("failed to update job: %v", err)
+		}
+	}
+	if err := appState.UpdateJobData("job-1", models.JobData{JobID: "job-1", Status: models.JobStatusCompleted}); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	if err := appState.RemoveJob("job-2"); err != nil {
+		t.Fatalf("failed to remove job: %v", err)
+	}
+	if err := appState.Close(); err != nil {
+		t.Fatalf("failed to close state store: %v", err)
+	}
+
+	loaded, err := models.LoadState(statePath)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if !loaded.Loaded || loaded.AgentID != "ccaid_test" {
+		t.Fatalf("expected loaded state for ccaid_test, got %+v", loaded)
+	}
+	if len(loaded.Jobs) != 1 || loaded.Jobs["job-1"].Status != models.JobStatusCompleted {
+		t.Errorf("expected only the completed job-1, got %+v", loaded.Jobs)
+	}
+}
+
+func TestLoadState_MissingStore(t *testing.T) {
+	loaded, err := models.LoadState(filepath.Join(t.TempDir(), models.StateFileName))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Loaded {
+		t.Errorf("expected nothing to be loaded from a missing store")
+	}
+}
+
+func TestRestoreAppState_RecoversFromCorruptLegacyJSONState(t *testing.T) {
+	configDir := t.TempDir()
+	legacyPath := filepath.Join(configDir, models.LegacyStateFileName)
+	statePath := filepath.Join(configDir, models.StateFileName)
+
+	// A backup of the store exists, but the store itself is gone
+	appState := models.NewAppState("ccaid_backup", statePath)
+	if err := appState.UpdateJobData("job-1", models.JobData{JobID: "job-1", BranchName: "eksecd/backup", Status: models.JobStatusCompleted}); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	appState.Close()
+	if err := models.BackupState(statePath); err != nil {
+		t.Fatalf("failed to back up state: %v", err)
+	}
+	if err := os.Remove(statePath); err != nil {
+		t.Fatalf("failed to remove state: %v", err)
+	}
+	if err := os.WriteFile(legacyPath, []byte(`{"agent_id": "ccaid_leg`), 0644); err != nil {
+		t.Fatalf("failed to write legacy state: %v", err)
+	}
+
+	restored, agentID, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer restored.Close()
+	if agentID != "ccaid_backup" {
+		t.Errorf("expected agent ID from backup, got %s", agentID)
+	}
+	if jobData, exists := restored.GetJobData("job-1"); !exists || jobData.BranchName != "eksecd/backup" {
+		t.Errorf("expected job from backup, got %+v", jobData)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("expected the corrupt legacy state file to be moved aside")
+	}
+	if quarantined, _ := filepath.Glob(legacyPath + ".corrupt-*"); len(quarantined) != 1 {
+		t.Errorf("expected the corrupt legacy state file to be kept, got %v", quarantined)
+	}
+}
+
+func TestRestoreAppState_FailsWhenLegacyStateCannotBeMigrated(t *testing.T) {
+	configDir := t.TempDir()
+	// A directory in place of the file cannot be read
+	if err := os.Mkdir(filepath.Join(configDir, models.LegacyStateFileName), 0755); err != nil {
+		t.Fatalf("failed to create legacy state dir: %v", err)
+	}
+
+	if _, _, err := RestoreAppState(filepath.Join(configDir, models.StateFileName)); err == nil {
+		t.Fatal("expected startup to fail when the legacy state cannot be migrated")
+	}
+}
+
+func TestRestoreAppState_MigratesSchema(t *testing.T) {
+	configDir := t.TempDir()
+	statePath := filepath.Join(configDir, models.StateFileName)
+	updatedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// A legacy file from before modes and the job history existed
+	legacy := `{"agent_id":"ccaid_old","jobs":{"job-1":{"job_id":"job-1","branch_name":"eksecd/old","status":"completed","updated_at":"` +
+		updatedAt.Format(time.RFC3339) + `"}}}`
+	if err := os.WriteFile(filepath.Join(configDir, models.LegacyStateFileName), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy state: %v", err)
+	}
+
+	appState, _, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer appState.Close()
+
+	jobData, exists := appState.GetJobData("job-1")
+	if !exists {
+		t.Fatalf("expected migrated job")
+	}
+	if jobData.Mode != models.AgentModeExecute {
+		t.Errorf("expected mode to default to execute, got %q", jobData.Mode)
+	}
+	if !jobData.CreatedAt.Equal(updatedAt) {
+		t.Errorf("expected CreatedAt to default to UpdatedAt %s, got %s", updatedAt, jobData.CreatedAt)
+	}
+}
+
+func TestRestoreAppState_RefusesNewerSchema(t *testing.T) {
+	configDir := t.TempDir()
+	legacy := `{"schema_version":999,"agent_id":"ccaid_future","jobs":{}}`
+	if err := os.WriteFile(filepath.Join(configDir, models.LegacyStateFileName), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy state: %v", err)
+	}
+
+	_, _, err := RestoreAppState(filepath.Join(configDir, models.StateFileName))
+	if !errors.Is(err, models.ErrStateSchemaTooNew) {
+		t.Fatalf("expected ErrStateSchemaTooNew, got %v", err)
+	}
+}
+
+func TestRestoreAppState_FallsBackToLastGoodBackup(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), models.StateFileName)
+
+	appState, agentID, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := appState.UpdateJobData("job-1", models.JobData{JobID: "job-1", BranchName: "eksecd/good", Status: models.JobStatusCompleted, UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	appState.Close()
+
+	// The next start loads the good state and backs it up
+	appState, _, err = RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	appState.Close()
+	if _, err := os.Stat(models.StateBackupPath(statePath, 1)); err != nil {
+		t.Fatalf("expected a state backup: %v", err)
+	}
+
+	if err := os.WriteFile(statePath, []byte("definitely not a bbolt database"), 0600); err != nil {
+		t.Fatalf("failed to corrupt state: %v", err)
+	}
+
+	restored, restoredAgentID, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer restored.Close()
+	if restoredAgentID != agentID {
+		t.Errorf("expected agent ID %s from backup, got %s", agentID, restoredAgentID)
+	}
+	if jobData, exists := restored.GetJobData("job-1"); !exists || jobData.BranchName != "eksecd/good" {
+		t.Errorf("expected job from backup, got %+v", jobData)
+	}
+	quarantined, _ := filepath.Glob(statePath + ".corrupt-*")
+	if len(quarantined) != 1 {
+		t.Errorf("expected the corrupt store to be moved aside, got %v", quarantined)
+	}
+}
+
+func TestRestoreAppState_StartsFreshWithoutBackup(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), models.StateFileName)
+	if err := os.WriteFile(statePath, []byte("definitely not a bbolt database"), 0600); err != nil {
+		t.Fatalf("failed to corrupt state: %v", err)
+	}
+
+	appState, agentID, err := RestoreAppState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer appState.Close()
+	if agentID == "" {
+		t.Errorf("expected a new agent ID")
+	}
+	if jobs := appState.GetAllJobs(); len(jobs) != 0 {
+		t.Errorf("expected empty state, got %+v", jobs)
+	}
+}
+
+func TestBackupState_KeepsNewestBackups(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), models.StateFileName)
+	appState := models.NewAppState("ccaid_test", statePath)
+	for i := 0; i < models.StateBackupCount+2; i++ {
+		jobID := fmt.Sprintf("job-%d", i)
+		if err := appState.UpdateJobData(jobID, models.JobData{JobID: jobID, Status: models.JobStatusCompleted}); err != nil {
+			t.Fatalf("failed to update job: %v", err)
+		}
+		appState.Close()
+		if err := models.BackupState(statePath); err != nil {
+			t.Fatalf("failed to back up state: %v", err)
+		}
+	}
+
+	// The newest backup has every job, the oldest kept one is StateBackupCount-1 writes behind
+	newest, err := models.LoadState(models.StateBackupPath(statePath, 1))
+	if err != nil {
+		t.Fatalf("failed to load newest backup: %v", err)
+	}
+	if len(newest.Jobs) != models.StateBackupCount+2 {
+		t.Errorf("expected %d jobs in newest backup, got %d", models.StateBackupCount+2, len(newest.Jobs))
+	}
+	oldest, err := models.LoadState(models.StateBackupPath(statePath, models.StateBackupCount))
+	if err != nil {
+		t.Fatalf("failed to load oldest backup: %v", err)
+	}
+	if len(oldest.Jobs) != 3 {
+		t.Errorf("expected 3 jobs in oldest backup, got %d", len(oldest.Jobs))
+	}
+	if _, err := os.Stat(models.StateBackupPath(statePath, models.StateBackupCount+1)); !os.IsNotExist(err) {
+		t.Errorf("expected no more than %d backups", models.StateBackupCount)
+	}
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"eksecd/core"
+	"eksecd/core/log"
+	"eksecd/models"
+)
+
+const (
+	// slackMaxMessageLength is the per-message length we target for Slack replies.
+	// Slack truncates section text around 3000 chars and messages around 4000 chars.
+	slackMaxMessageLength = 3900
+
+	// discordMaxMessageLength is the per-message length we target for Discord replies.
+	// Discord rejects messages longer than 2000 chars.
+	discordMaxMessageLength = 1900
+
+	// defaultReplyUploadThreshold is the reply length above which the full reply is
+	// uploaded as a markdown attachment instead of being split into many messages.
+	// Can be overridden via the REPLY_UPLOAD_THRESHOLD environment variable.
+	defaultReplyUploadThreshold = 12000
+
+	// replyAttachmentFilename is the filename used for uploaded oversized replies
+	replyAttachmentFilename = "response.md"
+)
+
+// maxMessageLengthForLink returns the per-message length limit for the platform
+// the message link points to. Discord links get Discord's limit, everything else Slack's.
+func maxMessageLengthForLink(link string) int {
+	if strings.Contains(link, "discord.com") || strings.Contains(link, "discord.gg") {
+		return discordMaxMessageLength
+	}
+	return slackMaxMessageLength
+}
+
+// splitReply splits a reply into ordered parts no longer than maxLen characters.
+// Splits happen at paragraph boundaries where possible. Fenced code blocks are
+// kept intact when they fit; oversized code blocks are split by line and each
+// chunk is re-fenced so that every part renders correctly on its own.
+func splitReply(message string, maxLen int) []string {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(message) <= maxLen {
+		return []string{message}
+	}
+
+	var parts []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	for _, block := range splitIntoBlocks(message) {
+		for _, piece := range splitOversizedBlock(block, maxLen) {
+			if current.Len() == 0 {
+				current.WriteString(piece)
+				continue
+			}
+			if utf8.RuneCountInString(current.String())+2+utf8.RuneCountInString(piece) > maxLen {
+				flush()
+				current.WriteString(piece)
+				continue
+			}
+			current.WriteString("\n\n")
+			current.WriteString(piece)
+		}
+	}
+	flush()
+
+	return parts
+}
+
+// splitIntoBlocks breaks a markdown message into paragraphs and fenced code blocks.
+// Blank lines inside a code fence never start a new block.
+func splitIntoBlocks(message string) []string {
+	var blocks []string
+	var current []string
+	inFence := false
+
+	flush := func() {
+		if len(current) > 0 {
+			block := strings.Trim(strings.Join(current, "\n"), "\n")
+			if strings.TrimSpace(block) != "" {
+				blocks = append(blocks, block)
+			}
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(message, "\n") {
+		isFenceLine := strings.HasPrefix(strings.TrimSpace(line), "```")
+		switch {
+		case isFenceLine && !inFence:
+			flush()
+			inFence = true
+			current = append(current, line)
+		case isFenceLine && inFence:
+			current = append(current, line)
+			inFence = false
+			flush()
+		case inFence:
+			current = append(current, line)
+		case strings.TrimSpace(line) == "":
+			flush()
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// splitOversizedBlock splits a single block that exceeds maxLen into smaller pieces.
+// Code blocks are split by line and re-fenced; prose is split by line, then by word,
+// and as a last resort by hard cut.
+func splitOversizedBlock(block string, maxLen int) []string {
+	if utf8.RuneCountInString(block) <= maxLen {
+		return []string{block}
+	}
+
+	lines := strings.Split(block, "\n")
+	firstLine := strings.TrimSpace(lines[0])
+	if strings.HasPrefix(firstLine, "```") {
+		body := lines[1:]
+		if len(body) > 0 && strings.HasPrefix(strings.TrimSpace(body[len(body)-1]), "```") {
+			body = body[:len(body)-1]
+		}
+		openFence := firstLine
+		closeFence := "```"
+		// Reserve room for the opening and closing fences in every chunk
+		innerLen := maxLen - utf8.RuneCountInString(openFence) - utf8.RuneCountInString(closeFence) - 2
+		if innerLen < 1 {
+			innerLen = 1
+		}
+		var pieces []string
+		for _, chunk := range packLines(body, innerLen) {
+			pieces = append(pieces, openFence+"\n"+chunk+"\n"+closeFence)
+		}
+		return pieces
+	}
+
+	return packLines(lines, maxLen)
+}
+
+// packLines greedily packs lines into chunks no longer than maxLen characters.
+// Lines that are themselves too long are broken at word boundaries or hard cut.
+func packLines(lines []string, maxLen int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, line := range lines {
+		for _, segment := range splitLongLine(line, maxLen) {
+			if current.Len() == 0 {
+				current.WriteString(segment)
+				continue
+			}
+			if utf8.RuneCountInString(current.String())+1+utf8.RuneCountInString(segment) > maxLen {
+				flush()
+				current.WriteString(segment)
+				continue
+			}
+			current.WriteString("\n")
+			current.WriteString(segment)
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitLongLine breaks a single line into segments no longer than maxLen characters,
+// preferring to break at spaces.
+func splitLongLine(line string, maxLen int) []string {
+	runes := []rune(line)
+	if len(runes) <= maxLen {
+		return []string{line}
+	}
+
+	var segments []string
+	for len(runes) > maxLen {
+		cut := maxLen
+		for i := maxLen; i > maxLen/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		segments = append(segments, strings.TrimRight(string(runes[:cut]), " "))
+		runes = runes[cut:]
+		for len(runes) > 0 && runes[0] == ' ' {
+			runes = runes[1:]
+		}
+	}
+	if len(runes) > 0 {
+		segments = append(segments, string(runes))
+	}
+
+	return segments
+}
+
+// buildUploadSummary builds the short message sent alongside an uploaded reply.
+// It includes the opening paragraph of the reply (truncated to fit) and a pointer
+// to the attachment.
+func buildUploadSummary(message string, maxLen int) string {
+	note := fmt.Sprintf(
+		"_The full response (%d characters) is attached as `%s`._",
+		utf8.RuneCountInString(message), replyAttachmentFilename,
+	)
+
+	blocks := splitIntoBlocks(strings.TrimSpace(message))
+	if len(blocks) == 0 {
+		return note
+	}
+
+	intro := blocks[0]
+	if strings.HasPrefix(strings.TrimSpace(intro), "```") {
+		return note
+	}
+
+	available := maxLen - utf8.RuneCountInString(note) - 2
+	if available <= 0 {
+		return note
+	}
+	if utf8.RuneCountInString(intro) > available {
+		runes := []rune(intro)
+		intro = strings.TrimSpace(string(runes[:available-1])) + "…"
+	}
+
+	return intro + "\n\n" + note
+}
+
+// getReplyUploadThreshold returns the reply length above which replies are uploaded
+// as attachments. Zero or negative values disable uploading.
+func (mh *MessageHandler) getReplyUploadThreshold() int {
+	if mh.envManager == nil {
+		return defaultReplyUploadThreshold
+	}
+	envVal := mh.envManager.Get("REPLY_UPLOAD_THRESHOLD")
+	if envVal == "" {
+		return defaultReplyUploadThreshold
+	}
+	val, err := strconv.Atoi(envVal)
+	if err != nil {
+		log.Warn("⚠️ Invalid REPLY_UPLOAD_THRESHOLD value %q, using default %d", envVal, defaultReplyUploadThreshold)
+		return defaultReplyUploadThreshold
+	}
+	return val
+}
+
+// sendAssistantReply queues the agent's reply for the given job, adapting it to the
+// platform the conversation lives on. Short replies go out as a single message. Long
+// replies are split into ordered parts, and replies above the upload threshold are
+// uploaded as a markdown attachment with a short summary message instead.
+func (mh *MessageHandler) sendAssistantReply(jobID, message, messageLink, processedMessageID string) {
+	maxLen := maxMessageLengthForLink(messageLink)
+	threshold := mh.getReplyUploadThreshold()
+
+	if threshold > 0 && utf8.RuneCountInString(message) > threshold && mh.agentsApiClient != nil {
+		uploadResp, err := mh.agentsApiClient.UploadAttachment(
+			replyAttachmentFilename, "text/markdown", []byte(message),
+		)
+		if err == nil {
+			mh.queueAssistantMessage(models.AssistantMessagePayload{
+				JobID:              jobID,
+				Message:            buildUploadSummary(message, maxLen),
+				ProcessedMessageID: processedMessageID,
+				Attachments:        []models.MessageAttachment{{AttachmentID: uploadResp.ID}},
+			})
+			log.Info("📎 Uploaded oversized reply (%d chars) as attachment %s", len(message), uploadResp.ID)
+			return
+		}
+		log.Warn("⚠️ Failed to upload oversized reply, falling back to split messages: %v", err)
+	}
+
+	parts := splitReply(message, maxLen)
+	if len(parts) <= 1 {
+		mh.queueAssistantMessage(models.AssistantMessagePayload{
+			JobID:              jobID,
+			Message:            message,
+			ProcessedMessageID: processedMessageID,
+		})
+		return
+	}
+
+	log.Info("✂️ Splitting reply (%d chars) into %d parts", len(message), len(parts))
+	for i, part := range parts {
+		mh.queueAssistantMessage(models.AssistantMessagePayload{
+			JobID:              jobID,
+			Message:            part,
+			ProcessedMessageID: processedMessageID,
+			PartIndex:          i + 1,
+			PartCount:          len(parts),
+		})
+	}
+}
+
+// queueAssistantMessage wraps the payload in a BaseMessage and queues it for sending
+func (mh *MessageHandler) queueAssistantMessage(payload models.AssistantMessagePayload) {
+	assistantMsg := models.BaseMessage{
+		ID:      core.NewID("msg"),
+		Type:    models.MessageTypeAssistantMessage,
+		Payload: payload,
+	}
+	mh.messageSender.QueueMessage("cc_message", assistantMsg)
+	log.Info("🤖 Queued assistant response (message ID: %s)", assistantMsg.ID)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMaxMessageLengthForLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     string
+		expected int
+	}{
+		{
+			name:     "Slack link",
+			link:     "https://workspace.slack.com/archives/C123/p456",
+			expected: slackMaxMessageLength,
+		},
+		{
+			name:     "Discord link",
+			link:     "https://discord.com/channels/1/2/3",
+			expected: discordMaxMessageLength,
+		},
+		{
+			name:     "Empty link defaults to Slack",
+			link:     "",
+			expected: slackMaxMessageLength,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := maxMessageLengthForLink(tt.link)
+			if result != tt.expected {
+				t.Errorf("maxMessageLengthForLink(%q) = %d, want %d", tt.link, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitReply(t *testing.T) {
+	paragraph := func(ch string, n int) string {
+		return strings.Repeat(ch, n)
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		maxLen   int
+		expected []string
+	}{
+		{
+			name:     "Empty message",
+			message:  "",
+			maxLen:   100,
+			expected: nil,
+		},
+		{
+			name:     "Short message is not split",
+			message:  "hello world",
+			maxLen:   100,
+			expected: []string{"hello world"},
+		},
+		{
+			name:     "Splits at paragraph boundaries",
+			message:  paragraph("a", 40) + "\n\n" + paragraph("b", 40) + "\n\n" + paragraph("c", 40),
+			maxLen:   90,
+			expected: []string{paragraph("a", 40) + "\n\n" + paragraph("b", 40), paragraph("c", 40)},
+		},
+		{
+			name:    "Keeps code block intact",
+			message: "intro text\n\n```go\nfunc a() {}\n\nfunc b() {}\n```\n\noutro",
+			maxLen:  40,
+			expected: []string{
+				"intro text",
+				"```go\nfunc a() {}\n\nfunc b() {}\n```",
+				"outro",
+			},
+		},
+		{
+			name:    "Re-fences oversized code block",
+			message: "```\nline one\nline two\nline three\n```",
+			maxLen:  24,
+			expected: []string{
+				"```\nline one\n```",
+				"```\nline two\n```",
+				"```\nline three\n```",
+			},
+		},
+		{
+			name:     "Breaks long line at spaces",
+			message:  "one two three four five six",
+			maxLen:   10,
+			expected: []string{"one two", "three four", "five six"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := splitReply(tt.message, tt.maxLen)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("splitReply() returned %d parts, want %d: %q", len(result), len(tt.expected), result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("part %d = %q, want %q", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitReplyRespectsMaxLength(t *testing.T) {
+	var builder strings.Builder
+	for i := 0; i < 50; i++ {
+		builder.WriteString(strings.Repeat("word ", 30))
+		builder.WriteString("\n\n```\n")
+		builder.WriteString(strings.Repeat("code line\n", 10))
+		builder.WriteString("```\n\n")
+	}
+	message := builder.String()
+
+	maxLen := 200
+	parts := splitReply(message, maxLen)
+	if len(parts) < 2 {
+		t.Fatalf("expected message to be split, got %d parts", len(parts))
+	}
+	for i, part := range parts {
+		if utf8.RuneCountInString(part) > maxLen {
+			t.Errorf("part %d has length %d, exceeds max %d", i, utf8.RuneCountInString(part), maxLen)
+		}
+		if strings.Count(part, "```")%2 != 0 {
+			t.Errorf("part %d has unbalanced code fences: %q", i, part)
+		}
+	}
+}
+
+func TestBuildUploadSummary(t *testing.T) {
+	t.Run("Includes intro paragraph and attachment note", func(t *testing.T) {
+		summary := buildUploadSummary("Here is the summary.\n\nMore details follow.", 200)
+		if !strings.HasPrefix(summary, "Here is the summary.") {
+			t.Errorf("expected summary to start with intro paragraph, got %q", summary)
+		}
+		if !strings.Contains(summary, replyAttachmentFilename) {
+			t.Errorf("expected summary to mention %s, got %q", replyAttachmentFilename, summary)
+		}
+	})
+
+	t.Run("Truncates long intro to fit", func(t *testing.T) {
+		summary := buildUploadSummary(strings.Repeat("x", 1000), 150)
+		if utf8.RuneCountInString(summary) > 150 {
+			t.Errorf("summary length %d exceeds max 150", utf8.RuneCountInString(summary))
+		}
+	})
+
+	t.Run("Skips code block intro", func(t *testing.T) {
+		summary := buildUploadSummary("```\ncode\n```\n\ntext", 200)
+		if strings.Contains(summary, "code") {
+			t.Errorf("expected code block intro to be skipped, got %q", summary)
+		}
+	})
+}
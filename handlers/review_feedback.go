@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/core"
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+)
+
+// reviewFeedbackEnvVar can be set to false to stop feeding PR review comments to jobs
+const reviewFeedbackEnvVar = "PR_REVIEW_FEEDBACK"
+
+// reviewDiffContextLines is how many lines of a thread's diff hunk are quoted in the prompt
+const reviewDiffContextLines = 8
+
+// MessageDispatcher queues a message for per-job sequential processing
+type MessageDispatcher interface {
+	Dispatch(msg models.BaseMessage)
+}
+
+// SetMessageDispatcher sets the dispatcher used to start turns from PR review comments.
+// This must be called after both the MessageHandler and JobDispatcher are created.
+func (mh *MessageHandler) SetMessageDispatcher(dispatcher MessageDispatcher) {
+	mh.messageDispatcher = dispatcher
+}
+
+// reviewFeedbackEnabled reports whether new PR review comments start a turn on the job
+func (mh *MessageHandler) reviewFeedbackEnabled() bool {
+	if mh.envManager == nil {
+		return true
+	}
+	envVal := mh.envManager.Get(reviewFeedbackEnvVar)
+	if envVal == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(envVal)
+	if err != nil {
+		log.Warn("⚠️ Invalid %s value %q, review feedback enabled", reviewFeedbackEnvVar, envVal)
+		return true
+	}
+	return enabled
+}
+
+// reviewFeedback is the PR review activity not yet seen by the agent
+type reviewFeedback struct {
+	Threads []clients.ReviewThread // Unresolved threads with new comments, with all their comments
+	Reviews []clients.Review       // New reviews with a summary comment
+	Newest  time.Time              // Creation time of the newest new comment or review
+}
+
+// collectReviewFeedback picks the review comments made after since by anyone but the viewer
+func collectReviewFeedback(reviews *clients.PullRequestReviews, since time.Time) reviewFeedback {
+	var feedback reviewFeedback
+	isNew := func(author string, at time.Time) bool {
+		return author != reviews.Viewer && at.After(since)
+	}
+	markSeen := func(at time.Time) {
+		if at.After(feedback.Newest) {
+			feedback.Newest = at
+		}
+	}
+
+	for _, thread := range reviews.Threads {
+		if thread.IsResolved {
+			continue
+		}
+		hasNew := false
+		for _, comment := range thread.Comments {
+			if isNew(comment.Author, comment.CreatedAt) {
+				hasNew = true
+				markSeen(comment.CreatedAt)
+			}
+		}
+		if hasNew {
+			feedback.Threads = append(feedback.Threads, thread)
+		}
+	}
+
+	for _, review := range reviews.Reviews {
+		if strings.TrimSpace(review.Body) == "" || review.State == "PENDING" {
+			continue
+		}
+		if isNew(review.Author, review.SubmittedAt) {
+			feedback.Reviews = append(feedback.Reviews, review)
+			markSeen(review.SubmittedAt)
+		}
+	}
+	return feedback
+}
+
+// feedReviewComments starts a turn on the job for PR review comments made since the last
+// check. Comments are marked as seen before the turn is queued so they are fed only once.
+func (mh *MessageHandler) feedReviewComments(jobID string, jobData models.JobData) error {
+	if mh.messageDispatcher == nil || !mh.reviewFeedbackEnabled() {
+		return nil
+	}
+	if jobData.PullRequestID == "" || jobData.Status == models.JobStatusInProgress || jobData.Mode == models.AgentModeAsk {
+		return nil
+	}
+
+	reviews, err := mh.gitUseCase.GetPRReviews(jobData.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if reviews == nil {
+		return nil
+	}
+
+	feedback := collectReviewFeedback(reviews, jobData.ReviewFeedbackSince)
+	if len(feedback.Threads) == 0 && len(feedback.Reviews) == 0 {
+		log.Info("ℹ️ No new review comments on PR %s for job %s", jobData.PullRequestID, jobID)
+		return nil
+	}
+
+	if err := mh.appState.SetJobReviewFeedbackSince(jobID, feedback.Newest); err != nil {
+		return fmt.Errorf("failed to record review feedback: %w", err)
+	}
+
+	threadIDs := make([]string, len(feedback.Threads))
+	for i, thread := range feedback.Threads {
+		threadIDs[i] = thread.ID
+	}
+	log.Info("💬 Feeding %d review threads and %d reviews on PR %s to job %s",
+		len(feedback.Threads), len(feedback.Reviews), jobData.PullRequestID, jobID)
+	mh.messageDispatcher.Dispatch(models.BaseMessage{
+		ID:   core.NewID("msg"),
+		Type: models.MessageTypeUserMessage,
+		Payload: models.UserMessagePayload{
+			JobID:              jobID,
+			Message:            reviewFeedbackPrompt(feedback),
+			ProcessedMessageID: core.NewID("msg"),
+			MessageLink:        jobData.MessageLink,
+			Author:             "PR review",
+			ReviewThreadIDs:    threadIDs,
+		},
+	})
+	return nil
+}
+
+// reviewFeedbackPrompt quotes the review comments with their file and line context
+func reviewFeedbackPrompt(feedback reviewFeedback) string {
+	var sb strings.Builder
+	sb.WriteString("New review comments were left on the pull request. Address them: make the requested changes, or explain why a change should not be made.\n")
+
+	for _, review := range feedback.Reviews {
+		state := strings.ToLower(strings.ReplaceAll(review.State, "_", " "))
+		fmt.Fprintf(&sb, "\nReview by @%s (%s):\n%s\n", review.Author, state, quoteLines(review.Body))
+	}
+
+	for _, thread := range feedback.Threads {
+		location := thread.Path
+		if thread.Line > 0 {
+			location = fmt.Sprintf("%s:%d", thread.Path, thread.Line)
+		}
+		outdated := ""
+		if thread.IsOutdated {
+			outdated = ", outdated"
+		}
+		fmt.Fprintf(&sb, "\nThread %s on %s%s:\n", thread.ID, location, outdated)
+		if len(thread.Comments) > 0 && thread.Comments[0].DiffHunk != "" {
+			fmt.Fprintf(&sb, "```\n%s\n```\n", lastLines(thread.Comments[0].DiffHunk, reviewDiffContextLines))
+		}
+		for _, comment := range thread.Comments {
+			fmt.Fprintf(&sb, "@%s:\n%s\n", comment.Author, quoteLines(comment.Body))
+		}
+	}
+
+	if len(feedback.Threads) > 0 {
+		sb.WriteString(`
+When you are done, end your reply with a block that answers each thread you addressed. Each reply is posted on its thread after your changes are pushed. Set resolve="true" only when your changes fully address the thread:
+<review_replies>
+<reply thread="THREAD_ID" resolve="true">One or two sentences on what you changed or why not</reply>
+</review_replies>`)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// reviewReply is the agent's answer to a review thread
+type reviewReply struct {
+	ThreadID string
+	Resolve  bool
+	Body     string
+}
+
+var (
+	reviewRepliesBlockPattern = regexp.MustCompile(`(?s)\s*<review_replies>(.*?)</review_replies>\s*`)
+	reviewReplyPattern        = regexp.MustCompile(`(?s)<reply\s+thread="([^"]+)"(?:\s+resolve="(true|false)")?\s*>(.*?)</reply>`)
+)
+
+// parseReviewReplies extracts the review_replies block from the agent's output. Returns the
+// output without the block and the replies it contained.
+func parseReviewReplies(output string) (string, []reviewReply) {
+	match := reviewRepliesBlockPattern.FindStringSubmatchIndex(output)
+	if match == nil {
+		return output, nil
+	}
+
+	var replies []reviewReply
+	for _, reply := range reviewReplyPattern.FindAllStringSubmatch(output[match[2]:match[3]], -1) {
+		replies = append(replies, reviewReply{
+			ThreadID: reply[1],
+			Resolve:  reply[2] == "true",
+			Body:     strings.TrimSpace(reply[3]),
+		})
+	}
+
+	cleaned := output[:match[0]]
+	if rest := output[match[1]:]; rest != "" {
+		cleaned += "\n\n" + rest
+	}
+	return strings.TrimSpace(cleaned), replies
+}
+
+// answerReviewThreads posts the agent's replies on the review threads it was given, resolves
+// the threads it addressed once changes were pushed, and summarizes both in the chat thread
+func (mh *MessageHandler) answerReviewThreads(
+	payload models.UserMessagePayload,
+	prID string,
+	replies []reviewReply,
+	commitResult *usecases.AutoCommitResult,
+) {
+	pushed := commitResult != nil && commitResult.CommitHash != ""
+
+	// Look threads up again for their location and to skip ones resolved in the meantime
+	threads := make(map[string]clients.ReviewThread)
+	if reviews, err := mh.gitUseCase.GetPRReviews(prID); err != nil {
+		log.Warn("⚠️ Failed to refresh review threads for PR %s: %v", prID, err)
+	} else if reviews != nil {
+		for _, thread := range reviews.Threads {
+			threads[thread.ID] = thread
+		}
+	}
+
+	var answered, failed []string
+	for _, reply := range replies {
+		if !slices.Contains(payload.ReviewThreadIDs, reply.ThreadID) {
+			log.Warn("⚠️ Ignoring reply to review thread %s, which was not part of the turn", reply.ThreadID)
+			continue
+		}
+		thread, known := threads[reply.ThreadID]
+		if known && thread.IsResolved {
+			continue
+		}
+		location := reply.ThreadID
+		if known {
+			location = fmt.Sprintf("`%s:%d`", thread.Path, thread.Line)
+		}
+
+		resolve := reply.Resolve && pushed
+		if err := mh.gitUseCase.AnswerPRReviewThread(reply.ThreadID, reply.Body, resolve); err != nil {
+			log.Error("❌ Failed to answer review thread %s: %v", reply.ThreadID, err)
+			failed = append(failed, location)
+			continue
+		}
+		if resolve {
+			location += " (resolved)"
+		}
+		answered = append(answered, location)
+	}
+
+	message := reviewAnswersSummary(len(payload.ReviewThreadIDs), answered, failed)
+	if err := mh.sendSystemMessage(message, payload.ProcessedMessageID, payload.JobID); err != nil {
+		log.Error("❌ Failed to send review answers summary: %v", err)
+	}
+}
+
+// reviewAnswersSummary describes which review threads were answered for the chat thread
+func reviewAnswersSummary(threadCount int, answered, failed []string) string {
+	var sb strings.Builder
+	if len(answered) == 0 {
+		fmt.Fprintf(&sb, "No replies were posted on the %d PR review threads.", threadCount)
+	} else {
+		fmt.Fprintf(&sb, "Replied to %d of %d PR review threads:", len(answered), threadCount)
+		for _, location := range answered {
+			fmt.Fprintf(&sb, "\n- %s", location)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&sb, "\nFailed to reply to: %s", strings.Join(failed, ", "))
+	}
+	return sb.String()
+}
+
+// quoteLines prefixes every line of text with "> "
+func quoteLines(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lastLines returns the last n lines of text
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"eksecd/clients"
+)
+
+func reviewAt(hour int) time.Time {
+	return time.Date(2026, 10, 1, hour, 0, 0, 0, time.UTC)
+}
+
+func testPullRequestReviews() *clients.PullRequestReviews {
+	return &clients.PullRequestReviews{
+		Viewer: "eksecd-bot",
+		Threads: []clients.ReviewThread{
+			{
+				ID: "PRRT_old", Path: "old.go", Line: 3,
+				Comments: []clients.ReviewComment{{Author: "alice", Body: "Seen already", CreatedAt: reviewAt(8)}},
+			},
+			{
+				ID: "PRRT_new", Path: "main.go", Line: 42,
+				Comments: []clients.ReviewComment{
+					{Author: "alice", Body: "Handle the error", DiffHunk: "@@ -1,3 +1,4 @@\n func main() {\n-\trun()\n+\t_ = run()", CreatedAt: reviewAt(8)},
+					{Author: "eksecd-bot", Body: "Done", CreatedAt: reviewAt(9)},
+					{Author: "alice", Body: "Not quite,\nreturn it instead", CreatedAt: reviewAt(11)},
+				},
+			},
+			{
+				ID: "PRRT_resolved", Path: "main.go", Line: 10, IsResolved: true,
+				Comments: []clients.ReviewComment{{Author: "bob", Body: "Rename this", CreatedAt: reviewAt(12)}},
+			},
+			{
+				ID: "PRRT_own", Path: "main.go", Line: 20,
+				Comments: []clients.ReviewComment{{Author: "eksecd-bot", Body: "Note to self", CreatedAt: reviewAt(12)}},
+			},
+		},
+		Reviews: []clients.Review{
+			{Author: "bob", State: "CHANGES_REQUESTED", Body: "Needs tests", SubmittedAt: reviewAt(13)},
+			{Author: "bob", State: "APPROVED", Body: "", SubmittedAt: reviewAt(14)},
+			{Author: "carol", State: "COMMENTED", Body: "Old remark", SubmittedAt: reviewAt(7)},
+		},
+	}
+}
+
+func TestCollectReviewFeedback(t *testing.T) {
+	feedback := collectReviewFeedback(testPullRequestReviews(), reviewAt(10))
+
+	if len(feedback.Threads) != 1 || feedback.Threads[0].ID != "PRRT_new" {
+		t.Errorf("expected only the thread with a new comment from someone else, got %+v", feedback.Threads)
+	}
+	if len(feedback.Threads[0].Comments) != 3 {
+		t.Errorf("expected the whole conversation of the thread, got %d comments", len(feedback.Threads[0].Comments))
+	}
+	if len(feedback.Reviews) != 1 || feedback.Reviews[0].Body != "Needs tests" {
+		t.Errorf("expected only the new review with a body, got %+v", feedback.Reviews)
+	}
+	if !feedback.Newest.Equal(reviewAt(13)) {
+		t.Errorf("expected newest at 13:00, got %v", feedback.Newest)
+	}
+
+	if later := collectReviewFeedback(testPullRequestReviews(), feedback.Newest); len(later.Threads)+len(later.Reviews) != 0 {
+		t.Errorf("expected nothing new after the newest comment, got %+v", later)
+	}
+}
+
+func TestReviewFeedbackPrompt(t *testing.T) {
+	prompt := reviewFeedbackPrompt(collectReviewFeedback(testPullRequestReviews(), reviewAt(10)))
+
+	for _, expected := range []string{
+		"Review by @bob (changes requested):\n> Needs tests",
+		"Thread PRRT_new on main.go:42:\n```\n@@ -1,3 +1,4 @@\n func main() {\n-\trun()\n+\t_ = run()\n```",
+		"@alice:\n> Not quite,\n> return it instead",
+		"<review_replies>",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", expected, prompt)
+		}
+	}
+	if strings.Contains(prompt, "PRRT_resolved") || strings.Contains(prompt, "Old remark") {
+		t.Errorf("expected prompt without resolved threads and old reviews, got:\n%s", prompt)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("a\nb\nc\nd\n", 2); got != "c\nd" {
+		t.Errorf("expected last two lines, got %q", got)
+	}
+	if got := lastLines("a\nb", 5); got != "a\nb" {
+		t.Errorf("expected all lines, got %q", got)
+	}
+}
+
+func TestParseReviewReplies(t *testing.T) {
+	output := `Returned the error from run and added a test.
+
+<review_replies>
+<reply thread="PRRT_new" resolve="true">Now returning the error.</reply>
+<reply thread="PRRT_other">Kept as is,
+the caller logs it.</reply>
+</review_replies>`
+
+	cleaned, replies := parseReviewReplies(output)
+	if cleaned != "Returned the error from run and added a test." {
+		t.Errorf("expected the block removed, got %q", cleaned)
+	}
+	if len(replies) != 2 {
+		t.Fatalf("expected 2 replies, got %+v", replies)
+	}
+	if replies[0] != (reviewReply{ThreadID: "PRRT_new", Resolve: true, Body: "Now returning the error."}) {
+		t.Errorf("unexpected first reply %+v", replies[0])
+	}
+	if replies[1].ThreadID != "PRRT_other" || replies[1].Resolve || replies[1].Body != "Kept as is,\nthe caller logs it." {
+		t.Errorf("unexpected second reply %+v", replies[1])
+	}
+
+	if cleaned, replies := parseReviewReplies("No block here"); cleaned != "No block here" || replies != nil {
+		t.Errorf("expected output unchanged without a block, got %q %+v", cleaned, replies)
+	}
+}
+
+func TestReviewAnswersSummary(t *testing.T) {
+	summary := reviewAnswersSummary(3, []string{"`main.go:42` (resolved)", "`util.go:7`"}, []string{"`api.go:1`"})
+	expected := "Replied to 2 of 3 PR review threads:\n- `main.go:42` (resolved)\n- `util.go:7`\nFailed to reply to: `api.go:1`"
+	if summary != expected {
+		t.Errorf("expected %q, got %q", expected, summary)
+	}
+
+	if summary := reviewAnswersSummary(2, nil, nil); summary != "No replies were posted on the 2 PR review threads." {
+		t.Errorf("unexpected summary without replies %q", summary)
+	}
+}
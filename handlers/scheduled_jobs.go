@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"eksecd/core"
+	"eksecd/core/log"
+	"eksecd/models"
+	"eksecd/usecases"
+	"eksecd/utils"
+)
+
+// ScheduledJobConfig describes a recurring job that eksecd starts on its own
+type ScheduledJobConfig struct {
+	Name        string           `json:"name"`
+	Cron        string           `json:"cron"` // Five-field cron expression, in the agent's local time zone
+	Prompt      string           `json:"prompt"`
+	Mode        models.AgentMode `json:"mode,omitempty"`         // Defaults to "execute"
+	Model       string           `json:"model,omitempty"`        // Overrides the agent's configured model
+	MessageLink string           `json:"message_link,omitempty"` // Thread to report results to; results are logged when empty
+}
+
+// scheduledJobsFile is the format of the schedule config file
+type scheduledJobsFile struct {
+	Schedules []ScheduledJobConfig `json:"schedules"`
+}
+
+// cronJob is a validated schedule with its parsed cron expression
+type cronJob struct {
+	config   ScheduledJobConfig
+	schedule *utils.CronSchedule
+}
+
+// loadCronJobs reads and validates the schedule config file
+func loadCronJobs(path string, validateModel func(model string) error) ([]cronJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule config: %w", err)
+	}
+
+	var file scheduledJobsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule config: %w", err)
+	}
+
+	return parseScheduledJobs(file.Schedules, validateModel)
+}
+
+// parseScheduledJobs validates schedule configs and parses their cron expressions.
+// Model overrides are checked with validateModel when it is set.
+func parseScheduledJobs(configs []ScheduledJobConfig, validateModel func(model string) error) ([]cronJob, error) {
+	jobs := make([]cronJob, 0, len(configs))
+	names := make(map[string]bool)
+	for i, config := range configs {
+		if config.Name == "" {
+			return nil, fmt.Errorf("schedule %d has no name", i+1)
+		}
+		if names[config.Name] {
+			return nil, fmt.Errorf("schedule name %q is used more than once", config.Name)
+		}
+		names[config.Name] = true
+
+		if config.Prompt == "" {
+			return nil, fmt.Errorf("schedule %q has no prompt", config.Name)
+		}
+		switch config.Mode {
+		case "":
+			config.Mode = models.AgentModeExecute
+		case models.AgentModeExecute, models.AgentModeAsk:
+		default:
+			return nil, fmt.Errorf("schedule %q has invalid mode %q", config.Name, config.Mode)
+		}
+		if config.Model != "" && validateModel != nil {
+			if err := validateModel(config.Model); err != nil {
+				return nil, fmt.Errorf("schedule %q has invalid model: %w", config.Name, err)
+			}
+		}
+
+		schedule, err := utils.ParseCronExpression(config.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q has invalid cron expression: %w", config.Name, err)
+		}
+		jobs = append(jobs, cronJob{config: config, schedule: schedule})
+	}
+	return jobs, nil
+}
+
+// ScheduledJobRunner starts jobs from the schedule config file when their cron expression
+// matches. Runs go through the JobDispatcher like jobs started from chat. The config file
+// is re-read whenever it changes.
+type ScheduledJobRunner struct {
+	mutex         sync.Mutex
+	configPath    string
+	validateModel func(model string) error // Checks model overrides against the agent
+	dispatcher    *JobDispatcher
+	dispatch      func(msg models.BaseMessage)
+	appState      *models.AppState
+	jobs          []cronJob
+	modTime       time.Time
+	lastJobIDs    map[string]string // Schedule name → job ID of its latest run
+	lastRunAt     time.Time         // Minute of the latest RunDue call, so a minute never fires twice
+}
+
+// NewScheduledJobRunner creates a runner for the schedules in configPath. Schedules with
+// a model override that validateModel rejects make the whole config invalid.
+func NewScheduledJobRunner(configPath string, dispatcher *JobDispatcher, appState *models.AppState, validateModel func(model string) error) *ScheduledJobRunner {
+	return &ScheduledJobRunner{
+		configPath:    configPath,
+		validateModel: validateModel,
+		dispatcher:    dispatcher,
+		dispatch:      dispatcher.Dispatch,
+		appState:      appState,
+		lastJobIDs:    make(map[string]string),
+	}
+}
+
+// ConfigPath returns the schedule config file location
+func (r *ScheduledJobRunner) ConfigPath() string {
+	return r.configPath
+}
+
+// RunDue reloads the config if it changed and starts every schedule that fires in the
+// minute containing now. A schedule whose previous run is still in progress is skipped.
+func (r *ScheduledJobRunner) RunDue(now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	minute := now.Truncate(time.Minute)
+	if !minute.After(r.lastRunAt) {
+		return
+	}
+	r.lastRunAt = minute
+
+	r.reloadIfChangedLocked()
+
+	for _, job := range r.jobs {
+		if !job.schedule.Matches(minute) {
+			continue
+		}
+		if r.dispatcher.IsDraining() {
+			log.Info("🚰 Draining, skipping scheduled job %s", job.config.Name)
+			continue
+		}
+		if lastJobID, ok := r.lastJobIDs[job.config.Name]; ok {
+			if jobData, exists := r.appState.GetJobData(lastJobID); exists && jobData.Status == models.JobStatusInProgress {
+				log.Info("⏰ Previous run %s of scheduled job %s is still in progress, skipping", lastJobID, job.config.Name)
+				continue
+			}
+		}
+
+		jobID := core.NewID("job")
+		r.lastJobIDs[job.config.Name] = jobID
+		log.Info("⏰ Starting scheduled job %s as job %s", job.config.Name, jobID)
+		r.dispatch(models.BaseMessage{
+			ID:   core.NewID("msg"),
+			Type: models.MessageTypeStartConversation,
+			Payload: models.StartConversationPayload{
+				JobID:              jobID,
+				Message:            job.config.Prompt,
+				ProcessedMessageID: core.NewID("msg"),
+				MessageLink:        job.config.MessageLink,
+				Mode:               job.config.Mode,
+				Model:              job.config.Model,
+				ScheduleName:       job.config.Name,
+			},
+		})
+	}
+}
+
+// reloadIfChangedLocked re-reads the config file when its modification time changed.
+// An invalid config keeps the previous schedules. Must be called with mutex held.
+func (r *ScheduledJobRunner) reloadIfChangedLocked() {
+	info, err := os.Stat(r.configPath)
+	if os.IsNotExist(err) {
+		if len(r.jobs) > 0 {
+			log.Info("⏰ Schedule config %s removed, clearing schedules", r.configPath)
+		}
+		r.jobs = nil
+		r.modTime = time.Time{}
+		return
+	}
+	if err != nil {
+		log.Warn("⚠️ Failed to stat schedule config %s: %v", r.configPath, err)
+		return
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return
+	}
+
+	jobs, err := loadCronJobs(r.configPath, r.validateModel)
+	if err != nil {
+		log.Error("❌ Invalid schedule config %s, keeping previous schedules: %v", r.configPath, err)
+		return
+	}
+	r.jobs = jobs
+	r.modTime = info.ModTime()
+	log.Info("⏰ Loaded %d scheduled jobs from %s", len(r.jobs), r.configPath)
+}
+
+// sendScheduledJobReport reports the result of a scheduled job to the server, or logs it
+// when the schedule has no target thread
+func (mh *MessageHandler) sendScheduledJobReport(
+	payload models.StartConversationPayload,
+	output string,
+	commitResult *usecases.AutoCommitResult,
+	jobErr error,
+) {
+	report := models.ScheduledJobReportPayload{
+		ScheduleName: payload.ScheduleName,
+		JobID:        payload.JobID,
+		MessageLink:  payload.MessageLink,
+		Status:       models.JobStatusCompleted,
+		Output:       output,
+		FinishedAt:   time.Now(),
+	}
+	if jobErr != nil {
+		report.Status = models.JobStatusFailed
+		report.Error = jobErr.Error()
+	}
+	if commitResult != nil {
+		report.BranchName = commitResult.BranchName
+		report.CommitHash = commitResult.CommitHash
+		report.PullRequestLink = commitResult.PullRequestLink
+	}
+
+	if payload.MessageLink == "" {
+		if jobErr != nil {
+			log.Error("❌ Scheduled job %s (%s) failed: %v", payload.ScheduleName, payload.JobID, jobErr)
+			return
+		}
+		log.Info("⏰ Scheduled job %s (%s) completed (branch: %s, PR: %s):\n%s",
+			payload.ScheduleName, payload.JobID, report.BranchName, report.PullRequestLink, output)
+		return
+	}
+
+	mh.messageSender.QueueMessage("cc_message", models.BaseMessage{
+		ID:      core.NewID("msg"),
+		Type:    models.MessageTypeScheduledJobReport,
+		Payload: report,
+	})
+	log.Info("⏰ Queued report for scheduled job %s (%s): %s", payload.ScheduleName, payload.JobID, report.Status)
+}
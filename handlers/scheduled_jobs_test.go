@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gammazero/workerpool"
+
+	"eksecd/models"
+)
+
+func TestParseScheduledJobs(t *testing.T) {
+	valid := ScheduledJobConfig{Name: "bump-deps", Cron: "0 9 * * mon", Prompt: "Bump dependencies", Model: "sonnet-4"}
+	validateModel := func(model string) error {
+		if model != "sonnet-4" {
+			return errors.New("unsupported model")
+		}
+		return nil
+	}
+
+	jobs, err := parseScheduledJobs([]ScheduledJobConfig{valid}, validateModel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jobs[0].config.Mode != models.AgentModeExecute {
+		t.Errorf("expected mode to default to execute, got %q", jobs[0].config.Mode)
+	}
+
+	tests := []struct {
+		name    string
+		configs []ScheduledJobConfig
+		errPart string
+	}{
+		{"Missing name", []ScheduledJobConfig{{Cron: "* * * * *", Prompt: "p"}}, "has no name"},
+		{"Duplicate name", []ScheduledJobConfig{valid, valid}, "more than once"},
+		{"Missing prompt", []ScheduledJobConfig{{Name: "a", Cron: "* * * * *"}}, "has no prompt"},
+		{"Invalid mode", []ScheduledJobConfig{{Name: "a", Cron: "* * * * *", Prompt: "p", Mode: "yolo"}}, "invalid mode"},
+		{"Invalid cron", []ScheduledJobConfig{{Name: "a", Cron: "every monday", Prompt: "p"}}, "invalid cron"},
+		{"Invalid model", []ScheduledJobConfig{{Name: "a", Cron: "* * * * *", Prompt: "p", Model: "gpt-5"}}, "invalid model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseScheduledJobs(tt.configs, validateModel)
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+// newTestScheduledJobRunner writes config to a schedule file and returns a runner that
+// records dispatched messages instead of running them
+func newTestScheduledJobRunner(t *testing.T, config string) (*ScheduledJobRunner, *[]models.StartConversationPayload) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "schedules.json")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write schedule config: %v", err)
+	}
+
+	wp := workerpool.New(1)
+	t.Cleanup(wp.StopWait)
+	appState := createTestAppState(t)
+	runner := NewScheduledJobRunner(configPath, NewJobDispatcher(nil, wp, appState), appState, nil)
+
+	var dispatched []models.StartConversationPayload
+	runner.dispatch = func(msg models.BaseMessage) {
+		dispatched = append(dispatched, msg.Payload.(models.StartConversationPayload))
+	}
+	return runner, &dispatched
+}
+
+func TestScheduledJobRunner_RunDue(t *testing.T) {
+	runner, dispatched := newTestScheduledJobRunner(t, `{"schedules": [
+		{"name": "bump-deps", "cron": "0 9 * * 1", "prompt": "Bump dependencies", "model": "haiku", "message_link": "https://team.slack.com/archives/C1/p1"},
+		{"name": "summary", "cron": "0 8 * * *", "prompt": "Summarize merged PRs", "mode": "ask"}
+	]}`)
+
+	// Monday 2024-01-15 09:00
+	monday9am := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	runner.RunDue(monday9am.Add(-time.Minute))
+	if len(*dispatched) != 0 {
+		t.Fatalf("expected nothing to run at 08:59, got %+v", *dispatched)
+	}
+
+	runner.RunDue(monday9am.Add(10 * time.Second))
+	runner.RunDue(monday9am.Add(20 * time.Second))
+	if len(*dispatched) != 1 {
+		t.Fatalf("expected exactly one run at 09:00, got %+v", *dispatched)
+	}
+	payload := (*dispatched)[0]
+	if payload.ScheduleName != "bump-deps" || payload.Message != "Bump dependencies" || payload.Model != "haiku" ||
+		payload.Mode != models.AgentModeExecute || payload.MessageLink != "https://team.slack.com/archives/C1/p1" {
+		t.Errorf("unexpected scheduled job payload: %+v", payload)
+	}
+	if payload.JobID == "" || payload.ProcessedMessageID == "" {
+		t.Errorf("expected synthetic job and message IDs, got %+v", payload)
+	}
+}
+
+func TestScheduledJobRunner_SkipsWhilePreviousRunInProgress(t *testing.T) {
+	runner, dispatched := newTestScheduledJobRunner(t, `{"schedules": [
+		{"name": "every-minute", "cron": "* * * * *", "prompt": "Check things"}
+	]}`)
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	runner.RunDue(start)
+	firstJobID := (*dispatched)[0].JobID
+	if err := runner.appState.UpdateJobData(firstJobID, models.JobData{
+		JobID:  firstJobID,
+		Status: models.JobStatusInProgress,
+	}); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+
+	runner.RunDue(start.Add(time.Minute))
+	if len(*dispatched) != 1 {
+		t.Fatalf("expected run to be skipped while the previous one is in progress, got %d runs", len(*dispatched))
+	}
+
+	if err := runner.appState.UpdateJobData(firstJobID, models.JobData{
+		JobID:  firstJobID,
+		Status: models.JobStatusCompleted,
+	}); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	runner.RunDue(start.Add(2 * time.Minute))
+	if len(*dispatched) != 2 {
+		t.Errorf("expected a new run once the previous one completed, got %d runs", len(*dispatched))
+	}
+}
+
+func TestScheduledJobRunner_InvalidConfigKeepsPreviousSchedules(t *testing.T) {
+	runner, dispatched := newTestScheduledJobRunner(t, `{"schedules": [
+		{"name": "every-minute", "cron": "* * * * *", "prompt": "Check things"}
+	]}`)
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	runner.RunDue(start)
+
+	if err := os.WriteFile(runner.ConfigPath(), []byte(`{"schedules": [{"name": "broken"}]}`), 0644); err != nil {
+		t.Fatalf("failed to write schedule config: %v", err)
+	}
+	// Make sure the modification time changes even on coarse-grained filesystems
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(runner.ConfigPath(), future, future); err != nil {
+		t.Fatalf("failed to update schedule config mtime: %v", err)
+	}
+
+	runner.RunDue(start.Add(time.Minute))
+	if len(*dispatched) != 2 {
+		t.Errorf("expected previous schedule to keep running after an invalid edit, got %d runs", len(*dispatched))
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gammazero/workerpool"
+
+	"eksecd/core/log"
+)
+
+const (
+	// askModePriorityBoost is added to the priority of ask-mode jobs so quick questions
+	// run ahead of execute jobs with the same priority
+	askModePriorityBoost = 1
+
+	// defaultFairnessKey groups jobs that carry no requester, channel or link information
+	defaultFairnessKey = "default"
+)
+
+// JobSchedulingInfo describes how a job should be ordered against other waiting jobs
+type JobSchedulingInfo struct {
+	Priority    int    // Higher runs first, defaults to 0
+	FairnessKey string // Requester or channel ID used for round-robin fairness
+	AskMode     bool   // Ask-mode jobs get a priority boost
+}
+
+// effectivePriority returns the priority used for ordering, including the ask-mode boost
+func (i JobSchedulingInfo) effectivePriority() int {
+	if i.AskMode {
+		return i.Priority + askModePriorityBoost
+	}
+	return i.Priority
+}
+
+// scheduledJob is a job processor waiting for a free slot
+type scheduledJob struct {
+	jobID string
+	info  JobSchedulingInfo
+	seq   uint64
+	run   func()
+}
+
+// JobScheduler decides which waiting job gets the next free worker slot.
+// Jobs are ordered by effective priority, then round-robin across fairness keys
+// (requesters or channels), then FIFO. A job holds its slot for as long as its
+// processor runs, so messages within a job are still processed sequentially.
+type JobScheduler struct {
+	mutex      sync.Mutex
+	workerPool *workerpool.WorkerPool
+	maxRunning int
+	running    int
+	pending    []*scheduledJob
+	lastServed map[string]uint64 // FairnessKey → tick of the last job started for it
+	tick       uint64
+	seq        uint64
+}
+
+// NewJobScheduler creates a scheduler that runs at most maxRunning jobs at a time on the worker pool
+func NewJobScheduler(workerPool *workerpool.WorkerPool, maxRunning int) *JobScheduler {
+	if maxRunning < 1 {
+		maxRunning = 1
+	}
+	return &JobScheduler{
+		workerPool: workerPool,
+		maxRunning: maxRunning,
+		lastServed: make(map[string]uint64),
+	}
+}
+
+// Schedule queues a job processor. It starts immediately if a slot is free,
+// otherwise it waits until the scheduler picks it.
+func (s *JobScheduler) Schedule(jobID string, info JobSchedulingInfo, run func()) {
+	if info.FairnessKey == "" {
+		info.FairnessKey = defaultFairnessKey
+	}
+
+	s.mutex.Lock()
+	s.seq++
+	s.pending = append(s.pending, &scheduledJob{
+		jobID: jobID,
+		info:  info,
+		seq:   s.seq,
+		run:   run,
+	})
+	log.Info("🗓️ Scheduled job %s (priority: %d, key: %s, waiting: %d)",
+		jobID, info.effectivePriority(), info.FairnessKey, len(s.pending))
+	toStart := s.startReadyLocked()
+	s.mutex.Unlock()
+
+	s.submit(toStart)
+}
+
+// SetMaxRunning changes how many jobs may run at a time. Raising the limit starts waiting
+// jobs right away; lowering it lets running jobs finish and starts no new ones until the
+// running count drops below the new limit.
+func (s *JobScheduler) SetMaxRunning(maxRunning int) {
+	if maxRunning < 1 {
+		maxRunning = 1
+	}
+
+	s.mutex.Lock()
+	s.maxRunning = maxRunning
+	toStart := s.startReadyLocked()
+	s.mutex.Unlock()
+
+	s.submit(toStart)
+}
+
+// MaxRunning returns how many jobs may run at a time
+func (s *JobScheduler) MaxRunning() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.maxRunning
+}
+
+// PendingCount returns the number of jobs waiting for a slot
+func (s *JobScheduler) PendingCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.pending)
+}
+
+// release frees a slot after a job processor exits and starts the next waiting jobs
+func (s *JobScheduler) release() {
+	s.mutex.Lock()
+	s.running--
+	toStart := s.startReadyLocked()
+	s.mutex.Unlock()
+
+	s.submit(toStart)
+}
+
+// startReadyLocked picks jobs to fill free slots and marks them running.
+// Must be called with mutex held.
+func (s *JobScheduler) startReadyLocked() []*scheduledJob {
+	var toStart []*scheduledJob
+	for s.running < s.maxRunning && len(s.pending) > 0 {
+		index := s.pickNextLocked()
+		job := s.pending[index]
+		s.pending = append(s.pending[:index], s.pending[index+1:]...)
+
+		s.tick++
+		s.lastServed[job.info.FairnessKey] = s.tick
+		s.running++
+		toStart = append(toStart, job)
+	}
+	return toStart
+}
+
+// pickNextLocked returns the index of the pending job that should run next.
+// Must be called with mutex held.
+func (s *JobScheduler) pickNextLocked() int {
+	best := 0
+	for i := 1; i < len(s.pending); i++ {
+		if s.runsBefore(s.pending[i], s.pending[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// runsBefore reports whether job a should run before job b
+func (s *JobScheduler) runsBefore(a, b *scheduledJob) bool {
+	if a.info.effectivePriority() != b.info.effectivePriority() {
+		return a.info.effectivePriority() > b.info.effectivePriority()
+	}
+	// Round-robin: prefer the key that was served least recently
+	aServed, bServed := s.lastServed[a.info.FairnessKey], s.lastServed[b.info.FairnessKey]
+	if aServed != bServed {
+		return aServed < bServed
+	}
+	return a.seq < b.seq
+}
+
+// submit hands the picked jobs to the worker pool
+func (s *JobScheduler) submit(jobs []*scheduledJob) {
+	for _, job := range jobs {
+		job := job
+		log.Info("▶️ Starting job %s (priority: %d, key: %s)", job.jobID, job.info.effectivePriority(), job.info.FairnessKey)
+		s.workerPool.Submit(func() {
+			defer s.release()
+			job.run()
+		})
+	}
+}
+
+// fairnessKeyFromLink derives a channel identifier from a Slack or Discord message link.
+// Slack: https://team.slack.com/archives/<channel>/p<ts>
+// Discord: https://discord.com/channels/<guild>/<channel>/<message>
+func fairnessKeyFromLink(link string) string {
+	if link == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i, segment := range segments {
+		switch segment {
+		case "archives":
+			if i+1 < len(segments) {
+				return segments[i+1]
+			}
+		case "channels":
+			if i+2 < len(segments) {
+				return segments[i+2]
+			}
+		}
+	}
+
+	return parsed.Host
+}
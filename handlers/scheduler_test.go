@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gammazero/workerpool"
+
+	"eksecd/models"
+)
+
+// runScheduledOrder blocks the only slot, schedules the given jobs, then releases the
+// slot and returns the order in which the jobs ran
+func runScheduledOrder(t *testing.T, jobs []struct {
+	id   string
+	info JobSchedulingInfo
+}) []string {
+	t.Helper()
+
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+	scheduler := NewJobScheduler(wp, 1)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+
+	blocker := make(chan struct{})
+	blockerStarted := make(chan struct{})
+	scheduler.Schedule("blocker", JobSchedulingInfo{FairnessKey: "blocker"}, func() {
+		close(blockerStarted)
+		<-blocker
+	})
+	<-blockerStarted
+
+	for _, job := range jobs {
+		job := job
+		wg.Add(1)
+		scheduler.Schedule(job.id, job.info, func() {
+			mu.Lock()
+			order = append(order, job.id)
+			mu.Unlock()
+			wg.Done()
+		})
+	}
+
+	if scheduler.PendingCount() != len(jobs) {
+		t.Fatalf("expected %d pending jobs, got %d", len(jobs), scheduler.PendingCount())
+	}
+
+	close(blocker)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for scheduled jobs")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return order
+}
+
+func assertOrder(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected order %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
+
+func TestJobScheduler_FIFOWithinSameKey(t *testing.T) {
+	order := runScheduledOrder(t, []struct {
+		id   string
+		info JobSchedulingInfo
+	}{
+		{"a1", JobSchedulingInfo{FairnessKey: "alice"}},
+		{"a2", JobSchedulingInfo{FairnessKey: "alice"}},
+		{"a3", JobSchedulingInfo{FairnessKey: "alice"}},
+	})
+	assertOrder(t, order, []string{"a1", "a2", "a3"})
+}
+
+func TestJobScheduler_RoundRobinAcrossRequesters(t *testing.T) {
+	order := runScheduledOrder(t, []struct {
+		id   string
+		info JobSchedulingInfo
+	}{
+		{"a1", JobSchedulingInfo{FairnessKey: "alice"}},
+		{"a2", JobSchedulingInfo{FairnessKey: "alice"}},
+		{"a3", JobSchedulingInfo{FairnessKey: "alice"}},
+		{"b1", JobSchedulingInfo{FairnessKey: "bob"}},
+		{"c1", JobSchedulingInfo{FairnessKey: "carol"}},
+	})
+	assertOrder(t, order, []string{"a1", "b1", "c1", "a2", "a3"})
+}
+
+func TestJobScheduler_PriorityAndAskMode(t *testing.T) {
+	order := runScheduledOrder(t, []struct {
+		id   string
+		info JobSchedulingInfo
+	}{
+		{"execute", JobSchedulingInfo{FairnessKey: "alice"}},
+		{"ask", JobSchedulingInfo{FairnessKey: "bob", AskMode: true}},
+		{"urgent", JobSchedulingInfo{FairnessKey: "carol", Priority: 5}},
+	})
+	assertOrder(t, order, []string{"urgent", "ask", "execute"})
+}
+
+func TestJobScheduler_LimitsConcurrency(t *testing.T) {
+	wp := workerpool.New(4)
+	defer wp.StopWait()
+	scheduler := NewJobScheduler(wp, 2)
+
+	var mu sync.Mutex
+	running, maxSeen := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		scheduler.Schedule("job", JobSchedulingInfo{}, func() {
+			defer wg.Done()
+			mu.Lock()
+			running++
+			if running > maxSeen {
+				maxSeen = running
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	if maxSeen > 2 {
+		t.Errorf("expected at most 2 concurrent jobs, saw %d", maxSeen)
+	}
+}
+
+func TestJobScheduler_SetMaxRunningStartsWaitingJobs(t *testing.T) {
+	wp := workerpool.New(4)
+	defer wp.StopWait()
+	scheduler := NewJobScheduler(wp, 1)
+
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	for _, id := range []string{"j1", "j2", "j3"} {
+		id := id
+		scheduler.Schedule(id, JobSchedulingInfo{}, func() {
+			started <- id
+			<-release
+		})
+	}
+
+	<-started
+	if scheduler.PendingCount() != 2 {
+		t.Fatalf("expected 2 pending jobs, got %d", scheduler.PendingCount())
+	}
+
+	scheduler.SetMaxRunning(3)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for jobs to start after raising the limit")
+		}
+	}
+	if scheduler.MaxRunning() != 3 || scheduler.PendingCount() != 0 {
+		t.Errorf("expected limit 3 with no pending jobs, got %d with %d pending", scheduler.MaxRunning(), scheduler.PendingCount())
+	}
+	close(release)
+}
+
+func TestFairnessKeyFromLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     string
+		expected string
+	}{
+		{"Slack link", "https://team.slack.com/archives/C0123ABC/p1700000000000000", "C0123ABC"},
+		{"Discord link", "https://discord.com/channels/111/222/333", "222"},
+		{"Unknown link falls back to host", "https://example.com/some/path", "example.com"},
+		{"Empty link", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fairnessKeyFromLink(tt.link); got != tt.expected {
+				t.Errorf("fairnessKeyFromLink(%q) = %q, want %q", tt.link, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDispatcher_SchedulingInfo(t *testing.T) {
+	appState := createTestAppState(t)
+	wp := workerpool.New(1)
+	defer wp.StopWait()
+	dispatcher := NewJobDispatcher(nil, wp, appState)
+
+	start := models.BaseMessage{
+		Type: models.MessageTypeStartConversation,
+		Payload: models.StartConversationPayload{
+			JobID:       "job-1",
+			MessageLink: "https://team.slack.com/archives/C1/p1",
+			Mode:        models.AgentModeAsk,
+			Priority:    3,
+			RequesterID: "U1",
+		},
+	}
+	info := dispatcher.schedulingInfo("job-1", start)
+	if info.Priority != 3 || info.FairnessKey != "U1" || !info.AskMode {
+		t.Errorf("unexpected scheduling info for start message: %+v", info)
+	}
+
+	// Follow-ups reuse the start message's scheduling info
+	followUp := createTestMessage(models.MessageTypeUserMessage, "job-1")
+	if got := dispatcher.schedulingInfo("job-1", followUp); got != info {
+		t.Errorf("expected follow-up to reuse %+v, got %+v", info, got)
+	}
+
+	// Unknown follow-ups fall back to persisted job data
+	if err := appState.UpdateJobData("job-2", models.JobData{
+		JobID:       "job-2",
+		MessageLink: "https://discord.com/channels/1/2/3",
+		Mode:        models.AgentModeExecute,
+	}); err != nil {
+		t.Fatalf("failed to update job data: %v", err)
+	}
+	fallback := dispatcher.schedulingInfo("job-2", createTestMessage(models.MessageTypeUserMessage, "job-2"))
+	if fallback.FairnessKey != "2" || fallback.AskMode || fallback.Priority != 0 {
+		t.Errorf("unexpected fallback scheduling info: %+v", fallback)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunWithTurnsPaused_WaitsForInFlightTurn(t *testing.T) {
+	mh := &MessageHandler{}
+
+	// Simulate an in-flight turn
+	mh.turnGate.RLock()
+	var turnFinished atomic.Bool
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		turnFinished.Store(true)
+		mh.turnGate.RUnlock()
+	}()
+
+	ranDuringTurn := false
+	err := mh.RunWithTurnsPaused(func() error {
+		ranDuringTurn = !turnFinished.Load()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ranDuringTurn {
+		t.Error("expected fn to run only after the in-flight turn finished")
+	}
+}
+
+func TestRunWithTurnsPaused_HoldsBackNewTurns(t *testing.T) {
+	mh := &MessageHandler{}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = mh.RunWithTurnsPaused(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+		close(done)
+	}()
+	<-started
+
+	turnStarted := make(chan struct{})
+	go func() {
+		mh.turnGate.RLock()
+		close(turnStarted)
+		mh.turnGate.RUnlock()
+	}()
+
+	select {
+	case <-turnStarted:
+		t.Fatal("expected new turn to wait while turns are paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+
+	select {
+	case <-turnStarted:
+	case <-time.After(time.Second):
+		t.Fatal("expected new turn to start once turns resumed")
+	}
+}
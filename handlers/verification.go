@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"eksecd/core"
+	"eksecd/core/log"
+	"eksecd/services"
+	"eksecd/usecases"
+)
+
+const (
+	verifyCommandEnvVar     = "VERIFY_COMMAND"         // Shell command that checks the changes, e.g. "go build ./... && go test ./..."
+	verifyTimeoutEnvVar     = "VERIFY_TIMEOUT_SECONDS" // Time limit of one verification run
+	verifyFixAttemptsEnvVar = "VERIFY_FIX_ATTEMPTS"    // Agent turns spent fixing failures before committing anyway
+
+	defaultVerifyTimeout     = 10 * time.Minute
+	defaultVerifyFixAttempts = 2
+)
+
+// verificationSettings returns the verification command, its timeout and the number of fix
+// attempts. An empty command disables verification.
+func (mh *MessageHandler) verificationSettings() (string, time.Duration, int) {
+	if mh.envManager == nil {
+		return "", 0, 0
+	}
+	command := mh.envManager.Get(verifyCommandEnvVar)
+
+	timeout := defaultVerifyTimeout
+	if envVal := mh.envManager.Get(verifyTimeoutEnvVar); envVal != "" {
+		seconds, err := strconv.Atoi(envVal)
+		if err != nil || seconds <= 0 {
+			log.Warn("⚠️ Invalid %s value %q, using %s", verifyTimeoutEnvVar, envVal, defaultVerifyTimeout)
+		} else {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	fixAttempts := defaultVerifyFixAttempts
+	if envVal := mh.envManager.Get(verifyFixAttemptsEnvVar); envVal != "" {
+		attempts, err := strconv.Atoi(envVal)
+		if err != nil || attempts < 0 {
+			log.Warn("⚠️ Invalid %s value %q, using %d", verifyFixAttemptsEnvVar, envVal, defaultVerifyFixAttempts)
+		} else {
+			fixAttempts = attempts
+		}
+	}
+	return command, timeout, fixAttempts
+}
+
+// verifyTurnChanges runs the verification command on the changes of the agent's turn and
+// feeds failures back to the agent in the same session until it passes or the fix attempts
+// run out. Returns the session ID to commit with and the result, nil if nothing was verified.
+func (mh *MessageHandler) verifyTurnChanges(jobID, sessionID, worktreePath string) (string, *usecases.VerificationResult) {
+	command, timeout, maxFixAttempts := mh.verificationSettings()
+	if command == "" {
+		return sessionID, nil
+	}
+
+	mh.activity.RecordActivity(jobID, activityVerifying)
+	run, err := mh.gitUseCase.VerifyChanges(worktreePath, command, timeout)
+	if err != nil {
+		log.Error("❌ Failed to verify changes of job %s: %v", jobID, err)
+		return sessionID, nil
+	}
+	if run == nil {
+		return sessionID, nil
+	}
+
+	result := &usecases.VerificationResult{Command: command}
+	for run != nil && !run.Passed && result.FixAttempts < maxFixAttempts {
+		result.FixAttempts++
+		log.Info("🔧 Asking agent to fix verification failures of job %s (attempt %d/%d)", jobID, result.FixAttempts, maxFixAttempts)
+
+		prompt := usecases.VerificationFixPrompt(command, run.Output, run.TimedOut, timeout)
+		mh.activity.MarkAgentStarted(jobID)
+		var fixResult *services.CLIAgentResult
+		var agentErr error
+		if worktreePath != "" {
+			fixResult, agentErr = mh.agentForJob(jobID).ContinueConversationInDir(sessionID, prompt, worktreePath)
+		} else {
+			fixResult, agentErr = mh.agentForJob(jobID).ContinueConversation(sessionID, prompt)
+		}
+		mh.activity.MarkAgentFinished(jobID, lastToolActivity(fixResult))
+		if interruptedErr, interrupted := core.IsAgentInterrupted(agentErr); interrupted {
+			if interruptedErr.SessionID != "" {
+				sessionID = interruptedErr.SessionID
+			}
+			log.Info("⏹️ Agent was interrupted while fixing verification failures of job %s", jobID)
+			break
+		}
+		if agentErr != nil {
+			// Commit what is there; the failure is reported in the git activity message
+			log.Error("❌ Agent failed to fix verification failures of job %s: %v", jobID, agentErr)
+			break
+		}
+		if fixResult != nil && fixResult.SessionID != "" {
+			sessionID = fixResult.SessionID
+		}
+
+		mh.activity.RecordActivity(jobID, activityVerifying)
+		nextRun, err := mh.gitUseCase.VerifyChanges(worktreePath, command, timeout)
+		if err != nil {
+			// Report the last run that completed rather than an unverified pass
+			log.Error("❌ Failed to verify changes of job %s: %v", jobID, err)
+			break
+		}
+		run = nextRun
+	}
+
+	// No changes left means the agent reverted them, and there is nothing that can fail
+	result.Passed = run == nil || run.Passed
+	result.TimedOut = run != nil && run.TimedOut
+	return sessionID, result
+}
+
+// verificationSummary reports the verification status of the pushed changes, or "" if they
+// were not verified
+func verificationSummary(result *usecases.VerificationResult) string {
+	if result == nil {
+		return ""
+	}
+	outcome := "failed"
+	if result.TimedOut {
+		outcome = "timed out"
+	}
+	if result.Passed {
+		outcome = "passed"
+	}
+	if result.FixAttempts > 0 {
+		attempts := "fix attempt"
+		if result.FixAttempts > 1 {
+			attempts += "s"
+		}
+		outcome += fmt.Sprintf(" after %d %s", result.FixAttempts, attempts)
+	}
+
+	if result.Passed {
+		return fmt.Sprintf("Verification %s: `%s`", outcome, result.Command)
+	}
+	return fmt.Sprintf("⚠️ Verification %s, pushed anyway: `%s`", outcome, result.Command)
+}
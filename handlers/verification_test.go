@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"eksecd/clients"
+	"eksecd/core/env"
+	"eksecd/models"
+	"eksecd/services"
+	"eksecd/usecases"
+)
+
+// fakeFixingAgent is a CLIAgent whose fix turns write the given files into the work dir
+type fakeFixingAgent struct {
+	services.CLIAgent
+	fixes   []string // File written by each fix turn, "" to change nothing
+	onFix   func(workDir string)
+	prompts []string
+}
+
+func (f *fakeFixingAgent) ForJob(jobID string) services.CLIAgent {
+	return f
+}
+
+func (f *fakeFixingAgent) ContinueConversationInDir(sessionID, prompt, workDir string) (*services.CLIAgentResult, error) {
+	f.prompts = append(f.prompts, prompt)
+	fix := f.fixes[0]
+	f.fixes = f.fixes[1:]
+	if fix != "" {
+		if err := os.WriteFile(filepath.Join(workDir, fix), []byte("fixed\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+	if f.onFix != nil {
+		f.onFix(workDir)
+	}
+	return &services.CLIAgentResult{Output: "Fixed", SessionID: sessionID + "-fixed"}, nil
+}
+
+// newVerificationTestHandler returns a handler for a repository with an uncommitted change
+func newVerificationTestHandler(t *testing.T, agent *fakeFixingAgent) (*MessageHandler, string) {
+	t.Helper()
+	t.Setenv("EKSEC_CONFIG_DIR", t.TempDir())
+	envManager, err := env.NewEnvManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{RepoPath: repo, IsRepoMode: true})
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return repo })
+
+	return &MessageHandler{
+		claudeService: agent,
+		gitUseCase:    usecases.NewGitUseCase(gitClient, agent, appState),
+		appState:      appState,
+		envManager:    envManager,
+		activity:      NewJobActivityTracker(),
+	}, repo
+}
+
+func TestVerifyTurnChanges_FixesFailures(t *testing.T) {
+	t.Setenv(verifyCommandEnvVar, "echo checking; test -f fixed.txt || { echo 'missing fixed.txt'; exit 1; }")
+	agent := &fakeFixingAgent{fixes: []string{"", "fixed.txt"}}
+	mh, repo := newVerificationTestHandler(t, agent)
+
+	sessionID, result := mh.verifyTurnChanges("job-1", "session", repo)
+	if result == nil || !result.Passed || result.FixAttempts != 2 {
+		t.Fatalf("expected verification to pass after two fix attempts, got %+v", result)
+	}
+	if sessionID != "session-fixed-fixed" {
+		t.Errorf("expected the session of the last fix turn, got %q", sessionID)
+	}
+	if len(agent.prompts) != 2 || !strings.Contains(agent.prompts[0], "missing fixed.txt") || !strings.Contains(agent.prompts[0], "test -f fixed.txt") {
+		t.Errorf("expected the command and its output in the fix prompt, got %q", agent.prompts)
+	}
+	if activity, ok := mh.activity.GetActivity("job-1"); !ok || activity.LastActivity != activityVerifying || activity.AgentRunning {
+		t.Errorf("expected the job to end verifying with no agent running, got %+v", activity)
+	}
+}
+
+func TestVerifyTurnChanges_StopsAfterMaxFixAttempts(t *testing.T) {
+	t.Setenv(verifyCommandEnvVar, "exit 1")
+	t.Setenv(verifyFixAttemptsEnvVar, "1")
+	agent := &fakeFixingAgent{fixes: []string{"", ""}}
+	mh, repo := newVerificationTestHandler(t, agent)
+
+	_, result := mh.verifyTurnChanges("job-1", "session", repo)
+	if result == nil || result.Passed || result.TimedOut || result.FixAttempts != 1 {
+		t.Fatalf("expected verification to fail after one fix attempt, got %+v", result)
+	}
+	if len(agent.prompts) != 1 {
+		t.Errorf("expected one fix turn, got %d", len(agent.prompts))
+	}
+}
+
+func TestVerifyTurnChanges_FailedRerunKeepsLastFailure(t *testing.T) {
+	t.Setenv(verifyCommandEnvVar, "exit 1")
+	// The fix turn leaves a checkout whose changes can no longer be checked
+	agent := &fakeFixingAgent{fixes: []string{"", ""}, onFix: func(workDir string) {
+		os.RemoveAll(filepath.Join(workDir, ".git"))
+	}}
+	mh, repo := newVerificationTestHandler(t, agent)
+
+	_, result := mh.verifyTurnChanges("job-1", "session", repo)
+	if result == nil || result.Passed || result.FixAttempts != 1 {
+		t.Fatalf("expected the last failing run to be reported after one fix attempt, got %+v", result)
+	}
+}
+
+func TestVerifyTurnChanges_Disabled(t *testing.T) {
+	agent := &fakeFixingAgent{}
+	mh, repo := newVerificationTestHandler(t, agent)
+
+	sessionID, result := mh.verifyTurnChanges("job-1", "session", repo)
+	if result != nil || sessionID != "session" {
+		t.Errorf("expected no verification without a command, got %+v, %q", result, sessionID)
+	}
+}
+
+func TestVerificationSettings(t *testing.T) {
+	t.Setenv(verifyCommandEnvVar, "go test ./...")
+	t.Setenv(verifyTimeoutEnvVar, "90")
+	t.Setenv(verifyFixAttemptsEnvVar, "invalid")
+	mh, _ := newVerificationTestHandler(t, &fakeFixingAgent{})
+
+	command, timeout, fixAttempts := mh.verificationSettings()
+	if command != "go test ./..." || timeout.Seconds() != 90 || fixAttempts != defaultVerifyFixAttempts {
+		t.Errorf("unexpected settings %q, %s, %d", command, timeout, fixAttempts)
+	}
+}
@@ -1,12 +1,13 @@
 package models
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 	"sync"
 	"time"
+
+	bolt "go.etcd.io/bbolt"
 )
 
 // RepositoryContext encapsulates information about the git repository being worked on
@@ -17,6 +18,15 @@ type RepositoryContext struct {
 	IsRepoMode bool
 	// RepositoryIdentifier is the owner/repo-name format identifier (e.g., "anthropics/eksecd")
 	RepositoryIdentifier string
+	// PushOnly pushes job branches without opening pull requests, for git servers with no
+	// PR concept. Jobs finish when their branch is merged into the default branch.
+	PushOnly bool
+	// DraftPRs opens job pull requests as drafts. They are marked ready for review on
+	// request or once their CI checks pass.
+	DraftPRs bool
+	// ConventionalCommits splits each turn's changes into Conventional Commits grouped by
+	// the agent, with messages checked against commitlint rules.
+	ConventionalCommits bool
 }
 
 // JobStatus represents the current state of a job
@@ -34,13 +44,47 @@ type JobData struct {
 	BranchName         string    `json:"branch_name"`
 	WorktreePath       string    `json:"worktree_path,omitempty"` // Path to the job's git worktree (empty if using main repo)
 	ClaudeSessionID    string    `json:"claude_session_id"`
-	PullRequestID      string    `json:"pull_request_id"`      // GitHub PR number (e.g., "123") - empty if no PR created yet
-	LastMessage        string    `json:"last_message"`         // The last message sent to Claude for this job
-	ProcessedMessageID string    `json:"processed_message_id"` // ID of the chat platform message being processed
-	MessageLink        string    `json:"message_link"`         // Link to the original chat message
-	Status             JobStatus `json:"status"`               // Current status of the job: "in_progress" or "completed"
-	Mode               AgentMode `json:"mode"`                 // "execute" or "ask" - determines if agent can modify files
+	PullRequestID      string    `json:"pull_request_id"`         // GitHub PR number (e.g., "123") - empty if no PR created yet
+	LastMessage        string    `json:"last_message"`            // The last message sent to Claude for this job
+	ProcessedMessageID string    `json:"processed_message_id"`    // ID of the chat platform message being processed
+	MessageLink        string    `json:"message_link"`            // Link to the original chat message
+	Status             JobStatus `json:"status"`                  // Current status of the job: "in_progress" or "completed"
+	Mode               AgentMode `json:"mode"`                    // "execute" or "ask" - determines if agent can modify files
+	ScheduleName       string    `json:"schedule_name,omitempty"` // Set on jobs started by a local schedule
 	UpdatedAt          time.Time `json:"updated_at"`
+
+	// Bookkeeping for the job history
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	TurnCount      int       `json:"turn_count,omitempty"`       // Number of agent turns run for the job
+	PullRequestURL string    `json:"pull_request_url,omitempty"` // Link to the job's PR, empty if no PR created yet
+	LastError      string    `json:"last_error,omitempty"`       // Error of the latest failed turn, set by SetJobError
+
+	// CI watcher bookkeeping, set by SetJobCIState
+	CIReportedCommit string `json:"ci_reported_commit,omitempty"` // Head commit whose finished checks were last handled
+	CIFixAttempts    int    `json:"ci_fix_attempts,omitempty"`    // Automatic CI fix turns since checks last passed
+
+	// Newest PR review comment already fed to the agent, set by SetJobReviewFeedbackSince
+	ReviewFeedbackSince time.Time `json:"review_feedback_since,omitempty"`
+
+	// Whether the job's PR is a draft, set by SetJobPullRequestDraft
+	PullRequestDraft bool `json:"pull_request_draft,omitempty"`
+
+	// Labels, reviewers and assignees requested for the job's PR
+	PullRequestOverrides *PullRequestOverrides `json:"pull_request_overrides,omitempty"`
+
+	// Reviewers of the job's draft PR, requested once it is marked ready. Set by SetJobPendingReviewers.
+	PendingReviewers *PendingReviewers `json:"pending_reviewers,omitempty"`
+
+	// Person credited with a Co-authored-by trailer on the job's commits
+	RequesterName  string `json:"requester_name,omitempty"`
+	RequesterEmail string `json:"requester_email,omitempty"`
+}
+
+// PendingReviewers are the reviewers held back while a job's PR is a draft
+type PendingReviewers struct {
+	Reviewers          []string `json:"reviewers,omitempty"`      // User logins
+	TeamReviewers      []string `json:"team_reviewers,omitempty"` // "org/team-slug"
+	CodeOwnerReviewers bool     `json:"codeowner_reviewers,omitempty"`
 }
 
 // QueuedMessage represents a message that has been queued for processing but not yet started
@@ -51,41 +95,77 @@ type QueuedMessage struct {
 	Message            string    `json:"message"`              // User's message text
 	MessageLink        string    `json:"message_link"`         // Link to original chat message
 	QueuedAt           time.Time `json:"queued_at"`            // When queued (for ordering)
+	Author             string    `json:"author,omitempty"`     // Who sent the message, used when coalescing follow-ups
+}
+
+// ProcessedMessageOutcome is the final result of processing a chat message
+type ProcessedMessageOutcome string
+
+const (
+	ProcessedMessageOutcomeCompleted ProcessedMessageOutcome = "completed" // The agent replied
+	ProcessedMessageOutcomeFailed    ProcessedMessageOutcome = "failed"    // Processing ended with an error
+)
+
+const (
+	// ProcessedMessageRetention is how long processed message IDs are remembered for
+	// deduplicating redeliveries across restarts
+	ProcessedMessageRetention = 7 * 24 * time.Hour
+	// maxProcessedMessages bounds the number of remembered processed messages
+	maxProcessedMessages = 1000
+)
+
+// ProcessedMessage records a chat message that has been fully processed, so a redelivery
+// of the same ProcessedMessageID is answered with the original result instead of running again
+type ProcessedMessage struct {
+	ProcessedMessageID string                  `json:"processed_message_id"`
+	JobID              string                  `json:"job_id"`
+	MessageType        string                  `json:"message_type"`
+	MessageLink        string                  `json:"message_link"`
+	Outcome            ProcessedMessageOutcome `json:"outcome"`
+	Reply              string                  `json:"reply,omitempty"` // Assistant reply sent for the message
+	Error              string                  `json:"error,omitempty"` // Error reported for the message
+	ProcessedAt        time.Time               `json:"processed_at"`
 }
 
 // PersistedState represents the state that gets persisted to disk
 type PersistedState struct {
-	AgentID        string                    `json:"agent_id"`
-	Jobs           map[string]*JobData       `json:"jobs"`
-	QueuedMessages map[string]*QueuedMessage `json:"queued_messages"` // Key: ProcessedMessageID
+	SchemaVersion     int                          `json:"schema_version,omitempty"` // Upgraded by the migrations in MigrateState
+	AgentID           string                       `json:"agent_id"`
+	Jobs              map[string]*JobData          `json:"jobs"`
+	QueuedMessages    map[string]*QueuedMessage    `json:"queued_messages"`              // Key: ProcessedMessageID
+	ProcessedMessages map[string]*ProcessedMessage `json:"processed_messages,omitempty"` // Key: ProcessedMessageID
 }
 
 // LoadedState represents the result of loading persisted state from disk
 type LoadedState struct {
-	AgentID        string
-	Jobs           map[string]*JobData
-	QueuedMessages map[string]*QueuedMessage
-	Loaded         bool // Indicates whether state was successfully loaded from disk
+	AgentID           string
+	Jobs              map[string]*JobData
+	QueuedMessages    map[string]*QueuedMessage
+	ProcessedMessages map[string]*ProcessedMessage
+	Loaded            bool // Indicates whether state was successfully loaded from disk
 }
 
 // AppState manages the state of all active jobs
 type AppState struct {
-	agentID        string
-	jobs           map[string]*JobData
-	queuedMessages map[string]*QueuedMessage
-	statePath      string
-	repoContext    *RepositoryContext
-	mutex          sync.RWMutex
+	agentID           string
+	jobs              map[string]*JobData
+	queuedMessages    map[string]*QueuedMessage
+	processedMessages map[string]*ProcessedMessage
+	statePath         string
+	store             *stateStore // Opened on first write
+	repoContext       *RepositoryContext
+	mutex             sync.RWMutex
 }
 
 // NewAppState creates a new AppState instance
 func NewAppState(agentID string, statePath string) *AppState {
 	return &AppState{
-		agentID:        agentID,
-		jobs:           make(map[string]*JobData),
-		queuedMessages: make(map[string]*QueuedMessage),
-		statePath:      statePath,
-		repoContext:    &RepositoryContext{}, // Initialize with empty context
+		agentID:           agentID,
+		jobs:              make(map[string]*JobData),
+		queuedMessages:    make(map[string]*QueuedMessage),
+		processedMessages: make(map[string]*ProcessedMessage),
+		statePath:         statePath,
+		repoContext:       &RepositoryContext{}, // Initialize with empty context
 	}
 }
 
@@ -107,6 +187,9 @@ func (a *AppState) GetRepositoryContext() *RepositoryContext {
 		RepoPath:             a.repoContext.RepoPath,
 		IsRepoMode:           a.repoContext.IsRepoMode,
 		RepositoryIdentifier: a.repoContext.RepositoryIdentifier,
+		PushOnly:             a.repoContext.PushOnly,
+		DraftPRs:             a.repoContext.DraftPRs,
+		ConventionalCommits:  a.repoContext.ConventionalCommits,
 	}
 }
 
@@ -123,8 +206,10 @@ func (a *AppState) UpdateJobData(jobID string, data JobData) error {
 	defer a.mutex.Unlock()
 	a.jobs[jobID] = &data
 
-	// Persist state after updating
-	if err := a.persistStateLocked(); err != nil {
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, &data)
+	}); err != nil {
 		return fmt.Errorf("failed to persist state: %w", err)
 	}
 
@@ -141,17 +226,30 @@ func (a *AppState) GetJobData(jobID string) (*JobData, bool) {
 	}
 	// Return a copy to avoid race conditions
 	return &JobData{
-		JobID:              data.JobID,
-		BranchName:         data.BranchName,
-		WorktreePath:       data.WorktreePath,
-		ClaudeSessionID:    data.ClaudeSessionID,
-		PullRequestID:      data.PullRequestID,
-		LastMessage:        data.LastMessage,
-		ProcessedMessageID: data.ProcessedMessageID,
-		MessageLink:        data.MessageLink,
-		Status:             data.Status,
-		Mode:               data.Mode,
-		UpdatedAt:          data.UpdatedAt,
+		JobID:                data.JobID,
+		BranchName:           data.BranchName,
+		WorktreePath:         data.WorktreePath,
+		ClaudeSessionID:      data.ClaudeSessionID,
+		PullRequestID:        data.PullRequestID,
+		LastMessage:          data.LastMessage,
+		ProcessedMessageID:   data.ProcessedMessageID,
+		MessageLink:          data.MessageLink,
+		Status:               data.Status,
+		Mode:                 data.Mode,
+		ScheduleName:         data.ScheduleName,
+		UpdatedAt:            data.UpdatedAt,
+		CreatedAt:            data.CreatedAt,
+		TurnCount:            data.TurnCount,
+		PullRequestURL:       data.PullRequestURL,
+		LastError:            data.LastError,
+		CIReportedCommit:     data.CIReportedCommit,
+		CIFixAttempts:        data.CIFixAttempts,
+		ReviewFeedbackSince:  data.ReviewFeedbackSince,
+		PullRequestDraft:     data.PullRequestDraft,
+		PullRequestOverrides: data.PullRequestOverrides,
+		PendingReviewers:     data.PendingReviewers,
+		RequesterName:        data.RequesterName,
+		RequesterEmail:       data.RequesterEmail,
 	}, true
 }
 
@@ -161,8 +259,182 @@ func (a *AppState) RemoveJob(jobID string) error {
 	defer a.mutex.Unlock()
 	delete(a.jobs, jobID)
 
-	// Persist state after removing
-	if err := a.persistStateLocked(); err != nil {
+	// Persist the removal
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return deleteRecord(tx, jobsBucket, jobID)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// IncrementTurnCount records that an agent turn started for the job
+func (a *AppState) IncrementTurnCount(jobID string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	job.TurnCount++
+
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, job)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobError records the error of the job's latest turn for the job history
+func (a *AppState) SetJobError(jobID, errText string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	job.LastError = errText
+
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, job)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobCIState records the head commit whose CI checks were handled and the number of
+// automatic fix turns started for failing checks
+func (a *AppState) SetJobCIState(jobID, reportedCommit string, fixAttempts int) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	job.CIReportedCommit = reportedCommit
+	job.CIFixAttempts = fixAttempts
+
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, job)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobPullRequestDraft records whether the job's pull request is a draft
+func (a *AppState) SetJobPullRequestDraft(jobID string, draft bool) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	job.PullRequestDraft = draft
+
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, job)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobPendingReviewers records the reviewers to request once the job's draft pull request
+// is marked ready. nil clears them.
+func (a *AppState) SetJobPendingReviewers(jobID string, reviewers *PendingReviewers) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	job.PendingReviewers = reviewers
+
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, job)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobReviewFeedbackSince records the creation time of the newest PR review comment fed
+// to the agent, so later checks only pick up newer comments
+func (a *AppState) SetJobReviewFeedbackSince(jobID string, since time.Time) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	job.ReviewFeedbackSince = since
+
+	// Persist the updated job
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, jobsBucket, jobID, job)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// ArchiveJob appends the job to the job history with its outcome and the PR state seen
+// when it finished, then removes it like RemoveJob. Unknown jobs are ignored.
+func (a *AppState) ArchiveJob(jobID string, outcome JobOutcome, pullRequestState string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	job, exists := a.jobs[jobID]
+	if !exists {
+		return nil
+	}
+	if a.statePath == "" {
+		return fmt.Errorf("state path not configured")
+	}
+
+	repository := ""
+	if a.repoContext != nil {
+		repository = a.repoContext.RepositoryIdentifier
+	}
+	if err := appendJobHistory(JobHistoryPath(a.statePath), JobHistoryEntry{
+		JobID:            jobID,
+		Outcome:          outcome,
+		Repository:       repository,
+		BranchName:       job.BranchName,
+		PullRequestID:    job.PullRequestID,
+		PullRequestURL:   job.PullRequestURL,
+		PullRequestState: pullRequestState,
+		MessageLink:      job.MessageLink,
+		Mode:             job.Mode,
+		ScheduleName:     job.ScheduleName,
+		TurnCount:        job.TurnCount,
+		Error:            job.LastError,
+		CreatedAt:        job.CreatedAt,
+		LastActivityAt:   job.UpdatedAt,
+		FinishedAt:       time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to archive job: %w", err)
+	}
+	delete(a.jobs, jobID)
+
+	// Persist the removal
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return deleteRecord(tx, jobsBucket, jobID)
+	}); err != nil {
 		return fmt.Errorf("failed to persist state: %w", err)
 	}
 
@@ -176,17 +448,30 @@ func (a *AppState) GetAllJobs() map[string]JobData {
 	result := make(map[string]JobData)
 	for jobID, data := range a.jobs {
 		result[jobID] = JobData{
-			JobID:              data.JobID,
-			BranchName:         data.BranchName,
-			WorktreePath:       data.WorktreePath,
-			ClaudeSessionID:    data.ClaudeSessionID,
-			PullRequestID:      data.PullRequestID,
-			LastMessage:        data.LastMessage,
-			ProcessedMessageID: data.ProcessedMessageID,
-			MessageLink:        data.MessageLink,
-			Status:             data.Status,
-			Mode:               data.Mode,
-			UpdatedAt:          data.UpdatedAt,
+			JobID:                data.JobID,
+			BranchName:           data.BranchName,
+			WorktreePath:         data.WorktreePath,
+			ClaudeSessionID:      data.ClaudeSessionID,
+			PullRequestID:        data.PullRequestID,
+			LastMessage:          data.LastMessage,
+			ProcessedMessageID:   data.ProcessedMessageID,
+			MessageLink:          data.MessageLink,
+			Status:               data.Status,
+			Mode:                 data.Mode,
+			ScheduleName:         data.ScheduleName,
+			UpdatedAt:            data.UpdatedAt,
+			CreatedAt:            data.CreatedAt,
+			TurnCount:            data.TurnCount,
+			PullRequestURL:       data.PullRequestURL,
+			LastError:            data.LastError,
+			CIReportedCommit:     data.CIReportedCommit,
+			CIFixAttempts:        data.CIFixAttempts,
+			ReviewFeedbackSince:  data.ReviewFeedbackSince,
+			PullRequestDraft:     data.PullRequestDraft,
+			PullRequestOverrides: data.PullRequestOverrides,
+			PendingReviewers:     data.PendingReviewers,
+			RequesterName:        data.RequesterName,
+			RequesterEmail:       data.RequesterEmail,
 		}
 	}
 	return result
@@ -198,8 +483,10 @@ func (a *AppState) AddQueuedMessage(msg QueuedMessage) error {
 	defer a.mutex.Unlock()
 	a.queuedMessages[msg.ProcessedMessageID] = &msg
 
-	// Persist state after adding
-	if err := a.persistStateLocked(); err != nil {
+	// Persist the queued message
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return putRecord(tx, queuedMessagesBucket, msg.ProcessedMessageID, &msg)
+	}); err != nil {
 		return fmt.Errorf("failed to persist state: %w", err)
 	}
 
@@ -212,8 +499,10 @@ func (a *AppState) RemoveQueuedMessage(processedMessageID string) error {
 	defer a.mutex.Unlock()
 	delete(a.queuedMessages, processedMessageID)
 
-	// Persist state after removing
-	if err := a.persistStateLocked(); err != nil {
+	// Persist the removal
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		return deleteRecord(tx, queuedMessagesBucket, processedMessageID)
+	}); err != nil {
 		return fmt.Errorf("failed to persist state: %w", err)
 	}
 
@@ -233,52 +522,176 @@ func (a *AppState) GetAllQueuedMessages() []QueuedMessage {
 			Message:            msg.Message,
 			MessageLink:        msg.MessageLink,
 			QueuedAt:           msg.QueuedAt,
+			Author:             msg.Author,
 		})
 	}
 	return result
 }
 
-// persistStateLocked persists the current state to disk
-// MUST be called with mutex already locked
-func (a *AppState) persistStateLocked() error {
-	if a.statePath == "" {
-		return fmt.Errorf("state path not configured")
+// RecordProcessedMessage remembers a processed message and persists it. Entries older than
+// ProcessedMessageRetention are dropped, as are the oldest entries beyond the size bound.
+func (a *AppState) RecordProcessedMessage(msg ProcessedMessage) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.processedMessages[msg.ProcessedMessageID] = &msg
+	pruned := a.pruneProcessedMessagesLocked(time.Now())
+
+	// Persist the record together with the pruned entries
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		if err := putRecord(tx, processedMessagesBucket, msg.ProcessedMessageID, &msg); err != nil {
+			return err
+		}
+		return deleteRecords(tx, processedMessagesBucket, pruned)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreProcessedMessages loads processed messages from persisted state in one write
+func (a *AppState) RestoreProcessedMessages(msgs map[string]*ProcessedMessage) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for id, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+		restored := *msg
+		a.processedMessages[id] = &restored
+	}
+	pruned := a.pruneProcessedMessagesLocked(time.Now())
+
+	// Persist the restored records in one transaction
+	if err := a.persistLocked(func(tx *bolt.Tx) error {
+		for id, msg := range a.processedMessages {
+			if err := putRecord(tx, processedMessagesBucket, id, msg); err != nil {
+				return err
+			}
+		}
+		return deleteRecords(tx, processedMessagesBucket, pruned)
+	}); err != nil {
+		return fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	return nil
+}
+
+// GetProcessedMessage returns the record for a processed message, if it is still retained
+func (a *AppState) GetProcessedMessage(processedMessageID string) (*ProcessedMessage, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	msg, exists := a.processedMessages[processedMessageID]
+	if !exists || time.Since(msg.ProcessedAt) > ProcessedMessageRetention {
+		return nil, false
 	}
+	// Return a copy to avoid race conditions
+	return &ProcessedMessage{
+		ProcessedMessageID: msg.ProcessedMessageID,
+		JobID:              msg.JobID,
+		MessageType:        msg.MessageType,
+		MessageLink:        msg.MessageLink,
+		Outcome:            msg.Outcome,
+		Reply:              msg.Reply,
+		Error:              msg.Error,
+		ProcessedAt:        msg.ProcessedAt,
+	}, true
+}
 
-	// Create the state object
-	state := PersistedState{
-		AgentID:        a.agentID,
-		Jobs:           a.jobs,
-		QueuedMessages: a.queuedMessages,
+// pruneProcessedMessagesLocked drops expired processed messages and, beyond
+// maxProcessedMessages, the oldest ones. Returns the dropped IDs.
+// MUST be called with mutex already locked.
+func (a *AppState) pruneProcessedMessagesLocked(now time.Time) []string {
+	var pruned []string
+	cutoff := now.Add(-ProcessedMessageRetention)
+	for id, msg := range a.processedMessages {
+		if msg.ProcessedAt.Before(cutoff) {
+			delete(a.processedMessages, id)
+			pruned = append(pruned, id)
+		}
 	}
 
-	// Marshal to JSON with pretty printing
-	data, err := json.MarshalIndent(state, "", "  ")
+	if len(a.processedMessages) <= maxProcessedMessages {
+		return pruned
+	}
+	ids := make([]string, 0, len(a.processedMessages))
+	for id := range a.processedMessages {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return a.processedMessages[ids[i]].ProcessedAt.Before(a.processedMessages[ids[j]].ProcessedAt)
+	})
+	for _, id := range ids[:len(ids)-maxProcessedMessages] {
+		delete(a.processedMessages, id)
+		pruned = append(pruned, id)
+	}
+	return pruned
+}
+
+// PersistState writes a full snapshot of the current state to the store.
+// Every mutation is persisted as it happens already; this is used during shutdown
+// to make sure the latest state is on disk before exiting.
+func (a *AppState) PersistState() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	store, err := a.storeLocked()
 	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
+		return err
 	}
+	return store.writeAll(PersistedState{
+		SchemaVersion:     CurrentStateSchemaVersion,
+		AgentID:           a.agentID,
+		Jobs:              a.jobs,
+		QueuedMessages:    a.queuedMessages,
+		ProcessedMessages: a.processedMessages,
+	})
+}
 
-	// Ensure directory exists
-	dir := filepath.Dir(a.statePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create state directory: %w", err)
+// Close closes the state store. Later writes reopen it.
+func (a *AppState) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.store == nil {
+		return nil
 	}
+	err := a.store.close()
+	a.store = nil
+	return err
+}
 
-	// Write to temporary file first
-	tempPath := a.statePath + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp state file: %w", err)
+// persistLocked runs fn in a single store transaction
+// MUST be called with mutex already locked
+func (a *AppState) persistLocked(fn func(tx *bolt.Tx) error) error {
+	store, err := a.storeLocked()
+	if err != nil {
+		return err
 	}
+	return store.update(fn)
+}
 
-	// Atomic rename
-	if err := os.Rename(tempPath, a.statePath); err != nil {
-		return fmt.Errorf("failed to rename state file: %w", err)
+// storeLocked returns the state store, opening it and recording the agent ID on first use
+// MUST be called with mutex already locked
+func (a *AppState) storeLocked() (*stateStore, error) {
+	if a.statePath == "" {
+		return nil, fmt.Errorf("state path not configured")
+	}
+	if a.store != nil {
+		return a.store, nil
 	}
 
-	return nil
+	store, err := openStateStore(a.statePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.putAgentID(a.agentID); err != nil {
+		store.close()
+		return nil, fmt.Errorf("failed to persist agent ID: %w", err)
+	}
+	a.store = store
+	return store, nil
 }
 
-// LoadState loads persisted state from disk
+// LoadState loads persisted state from the store at statePath
 // Returns LoadedState containing the loaded data and a boolean indicating success, or an error
 func LoadState(statePath string) (*LoadedState, error) {
 	// Check if state file exists
@@ -291,22 +704,22 @@ func LoadState(statePath string) (*LoadedState, error) {
 		}, nil
 	}
 
-	// Read the state file
-	data, err := os.ReadFile(statePath)
+	store, err := openStateStore(statePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read state file: %w", err)
+		return nil, err
 	}
+	defer store.close()
 
-	// Unmarshal the state
-	var state PersistedState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	state, err := store.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
 	}
 
 	return &LoadedState{
-		AgentID:        state.AgentID,
-		Jobs:           state.Jobs,
-		QueuedMessages: state.QueuedMessages,
-		Loaded:         true,
+		AgentID:           state.AgentID,
+		Jobs:              state.Jobs,
+		QueuedMessages:    state.QueuedMessages,
+		ProcessedMessages: state.ProcessedMessages,
+		Loaded:            true,
 	}, nil
 }
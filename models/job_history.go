@@ -0,0 +1,112 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// JobHistoryFileName is the append-only archive of finished jobs, stored next to the state
+// database. It is a separate file so `eksecd jobs history` can read it while the agent runs.
+const JobHistoryFileName = "job_history.jsonl"
+
+// JobOutcome is how a job ended
+type JobOutcome string
+
+const (
+	JobOutcomeMerged    JobOutcome = "merged"    // Pull request was merged
+	JobOutcomeClosed    JobOutcome = "closed"    // Pull request was closed without merging
+	JobOutcomeInactive  JobOutcome = "inactive"  // Thread went quiet before the work was merged or closed
+	JobOutcomeFailed    JobOutcome = "failed"    // Last turn failed and the thread went quiet
+	JobOutcomeAbandoned JobOutcome = "abandoned" // Job branch or worktree disappeared, or the job went stale across a restart
+)
+
+// JobHistoryEntry is the archived record of a finished job
+type JobHistoryEntry struct {
+	JobID            string     `json:"job_id"`
+	Outcome          JobOutcome `json:"outcome"`
+	Repository       string     `json:"repository,omitempty"` // owner/repo-name, empty in no-repo mode
+	BranchName       string     `json:"branch_name,omitempty"`
+	PullRequestID    string     `json:"pull_request_id,omitempty"`
+	PullRequestURL   string     `json:"pull_request_url,omitempty"`
+	PullRequestState string     `json:"pull_request_state,omitempty"` // open, merged, closed or no_pr when the job was archived
+	MessageLink      string     `json:"message_link,omitempty"`       // Link to the chat message that requested the work
+	Mode             AgentMode  `json:"mode,omitempty"`
+	ScheduleName     string     `json:"schedule_name,omitempty"`
+	TurnCount        int        `json:"turn_count"`
+	Error            string     `json:"error,omitempty"` // Error of the last failed turn, if any
+	CreatedAt        time.Time  `json:"created_at"`
+	LastActivityAt   time.Time  `json:"last_activity_at"`
+	FinishedAt       time.Time  `json:"finished_at"`
+}
+
+// JobHistoryPath returns the job history file that belongs to the state database at statePath
+func JobHistoryPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), JobHistoryFileName)
+}
+
+// appendJobHistory appends entry to the history file at path and syncs it to disk
+func appendJobHistory(path string, entry JobHistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job history entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create job history directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open job history file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write job history entry: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync job history file: %w", err)
+	}
+	return nil
+}
+
+// ReadJobHistory reads the archived jobs at path, oldest first. A missing file is an empty
+// history. Lines that cannot be parsed, such as one cut short by a crash, are skipped, and
+// a job archived more than once keeps only its latest entry.
+func ReadJobHistory(path string) ([]JobHistoryEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job history file: %w", err)
+	}
+	defer file.Close()
+
+	latest := make(map[string]JobHistoryEntry)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry JobHistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.JobID == "" {
+			continue
+		}
+		latest[entry.JobID] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read job history file: %w", err)
+	}
+
+	entries := make([]JobHistoryEntry, 0, len(latest))
+	for _, entry := range latest {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FinishedAt.Before(entries[j].FinishedAt)
+	})
+	return entries, nil
+}
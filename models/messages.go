@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // AgentMode represents the mode of a conversation
 type AgentMode string
 
@@ -18,6 +20,15 @@ const (
 	MessageTypeProcessingMessage         = "processing_message_v1"
 	MessageTypeCheckIdleJobs             = "check_idle_jobs_v1"
 	MessageTypeJobComplete               = "job_complete_v1"
+	MessageTypeJobStatusRequest          = "job_status_request_v1"
+	MessageTypeJobStatusResponse         = "job_status_response_v1"
+	MessageTypeDrain                     = "drain_v1"
+	MessageTypeAgentDraining             = "agent_draining_v1"
+	MessageTypeArtifactsUpdated          = "artifacts_updated_v1"
+	MessageTypeSetConcurrency            = "set_concurrency_v1"
+	MessageTypeSetConcurrencyResponse    = "set_concurrency_response_v1"
+	MessageTypeScheduledJobReport        = "scheduled_job_report_v1"
+	MessageTypeMarkReady                 = "mark_ready_v1"
 )
 
 type BaseMessage struct {
@@ -38,13 +49,31 @@ type PreviousMessage struct {
 }
 
 type StartConversationPayload struct {
-	JobID              string              `json:"job_id"`
-	Message            string              `json:"message"`
-	ProcessedMessageID string              `json:"processed_message_id"`
-	MessageLink        string              `json:"message_link"`
-	Attachments        []MessageAttachment `json:"attachments,omitempty"`
-	PreviousMessages   []PreviousMessage   `json:"previous_messages,omitempty"`
-	Mode               AgentMode           `json:"mode"`
+	JobID              string                `json:"job_id"`
+	Message            string                `json:"message"`
+	ProcessedMessageID string                `json:"processed_message_id"`
+	MessageLink        string                `json:"message_link"`
+	Attachments        []MessageAttachment   `json:"attachments,omitempty"`
+	PreviousMessages   []PreviousMessage     `json:"previous_messages,omitempty"`
+	Mode               AgentMode             `json:"mode"`
+	Priority           int                   `json:"priority,omitempty"`        // Higher runs first when jobs wait for a slot
+	RequesterID        string                `json:"requester_id,omitempty"`    // Used for fair scheduling across requesters
+	RequesterName      string                `json:"requester_name,omitempty"`  // Credited with a Co-authored-by trailer on the job's commits
+	RequesterEmail     string                `json:"requester_email,omitempty"` // Email of the Co-authored-by trailer, required for it
+	ChannelID          string                `json:"channel_id,omitempty"`      // Used for fair scheduling when no requester is set
+	Model              string                `json:"model,omitempty"`           // Overrides the agent's configured model for this job
+	ScheduleName       string                `json:"schedule_name,omitempty"`   // Set on jobs started by a local schedule
+	PullRequest        *PullRequestOverrides `json:"pull_request,omitempty"`    // Overrides the default labels, reviewers and assignees of the job's PR
+}
+
+// PullRequestOverrides replace the agent's default labels, reviewers and assignees for the
+// pull request of one job. A missing field keeps the default and an empty list clears it.
+type PullRequestOverrides struct {
+	Labels             []string `json:"labels"`
+	Reviewers          []string `json:"reviewers"`      // GitHub logins
+	TeamReviewers      []string `json:"team_reviewers"` // "org/team-slug"
+	Assignees          []string `json:"assignees"`
+	CodeOwnerReviewers *bool    `json:"codeowner_reviewers,omitempty"` // Request reviews from the code owners of the changed paths
 }
 
 type StartConversationResponsePayload struct {
@@ -53,17 +82,24 @@ type StartConversationResponsePayload struct {
 }
 
 type UserMessagePayload struct {
-	JobID              string              `json:"job_id"`
-	Message            string              `json:"message"`
-	ProcessedMessageID string              `json:"processed_message_id"`
-	MessageLink        string              `json:"message_link"`
-	Attachments        []MessageAttachment `json:"attachments,omitempty"`
+	JobID               string              `json:"job_id"`
+	Message             string              `json:"message"`
+	ProcessedMessageID  string              `json:"processed_message_id"`
+	MessageLink         string              `json:"message_link"`
+	Attachments         []MessageAttachment `json:"attachments,omitempty"`
+	Author              string              `json:"author,omitempty"`                // Display name of the user who sent the message
+	CoalescedMessageIDs []string            `json:"coalesced_message_ids,omitempty"` // Earlier queued messages merged into this one
+	Interrupt           bool                `json:"interrupt,omitempty"`             // Terminate the running turn and resume with this message
+	ReviewThreadIDs     []string            `json:"review_thread_ids,omitempty"`     // PR review threads the agent may answer, set on turns fed from PR reviews
 }
 
 type AssistantMessagePayload struct {
-	JobID              string `json:"job_id"`
-	Message            string `json:"message"`
-	ProcessedMessageID string `json:"processed_message_id"`
+	JobID              string              `json:"job_id"`
+	Message            string              `json:"message"`
+	ProcessedMessageID string              `json:"processed_message_id"`
+	PartIndex          int                 `json:"part_index,omitempty"`  // 1-based index when a reply is split into parts
+	PartCount          int                 `json:"part_count,omitempty"`  // Total number of parts when a reply is split
+	Attachments        []MessageAttachment `json:"attachments,omitempty"` // Uploaded files accompanying the reply
 }
 
 type SystemMessagePayload struct {
@@ -86,3 +122,82 @@ type JobCompletePayload struct {
 	Reason string `json:"reason"`
 }
 
+// JobStatusRequestPayload asks the agent to report what a job is currently doing
+type JobStatusRequestPayload struct {
+	JobID string `json:"job_id"`
+}
+
+// JobStatusResponsePayload reports the current state of a job back to the server
+type JobStatusResponsePayload struct {
+	JobID               string     `json:"job_id"`
+	Found               bool       `json:"found"` // False when the agent has no record of the job
+	BranchName          string     `json:"branch_name,omitempty"`
+	WorktreePath        string     `json:"worktree_path,omitempty"`
+	PullRequestID       string     `json:"pull_request_id,omitempty"`
+	Status              JobStatus  `json:"status,omitempty"`
+	Mode                AgentMode  `json:"mode,omitempty"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+	AgentRunning        bool       `json:"agent_running"`
+	AgentStartedAt      *time.Time `json:"agent_started_at,omitempty"`
+	AgentRunningSeconds int64      `json:"agent_running_seconds,omitempty"`
+	QueuedMessageCount  int        `json:"queued_message_count"`
+	LastActivity        string     `json:"last_activity,omitempty"`      // Current processing phase, e.g. "running agent"
+	LastToolActivity    string     `json:"last_tool_activity,omitempty"` // Last tool the agent used, e.g. "Edit main.go"
+	LastActivityAt      *time.Time `json:"last_activity_at,omitempty"`
+}
+
+// MarkReadyPayload asks the agent to mark the job's draft pull request ready for review
+type MarkReadyPayload struct {
+	JobID              string `json:"job_id"`
+	ProcessedMessageID string `json:"processed_message_id,omitempty"` // Thread message to reply to
+}
+
+// ArtifactsUpdatedPayload notifies the agent that its rules, MCP configs or skills
+// changed on the server and should be re-fetched
+type ArtifactsUpdatedPayload struct {
+	// Empty payload - agent re-fetches all artifacts
+}
+
+// SetConcurrencyPayload changes how many jobs the agent processes at a time
+type SetConcurrencyPayload struct {
+	MaxConcurrency  int   `json:"max_concurrency,omitempty"`  // 0 keeps the current value
+	AutoConcurrency *bool `json:"auto_concurrency,omitempty"` // Lower concurrency under memory pressure
+}
+
+// SetConcurrencyResponsePayload tells the server whether a set_concurrency_v1 request
+// was applied
+type SetConcurrencyResponsePayload struct {
+	Applied     bool   `json:"applied"`
+	Concurrency int    `json:"concurrency"`     // Jobs allowed to run at a time after the request
+	Error       string `json:"error,omitempty"` // Why the request was rejected
+}
+
+// DrainPayload asks the agent to stop accepting new conversations, finish in-flight
+// turns and shut down cleanly
+type DrainPayload struct {
+	Reason          string `json:"reason,omitempty"`
+	DeadlineSeconds int    `json:"deadline_seconds,omitempty"` // Overrides DRAIN_TIMEOUT_SECONDS when set
+}
+
+// AgentDrainingPayload tells the server that the agent is draining and will shut down
+// once its in-flight jobs finish or the deadline passes
+type AgentDrainingPayload struct {
+	Reason         string    `json:"reason"`
+	DeadlineAt     time.Time `json:"deadline_at"`
+	InFlightJobIDs []string  `json:"in_flight_job_ids"`
+}
+
+// ScheduledJobReportPayload reports the result of a job started by a local schedule.
+// MessageLink is the thread the result should be posted to.
+type ScheduledJobReportPayload struct {
+	ScheduleName    string    `json:"schedule_name"`
+	JobID           string    `json:"job_id"`
+	MessageLink     string    `json:"message_link"`
+	Status          JobStatus `json:"status"` // "completed" or "failed"
+	Output          string    `json:"output,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	BranchName      string    `json:"branch_name,omitempty"`
+	CommitHash      string    `json:"commit_hash,omitempty"`
+	PullRequestLink string    `json:"pull_request_link,omitempty"`
+	FinishedAt      time.Time `json:"finished_at"`
+}
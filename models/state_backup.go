@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// StateBackupCount is the number of rotated state backups kept next to the state database
+const StateBackupCount = 3
+
+// StateBackupPath returns the path of the n-th newest backup of the state at statePath,
+// starting at 1
+func StateBackupPath(statePath string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", statePath, n)
+}
+
+// BackupState copies the state database to the newest backup slot, shifting older backups
+// down and dropping the oldest beyond StateBackupCount. The copy is taken in a read
+// transaction, so it is consistent.
+func BackupState(statePath string) error {
+	store, err := openStateStore(statePath)
+	if err != nil {
+		return err
+	}
+	defer store.close()
+
+	tmpPath := statePath + ".bak.tmp"
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(tmpPath, 0600)
+	}); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy state store: %w", err)
+	}
+
+	for n := StateBackupCount; n > 1; n-- {
+		if err := os.Rename(StateBackupPath(statePath, n-1), StateBackupPath(statePath, n)); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to rotate state backups: %w", err)
+		}
+	}
+	if err := os.Rename(tmpPath, StateBackupPath(statePath, 1)); err != nil {
+		return fmt.Errorf("failed to write state backup: %w", err)
+	}
+	return nil
+}
+
+// RecoverState moves the unreadable state database at statePath aside and puts the newest
+// backup that can be migrated and loaded in its place. Returns the path the unreadable
+// database was moved to and the backup that was restored, which is empty when no backup
+// was usable and the agent has to start with empty state.
+func RecoverState(statePath string) (quarantinePath string, restoredFrom string, err error) {
+	quarantinePath = fmt.Sprintf("%s.corrupt-%s", statePath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(statePath, quarantinePath); err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to move unreadable state store aside: %w", err)
+	}
+
+	for n := 1; n <= StateBackupCount; n++ {
+		backupPath := StateBackupPath(statePath, n)
+		if _, err := os.Stat(backupPath); err != nil {
+			continue
+		}
+		// Work on a copy so a failed attempt leaves the backup itself untouched
+		if err := copyFile(backupPath, statePath); err != nil {
+			return quarantinePath, "", fmt.Errorf("failed to restore state backup %s: %w", backupPath, err)
+		}
+		if _, _, err := MigrateState(statePath); err == nil {
+			if _, err := LoadState(statePath); err == nil {
+				return quarantinePath, backupPath, nil
+			}
+		}
+		if err := os.Remove(statePath); err != nil {
+			return quarantinePath, "", fmt.Errorf("failed to remove unusable state backup copy: %w", err)
+		}
+	}
+
+	return quarantinePath, "", nil
+}
+
+// copyFile copies src to dst, replacing dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
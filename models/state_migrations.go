@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// CurrentStateSchemaVersion is the schema version of the state written by this build
+const CurrentStateSchemaVersion = 2
+
+// ErrStateSchemaTooNew is returned when the state was written by a newer eksecd
+var ErrStateSchemaTooNew = errors.New("state was written by a newer version of eksecd")
+
+// StateMigration upgrades stored state from schema Version-1 to Version
+type StateMigration struct {
+	Version     int
+	Description string
+	Migrate     func(tx *bolt.Tx) error
+}
+
+// stateMigrations is the registry of schema migrations, ordered by version. When a change
+// to a stored record needs more than JSON zero values, add a migration here and bump
+// CurrentStateSchemaVersion.
+var stateMigrations = []StateMigration{
+	{
+		Version:     1,
+		Description: "default the mode of jobs stored before agent modes existed to execute",
+		Migrate: func(tx *bolt.Tx) error {
+			return migrateJobs(tx, func(job *JobData) bool {
+				if job.Mode != "" {
+					return false
+				}
+				job.Mode = AgentModeExecute
+				return true
+			})
+		},
+	},
+	{
+		Version:     2,
+		Description: "set the creation time of jobs stored before the job history existed",
+		Migrate: func(tx *bolt.Tx) error {
+			return migrateJobs(tx, func(job *JobData) bool {
+				if !job.CreatedAt.IsZero() {
+					return false
+				}
+				job.CreatedAt = job.UpdatedAt
+				return true
+			})
+		},
+	},
+}
+
+// MigrateState upgrades the store at statePath to CurrentStateSchemaVersion by running the
+// pending migrations in one transaction, so a failed migration leaves the store untouched.
+// Returns the version the store was at and the migrations that ran.
+func MigrateState(statePath string) (from int, applied []StateMigration, err error) {
+	if _, err := os.Stat(statePath); os.IsNotExist(err) {
+		return CurrentStateSchemaVersion, nil, nil
+	}
+
+	store, err := openStateStore(statePath)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer store.close()
+	// bbolt panics on some kinds of page corruption; report it as an unreadable store
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("state store is corrupt: %v", r)
+		}
+	}()
+
+	return store.migrate(stateMigrations, CurrentStateSchemaVersion)
+}
+
+// migrate runs the migrations above the stored schema version up to target
+func (s *stateStore) migrate(migrations []StateMigration, target int) (int, []StateMigration, error) {
+	var from int
+	var applied []StateMigration
+	err := s.update(func(tx *bolt.Tx) error {
+		var err error
+		if from, err = getSchemaVersion(tx); err != nil {
+			return err
+		}
+		if from > target {
+			return fmt.Errorf("%w: schema version %d, this build supports up to %d", ErrStateSchemaTooNew, from, target)
+		}
+
+		version := from
+		for _, migration := range migrations {
+			if migration.Version <= from || migration.Version > target {
+				continue
+			}
+			if migration.Version != version+1 {
+				return fmt.Errorf("no state migration to schema version %d", version+1)
+			}
+			if err := migration.Migrate(tx); err != nil {
+				return fmt.Errorf("state migration to schema version %d (%s) failed: %w", migration.Version, migration.Description, err)
+			}
+			applied = append(applied, migration)
+			version = migration.Version
+		}
+		if version != target {
+			return fmt.Errorf("no state migration to schema version %d", version+1)
+		}
+		return putSchemaVersion(tx, target)
+	})
+	if err != nil {
+		return from, nil, err
+	}
+	return from, applied, nil
+}
+
+// migrateJobs rewrites every stored job for which fn reports a change
+func migrateJobs(tx *bolt.Tx, fn func(job *JobData) bool) error {
+	bucket := tx.Bucket(jobsBucket)
+	changed := make(map[string]*JobData)
+	if err := bucket.ForEach(func(k, v []byte) error {
+		var job JobData
+		if err := json.Unmarshal(v, &job); err != nil {
+			return fmt.Errorf("failed to unmarshal job %s: %w", k, err)
+		}
+		if fn(&job) {
+			changed[string(k)] = &job
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Records are written after iterating, bbolt does not allow changing a bucket during ForEach
+	for jobID, job := range changed {
+		if err := putRecord(tx, jobsBucket, jobID, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
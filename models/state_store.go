@@ -0,0 +1,290 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// StateFileName is the embedded database holding the agent's persisted state
+	StateFileName = "state.db"
+	// LegacyStateFileName is the JSON state file used before the embedded database.
+	// It is migrated into StateFileName once and then renamed with a ".migrated" suffix.
+	LegacyStateFileName = "state.json"
+
+	// stateStoreOpenTimeout bounds how long opening the store waits for another process
+	// holding it, e.g. a second agent sharing the config directory
+	stateStoreOpenTimeout = 5 * time.Second
+)
+
+var (
+	metaBucket              = []byte("meta")
+	jobsBucket              = []byte("jobs")
+	queuedMessagesBucket    = []byte("queued_messages")
+	processedMessagesBucket = []byte("processed_messages")
+
+	agentIDKey       = []byte("agent_id")
+	schemaVersionKey = []byte("schema_version")
+)
+
+// ErrStateLocked is returned when another process holds the state store
+var ErrStateLocked = errors.New("state store is locked by another eksecd process")
+
+// ErrLegacyStateCorrupt is returned when the legacy JSON state file cannot be parsed
+var ErrLegacyStateCorrupt = errors.New("legacy state file is corrupt")
+
+// stateStore persists state records in an embedded bbolt database. Every write is its own
+// transaction that only touches the changed records and is fsynced on commit.
+type stateStore struct {
+	db *bolt.DB
+}
+
+// openStateStore opens or creates the state database at path
+func openStateStore(path string) (*stateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	_, statErr := os.Stat(path)
+	isNew := os.IsNotExist(statErr)
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: stateStoreOpenTimeout})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return nil, fmt.Errorf("%w: %s (set EKSEC_CONFIG_DIR to give each agent its own config directory)", ErrStateLocked, path)
+		}
+		return nil, fmt.Errorf("failed to open state store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{metaBucket, jobsBucket, queuedMessagesBucket, processedMessagesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+			}
+		}
+		// A new store starts at the current schema; existing stores are upgraded by MigrateState
+		if isNew {
+			return putSchemaVersion(tx, CurrentStateSchemaVersion)
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &stateStore{db: db}, nil
+}
+
+// close closes the database
+func (s *stateStore) close() error {
+	return s.db.Close()
+}
+
+// update runs fn in a single read-write transaction
+func (s *stateStore) update(fn func(tx *bolt.Tx) error) error {
+	return s.db.Update(fn)
+}
+
+// putRecord stores value as JSON under key in bucket
+func putRecord(tx *bolt.Tx, bucket []byte, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s record %s: %w", bucket, key, err)
+	}
+	if err := tx.Bucket(bucket).Put([]byte(key), data); err != nil {
+		return fmt.Errorf("failed to write %s record %s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// deleteRecord removes key from bucket
+func deleteRecord(tx *bolt.Tx, bucket []byte, key string) error {
+	if err := tx.Bucket(bucket).Delete([]byte(key)); err != nil {
+		return fmt.Errorf("failed to delete %s record %s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// deleteRecords removes keys from bucket
+func deleteRecords(tx *bolt.Tx, bucket []byte, keys []string) error {
+	for _, key := range keys {
+		if err := deleteRecord(tx, bucket, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getSchemaVersion returns the stored schema version. Stores written before versioning
+// have none and are version 0.
+func getSchemaVersion(tx *bolt.Tx) (int, error) {
+	value := tx.Bucket(metaBucket).Get(schemaVersionKey)
+	if value == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q: %w", value, err)
+	}
+	return version, nil
+}
+
+// putSchemaVersion stores the schema version
+func putSchemaVersion(tx *bolt.Tx, version int) error {
+	if err := tx.Bucket(metaBucket).Put(schemaVersionKey, []byte(strconv.Itoa(version))); err != nil {
+		return fmt.Errorf("failed to write schema version: %w", err)
+	}
+	return nil
+}
+
+// putAgentID stores the agent ID
+func (s *stateStore) putAgentID(agentID string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(agentIDKey, []byte(agentID))
+	})
+}
+
+// writeAll replaces the stored state with state in one transaction
+func (s *stateStore) writeAll(state PersistedState) error {
+	return s.update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{jobsBucket, queuedMessagesBucket, processedMessagesBucket} {
+			if err := tx.DeleteBucket(bucket); err != nil {
+				return fmt.Errorf("failed to clear bucket %s: %w", bucket, err)
+			}
+			if _, err := tx.CreateBucket(bucket); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+			}
+		}
+		if err := tx.Bucket(metaBucket).Put(agentIDKey, []byte(state.AgentID)); err != nil {
+			return fmt.Errorf("failed to write agent ID: %w", err)
+		}
+		if err := putSchemaVersion(tx, state.SchemaVersion); err != nil {
+			return err
+		}
+		for jobID, job := range state.Jobs {
+			if err := putRecord(tx, jobsBucket, jobID, job); err != nil {
+				return err
+			}
+		}
+		for id, msg := range state.QueuedMessages {
+			if err := putRecord(tx, queuedMessagesBucket, id, msg); err != nil {
+				return err
+			}
+		}
+		for id, msg := range state.ProcessedMessages {
+			if err := putRecord(tx, processedMessagesBucket, id, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// load reads the whole stored state
+func (s *stateStore) load() (state *PersistedState, err error) {
+	// bbolt panics on some kinds of page corruption; report it as an unreadable store
+	defer func() {
+		if r := recover(); r != nil {
+			state = nil
+			err = fmt.Errorf("state store is corrupt: %v", r)
+		}
+	}()
+
+	state = &PersistedState{
+		Jobs:              make(map[string]*JobData),
+		QueuedMessages:    make(map[string]*QueuedMessage),
+		ProcessedMessages: make(map[string]*ProcessedMessage),
+	}
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		state.AgentID = string(tx.Bucket(metaBucket).Get(agentIDKey))
+		version, err := getSchemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		state.SchemaVersion = version
+
+		if err := tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var job JobData
+			if err := json.Unmarshal(v, &job); err != nil {
+				return fmt.Errorf("failed to unmarshal job %s: %w", k, err)
+			}
+			state.Jobs[string(k)] = &job
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(queuedMessagesBucket).ForEach(func(k, v []byte) error {
+			var msg QueuedMessage
+			if err := json.Unmarshal(v, &msg); err != nil {
+				return fmt.Errorf("failed to unmarshal queued message %s: %w", k, err)
+			}
+			state.QueuedMessages[string(k)] = &msg
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return tx.Bucket(processedMessagesBucket).ForEach(func(k, v []byte) error {
+			var msg ProcessedMessage
+			if err := json.Unmarshal(v, &msg); err != nil {
+				return fmt.Errorf("failed to unmarshal processed message %s: %w", k, err)
+			}
+			state.ProcessedMessages[string(k)] = &msg
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// MigrateJSONState moves state from the legacy JSON file into the state database. It only
+// runs when the database does not exist yet, and renames the JSON file afterwards so the
+// migration happens once. Returns true if state was migrated.
+func MigrateJSONState(jsonPath, statePath string) (bool, error) {
+	if _, err := os.Stat(statePath); err == nil {
+		return false, nil
+	}
+	data, err := os.ReadFile(jsonPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read legacy state file: %w", err)
+	}
+
+	var state PersistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrLegacyStateCorrupt, err)
+	}
+
+	store, err := openStateStore(statePath)
+	if err != nil {
+		return false, err
+	}
+	if err := store.writeAll(state); err != nil {
+		store.close()
+		os.Remove(statePath)
+		return false, fmt.Errorf("failed to write migrated state: %w", err)
+	}
+	if err := store.close(); err != nil {
+		return false, fmt.Errorf("failed to close state store: %w", err)
+	}
+
+	if err := os.Rename(jsonPath, jsonPath+".migrated"); err != nil {
+		return true, fmt.Errorf("failed to rename migrated legacy state file: %w", err)
+	}
+	return true, nil
+}
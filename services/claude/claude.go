@@ -20,6 +20,8 @@ type ClaudeService struct {
 	model           string
 	agentsApiClient *clients.AgentsApiClient
 	envManager      EnvManager
+	jobID           string                // Set on copies returned by ForJob
+	onToolActivity  func(activity string) // Set on copies returned by WithToolActivityCallback
 }
 
 // EnvManager defines the interface for environment variable management
@@ -118,6 +120,19 @@ func (c *ClaudeService) mergeOptions(options *clients.ClaudeOptions) *clients.Cl
 		*merged = *options
 	}
 
+	if merged.JobID == "" {
+		merged.JobID = c.jobID
+	}
+
+	if merged.OnOutputLine == nil && c.onToolActivity != nil {
+		onToolActivity := c.onToolActivity
+		merged.OnOutputLine = func(line string) {
+			if activity := services.ToolActivityFromStreamLine(line); activity != "" {
+				onToolActivity(activity)
+			}
+		}
+	}
+
 	// Only set model if not already specified in options and service has a model
 	if merged.Model == "" && c.model != "" {
 		merged.Model = c.model
@@ -185,8 +200,9 @@ func (c *ClaudeService) StartNewConversationWithOptions(
 	log.Info("Parsed %d messages from StartNewConversation", len(messages))
 	log.Info("📋 Claude response extracted successfully, session: %s, output length: %d", sessionID, len(output))
 	result := &services.CLIAgentResult{
-		Output:    output,
-		SessionID: sessionID,
+		Output:           output,
+		SessionID:        sessionID,
+		LastToolActivity: services.ExtractLastToolActivity(messages),
 	}
 
 	log.Info("📋 Completed successfully - started new Claude conversation with session: %s", sessionID)
@@ -293,8 +309,9 @@ func (c *ClaudeService) ContinueConversationWithOptions(
 	log.Info("Parsed %d messages from ContinueConversation", len(messages))
 	log.Info("📋 Claude response extracted successfully, session: %s, output length: %d", actualSessionID, len(output))
 	result := &services.CLIAgentResult{
-		Output:    output,
-		SessionID: actualSessionID,
+		Output:           output,
+		SessionID:        actualSessionID,
+		LastToolActivity: services.ExtractLastToolActivity(messages),
 	}
 
 	log.Info("📋 Completed successfully - continued Claude conversation with session: %s", actualSessionID)
@@ -470,6 +487,17 @@ func (c *ClaudeService) handleClaudeClientError(err error, operation string) err
 		return fmt.Errorf("%s: %w", operation, err)
 	}
 
+	// An interrupted turn is not a failure - recover the session ID so it can be resumed
+	if _, interrupted := core.IsAgentInterrupted(err); interrupted {
+		messages, _ := services.MapClaudeOutputToMessages(claudeErr.Output)
+		sessionID := c.extractSessionID(messages)
+		if sessionID == "unknown" {
+			sessionID = ""
+		}
+		log.Info("🛑 Claude turn interrupted, session: %s", sessionID)
+		return &core.ErrAgentInterrupted{SessionID: sessionID}
+	}
+
 	// Try to parse the output as Claude messages using internal parsing
 	messages, parseErr := services.MapClaudeOutputToMessages(claudeErr.Output)
 	if parseErr != nil {
@@ -541,6 +569,31 @@ func (c *ClaudeService) handleClaudeClientError(err error, operation string) err
 	return fmt.Errorf("%s: %w", operation, err)
 }
 
+// ForJob returns a copy of the service whose agent processes are registered under jobID
+func (c *ClaudeService) ForJob(jobID string) services.CLIAgent {
+	bound := *c
+	bound.jobID = jobID
+	return &bound
+}
+
+// WithToolActivityCallback returns a copy of the service that reports each tool the agent
+// starts using while its turn runs
+func (c *ClaudeService) WithToolActivityCallback(onToolActivity func(activity string)) services.CLIAgent {
+	bound := *c
+	bound.onToolActivity = onToolActivity
+	return &bound
+}
+
+// WithModel returns a copy of the service that uses model, or the service itself if model is empty
+func (c *ClaudeService) WithModel(model string) services.CLIAgent {
+	if model == "" {
+		return c
+	}
+	overridden := *c
+	overridden.model = model
+	return &overridden
+}
+
 // AgentName identifies this service implementation
 func (c *ClaudeService) AgentName() string {
 	return "claude"
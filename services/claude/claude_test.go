@@ -1080,3 +1080,67 @@ func TestClaudeService_WriteErrorLogHandling(t *testing.T) {
 
 	// Mock verification not needed with function-based mocks
 }
+
+func TestClaudeService_ForJob(t *testing.T) {
+	var receivedOptions *clients.ClaudeOptions
+	mockClient := &services.MockClaudeClient{
+		ContinueSessionFunc: func(sessionID, prompt string, options *clients.ClaudeOptions) (string, error) {
+			receivedOptions = options
+			return `{"type":"assistant","message":{"id":"msg_1","type":"message","content":[{"type":"text","text":"done"}]},"session_id":"session_123"}`, nil
+		},
+	}
+	service := NewClaudeService(mockClient, t.TempDir(), "", nil, nil)
+
+	if _, err := service.ForJob("job-1").ContinueConversationInDir("session_123", "hi", "/tmp/worktree"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if receivedOptions == nil || receivedOptions.JobID != "job-1" || receivedOptions.WorkDir != "/tmp/worktree" {
+		t.Errorf("Expected options bound to job-1 in /tmp/worktree, got %+v", receivedOptions)
+	}
+	if service.jobID != "" {
+		t.Errorf("Expected original service to stay unbound, got job %q", service.jobID)
+	}
+}
+
+func TestClaudeService_WithModel(t *testing.T) {
+	var receivedOptions *clients.ClaudeOptions
+	mockClient := &services.MockClaudeClient{
+		ContinueSessionFunc: func(sessionID, prompt string, options *clients.ClaudeOptions) (string, error) {
+			receivedOptions = options
+			return `{"type":"assistant","message":{"id":"msg_1","type":"message","content":[{"type":"text","text":"done"}]},"session_id":"session_123"}`, nil
+		},
+	}
+	service := NewClaudeService(mockClient, t.TempDir(), "sonnet", nil, nil)
+
+	if service.WithModel("") != service {
+		t.Errorf("Expected empty model to keep the service")
+	}
+	if _, err := service.WithModel("haiku").ContinueConversation("session_123", "hi"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if receivedOptions == nil || receivedOptions.Model != "haiku" {
+		t.Errorf("Expected model override haiku, got %+v", receivedOptions)
+	}
+	if service.model != "sonnet" {
+		t.Errorf("Expected original service to keep its model, got %q", service.model)
+	}
+}
+
+func TestClaudeService_handleClaudeClientError_Interrupted(t *testing.T) {
+	service := NewClaudeService(&services.MockClaudeClient{}, t.TempDir(), "", nil, nil)
+
+	partialOutput := `{"type":"system","subtype":"init","session_id":"session_partial"}
+{"type":"assistant","message":{"id":"msg_1","type":"message","content":[{"type":"text","text":"Working on it"}]},"session_id":"session_partial"}`
+	err := service.handleClaudeClientError(&core.ErrClaudeCommandErr{
+		Err:    &core.ErrAgentInterrupted{},
+		Output: partialOutput,
+	}, "failed to continue Claude session")
+
+	interruptedErr, ok := core.IsAgentInterrupted(err)
+	if !ok {
+		t.Fatalf("Expected interrupted error, got %v", err)
+	}
+	if interruptedErr.SessionID != "session_partial" {
+		t.Errorf("Expected session ID session_partial, got %q", interruptedErr.SessionID)
+	}
+}
@@ -175,6 +175,60 @@ func MapClaudeOutputToMessagesFromReader(r io.Reader) ([]ClaudeMessage, error) {
 	return messages, nil
 }
 
+// ExtractLastToolActivity returns a short human-readable summary of the last tool_use
+// in the given messages (e.g. "Edit handlers/messages.go"), or "" if no tool was used.
+func ExtractLastToolActivity(messages []ClaudeMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		assistantMsg, ok := messages[i].(AssistantMessage)
+		if !ok {
+			continue
+		}
+		for j := len(assistantMsg.Message.Content) - 1; j >= 0; j-- {
+			var contentItem struct {
+				Type  string         `json:"type"`
+				Name  string         `json:"name"`
+				Input map[string]any `json:"input"`
+			}
+			if err := json.Unmarshal(assistantMsg.Message.Content[j], &contentItem); err != nil {
+				continue
+			}
+			if contentItem.Type != "tool_use" || contentItem.Name == "" {
+				continue
+			}
+			return formatToolActivity(contentItem.Name, contentItem.Input)
+		}
+	}
+	return ""
+}
+
+// ToolActivityFromStreamLine returns the summary of the last tool_use in one line of
+// stream-json output, or "" if the line is not an assistant message using a tool
+func ToolActivityFromStreamLine(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return ""
+	}
+	return ExtractLastToolActivity([]ClaudeMessage{parseClaudeMessage([]byte(line))})
+}
+
+// formatToolActivity renders a tool name together with its most descriptive input field
+func formatToolActivity(name string, input map[string]any) string {
+	const maxDetailLength = 120
+
+	for _, key := range []string{"file_path", "command", "pattern", "url", "description", "path"} {
+		value, ok := input[key].(string)
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		detail := strings.Join(strings.Fields(value), " ")
+		if runes := []rune(detail); len(runes) > maxDetailLength {
+			detail = string(runes[:maxDetailLength]) + "..."
+		}
+		return name + " " + detail
+	}
+	return name
+}
+
 // isExitPlanModeMessage checks if an assistant message contains ExitPlanMode tool use
 func isExitPlanModeMessage(lineBytes []byte) bool {
 	var tempMsg struct {
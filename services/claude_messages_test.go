@@ -735,3 +735,65 @@ func TestInputWithoutTrailingNewline(t *testing.T) {
 		t.Errorf("Expected second message type 'result', got '%s'", messages[1].GetType())
 	}
 }
+
+func TestExtractLastToolActivity(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no tool use",
+			input:    `{"type":"assistant","message":{"id":"msg_01","type":"message","content":[{"type":"text","text":"Hello"}]},"session_id":"s1"}`,
+			expected: "",
+		},
+		{
+			name: "returns last tool use with file path",
+			input: `{"type":"assistant","message":{"id":"msg_01","type":"message","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"README.md"}}]},"session_id":"s1"}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},"session_id":"s1"}
+{"type":"assistant","message":{"id":"msg_02","type":"message","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"main.go","old_string":"a","new_string":"b"}}]},"session_id":"s1"}
+{"type":"assistant","message":{"id":"msg_03","type":"message","content":[{"type":"text","text":"Done"}]},"session_id":"s1"}`,
+			expected: "Edit main.go",
+		},
+		{
+			name:     "collapses whitespace in commands",
+			input:    `{"type":"assistant","message":{"id":"msg_01","type":"message","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test\n  ./..."}}]},"session_id":"s1"}`,
+			expected: "Bash go test ./...",
+		},
+		{
+			name:     "tool without known input fields",
+			input:    `{"type":"assistant","message":{"id":"msg_01","type":"message","content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{"todos":[]}}]},"session_id":"s1"}`,
+			expected: "TodoWrite",
+		},
+		{
+			name:     "truncates long details on rune boundaries",
+			input:    `{"type":"assistant","message":{"id":"msg_01","type":"message","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"echo ` + strings.Repeat("é", 130) + `"}}]},"session_id":"s1"}`,
+			expected: "Bash echo " + strings.Repeat("é", 115) + "...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := MapClaudeOutputToMessages(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			result := ExtractLastToolActivity(messages)
+			if result != tt.expected {
+				t.Errorf("ExtractLastToolActivity() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToolActivityFromStreamLine(t *testing.T) {
+	line := `{"type":"assistant","message":{"id":"msg_01","type":"message","content":[{"type":"tool_use","id":"t1","name":"Grep","input":{"pattern":"TODO"}}]},"session_id":"s1"}`
+	if activity := ToolActivityFromStreamLine(line); activity != "Grep TODO" {
+		t.Errorf("expected the tool of the line, got %q", activity)
+	}
+	for _, line := range []string{"", "not json", `{"type":"result","result":"done","session_id":"s1"}`} {
+		if activity := ToolActivityFromStreamLine(line); activity != "" {
+			t.Errorf("expected no tool for %q, got %q", line, activity)
+		}
+	}
+}
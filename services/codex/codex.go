@@ -17,6 +17,7 @@ type CodexService struct {
 	codexClient clients.CodexClient
 	logDir      string
 	model       string
+	jobID       string // Set on copies returned by ForJob
 }
 
 func NewCodexService(codexClient clients.CodexClient, logDir, model string) *CodexService {
@@ -98,7 +99,7 @@ func (c *CodexService) StartNewConversation(prompt string) (*services.CLIAgentRe
 	return c.StartNewConversationWithOptions(prompt, nil)
 }
 
-// deriveCodexOptions creates a final options struct, applying service model if set
+// deriveCodexOptions creates a final options struct, applying service model and job if set
 func (c *CodexService) deriveCodexOptions(options *clients.CodexOptions) *clients.CodexOptions {
 	finalOptions := options
 	if c.model != "" {
@@ -110,9 +111,19 @@ func (c *CodexService) deriveCodexOptions(options *clients.CodexOptions) *client
 				Model:     c.model, // Service model takes precedence
 				Sandbox:   finalOptions.Sandbox,
 				WebSearch: finalOptions.WebSearch,
+				JobID:     finalOptions.JobID,
 			}
 		}
 	}
+	if c.jobID != "" {
+		// Copy so the job binding never leaks into the caller's options
+		withJob := clients.CodexOptions{}
+		if finalOptions != nil {
+			withJob = *finalOptions
+		}
+		withJob.JobID = c.jobID
+		finalOptions = &withJob
+	}
 	return finalOptions
 }
 
@@ -274,6 +285,17 @@ func (c *CodexService) handleCodexClientError(err error, operation string) error
 		return fmt.Errorf("%s: %w", operation, err)
 	}
 
+	// An interrupted turn is not a failure - recover the session ID so it can be resumed
+	if _, interrupted := core.IsAgentInterrupted(err); interrupted {
+		messages, _ := MapCodexOutputToMessages(claudeErr.Output)
+		sessionID := ExtractCodexThreadID(messages)
+		if sessionID == "unknown" {
+			sessionID = ""
+		}
+		log.Info("🛑 Codex turn interrupted, session: %s", sessionID)
+		return &core.ErrAgentInterrupted{SessionID: sessionID}
+	}
+
 	// Try to parse the output as Codex messages using internal parsing
 	messages, parseErr := MapCodexOutputToMessages(claudeErr.Output)
 	if parseErr != nil {
@@ -294,6 +316,23 @@ func (c *CodexService) handleCodexClientError(err error, operation string) error
 	return fmt.Errorf("%s: %w", operation, err)
 }
 
+// ForJob returns a copy of the service whose agent processes are registered under jobID
+func (c *CodexService) ForJob(jobID string) services.CLIAgent {
+	bound := *c
+	bound.jobID = jobID
+	return &bound
+}
+
+// WithModel returns a copy of the service that uses model, or the service itself if model is empty
+func (c *CodexService) WithModel(model string) services.CLIAgent {
+	if model == "" {
+		return c
+	}
+	overridden := *c
+	overridden.model = model
+	return &overridden
+}
+
 // AgentName identifies this service implementation
 func (c *CodexService) AgentName() string {
 	return "codex"
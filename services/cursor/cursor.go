@@ -17,6 +17,7 @@ type CursorService struct {
 	cursorClient clients.CursorClient
 	logDir       string
 	model        string
+	jobID        string // Set on copies returned by ForJob
 }
 
 func NewCursorService(cursorClient clients.CursorClient, logDir, model string) *CursorService {
@@ -98,7 +99,7 @@ func (c *CursorService) StartNewConversation(prompt string) (*services.CLIAgentR
 	return c.StartNewConversationWithOptions(prompt, nil)
 }
 
-// deriveCursorOptions creates a final options struct, applying service model if set
+// deriveCursorOptions creates a final options struct, applying service model and job if set
 func (c *CursorService) deriveCursorOptions(options *clients.CursorOptions) *clients.CursorOptions {
 	finalOptions := options
 	if c.model != "" {
@@ -109,9 +110,19 @@ func (c *CursorService) deriveCursorOptions(options *clients.CursorOptions) *cli
 			finalOptions = &clients.CursorOptions{
 				SystemPrompt: finalOptions.SystemPrompt,
 				Model:        c.model, // Service model takes precedence
+				JobID:        finalOptions.JobID,
 			}
 		}
 	}
+	if c.jobID != "" {
+		// Copy so the job binding never leaks into the caller's options
+		withJob := clients.CursorOptions{}
+		if finalOptions != nil {
+			withJob = *finalOptions
+		}
+		withJob.JobID = c.jobID
+		finalOptions = &withJob
+	}
 	return finalOptions
 }
 
@@ -268,6 +279,17 @@ func (c *CursorService) handleCursorClientError(err error, operation string) err
 		return fmt.Errorf("%s: %w", operation, err)
 	}
 
+	// An interrupted turn is not a failure - recover the session ID so it can be resumed
+	if _, interrupted := core.IsAgentInterrupted(err); interrupted {
+		messages, _ := MapCursorOutputToMessages(claudeErr.Output)
+		sessionID := ExtractCursorSessionID(messages)
+		if sessionID == "unknown" {
+			sessionID = ""
+		}
+		log.Info("🛑 Cursor turn interrupted, session: %s", sessionID)
+		return &core.ErrAgentInterrupted{SessionID: sessionID}
+	}
+
 	// Try to parse the output as Cursor messages using internal parsing
 	messages, parseErr := MapCursorOutputToMessages(claudeErr.Output)
 	if parseErr != nil {
@@ -291,6 +313,23 @@ func (c *CursorService) handleCursorClientError(err error, operation string) err
 	return fmt.Errorf("%s: %w", operation, err)
 }
 
+// ForJob returns a copy of the service whose agent processes are registered under jobID
+func (c *CursorService) ForJob(jobID string) services.CLIAgent {
+	bound := *c
+	bound.jobID = jobID
+	return &bound
+}
+
+// WithModel returns a copy of the service that uses model, or the service itself if model is empty
+func (c *CursorService) WithModel(model string) services.CLIAgent {
+	if model == "" {
+		return c
+	}
+	overridden := *c
+	overridden.model = model
+	return &overridden
+}
+
 // AgentName identifies this service implementation
 func (c *CursorService) AgentName() string {
 	return "cursor"
@@ -17,6 +17,7 @@ type OpenCodeService struct {
 	openCodeClient clients.OpenCodeClient
 	logDir         string
 	model          string
+	jobID          string // Set on copies returned by ForJob
 }
 
 func NewOpenCodeService(openCodeClient clients.OpenCodeClient, logDir, model string) *OpenCodeService {
@@ -98,11 +99,11 @@ func (o *OpenCodeService) StartNewConversation(prompt string) (*services.CLIAgen
 	return o.StartNewConversationWithOptions(prompt, nil)
 }
 
-// deriveOpenCodeOptions creates a final options struct, applying service model if set
+// deriveOpenCodeOptions creates a final options struct, applying service model and job if set
 func (o *OpenCodeService) deriveOpenCodeOptions(options *clients.OpenCodeOptions) *clients.OpenCodeOptions {
 	if options == nil {
-		if o.model != "" {
-			return &clients.OpenCodeOptions{Model: o.model}
+		if o.model != "" || o.jobID != "" {
+			return &clients.OpenCodeOptions{Model: o.model, JobID: o.jobID}
 		}
 		return nil
 	}
@@ -110,6 +111,10 @@ func (o *OpenCodeService) deriveOpenCodeOptions(options *clients.OpenCodeOptions
 	// Create a copy to avoid modifying the original, preserving WorkDir
 	finalOptions := &clients.OpenCodeOptions{
 		WorkDir: options.WorkDir,
+		JobID:   options.JobID,
+	}
+	if o.jobID != "" {
+		finalOptions.JobID = o.jobID
 	}
 
 	// Apply service model if set, otherwise use options model
@@ -287,6 +292,17 @@ func (o *OpenCodeService) handleOpenCodeClientError(err error, operation string)
 		return fmt.Errorf("%s: %w", operation, err)
 	}
 
+	// An interrupted turn is not a failure - recover the session ID so it can be resumed
+	if _, interrupted := core.IsAgentInterrupted(err); interrupted {
+		messages, _ := MapOpenCodeOutputToMessages(claudeErr.Output)
+		sessionID := ExtractOpenCodeSessionID(messages)
+		if sessionID == "unknown" {
+			sessionID = ""
+		}
+		log.Info("🛑 OpenCode turn interrupted, session: %s", sessionID)
+		return &core.ErrAgentInterrupted{SessionID: sessionID}
+	}
+
 	// Try to parse the output as OpenCode messages using internal parsing
 	messages, parseErr := MapOpenCodeOutputToMessages(claudeErr.Output)
 	if parseErr != nil {
@@ -307,6 +323,23 @@ func (o *OpenCodeService) handleOpenCodeClientError(err error, operation string)
 	return fmt.Errorf("%s: %w", operation, err)
 }
 
+// ForJob returns a copy of the service whose agent processes are registered under jobID
+func (o *OpenCodeService) ForJob(jobID string) services.CLIAgent {
+	bound := *o
+	bound.jobID = jobID
+	return &bound
+}
+
+// WithModel returns a copy of the service that uses model, or the service itself if model is empty
+func (o *OpenCodeService) WithModel(model string) services.CLIAgent {
+	if model == "" {
+		return o
+	}
+	overridden := *o
+	overridden.model = model
+	return &overridden
+}
+
 // AgentName identifies this service implementation
 func (o *OpenCodeService) AgentName() string {
 	return "opencode"
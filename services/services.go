@@ -2,8 +2,9 @@ package services
 
 // CLIAgentResult represents the result of a CLI agent conversation
 type CLIAgentResult struct {
-	Output    string
-	SessionID string
+	Output           string
+	SessionID        string
+	LastToolActivity string // Summary of the last tool the agent used, empty if unknown
 }
 
 // CLIAgent defines the interface for CLI agent operations like Claude Code, Cursor, etc.
@@ -32,7 +33,23 @@ type CLIAgent interface {
 	// CleanupOldLogs removes old log files based on age
 	CleanupOldLogs(maxAgeDays int) error
 
+	// ForJob returns an agent whose processes are registered under the job ID,
+	// so its running turn can be interrupted with clients.InterruptAgentTurn
+	ForJob(jobID string) CLIAgent
+
+	// WithModel returns an agent that uses model instead of the configured one.
+	// An empty model keeps the configured model.
+	WithModel(model string) CLIAgent
+
 	// AgentName returns the identifier for the concrete agent implementation
 	// (e.g., "claude" or "cursor") so callers can adapt behavior per agent
 	AgentName() string
 }
+
+// ToolActivityReporter is implemented by agents that report the tools they use while a turn
+// is still running
+type ToolActivityReporter interface {
+	// WithToolActivityCallback returns an agent that calls onToolActivity with a summary of
+	// each tool as the agent starts using it (e.g. "Edit handlers/messages.go")
+	WithToolActivityCallback(onToolActivity func(activity string)) CLIAgent
+}
@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lucasepe/codename"
+
+	"eksecd/core/log"
+)
+
+const (
+	// JobBranchPrefix starts the name of every branch eksecd creates for a job. Stale branch
+	// cleanup only considers branches with this prefix.
+	JobBranchPrefix = "eksecd"
+	// poolBranchPrefix names the branches of pre-created pool worktrees
+	poolBranchPrefix = JobBranchPrefix + "/pool-ready-"
+
+	// DefaultBranchNameTemplate is the adjective-noun-timestamp name eksecd has always used
+	DefaultBranchNameTemplate = "{{.Prefix}}/{{.Codename}}-{{.Timestamp}}"
+
+	maxBranchNameLength = 100
+	maxSlugWords        = 6
+	maxSlugLength       = 40
+)
+
+var (
+	// ticketKeyPattern matches issue keys such as PROJ-123 (Jira, Linear, YouTrack)
+	ticketKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]{1,9}-[0-9]+\b`)
+	// slugNoisePattern matches chat mentions, channel links and URLs, which make poor slugs
+	slugNoisePattern = regexp.MustCompile(`<[^>]*>|https?://\S+|@\S+`)
+	slugWordPattern  = regexp.MustCompile(`[a-z0-9]+`)
+	// invalidRefChars are characters not allowed in a branch name by git check-ref-format,
+	// plus characters that need quoting in shells
+	invalidRefChars = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+)
+
+// BranchNameData are the fields available to a branch name template
+type BranchNameData struct {
+	Prefix     string // Always "eksecd"
+	TicketKey  string // First issue key in the request (e.g. "PROJ-123"), empty if none
+	Slug       string // First words of the request, e.g. "fix-login-redirect"
+	ShortJobID string // Last 8 characters of the job ID
+	Codename   string // Random adjective-noun pair
+	Timestamp  string // Creation time, e.g. "20060102-150405"
+}
+
+// ParseBranchNameTemplate parses a text/template branch name template and checks that it
+// renders a usable name that differs between jobs. Two jobs with the same request must
+// not get the same branch, so the name has to use .ShortJobID, .Timestamp or .Codename.
+func ParseBranchNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("branch").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse branch name template: %w", err)
+	}
+
+	sample := BranchNameData{
+		Prefix: JobBranchPrefix, TicketKey: "PROJ-1", Slug: "sample", ShortJobID: "abcd1234",
+		Codename: "sample-name", Timestamp: "20060102-150405",
+	}
+	other := sample
+	other.ShortJobID, other.Codename, other.Timestamp = "efgh5678", "other-name", "20070203-160506"
+
+	var names []string
+	for _, data := range []BranchNameData{sample, other} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render branch name template: %w", err)
+		}
+		names = append(names, sanitizeBranchName(buf.String(), data))
+	}
+	if names[0] == names[1] {
+		return nil, fmt.Errorf("branch name template must use .ShortJobID, .Timestamp or .Codename so job branches are unique")
+	}
+	return tmpl, nil
+}
+
+// SetBranchNameTemplate sets the template job branch names are rendered from
+func (g *GitUseCase) SetBranchNameTemplate(tmpl *template.Template) {
+	g.branchNameTemplate = tmpl
+}
+
+// generateBranchName renders the job's branch name from the template, using the first
+// message of the job for the ticket key and slug
+func (g *GitUseCase) generateBranchName(jobID, conversationHint string) (string, error) {
+	log.Info("🎲 Generating branch name")
+
+	rng, err := codename.DefaultRNG()
+	if err != nil {
+		return "", fmt.Errorf("failed to create random generator: %w", err)
+	}
+	data := BranchNameData{
+		Prefix:     JobBranchPrefix,
+		TicketKey:  ticketKeyPattern.FindString(conversationHint),
+		Slug:       branchSlug(conversationHint),
+		ShortJobID: shortJobID(jobID),
+		Codename:   codename.Generate(rng, 0),
+		Timestamp:  time.Now().Format("20060102-150405"),
+	}
+
+	tmpl := g.branchNameTemplate
+	if tmpl == nil {
+		tmpl = template.Must(ParseBranchNameTemplate(DefaultBranchNameTemplate))
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render branch name: %w", err)
+	}
+
+	branchName := sanitizeBranchName(buf.String(), data)
+	log.Info("🎲 Generated branch name: %s", branchName)
+	return branchName, nil
+}
+
+// branchSlug turns the first words of a message into a lowercase, dash-separated slug
+func branchSlug(message string) string {
+	message = slugNoisePattern.ReplaceAllString(message, " ")
+	message = ticketKeyPattern.ReplaceAllString(message, " ")
+
+	var slug string
+	for i, word := range slugWordPattern.FindAllString(strings.ToLower(message), maxSlugWords) {
+		next := slug + "-" + word
+		if i == 0 {
+			next = word
+		}
+		if len(next) > maxSlugLength {
+			if i == 0 {
+				slug = word[:maxSlugLength]
+			}
+			break
+		}
+		slug = next
+	}
+	return slug
+}
+
+// shortJobID returns the last 8 characters of the job ID, which are random in ULID-based IDs
+func shortJobID(jobID string) string {
+	id := strings.ToLower(jobID[strings.LastIndex(jobID, "_")+1:])
+	if len(id) > 8 {
+		return id[len(id)-8:]
+	}
+	return id
+}
+
+// sanitizeBranchName makes a rendered name a valid git ref under the job branch prefix. A
+// name left empty after the prefix falls back to the codename and timestamp.
+func sanitizeBranchName(name string, data BranchNameData) string {
+	name = invalidRefChars.ReplaceAllString(name, "-")
+
+	var components []string
+	for _, component := range strings.Split(name, "/") {
+		for strings.Contains(component, "..") {
+			component = strings.ReplaceAll(component, "..", ".")
+		}
+		for strings.Contains(component, "--") {
+			component = strings.ReplaceAll(component, "--", "-")
+		}
+		component = strings.TrimLeft(component, ".-")
+		for {
+			trimmed := strings.TrimSuffix(strings.TrimRight(component, ".-"), ".lock")
+			if trimmed == component {
+				break
+			}
+			component = trimmed
+		}
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+
+	// Keep every job branch under the prefix, and out of the pool's namespace
+	if len(components) > 0 && components[0] == JobBranchPrefix {
+		components = components[1:]
+	}
+	rest := strings.Join(components, "/")
+	if rest == "" {
+		rest = data.Codename + "-" + data.Timestamp
+	}
+	branchName := JobBranchPrefix + "/" + rest
+	if strings.HasPrefix(branchName, poolBranchPrefix) {
+		branchName = JobBranchPrefix + "/job-" + rest
+	}
+
+	if len(branchName) > maxBranchNameLength {
+		branchName = strings.TrimRight(branchName[:maxBranchNameLength], "./-")
+		branchName = strings.TrimSuffix(branchName, ".lock")
+	}
+	return branchName
+}
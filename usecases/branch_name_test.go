@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBranchSlug(t *testing.T) {
+	cases := map[string]string{
+		"Fix the login redirect loop on Safari please":            "fix-the-login-redirect-loop-on",
+		"<@U123> PROJ-42: add CSV export to reports":              "add-csv-export-to-reports",
+		"Look at https://example.com/issue/7 and update the docs": "look-at-and-update-the-docs",
+		"¿Qué pasa?":                   "qu-pasa",
+		"":                             "",
+		strings.Repeat("a", 60) + " b": strings.Repeat("a", 40),
+	}
+	for message, expected := range cases {
+		if slug := branchSlug(message); slug != expected {
+			t.Errorf("%q: expected slug %q, got %q", message, expected, slug)
+		}
+	}
+}
+
+func TestGenerateBranchName(t *testing.T) {
+	tmpl, err := ParseBranchNameTemplate("{{.Prefix}}/{{.TicketKey}}-{{.Slug}}-{{.ShortJobID}}")
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	gitUseCase := &GitUseCase{branchNameTemplate: tmpl}
+
+	cases := map[string]string{
+		"PROJ-123 fix the login redirect": "eksecd/PROJ-123-fix-the-login-redirect-0abcdefg",
+		"fix the login redirect":          "eksecd/fix-the-login-redirect-0abcdefg",
+		"!!!":                             "eksecd/0abcdefg",
+	}
+	for message, expected := range cases {
+		branchName, err := gitUseCase.generateBranchName("job_01JABCDEFGH0ABCDEFG", message)
+		if err != nil {
+			t.Fatalf("failed to generate branch name: %v", err)
+		}
+		if branchName != expected {
+			t.Errorf("%q: expected %q, got %q", message, expected, branchName)
+		}
+	}
+
+	// The default keeps the adjective-noun-timestamp names
+	branchName, err := (&GitUseCase{}).generateBranchName("job_01JABCDEFGH0ABCDEFG", "fix it")
+	if err != nil {
+		t.Fatalf("failed to generate branch name: %v", err)
+	}
+	if !regexp.MustCompile(`^eksecd/[a-z]+(-[a-z]+)+-\d{8}-\d{6}$`).MatchString(branchName) {
+		t.Errorf("expected a codename branch, got %q", branchName)
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	data := BranchNameData{Codename: "brave-otter", Timestamp: "20260101-120000"}
+	cases := map[string]string{
+		"eksecd/feature/x":                   "eksecd/feature/x",
+		"feature/x":                          "eksecd/feature/x",
+		"eksecd/-.a..b.lock":                 "eksecd/a.b",
+		"eksecd//x y~z^:?*[\\@{":             "eksecd/x-y-z",
+		"eksecd/":                            "eksecd/brave-otter-20260101-120000",
+		"eksecd/pool-ready-1":                "eksecd/job-pool-ready-1",
+		"eksecd/" + strings.Repeat("a", 120): "eksecd/" + strings.Repeat("a", 93),
+	}
+	for name, expected := range cases {
+		sanitized := sanitizeBranchName(name, data)
+		if sanitized != expected {
+			t.Errorf("%q: expected %q, got %q", name, expected, sanitized)
+		}
+		if err := exec.Command("git", "check-ref-format", "--branch", sanitized).Run(); err != nil {
+			t.Errorf("%q: %q is not a valid branch name", name, sanitized)
+		}
+	}
+}
+
+func TestParseBranchNameTemplate_Invalid(t *testing.T) {
+	for _, text := range []string{"{{.Prefix}/x", "{{.Unknown}}", "{{.Prefix}}/{{.TicketKey}}-{{.Slug}}"} {
+		if _, err := ParseBranchNameTemplate(text); err == nil {
+			t.Errorf("expected an error for %q", text)
+		}
+	}
+}
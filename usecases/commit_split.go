@@ -0,0 +1,263 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"eksecd/clients"
+	"eksecd/core/log"
+)
+
+const (
+	// splitCommitAttempts is how often the agent is asked to group the changes before the
+	// commit is given up
+	splitCommitAttempts = 3
+	// maxUnitPromptLines limits the lines of each hunk shown to the agent
+	maxUnitPromptLines = 40
+)
+
+// commitGroup is one commit of the agent's plan
+type commitGroup struct {
+	Message string   `json:"message"`
+	Units   []string `json:"units"`
+}
+
+// commitConventionalChanges commits the uncommitted changes in dir as Conventional Commits
+// grouped by the agent into logical units, each message checked against the commitlint
+// rules. worktreePath is empty for the main checkout. Returns the commit hashes, oldest
+// first, or nil when the changes cannot be split into units or the agent cannot plan the
+// commits, so the caller commits them as one. If a planned commit cannot be staged, the
+// changes from there on are left unstaged for the caller to commit as one. A failure after
+// some commits leaves those commits and unstages the rest of the changes.
+func (g *GitUseCase) commitConventionalChanges(sessionID, worktreePath, dir string, trailers []string) (commitHashes []string, err error) {
+	log.Info("📋 Starting to commit changes as Conventional Commits in: %s", dir)
+
+	units, err := g.gitClient.ListDiffUnitsInDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+	if len(units) == 0 {
+		return nil, nil
+	}
+
+	groups, err := g.planCommitGroups(sessionID, worktreePath, units, g.commitLintRulesForDir(dir))
+	if err != nil {
+		log.Warn("⚠️ Failed to plan Conventional Commits, committing the changes as one: %v", err)
+		return nil, nil
+	}
+
+	if err := g.gitClient.ResetIndexInDir(dir); err != nil {
+		return nil, err
+	}
+	// Don't leave a partly staged commit behind
+	defer func() {
+		if err != nil {
+			if resetErr := g.gitClient.ResetIndexInDir(dir); resetErr != nil {
+				log.Warn("⚠️ Failed to unstage changes after failed commit: %v", resetErr)
+			}
+		}
+	}()
+
+	for i, group := range groups {
+		if i < len(groups)-1 {
+			if err := g.gitClient.StageDiffUnitsInDir(dir, groupUnits(group, units)); err != nil {
+				// The planned message only describes part of what is left
+				log.Warn("⚠️ Failed to stage commit %d, leaving the remaining changes to be committed as one: %v", i+1, err)
+				return commitHashes, g.gitClient.ResetIndexInDir(dir)
+			}
+		} else {
+			// The last commit takes whatever is left, so no change stays behind
+			if err := g.gitClient.AddAllInWorktree(dir); err != nil {
+				return commitHashes, err
+			}
+		}
+
+		if err := g.gitClient.CommitInWorktree(dir, group.Message, trailers); err != nil {
+			return commitHashes, err
+		}
+		commitHash, err := g.gitClient.GetLatestCommitHashInWorktree(dir)
+		if err != nil {
+			return commitHashes, fmt.Errorf("failed to get commit hash: %w", err)
+		}
+		commitHashes = append(commitHashes, commitHash)
+		log.Info("📝 Committed %s: %s", shortHash(commitHash), strings.SplitN(group.Message, "\n", 2)[0])
+	}
+
+	log.Info("✅ Committed %d changes as %d Conventional Commits", len(units), len(commitHashes))
+	return commitHashes, nil
+}
+
+// planCommitGroups asks the agent to group the units into commits until the plan covers
+// every unit once and its messages pass the commitlint rules
+func (g *GitUseCase) planCommitGroups(
+	sessionID, worktreePath string,
+	units []clients.DiffUnit,
+	rules CommitLintRules,
+) ([]commitGroup, error) {
+	prompt := CommitGroupingPrompt(describeDiffUnits(units), describeCommitLintRules(rules))
+
+	var problems []string
+	for attempt := 1; attempt <= splitCommitAttempts; attempt++ {
+		log.Info("🤖 Asking agent to group %d changes into commits (attempt %d/%d)", len(units), attempt, splitCommitAttempts)
+
+		var output string
+		if worktreePath != "" {
+			result, err := g.claudeService.ContinueConversationInDir(sessionID, prompt, worktreePath)
+			if err != nil {
+				return nil, fmt.Errorf("agent failed to group the changes: %w", err)
+			}
+			output = result.Output
+		} else {
+			result, err := g.claudeService.ContinueConversation(sessionID, prompt)
+			if err != nil {
+				return nil, fmt.Errorf("agent failed to group the changes: %w", err)
+			}
+			output = result.Output
+		}
+
+		var groups []commitGroup
+		groups, problems = parseCommitGroups(output)
+		if len(problems) == 0 {
+			problems = validateCommitGroups(groups, units, rules)
+		}
+		if len(problems) == 0 {
+			return groups, nil
+		}
+
+		log.Warn("⚠️ Agent's commit plan was rejected: %s", strings.Join(problems, "; "))
+		prompt = CommitGroupingRetryPrompt(problems)
+	}
+	return nil, fmt.Errorf("commit plan still invalid after %d attempts: %s", splitCommitAttempts, strings.Join(problems, "; "))
+}
+
+// parseCommitGroups reads the JSON array of commits from the agent's answer
+func parseCommitGroups(output string) ([]commitGroup, []string) {
+	start, end := strings.Index(output, "["), strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return nil, []string{"the answer has no JSON array of commits"}
+	}
+
+	var groups []commitGroup
+	if err := json.Unmarshal([]byte(output[start:end+1]), &groups); err != nil {
+		return nil, []string{fmt.Sprintf("the JSON array of commits is invalid: %v", err)}
+	}
+	for i := range groups {
+		groups[i].Message = strings.TrimSpace(groups[i].Message)
+	}
+	return groups, nil
+}
+
+// validateCommitGroups checks that every unit is in exactly one commit and that the commit
+// messages pass the rules
+func validateCommitGroups(groups []commitGroup, units []clients.DiffUnit, rules CommitLintRules) []string {
+	if len(groups) == 0 {
+		return []string{"the plan has no commits"}
+	}
+
+	var problems []string
+	assigned := make(map[string]int)
+	for i, group := range groups {
+		if len(group.Units) == 0 {
+			problems = append(problems, fmt.Sprintf("commit %d has no units", i+1))
+		}
+		for _, id := range group.Units {
+			assigned[id]++
+		}
+		for _, problem := range LintCommitMessage(group.Message, rules) {
+			problems = append(problems, fmt.Sprintf("commit %d %q: %s", i+1, strings.SplitN(group.Message, "\n", 2)[0], problem))
+		}
+	}
+
+	known := make(map[string]bool, len(units))
+	for _, unit := range units {
+		known[unit.ID] = true
+		switch assigned[unit.ID] {
+		case 0:
+			problems = append(problems, fmt.Sprintf("%s is in no commit", unit.ID))
+		case 1:
+		default:
+			problems = append(problems, fmt.Sprintf("%s is in more than one commit", unit.ID))
+		}
+	}
+	for id := range assigned {
+		if !known[id] {
+			problems = append(problems, fmt.Sprintf("%s is not a unit", id))
+		}
+	}
+	return problems
+}
+
+// groupUnits returns the units of the group in diff order
+func groupUnits(group commitGroup, units []clients.DiffUnit) []clients.DiffUnit {
+	selected := make([]clients.DiffUnit, 0, len(group.Units))
+	for _, unit := range units {
+		if slices.Contains(group.Units, unit.ID) {
+			selected = append(selected, unit)
+		}
+	}
+	return selected
+}
+
+// describeDiffUnits lists the units for the grouping prompt
+func describeDiffUnits(units []clients.DiffUnit) string {
+	var sb strings.Builder
+	for _, unit := range units {
+		if unit.WholeFile {
+			fmt.Fprintf(&sb, "%s: %s (%s)\n\n", unit.ID, unit.Path, unit.Summary)
+			continue
+		}
+		lines := strings.Split(strings.TrimSuffix(unit.Hunk, "\n"), "\n")
+		if len(lines) > maxUnitPromptLines {
+			omitted := len(lines) - maxUnitPromptLines
+			lines = append(lines[:maxUnitPromptLines], fmt.Sprintf("... (%d more lines)", omitted))
+		}
+		fmt.Fprintf(&sb, "%s: %s\n%s\n\n", unit.ID, unit.Path, strings.Join(lines, "\n"))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// describeCommitLintRules summarizes the rules the agent's messages must follow
+func describeCommitLintRules(rules CommitLintRules) string {
+	var lines []string
+	enumValues := func(rule CommitLintRule) string {
+		values, _ := rule.Value.([]any)
+		var names []string
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+
+	if rule, ok := rules["type-enum"]; ok && rule.Severity == commitLintError && rule.Always {
+		lines = append(lines, "- type is one of: "+enumValues(rule))
+	}
+	if rule, ok := rules["scope-enum"]; ok && rule.Severity == commitLintError && rule.Always {
+		lines = append(lines, "- scope, if any, is one of: "+enumValues(rule))
+	}
+	if rule, ok := rules["scope-empty"]; ok && rule.Severity == commitLintError && !rule.Always {
+		lines = append(lines, "- a scope is required")
+	}
+	if rule, ok := rules["header-max-length"]; ok && rule.Severity == commitLintError {
+		if limit, ok := rule.Value.(float64); ok {
+			lines = append(lines, fmt.Sprintf("- the first line is at most %d characters", int(limit)))
+		}
+	}
+	if rule, ok := rules["body-max-line-length"]; ok && rule.Severity == commitLintError {
+		if limit, ok := rule.Value.(float64); ok {
+			lines = append(lines, fmt.Sprintf("- body lines are at most %d characters", int(limit)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortHash abbreviates a commit hash for logs
+func shortHash(commitHash string) string {
+	if len(commitHash) > 7 {
+		return commitHash[:7]
+	}
+	return commitHash
+}
@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"eksecd/clients"
+	"eksecd/models"
+	"eksecd/services"
+)
+
+// fakeCommitPlanner is a CLIAgent that answers the grouping prompts with the given plans
+type fakeCommitPlanner struct {
+	services.CLIAgent
+	plans   []string
+	onPlan  func(workDir string)
+	prompts []string
+}
+
+func (f *fakeCommitPlanner) ContinueConversationInDir(sessionID, prompt, workDir string) (*services.CLIAgentResult, error) {
+	f.prompts = append(f.prompts, prompt)
+	if len(f.plans) == 0 {
+		return nil, fmt.Errorf("no plan left")
+	}
+	plan := f.plans[0]
+	f.plans = f.plans[1:]
+	if f.onPlan != nil {
+		f.onPlan(workDir)
+	}
+	return &services.CLIAgentResult{Output: plan, SessionID: sessionID}, nil
+}
+
+// setupCommitSplitTest creates a job worktree with two separate hunks in app.txt (U1, U2)
+// and a new file (U3), and a GitUseCase in Conventional Commits mode using agent
+func setupCommitSplitTest(t *testing.T, agent services.CLIAgent) (*GitUseCase, string) {
+	t.Helper()
+	mainRepo, worktreeBase, cleanup := setupTestGitRepoWithRemote(t)
+	t.Cleanup(cleanup)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	if err := os.WriteFile(filepath.Join(mainRepo, "app.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, mainRepo, "add", "-A")
+	runGit(t, mainRepo, "commit", "-m", "Add app")
+
+	worktreePath := filepath.Join(worktreeBase, "job")
+	runGit(t, mainRepo, "worktree", "add", "-b", "eksecd/job", worktreePath, "main")
+
+	lines[1] = "line 2 fixed"
+	lines[27] = "line 28 with feature"
+	if err := os.WriteFile(filepath.Join(worktreePath, "app.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "fix_test.txt"), []byte("test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{RepoPath: mainRepo, IsRepoMode: true, ConventionalCommits: true})
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return mainRepo })
+	return NewGitUseCase(gitClient, agent, appState), worktreePath
+}
+
+func TestCommitConventionalChanges(t *testing.T) {
+	agent := &fakeCommitPlanner{plans: []string{
+		// Rejected: unknown type and U3 left out
+		`[{"message": "feature: add feature", "units": ["U2"]}, {"message": "fix: fix line two", "units": ["U1"]}]`,
+		"Here is the plan:\n" + `[{"message": "feat(app): add feature to line 28", "units": ["U2"]}, {"message": "fix(app): fix line two\n\nWith a test.", "units": ["U1", "U3"]}]`,
+	}}
+	gitUseCase, worktreePath := setupCommitSplitTest(t, agent)
+
+	commitHashes, err := gitUseCase.commitConventionalChanges("session", worktreePath, worktreePath, []string{"Eksecd-Thread: https://example.com/t"})
+	if err != nil {
+		t.Fatalf("failed to commit changes: %v", err)
+	}
+	if len(commitHashes) != 2 {
+		t.Fatalf("expected 2 commits, got %v", commitHashes)
+	}
+
+	if len(agent.prompts) != 2 {
+		t.Fatalf("expected a retry after the rejected plan, got %d prompts", len(agent.prompts))
+	}
+	if !strings.Contains(agent.prompts[0], "U3: fix_test.txt (new file)") || !strings.Contains(agent.prompts[0], "+line 28 with feature") {
+		t.Errorf("expected the units in the prompt, got:\n%s", agent.prompts[0])
+	}
+	if !strings.Contains(agent.prompts[1], "U3 is in no commit") || !strings.Contains(agent.prompts[1], "type must be one of") {
+		t.Errorf("expected the problems in the retry prompt, got:\n%s", agent.prompts[1])
+	}
+
+	if files := runGit(t, worktreePath, "show", "--name-only", "--format=", commitHashes[0]); files != "app.txt" {
+		t.Errorf("expected the first commit to change app.txt only, got %q", files)
+	}
+	if diff := runGit(t, worktreePath, "show", "--format=", commitHashes[0]); !strings.Contains(diff, "+line 28 with feature") || strings.Contains(diff, "line 2 fixed") {
+		t.Errorf("expected the first commit to hold the feature hunk only, got:\n%s", diff)
+	}
+	if message := runGit(t, worktreePath, "log", "-1", "--format=%B", commitHashes[1]); message != "fix(app): fix line two\n\nWith a test.\n\nEksecd-Thread: https://example.com/t" {
+		t.Errorf("unexpected second commit message %q", message)
+	}
+	if status := runGit(t, worktreePath, "status", "--porcelain"); status != "" {
+		t.Errorf("expected no uncommitted changes, got %q", status)
+	}
+}
+
+func TestCommitConventionalChanges_FallsBackWithoutPlan(t *testing.T) {
+	invalid := `[{"message": "Update app", "units": ["U1", "U2", "U3"]}]`
+	agent := &fakeCommitPlanner{plans: []string{invalid, invalid, invalid}}
+	gitUseCase, worktreePath := setupCommitSplitTest(t, agent)
+
+	commitHashes, err := gitUseCase.commitConventionalChanges("session", worktreePath, worktreePath, nil)
+	if err != nil || commitHashes != nil {
+		t.Fatalf("expected a fallback to a single commit, got %v, %v", commitHashes, err)
+	}
+	if len(agent.prompts) != splitCommitAttempts {
+		t.Errorf("expected %d planning attempts, got %d", splitCommitAttempts, len(agent.prompts))
+	}
+	if subject := runGit(t, worktreePath, "log", "-1", "--format=%s"); subject != "Add app" {
+		t.Errorf("expected nothing to be committed, got %q", subject)
+	}
+}
+
+func TestCommitConventionalChanges_UnstagesAfterFailedCommit(t *testing.T) {
+	agent := &fakeCommitPlanner{plans: []string{
+		`[{"message": "feat(app): add feature to line 28", "units": ["U2"]}, {"message": "fix(app): fix line two", "units": ["U1", "U3"]}]`,
+	}}
+	gitUseCase, worktreePath := setupCommitSplitTest(t, agent)
+
+	// A hook that rejects the second commit
+	hooksDir := runGit(t, worktreePath, "rev-parse", "--path-format=absolute", "--git-common-dir") + "/hooks"
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hooksDir, "commit-msg"), []byte("#!/bin/sh\n! grep -q '^fix' \"$1\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	commitHashes, err := gitUseCase.commitConventionalChanges("session", worktreePath, worktreePath, nil)
+	if err == nil {
+		t.Fatalf("expected the rejected commit to fail")
+	}
+	if len(commitHashes) != 1 {
+		t.Errorf("expected the first commit to be kept, got %v", commitHashes)
+	}
+	if staged := runGit(t, worktreePath, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("expected nothing staged after the failure, got %q", staged)
+	}
+	if status := runGit(t, worktreePath, "status", "--porcelain"); !strings.Contains(status, "M app.txt") || !strings.Contains(status, "?? fix_test.txt") {
+		t.Errorf("expected the remaining changes to be left unstaged, got %q", status)
+	}
+}
+
+func TestCommitConventionalChanges_LeavesRestAfterFailedStaging(t *testing.T) {
+	agent := &fakeCommitPlanner{
+		plans: []string{
+			`[{"message": "feat(app): add feature to line 28", "units": ["U2"]}, {"message": "test: add fix test", "units": ["U3"]}, {"message": "fix(app): fix line two", "units": ["U1"]}]`,
+		},
+		// The new file disappears while the agent plans, so its commit cannot be staged
+		onPlan: func(workDir string) {
+			if err := os.Remove(filepath.Join(workDir, "fix_test.txt")); err != nil {
+				t.Error(err)
+			}
+		},
+	}
+	gitUseCase, worktreePath := setupCommitSplitTest(t, agent)
+
+	commitHashes, err := gitUseCase.commitConventionalChanges("session", worktreePath, worktreePath, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commitHashes) != 1 {
+		t.Fatalf("expected only the first commit, got %v", commitHashes)
+	}
+	if subject := runGit(t, worktreePath, "log", "-1", "--format=%s"); subject != "feat(app): add feature to line 28" {
+		t.Errorf("expected the remaining changes not to be committed under a planned message, got %q", subject)
+	}
+	if staged := runGit(t, worktreePath, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("expected nothing staged, got %q", staged)
+	}
+	if status := runGit(t, worktreePath, "status", "--porcelain"); status != "M app.txt" {
+		t.Errorf("expected the fix to be left for a single commit, got %q", status)
+	}
+}
+
+func TestValidateCommitGroups(t *testing.T) {
+	units := []clients.DiffUnit{{ID: "U1"}, {ID: "U2"}}
+	rules := DefaultCommitLintRules()
+
+	valid := []commitGroup{{Message: "feat: add a", Units: []string{"U1"}}, {Message: "docs: document a", Units: []string{"U2"}}}
+	if problems := validateCommitGroups(valid, units, rules); len(problems) != 0 {
+		t.Errorf("expected a valid plan, got %v", problems)
+	}
+
+	invalid := []commitGroup{{Message: "feat: add a", Units: []string{"U1", "U9"}}, {Message: "Update docs", Units: []string{"U1"}}}
+	problems := strings.Join(validateCommitGroups(invalid, units, rules), "\n")
+	for _, expected := range []string{"U1 is in more than one commit", "U2 is in no commit", "U9 is not a unit", `commit 2 "Update docs"`} {
+		if !strings.Contains(problems, expected) {
+			t.Errorf("expected problem %q, got:\n%s", expected, problems)
+		}
+	}
+
+	if _, problems := parseCommitGroups("I could not group them"); len(problems) != 1 {
+		t.Errorf("expected a problem for an answer without JSON, got %v", problems)
+	}
+}
@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"eksecd/models"
+)
+
+// threadTrailerKey is the trailer linking a commit to the thread that asked for it
+const threadTrailerKey = "Eksecd-Thread"
+
+// CommitRequester is the person who asked for a job's changes. Commits credit them with a
+// Co-authored-by trailer, which needs the email GitHub and GitLab match to an account.
+type CommitRequester struct {
+	Name  string
+	Email string
+}
+
+// JobRequester returns the requester stored on the job
+func JobRequester(jobData models.JobData) CommitRequester {
+	return CommitRequester{Name: jobData.RequesterName, Email: jobData.RequesterEmail}
+}
+
+// commitTrailers builds the trailers of an agent commit: the requester as co-author and the
+// link to the thread. Parts that are missing or invalid are left out.
+func commitTrailers(requester CommitRequester, threadLink string) []string {
+	var trailers []string
+	if coAuthor := coAuthorTrailerValue(requester); coAuthor != "" {
+		trailers = append(trailers, "Co-authored-by: "+coAuthor)
+	}
+	if link := strings.TrimSpace(threadLink); link != "" && !strings.ContainsAny(link, "\r\n") {
+		trailers = append(trailers, fmt.Sprintf("%s: %s", threadTrailerKey, link))
+	}
+	return trailers
+}
+
+// coAuthorTrailerValue formats the requester as "Name <email>", falling back to the local
+// part of the email for the name. Returns "" without a valid email.
+func coAuthorTrailerValue(requester CommitRequester) string {
+	address, err := mail.ParseAddress(strings.TrimSpace(requester.Email))
+	if err != nil || address.Name != "" {
+		return ""
+	}
+
+	name := strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if r == '<' || r == '>' {
+			return -1
+		}
+		return r
+	}, requester.Name)), " ")
+	if name == "" {
+		name = address.Address[:strings.Index(address.Address, "@")]
+	}
+	return fmt.Sprintf("%s <%s>", name, address.Address)
+}
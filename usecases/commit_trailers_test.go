@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommitTrailers(t *testing.T) {
+	link := "https://example.slack.com/archives/C1/p1"
+	cases := []struct {
+		name      string
+		requester CommitRequester
+		expected  []string
+	}{
+		{
+			name:      "name and email",
+			requester: CommitRequester{Name: "Jane Doe", Email: "jane@example.com"},
+			expected:  []string{"Co-authored-by: Jane Doe <jane@example.com>", "Eksecd-Thread: " + link},
+		},
+		{
+			name:      "email only",
+			requester: CommitRequester{Email: " jane@example.com "},
+			expected:  []string{"Co-authored-by: jane <jane@example.com>", "Eksecd-Thread: " + link},
+		},
+		{
+			name:      "name with angle brackets",
+			requester: CommitRequester{Name: "Jane <Ops>\nDoe", Email: "jane@example.com"},
+			expected:  []string{"Co-authored-by: Jane Ops Doe <jane@example.com>", "Eksecd-Thread: " + link},
+		},
+		{
+			name:      "invalid email",
+			requester: CommitRequester{Name: "Jane Doe", Email: "Jane <jane@example.com>"},
+			expected:  []string{"Eksecd-Thread: " + link},
+		},
+		{
+			name:     "no requester",
+			expected: []string{"Eksecd-Thread: " + link},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if trailers := commitTrailers(tc.requester, link); !reflect.DeepEqual(trailers, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, trailers)
+			}
+		})
+	}
+
+	if trailers := commitTrailers(CommitRequester{}, ""); trailers != nil {
+		t.Errorf("expected no trailers, got %q", trailers)
+	}
+}
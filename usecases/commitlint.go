@@ -0,0 +1,283 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"eksecd/core/log"
+)
+
+// commitLintConfigFile is the commitlint config eksecd reads from the repository root
+const commitLintConfigFile = ".commitlintrc.json"
+
+// Rule severities of a commitlint rule
+const (
+	commitLintDisabled = 0
+	commitLintWarning  = 1
+	commitLintError    = 2
+)
+
+// conventionalHeaderPattern parses "type(scope)!: subject"
+var conventionalHeaderPattern = regexp.MustCompile(`^(\w*)(?:\(([^()\r\n]*)\))?!?: (.*)$`)
+
+// CommitLintRule is a commitlint rule: [severity, "always" | "never", value]
+type CommitLintRule struct {
+	Severity int
+	Always   bool
+	Value    any
+}
+
+// UnmarshalJSON reads a rule in commitlint's array form
+func (r *CommitLintRule) UnmarshalJSON(data []byte) error {
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("rule must be an array: %w", err)
+	}
+	if len(parts) == 0 {
+		return errors.New("rule must have a severity")
+	}
+	if err := json.Unmarshal(parts[0], &r.Severity); err != nil {
+		return fmt.Errorf("invalid rule severity: %w", err)
+	}
+
+	r.Always = true
+	if len(parts) > 1 {
+		var applicable string
+		if err := json.Unmarshal(parts[1], &applicable); err != nil {
+			return fmt.Errorf("invalid rule applicability: %w", err)
+		}
+		r.Always = applicable != "never"
+	}
+	if len(parts) > 2 {
+		if err := json.Unmarshal(parts[2], &r.Value); err != nil {
+			return fmt.Errorf("invalid rule value: %w", err)
+		}
+	}
+	return nil
+}
+
+// CommitLintRules are commitlint rules by name. Only the rules in commitLintCheckers are
+// checked; others are ignored.
+type CommitLintRules map[string]CommitLintRule
+
+// DefaultCommitLintRules follow @commitlint/config-conventional
+func DefaultCommitLintRules() CommitLintRules {
+	return CommitLintRules{
+		"type-enum": {Severity: commitLintError, Always: true, Value: []any{
+			"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+		}},
+		"type-case":            {Severity: commitLintError, Always: true, Value: "lower-case"},
+		"type-empty":           {Severity: commitLintError, Always: false},
+		"scope-case":           {Severity: commitLintError, Always: true, Value: "lower-case"},
+		"subject-empty":        {Severity: commitLintError, Always: false},
+		"subject-full-stop":    {Severity: commitLintError, Always: false, Value: "."},
+		"header-max-length":    {Severity: commitLintError, Always: true, Value: float64(100)},
+		"body-max-line-length": {Severity: commitLintError, Always: true, Value: float64(100)},
+	}
+}
+
+// LoadCommitLintRules reads the "rules" of a commitlint JSON config on top of the defaults
+func LoadCommitLintRules(path string) (CommitLintRules, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commitlint config: %w", err)
+	}
+
+	var config struct {
+		Rules CommitLintRules `json:"rules"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse commitlint config %s: %w", path, err)
+	}
+
+	rules := DefaultCommitLintRules()
+	for name, rule := range config.Rules {
+		if _, ok := commitLintCheckers[name]; !ok {
+			log.Warn("⚠️ Ignoring unsupported commitlint rule %s", name)
+			continue
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+// commitLintRulesForDir returns the configured rules, the rules of the repository's
+// .commitlintrc.json, or the defaults
+func (g *GitUseCase) commitLintRulesForDir(dir string) CommitLintRules {
+	if g.commitLintRules != nil {
+		return g.commitLintRules
+	}
+
+	path := filepath.Join(dir, commitLintConfigFile)
+	if _, err := os.Stat(path); err != nil {
+		return DefaultCommitLintRules()
+	}
+	rules, err := LoadCommitLintRules(path)
+	if err != nil {
+		log.Warn("⚠️ Using default commitlint rules: %v", err)
+		return DefaultCommitLintRules()
+	}
+	return rules
+}
+
+// parsedCommitMessage is a commit message split into its Conventional Commits parts
+type parsedCommitMessage struct {
+	Header  string
+	Type    string
+	Scope   string
+	Subject string
+	Body    string
+}
+
+func parseCommitMessage(message string) parsedCommitMessage {
+	header, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	parsed := parsedCommitMessage{Header: header, Body: strings.TrimSpace(body)}
+	if match := conventionalHeaderPattern.FindStringSubmatch(header); match != nil {
+		parsed.Type, parsed.Scope, parsed.Subject = match[1], match[2], match[3]
+	}
+	return parsed
+}
+
+// commitLintChecker returns a problem when the message breaks the rule, or ""
+type commitLintChecker func(message parsedCommitMessage, rule CommitLintRule) string
+
+var commitLintCheckers = map[string]commitLintChecker{
+	"type-enum": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkEnum("type", m.Type, r)
+	},
+	"type-case": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkCase("type", m.Type, r)
+	},
+	"type-empty": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkEmpty("type", m.Type, r)
+	},
+	"scope-enum": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkEnum("scope", m.Scope, r)
+	},
+	"scope-case": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkCase("scope", m.Scope, r)
+	},
+	"scope-empty": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkEmpty("scope", m.Scope, r)
+	},
+	"subject-empty": func(m parsedCommitMessage, r CommitLintRule) string {
+		return checkEmpty("subject", m.Subject, r)
+	},
+	"subject-full-stop": func(m parsedCommitMessage, r CommitLintRule) string {
+		stop, _ := r.Value.(string)
+		if stop == "" || m.Subject == "" {
+			return ""
+		}
+		if strings.HasSuffix(m.Subject, stop) != r.Always {
+			return fmt.Sprintf("subject must %send with %q", negation(r.Always), stop)
+		}
+		return ""
+	},
+	"header-max-length": func(m parsedCommitMessage, r CommitLintRule) string {
+		limit, ok := r.Value.(float64)
+		if ok && len([]rune(m.Header)) > int(limit) {
+			return fmt.Sprintf("header must not be longer than %d characters", int(limit))
+		}
+		return ""
+	},
+	"body-max-line-length": func(m parsedCommitMessage, r CommitLintRule) string {
+		limit, ok := r.Value.(float64)
+		if !ok {
+			return ""
+		}
+		for _, line := range strings.Split(m.Body, "\n") {
+			// Long URLs cannot be wrapped
+			if len([]rune(line)) > int(limit) && !strings.Contains(line, "://") {
+				return fmt.Sprintf("body lines must not be longer than %d characters", int(limit))
+			}
+		}
+		return ""
+	},
+}
+
+func checkEnum(field, value string, rule CommitLintRule) string {
+	values, _ := rule.Value.([]any)
+	if value == "" || len(values) == 0 {
+		return ""
+	}
+	allowed := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			allowed = append(allowed, s)
+		}
+	}
+	if slices.Contains(allowed, value) != rule.Always {
+		return fmt.Sprintf("%s must %sbe one of [%s]", field, negation(rule.Always), strings.Join(allowed, ", "))
+	}
+	return ""
+}
+
+func checkCase(field, value string, rule CommitLintRule) string {
+	var converted string
+	switch rule.Value {
+	case "lower-case":
+		converted = strings.ToLower(value)
+	case "upper-case":
+		converted = strings.ToUpper(value)
+	default:
+		return ""
+	}
+	if value != "" && (value == converted) != rule.Always {
+		return fmt.Sprintf("%s must %sbe %s", field, negation(rule.Always), rule.Value)
+	}
+	return ""
+}
+
+func checkEmpty(field, value string, rule CommitLintRule) string {
+	if (value == "") != rule.Always {
+		return fmt.Sprintf("%s must %sbe empty", field, negation(rule.Always))
+	}
+	return ""
+}
+
+func negation(always bool) string {
+	if always {
+		return ""
+	}
+	return "not "
+}
+
+// LintCommitMessage checks a commit message against the rules. Returns the problems of
+// error rules; problems of warning rules are logged.
+func LintCommitMessage(message string, rules CommitLintRules) []string {
+	parsed := parseCommitMessage(message)
+
+	var problems []string
+	if parsed.Type == "" && parsed.Subject == "" {
+		problems = append(problems, "header must be \"type(scope): subject\"")
+	}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		rule := rules[name]
+		checker, ok := commitLintCheckers[name]
+		if !ok || rule.Severity == commitLintDisabled {
+			continue
+		}
+		problem := checker(parsed, rule)
+		if problem == "" {
+			continue
+		}
+		if rule.Severity == commitLintWarning {
+			log.Warn("⚠️ Commit message %q: %s [%s]", parsed.Header, problem, name)
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s [%s]", problem, name))
+	}
+	return problems
+}
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintCommitMessage(t *testing.T) {
+	rules := DefaultCommitLintRules()
+	cases := map[string]string{
+		"feat(api): add pagination":                "",
+		"fix!: drop the legacy endpoint":           "",
+		"docs: explain setup\n\nSee the README.":   "",
+		"Add pagination":                           "header must be",
+		"feature: add pagination":                  "type must be one of",
+		"Feat: add pagination":                     "type must be lower-case",
+		"feat(API): add pagination":                "scope must be lower-case",
+		"feat: add pagination.":                    `subject must not end with "."`,
+		"feat: " + strings.Repeat("a", 100):        "header must not be longer than 100 characters",
+		"feat: add\n\n" + strings.Repeat("b ", 60): "body lines must not be longer than 100 characters",
+	}
+	for message, expected := range cases {
+		problems := strings.Join(LintCommitMessage(message, rules), "; ")
+		if expected == "" && problems != "" {
+			t.Errorf("%q: expected no problems, got %s", message, problems)
+		}
+		if expected != "" && !strings.Contains(problems, expected) {
+			t.Errorf("%q: expected %q, got %q", message, expected, problems)
+		}
+	}
+}
+
+func TestLoadCommitLintRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".commitlintrc.json")
+	config := `{"extends": ["@commitlint/config-conventional"], "rules": {
+		"scope-enum": [2, "always", ["api", "cli"]],
+		"scope-empty": [2, "never"],
+		"subject-full-stop": [0],
+		"footer-leading-blank": [1, "always"]
+	}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadCommitLintRules(path)
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	if _, ok := rules["footer-leading-blank"]; ok {
+		t.Error("expected unsupported rules to be ignored")
+	}
+
+	if problems := LintCommitMessage("feat(api): add pagination.", rules); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+	problems := strings.Join(LintCommitMessage("feat: add pagination", rules), "; ")
+	if !strings.Contains(problems, "scope must not be empty") {
+		t.Errorf("expected a missing scope, got %q", problems)
+	}
+	problems = strings.Join(LintCommitMessage("feat(web): add pagination", rules), "; ")
+	if !strings.Contains(problems, "scope must be one of [api, cli]") {
+		t.Errorf("expected an unknown scope, got %q", problems)
+	}
+	if !strings.Contains(describeCommitLintRules(rules), "a scope is required") {
+		t.Errorf("expected the prompt to ask for a scope, got %q", describeCommitLintRules(rules))
+	}
+}
@@ -6,13 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
-	"time"
-
-	"github.com/lucasepe/codename"
+	"text/template"
 
 	"eksecd/clients"
+	"eksecd/core"
 	"eksecd/core/log"
 	"eksecd/models"
 	"eksecd/services"
@@ -24,6 +24,10 @@ type GitUseCase struct {
 	appState      *models.AppState
 	lastGHToken   string
 	worktreePool  *WorktreePool
+	useWorktrees  *bool // Pinned at startup so runtime concurrency changes never switch checkout modes
+
+	commitLintRules    CommitLintRules    // Rules for Conventional Commits messages; nil reads the repository's config
+	branchNameTemplate *template.Template // Renders job branch names; nil uses DefaultBranchNameTemplate
 }
 
 type CLIAgentResult struct {
@@ -38,6 +42,12 @@ type AutoCommitResult struct {
 	CommitHash      string
 	RepositoryURL   string
 	BranchName      string
+	PushOnly        bool     // Branch was pushed without a pull request (push-only mode)
+	Draft           bool     // The pull request is a draft
+	DraftPRs        bool     // Pull requests are opened as drafts (draft-first mode)
+	CommitHashes    []string // All commits of the turn, oldest first, when its changes were split
+
+	Verification *VerificationResult // Set by the caller when the changes were verified before committing
 }
 
 func NewGitUseCase(
@@ -115,10 +125,13 @@ func (g *GitUseCase) ValidateGitEnvironment() error {
 		return fmt.Errorf("git repository must have a remote configured: %w", err)
 	}
 
-	// Check if GitHub CLI is available (for PR creation)
-	if err := g.gitClient.IsGitHubCLIAvailable(); err != nil {
-		log.Error("❌ GitHub CLI not available: %v", err)
-		return fmt.Errorf("GitHub CLI (gh) must be installed and configured: %w", err)
+	// Check that the code host can be reached (for PR creation): gh for GitHub, GITLAB_TOKEN for GitLab
+	pushOnly := g.appState.GetRepositoryContext().PushOnly
+	if pushOnly {
+		log.Info("📦 Push-only mode: Skipping code host validation")
+	} else if err := g.gitClient.ValidateCodeHostAccess(); err != nil {
+		log.Error("❌ Code host not available: %v", err)
+		return fmt.Errorf("pull request access to the code host must be configured: %w", err)
 	}
 
 	// Validate remote repository access credentials
@@ -129,6 +142,11 @@ func (g *GitUseCase) ValidateGitEnvironment() error {
 
 	// Get and store repository identifier
 	repoIdentifier, err := g.gitClient.GetRepositoryIdentifier()
+	if err != nil && pushOnly {
+		// Plain git servers may use remotes without a web URL, e.g. a local path
+		log.Warn("⚠️ Failed to get repository identifier, using the remote URL: %v", err)
+		repoIdentifier, err = g.gitClient.GetRemoteURL()
+	}
 	if err != nil {
 		log.Error("❌ Failed to get repository identifier: %v", err)
 		return fmt.Errorf("failed to get repository identifier: %w", err)
@@ -273,7 +291,7 @@ func (g *GitUseCase) SwitchToJobBranch(branchName string) error {
 	return nil
 }
 
-func (g *GitUseCase) PrepareForNewConversation(conversationHint string) (string, error) {
+func (g *GitUseCase) PrepareForNewConversation(jobID, conversationHint string) (string, error) {
 	log.Info("📋 Starting to prepare for new conversation")
 
 	// Check if we're in repo mode
@@ -283,10 +301,10 @@ func (g *GitUseCase) PrepareForNewConversation(conversationHint string) (string,
 		return "", nil // Return empty branch name in no-repo mode
 	}
 
-	// Generate random branch name
-	branchName, err := g.generateRandomBranchName()
+	// Generate the branch name from the template and the first message
+	branchName, err := g.generateBranchName(jobID, conversationHint)
 	if err != nil {
-		log.Error("❌ Failed to generate random branch name: %v", err)
+		log.Error("❌ Failed to generate branch name: %v", err)
 		return "", fmt.Errorf("failed to generate branch name: %w", err)
 	}
 
@@ -350,7 +368,12 @@ func (g *GitUseCase) resetAndPullDefaultBranch() error {
 	return nil
 }
 
-func (g *GitUseCase) AutoCommitChangesIfNeeded(threadLink, sessionID string) (*AutoCommitResult, error) {
+// AutoCommitChangesIfNeeded commits and pushes the changes in the main checkout and opens or
+// updates the branch's PR. The commit credits the requester and links the thread in trailers.
+func (g *GitUseCase) AutoCommitChangesIfNeeded(
+	threadLink, sessionID string,
+	requester CommitRequester,
+) (*AutoCommitResult, error) {
 	log.Info("📋 Starting to auto-commit changes if needed")
 
 	// Check if we're in repo mode
@@ -389,25 +412,45 @@ func (g *GitUseCase) AutoCommitChangesIfNeeded(threadLink, sessionID string) (*A
 
 	log.Info("✅ Uncommitted changes detected - proceeding with auto-commit")
 
-	// Generate commit message using Claude
-	commitMessage, err := g.generateCommitMessageWithClaude(sessionID, currentBranch)
-	if err != nil {
-		log.Error("❌ Failed to generate commit message with Claude: %v", err)
-		return nil, fmt.Errorf("failed to generate commit message with Claude: %w", err)
+	// Split the changes into Conventional Commits in that mode; whatever the split leaves
+	// behind is committed as one
+	var commitHashes []string
+	commitRemaining := true
+	if repoContext.ConventionalCommits {
+		commitHashes, err = g.commitConventionalChanges(sessionID, "", repoContext.RepoPath, commitTrailers(requester, threadLink))
+		if err != nil {
+			log.Error("❌ Failed to commit changes as Conventional Commits: %v", err)
+			return nil, fmt.Errorf("failed to commit changes as Conventional Commits: %w", err)
+		}
+		if commitHashes != nil {
+			if commitRemaining, err = g.gitClient.HasUncommittedChanges(); err != nil {
+				log.Error("❌ Failed to check for uncommitted changes: %v", err)
+				return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+			}
+		}
 	}
 
-	log.Info("📝 Generated commit message: %s", commitMessage)
+	if commitRemaining {
+		// Generate commit message using Claude
+		commitMessage, err := g.generateCommitMessageWithClaude(sessionID, currentBranch)
+		if err != nil {
+			log.Error("❌ Failed to generate commit message with Claude: %v", err)
+			return nil, fmt.Errorf("failed to generate commit message with Claude: %w", err)
+		}
+
+		log.Info("📝 Generated commit message: %s", commitMessage)
 
-	// Add all changes
-	if err := g.gitClient.AddAll(); err != nil {
-		log.Error("❌ Failed to add all changes: %v", err)
-		return nil, fmt.Errorf("failed to add all changes: %w", err)
-	}
+		// Add all changes
+		if err := g.gitClient.AddAll(); err != nil {
+			log.Error("❌ Failed to add all changes: %v", err)
+			return nil, fmt.Errorf("failed to add all changes: %w", err)
+		}
 
-	// Commit with message
-	if err := g.gitClient.Commit(commitMessage); err != nil {
-		log.Error("❌ Failed to commit changes: %v", err)
-		return nil, fmt.Errorf("failed to commit changes: %w", err)
+		// Commit with message
+		if err := g.gitClient.Commit(commitMessage, commitTrailers(requester, threadLink)); err != nil {
+			log.Error("❌ Failed to commit changes: %v", err)
+			return nil, fmt.Errorf("failed to commit changes: %w", err)
+		}
 	}
 
 	// Get commit hash after successful commit
@@ -416,6 +459,9 @@ func (g *GitUseCase) AutoCommitChangesIfNeeded(threadLink, sessionID string) (*A
 		log.Error("❌ Failed to get commit hash: %v", err)
 		return nil, fmt.Errorf("failed to get commit hash: %w", err)
 	}
+	if commitRemaining && commitHashes != nil {
+		commitHashes = append(commitHashes, commitHash)
+	}
 
 	// Get repository URL for commit link
 	repositoryURL, err := g.gitClient.GetRemoteURL()
@@ -430,6 +476,13 @@ func (g *GitUseCase) AutoCommitChangesIfNeeded(threadLink, sessionID string) (*A
 		return nil, fmt.Errorf("failed to push branch %s: %w", currentBranch, err)
 	}
 
+	if repoContext.PushOnly {
+		log.Info("✅ Push-only mode: pushed branch %s without a pull request", currentBranch)
+		result := pushOnlyResult(currentBranch, commitHash, repositoryURL)
+		result.CommitHashes = commitHashes
+		return result, nil
+	}
+
 	// Handle PR creation/update
 	prResult, err := g.handlePRCreationOrUpdate(sessionID, currentBranch, threadLink)
 	if err != nil {
@@ -439,6 +492,7 @@ func (g *GitUseCase) AutoCommitChangesIfNeeded(threadLink, sessionID string) (*A
 
 	// Update the result with commit information
 	prResult.CommitHash = commitHash
+	prResult.CommitHashes = commitHashes
 	prResult.RepositoryURL = repositoryURL
 
 	// Extract and store PR ID from the PR URL if available
@@ -451,22 +505,6 @@ func (g *GitUseCase) AutoCommitChangesIfNeeded(threadLink, sessionID string) (*A
 	return prResult, nil
 }
 
-func (g *GitUseCase) generateRandomBranchName() (string, error) {
-	log.Info("🎲 Generating random branch name")
-
-	rng, err := codename.DefaultRNG()
-	if err != nil {
-		return "", fmt.Errorf("failed to create random generator: %w", err)
-	}
-
-	randomName := codename.Generate(rng, 0)
-	timestamp := time.Now().Format("20060102-150405")
-	finalBranchName := fmt.Sprintf("eksecd/%s-%s", randomName, timestamp)
-
-	log.Info("🎲 Generated random name: %s", finalBranchName)
-	return finalBranchName, nil
-}
-
 func (g *GitUseCase) generateCommitMessageWithClaude(sessionID, branchName string) (string, error) {
 	log.Info("🤖 Asking Claude to generate commit message")
 
@@ -493,12 +531,15 @@ func (g *GitUseCase) handlePRCreationOrUpdate(sessionID, branchName, threadLink
 	if hasExistingPR {
 		log.Info("✅ Existing PR found for branch %s - changes have been pushed", branchName)
 
-		// Get the PR URL for the existing PR
-		prURL, err := g.gitClient.GetPRURL(branchName)
+		// Get the PR URL and draft state of the existing PR
+		var prURL string
+		var draft bool
+		pr, err := g.gitClient.GetPullRequest(branchName)
 		if err != nil {
 			log.Error("❌ Failed to get PR URL for existing PR: %v", err)
 			// Continue without the URL rather than failing
-			prURL = ""
+		} else {
+			prURL, draft = pr.URL, pr.Draft
 		}
 
 		// Update PR title and description based on new changes
@@ -515,6 +556,8 @@ func (g *GitUseCase) handlePRCreationOrUpdate(sessionID, branchName, threadLink
 			CommitHash:      "", // Will be filled in by caller
 			RepositoryURL:   "", // Will be filled in by caller
 			BranchName:      branchName,
+			Draft:           draft,
+			DraftPRs:        g.appState.GetRepositoryContext().DraftPRs,
 		}, nil
 	}
 
@@ -564,7 +607,8 @@ func (g *GitUseCase) handlePRCreationOrUpdate(sessionID, branchName, threadLink
 	}
 
 	// Create pull request
-	prURL, err := g.gitClient.CreatePullRequest(prTitle, prBody, defaultBranch)
+	draft := g.appState.GetRepositoryContext().DraftPRs
+	prURL, err := g.gitClient.CreatePullRequest(prTitle, prBody, defaultBranch, draft)
 	if err != nil {
 		log.Error("❌ Failed to create pull request: %v", err)
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
@@ -579,6 +623,8 @@ func (g *GitUseCase) handlePRCreationOrUpdate(sessionID, branchName, threadLink
 		CommitHash:      "", // Will be filled in by caller
 		RepositoryURL:   "", // Will be filled in by caller
 		BranchName:      branchName,
+		Draft:           draft,
+		DraftPRs:        draft,
 	}, nil
 }
 
@@ -632,6 +678,10 @@ func (g *GitUseCase) ValidateAndRestorePRDescriptionFooter(threadLink string) er
 		log.Info("📦 No-repo mode: Skipping PR description footer validation")
 		return nil
 	}
+	if repoContext.PushOnly {
+		log.Info("📦 Push-only mode: Skipping PR description footer validation")
+		return nil
+	}
 
 	// Get current branch
 	currentBranch, err := g.gitClient.GetCurrentBranch()
@@ -770,6 +820,10 @@ func (g *GitUseCase) CheckPRStatus(branchName string) (string, error) {
 		return "no_pr", nil
 	}
 
+	if repoContext.PushOnly {
+		return g.CheckBranchMergeStatus(branchName)
+	}
+
 	// First check if a PR exists for this branch
 	hasExistingPR, err := g.gitClient.HasExistingPR(branchName)
 	if err != nil {
@@ -814,6 +868,172 @@ func (g *GitUseCase) CheckPRStatusByID(prID string) (string, error) {
 	return prStatus, nil
 }
 
+// GetPRChecks returns the CI checks of the pull request's head commit, or nil in no-repo mode
+func (g *GitUseCase) GetPRChecks(prID string) (*clients.PullRequestChecks, error) {
+	repoContext := g.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		return nil, nil
+	}
+
+	checks, err := g.gitClient.GetPRChecksByID(prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checks for PR %s: %w", prID, err)
+	}
+	return checks, nil
+}
+
+// MarkPRReady marks a draft pull request as ready for review. Does nothing in no-repo mode.
+func (g *GitUseCase) MarkPRReady(prID string) error {
+	repoContext := g.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		return nil
+	}
+
+	if err := g.gitClient.MarkPRReadyByID(prID); err != nil {
+		return fmt.Errorf("failed to mark PR %s ready for review: %w", prID, err)
+	}
+	return nil
+}
+
+// AddPRMetadata adds labels, reviewers and assignees to a newly created pull request. With
+// codeOwnerReviewers, the code owners of the files changed on the job's branch are requested
+// as reviewers too. worktreePath is empty for jobs in the main checkout. Returns what was added.
+func (g *GitUseCase) AddPRMetadata(
+	prID, worktreePath string,
+	metadata clients.PullRequestMetadata,
+	codeOwnerReviewers bool,
+) (clients.PullRequestMetadata, error) {
+	repoContext := g.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		return clients.PullRequestMetadata{}, nil
+	}
+
+	if codeOwnerReviewers {
+		reviewers, teamReviewers, err := g.codeOwnerReviewers(worktreePath)
+		if err != nil {
+			// Still apply the configured metadata
+			log.Warn("⚠️ Failed to find code owners of the changes for PR %s: %v", prID, err)
+		}
+		metadata.Reviewers = appendMissing(metadata.Reviewers, reviewers)
+		metadata.TeamReviewers = appendMissing(metadata.TeamReviewers, teamReviewers)
+	}
+	if metadata.Empty() {
+		return metadata, nil
+	}
+
+	if err := g.gitClient.AddPRMetadataByID(prID, metadata); err != nil {
+		return clients.PullRequestMetadata{}, fmt.Errorf("failed to add labels, reviewers and assignees to PR %s: %w", prID, err)
+	}
+	return metadata, nil
+}
+
+// codeOwnerReviewers returns the CODEOWNERS owners of the files changed on the job's branch
+func (g *GitUseCase) codeOwnerReviewers(worktreePath string) (reviewers, teamReviewers []string, err error) {
+	dir := worktreePath
+	if dir == "" {
+		dir = g.appState.GetRepositoryContext().RepoPath
+	}
+
+	codeOwners, err := clients.FindCodeOwners(dir)
+	if err != nil || codeOwners == nil {
+		return nil, nil, err
+	}
+
+	defaultBranch, err := g.gitClient.GetDefaultBranchInWorktree(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+	changedFiles, err := g.gitClient.ListChangedFilesInWorktree(dir, "origin/"+defaultBranch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewers, teamReviewers = codeOwners.CodeOwnerReviewers(changedFiles)
+	log.Info("👥 Code owners of %d changed files: %d reviewers, %d team reviewers", len(changedFiles), len(reviewers), len(teamReviewers))
+	return reviewers, teamReviewers, nil
+}
+
+// GetPRReviews returns the reviews and review threads of the pull request. Returns nil in
+// no-repo mode and when the code host has no review threads eksecd can answer.
+func (g *GitUseCase) GetPRReviews(prID string) (*clients.PullRequestReviews, error) {
+	repoContext := g.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		return nil, nil
+	}
+
+	reviews, err := g.gitClient.GetPRReviewsByID(prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews for PR %s: %w", prID, err)
+	}
+	return reviews, nil
+}
+
+// AnswerPRReviewThread replies to a pull request review thread and optionally resolves it
+func (g *GitUseCase) AnswerPRReviewThread(threadID, reply string, resolve bool) error {
+	if reply != "" {
+		if err := g.gitClient.ReplyToPRReviewThread(threadID, reply); err != nil {
+			return err
+		}
+	}
+	if resolve {
+		if err := g.gitClient.ResolvePRReviewThread(threadID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckBranchMergeStatus reports the status of a branch pushed without a pull request:
+// "merged" once origin/<branch> is merged into the default branch (git branch -r --merged),
+// "open" while it is on the remote unmerged, and "no_pr" if it was never pushed.
+// Squash and rebase merges rewrite commits and are not detected.
+func (g *GitUseCase) CheckBranchMergeStatus(branchName string) (string, error) {
+	log.Info("📋 Starting to check merge status of branch: %s", branchName)
+
+	if err := g.gitClient.FetchOrigin(); err != nil {
+		return "", fmt.Errorf("failed to fetch origin: %w", err)
+	}
+
+	defaultBranch, err := g.gitClient.GetDefaultBranch()
+	if err != nil {
+		log.Error("❌ Failed to get default branch: %v", err)
+		return "", fmt.Errorf("failed to get default branch: %w", err)
+	}
+
+	mergedBranches, err := g.gitClient.ListRemoteBranchesMergedInto("origin/" + defaultBranch)
+	if err != nil {
+		return "", err
+	}
+	for _, merged := range mergedBranches {
+		if merged == "origin/"+branchName {
+			log.Info("📋 Completed successfully - branch %s is merged into %s", branchName, defaultBranch)
+			return "merged", nil
+		}
+	}
+
+	exists, err := g.gitClient.RemoteBranchExists(branchName)
+	if err != nil {
+		return "", fmt.Errorf("failed to check remote branch: %w", err)
+	}
+	if !exists {
+		log.Info("📋 Completed successfully - branch %s was not pushed", branchName)
+		return "no_pr", nil
+	}
+
+	log.Info("📋 Completed successfully - branch %s is not merged yet", branchName)
+	return "open", nil
+}
+
+// pushOnlyResult is the auto-commit result of a commit pushed without a pull request
+func pushOnlyResult(branchName, commitHash, repositoryURL string) *AutoCommitResult {
+	return &AutoCommitResult{
+		CommitHash:    commitHash,
+		RepositoryURL: repositoryURL,
+		BranchName:    branchName,
+		PushOnly:      true,
+	}
+}
+
 func (g *GitUseCase) CleanupStaleBranches() error {
 	log.Info("📋 Starting to cleanup stale eksecd branches")
 
@@ -860,7 +1080,7 @@ func (g *GitUseCase) CleanupStaleBranches() error {
 
 	for _, branch := range localBranches {
 		// Only process eksecd/ branches
-		if !strings.HasPrefix(branch, "eksecd/") {
+		if !strings.HasPrefix(branch, JobBranchPrefix+"/") {
 			continue
 		}
 
@@ -884,7 +1104,7 @@ func (g *GitUseCase) CleanupStaleBranches() error {
 		}
 
 		// Skip pool worktree branches (managed by worktree pool)
-		if strings.HasPrefix(branch, "eksecd/pool-ready-") {
+		if strings.HasPrefix(branch, poolBranchPrefix) {
 			log.Info("⚠️ Skipping pool branch: %s", branch)
 			continue
 		}
@@ -1087,8 +1307,8 @@ func (g *GitUseCase) BranchExists(branchName string) (bool, error) {
 func (g *GitUseCase) AbandonJobAndCleanup(jobID, branchName string) error {
 	log.Info("📋 Starting to abandon job %s and cleanup branch %s", jobID, branchName)
 
-	// Remove job from app state first (always do this, even in no-repo mode)
-	if err := g.appState.RemoveJob(jobID); err != nil {
+	// Archive job from app state first (always do this, even in no-repo mode)
+	if err := g.appState.ArchiveJob(jobID, models.JobOutcomeAbandoned, ""); err != nil {
 		log.Error("❌ Failed to remove job %s from state: %v", jobID, err)
 		return fmt.Errorf("failed to remove job from state: %w", err)
 	}
@@ -1135,6 +1355,11 @@ func (g *GitUseCase) AbandonJobAndCleanup(jobID, branchName string) error {
 // If AGENT_EXEC_USER is set (managed mode), worktrees are stored in that user's home
 // directory to ensure they persist on the mounted volume.
 func (g *GitUseCase) GetWorktreeBasePath() (string, error) {
+	return WorktreeBasePath()
+}
+
+// WorktreeBasePath returns the base path for eksecd worktrees, see GetWorktreeBasePath
+func WorktreeBasePath() (string, error) {
 	// In managed mode, use the agent execution user's home for persistent storage
 	if execUser := os.Getenv("AGENT_EXEC_USER"); execUser != "" {
 		return filepath.Join("/home", execUser, ".eksec_worktrees"), nil
@@ -1163,9 +1388,22 @@ func (g *GitUseCase) GetMaxConcurrency() int {
 
 // ShouldUseWorktrees returns true if concurrent worktree mode should be used
 func (g *GitUseCase) ShouldUseWorktrees() bool {
+	if g.useWorktrees != nil {
+		return *g.useWorktrees
+	}
 	return g.GetMaxConcurrency() > 1
 }
 
+// PinWorktreeMode fixes the current worktree decision for the lifetime of the process.
+// Switching between worktrees and the main checkout while jobs run would reset the main
+// repository under a running job, so later MAX_CONCURRENCY changes only affect how many
+// jobs run at a time.
+func (g *GitUseCase) PinWorktreeMode() bool {
+	useWorktrees := g.GetMaxConcurrency() > 1
+	g.useWorktrees = &useWorktrees
+	return useWorktrees
+}
+
 // PrepareForNewConversationWithWorktree creates a worktree for a new conversation
 // Returns (branchName, worktreePath, error)
 // This is used when MAX_CONCURRENCY > 1 for concurrent job processing
@@ -1215,10 +1453,10 @@ func (g *GitUseCase) PrepareForNewConversationWithWorktree(jobID, conversationHi
 		log.Info("✅ Successfully cleaned up existing worktree for jobID %s", jobID)
 	}
 
-	// Generate random branch name
-	branchName, err := g.generateRandomBranchName()
+	// Generate the branch name from the template and the first message
+	branchName, err := g.generateBranchName(jobID, conversationHint)
 	if err != nil {
-		log.Error("❌ Failed to generate random branch name: %v", err)
+		log.Error("❌ Failed to generate branch name: %v", err)
 		return "", "", fmt.Errorf("failed to generate branch name: %w", err)
 	}
 
@@ -1282,6 +1520,11 @@ func (g *GitUseCase) PrepareForNewConversationWithWorktree(jobID, conversationHi
 	return branchName, worktreePath, nil
 }
 
+// SetCommitLintRules sets the rules Conventional Commits messages are checked against
+func (g *GitUseCase) SetCommitLintRules(rules CommitLintRules) {
+	g.commitLintRules = rules
+}
+
 // SetWorktreePool sets the worktree pool for fast worktree acquisition
 func (g *GitUseCase) SetWorktreePool(pool *WorktreePool) {
 	g.worktreePool = pool
@@ -1297,21 +1540,29 @@ func (g *GitUseCase) GetGitClient() *clients.GitClient {
 	return g.gitClient
 }
 
-// PrepareWorktreeForJob validates and prepares an existing worktree for continuing a job
-func (g *GitUseCase) PrepareWorktreeForJob(worktreePath, branchName string) error {
+// PrepareWorktreeForJob validates and prepares an existing worktree for continuing a job.
+// With rebaseOntoDefault set, the job branch is also rebased onto the default branch; the
+// returned result describes the rebase and is nil when none was attempted. Rebase conflicts
+// are resolved in a turn of agent, which should be bound to the job so the turn can be
+// interrupted.
+func (g *GitUseCase) PrepareWorktreeForJob(
+	worktreePath, branchName, sessionID string,
+	agent services.CLIAgent,
+	rebaseOntoDefault bool,
+) (*WorktreeRebaseResult, error) {
 	log.Info("📋 Starting to prepare worktree for job: %s (branch: %s)", worktreePath, branchName)
 
 	// Check if we're in repo mode
 	repoContext := g.appState.GetRepositoryContext()
 	if !repoContext.IsRepoMode {
 		log.Info("📦 No-repo mode: Skipping worktree preparation")
-		return nil
+		return nil, nil
 	}
 
 	// Check if worktree exists
 	if !g.gitClient.WorktreeExists(worktreePath) {
 		log.Error("❌ Worktree does not exist at %s", worktreePath)
-		return fmt.Errorf("worktree not found at %s", worktreePath)
+		return nil, fmt.Errorf("worktree not found at %s", worktreePath)
 	}
 
 	// Pull latest changes in the worktree
@@ -1319,15 +1570,215 @@ func (g *GitUseCase) PrepareWorktreeForJob(worktreePath, branchName string) erro
 		// Check if error is due to remote branch being deleted
 		if strings.Contains(err.Error(), "remote branch deleted") {
 			log.Warn("⚠️ Remote branch was deleted for worktree")
-			return fmt.Errorf("remote branch deleted, cannot continue job: %w", err)
+			return nil, fmt.Errorf("remote branch deleted, cannot continue job: %w", err)
 		}
 
 		log.Error("❌ Failed to pull latest in worktree: %v", err)
-		return fmt.Errorf("failed to pull latest in worktree: %w", err)
+		return nil, fmt.Errorf("failed to pull latest in worktree: %w", err)
+	}
+
+	var rebaseResult *WorktreeRebaseResult
+	if rebaseOntoDefault {
+		rebaseResult = g.rebaseJobBranchInWorktree(worktreePath, branchName, sessionID, agent)
 	}
 
 	log.Info("✅ Successfully prepared worktree for job")
-	return nil
+	return rebaseResult, nil
+}
+
+// WorktreeRebaseResult describes the rebase of a job branch onto the default branch
+type WorktreeRebaseResult struct {
+	BranchName        string
+	Onto              string   // e.g. origin/main
+	Rebased           bool     // The branch now contains the tip of Onto
+	Pushed            bool     // The rebased branch was force-pushed
+	ResolvedConflicts []string // Files the agent resolved conflicts in
+	Aborted           bool     // The rebase failed and the branch was restored
+	Interrupted       bool     // The conflict resolution turn was interrupted, which aborted the rebase
+	AbortReason       string
+}
+
+// maxRebaseConflictRounds caps how many conflicted commits the agent resolves in one rebase
+const maxRebaseConflictRounds = 5
+
+// rebaseJobBranchInWorktree rebases the job branch onto origin/<default>. Conflicts are handed
+// to the agent in a dedicated turn, after which the rebase continues; the rebased branch is
+// force-pushed with lease if it was pushed before. Anything that goes wrong leaves the branch
+// as it was. Returns nil when the branch is already up to date or cannot be rebased right now.
+func (g *GitUseCase) rebaseJobBranchInWorktree(worktreePath, branchName, sessionID string, agent services.CLIAgent) *WorktreeRebaseResult {
+	log.Info("📋 Starting to rebase job branch %s onto the default branch", branchName)
+
+	// Partial changes from an interrupted turn must survive into the next turn
+	hasChanges, err := g.gitClient.HasUncommittedChangesInWorktree(worktreePath)
+	if err != nil {
+		log.Warn("⚠️ Skipping rebase, failed to check worktree status: %v", err)
+		return nil
+	}
+	if hasChanges {
+		log.Info("ℹ️ Skipping rebase, worktree has uncommitted changes")
+		return nil
+	}
+
+	defaultBranch, err := g.gitClient.GetDefaultBranchInWorktree(worktreePath)
+	if err != nil {
+		log.Warn("⚠️ Skipping rebase, failed to get default branch: %v", err)
+		return nil
+	}
+	if err := g.gitClient.FetchBranchInWorktree(worktreePath, defaultBranch); err != nil {
+		log.Warn("⚠️ Skipping rebase, failed to fetch %s: %v", defaultBranch, err)
+		return nil
+	}
+
+	onto := "origin/" + defaultBranch
+	upToDate, err := g.gitClient.IsAncestorInWorktree(worktreePath, onto, "HEAD")
+	if err != nil {
+		log.Warn("⚠️ Skipping rebase, failed to compare with %s: %v", onto, err)
+		return nil
+	}
+	if upToDate {
+		log.Info("✅ Branch %s already contains %s", branchName, onto)
+		return nil
+	}
+
+	originalHead, err := g.gitClient.GetLatestCommitHashInWorktree(worktreePath)
+	if err != nil {
+		log.Warn("⚠️ Skipping rebase, failed to get branch head: %v", err)
+		return nil
+	}
+
+	result := &WorktreeRebaseResult{BranchName: branchName, Onto: onto}
+	conflicted, err := g.gitClient.RebaseInWorktree(worktreePath, onto)
+	if err != nil {
+		return g.abortJobRebase(worktreePath, originalHead, result, fmt.Sprintf("git rebase failed: %v", err))
+	}
+
+	for round := 0; conflicted; round++ {
+		if round == maxRebaseConflictRounds {
+			return g.abortJobRebase(worktreePath, originalHead, result,
+				fmt.Sprintf("conflicts in more than %d commits", maxRebaseConflictRounds))
+		}
+
+		files, err := g.gitClient.ListConflictedFilesInWorktree(worktreePath)
+		if err != nil {
+			return g.abortJobRebase(worktreePath, originalHead, result, err.Error())
+		}
+		reason, err := resolveRebaseConflicts(agent, worktreePath, sessionID, onto, files)
+		if _, interrupted := core.IsAgentInterrupted(err); interrupted {
+			result.Interrupted = true
+			return g.abortJobRebase(worktreePath, originalHead, result, "the conflict resolution was interrupted")
+		}
+		if reason != "" {
+			return g.abortJobRebase(worktreePath, originalHead, result, reason)
+		}
+		result.ResolvedConflicts = appendMissing(result.ResolvedConflicts, files)
+
+		conflicted, err = g.gitClient.ContinueRebaseInWorktree(worktreePath)
+		if err != nil {
+			return g.abortJobRebase(worktreePath, originalHead, result, fmt.Sprintf("git rebase --continue failed: %v", err))
+		}
+	}
+
+	// The agent may have left files behind that the rebase did not pick up
+	hasChanges, err = g.gitClient.HasUncommittedChangesInWorktree(worktreePath)
+	if err != nil || hasChanges {
+		return g.abortJobRebase(worktreePath, originalHead, result, "working tree is not clean after the rebase")
+	}
+
+	hasUpstream, err := g.gitClient.HasUpstreamTrackingInWorktree(worktreePath)
+	if err != nil {
+		return g.abortJobRebase(worktreePath, originalHead, result, fmt.Sprintf("failed to check upstream tracking: %v", err))
+	}
+	if hasUpstream {
+		if err := g.gitClient.ForcePushWithLeaseFromWorktree(worktreePath, branchName); err != nil {
+			return g.abortJobRebase(worktreePath, originalHead, result, fmt.Sprintf("force-push was rejected: %v", err))
+		}
+		result.Pushed = true
+	}
+
+	result.Rebased = true
+	log.Info("✅ Successfully rebased branch %s onto %s (%d files with resolved conflicts)",
+		branchName, onto, len(result.ResolvedConflicts))
+	return result
+}
+
+// resolveRebaseConflicts runs an agent turn asking it to resolve the conflict markers in files.
+// Returns why the conflicts are still unresolved, or "" once no markers are left. The error
+// is only set when the turn was interrupted.
+func resolveRebaseConflicts(agent services.CLIAgent, worktreePath, sessionID, onto string, files []string) (string, error) {
+	if len(files) == 0 {
+		return "the rebase stopped without conflicted files", nil
+	}
+	if sessionID == "" || agent == nil {
+		return "no agent session to resolve the conflicts", nil
+	}
+
+	log.Info("🤖 Asking agent to resolve rebase conflicts in %d files", len(files))
+	prompt := RebaseConflictResolutionPrompt(onto, files)
+	if _, err := agent.ContinueConversationInDir(sessionID, prompt, worktreePath); err != nil {
+		if _, interrupted := core.IsAgentInterrupted(err); interrupted {
+			return "", err
+		}
+		return fmt.Sprintf("agent failed to resolve the conflicts: %v", err), nil
+	}
+
+	unresolved := filesWithConflictMarkers(worktreePath, files)
+	if len(unresolved) > 0 {
+		return fmt.Sprintf("conflict markers left in %s", strings.Join(unresolved, ", ")), nil
+	}
+	return "", nil
+}
+
+// abortJobRebase aborts a rebase in progress and restores the branch to originalHead
+func (g *GitUseCase) abortJobRebase(worktreePath, originalHead string, result *WorktreeRebaseResult, reason string) *WorktreeRebaseResult {
+	log.Warn("⚠️ Aborting rebase of %s onto %s: %s", result.BranchName, result.Onto, reason)
+
+	if inProgress, err := g.gitClient.IsRebaseInProgressInWorktree(worktreePath); err != nil || inProgress {
+		if err := g.gitClient.AbortRebaseInWorktree(worktreePath); err != nil {
+			log.Error("❌ Failed to abort rebase: %v", err)
+		}
+	}
+	if err := g.gitClient.ResetHardInWorktreeToRef(worktreePath, originalHead); err != nil {
+		log.Error("❌ Failed to restore branch %s to %s: %v", result.BranchName, originalHead, err)
+	}
+	if err := g.gitClient.CleanUntrackedInWorktree(worktreePath); err != nil {
+		log.Error("❌ Failed to clean worktree after aborted rebase: %v", err)
+	}
+
+	result.Aborted = true
+	result.AbortReason = reason
+	return result
+}
+
+// filesWithConflictMarkers returns the files that still contain conflict markers.
+// Files that no longer exist were resolved by deleting them.
+func filesWithConflictMarkers(worktreePath string, files []string) []string {
+	var unresolved []string
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(worktreePath, file))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				unresolved = append(unresolved, file)
+			}
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+				unresolved = append(unresolved, file)
+				break
+			}
+		}
+	}
+	return unresolved
+}
+
+// appendMissing appends the items not already in list
+func appendMissing(list, items []string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
 }
 
 // CleanupJobWorktree removes the worktree for a completed or abandoned job
@@ -1455,9 +1906,11 @@ func (g *GitUseCase) WorktreeExists(worktreePath string) bool {
 	return g.gitClient.WorktreeExists(worktreePath)
 }
 
-// AutoCommitChangesInWorktreeIfNeeded auto-commits changes in a specific worktree
+// AutoCommitChangesInWorktreeIfNeeded auto-commits changes in a specific worktree. The commit
+// credits the requester and links the thread in trailers.
 func (g *GitUseCase) AutoCommitChangesInWorktreeIfNeeded(
 	threadLink, sessionID, worktreePath string,
+	requester CommitRequester,
 ) (*AutoCommitResult, error) {
 	log.Info("📋 Starting to auto-commit changes in worktree: %s", worktreePath)
 
@@ -1496,25 +1949,45 @@ func (g *GitUseCase) AutoCommitChangesInWorktreeIfNeeded(
 
 	log.Info("✅ Uncommitted changes detected in worktree - proceeding with auto-commit")
 
-	// Generate commit message using Claude (in the worktree directory)
-	commitMessage, err := g.generateCommitMessageWithClaudeInWorktree(sessionID, currentBranch, worktreePath)
-	if err != nil {
-		log.Error("❌ Failed to generate commit message with Claude: %v", err)
-		return nil, fmt.Errorf("failed to generate commit message with Claude: %w", err)
+	// Split the changes into Conventional Commits in that mode; whatever the split leaves
+	// behind is committed as one
+	var commitHashes []string
+	commitRemaining := true
+	if repoContext.ConventionalCommits {
+		commitHashes, err = g.commitConventionalChanges(sessionID, worktreePath, worktreePath, commitTrailers(requester, threadLink))
+		if err != nil {
+			log.Error("❌ Failed to commit changes as Conventional Commits in worktree: %v", err)
+			return nil, fmt.Errorf("failed to commit changes as Conventional Commits in worktree: %w", err)
+		}
+		if commitHashes != nil {
+			if commitRemaining, err = g.gitClient.HasUncommittedChangesInWorktree(worktreePath); err != nil {
+				log.Error("❌ Failed to check for uncommitted changes in worktree: %v", err)
+				return nil, fmt.Errorf("failed to check for uncommitted changes in worktree: %w", err)
+			}
+		}
 	}
 
-	log.Info("📝 Generated commit message: %s", commitMessage)
+	if commitRemaining {
+		// Generate commit message using Claude (in the worktree directory)
+		commitMessage, err := g.generateCommitMessageWithClaudeInWorktree(sessionID, currentBranch, worktreePath)
+		if err != nil {
+			log.Error("❌ Failed to generate commit message with Claude: %v", err)
+			return nil, fmt.Errorf("failed to generate commit message with Claude: %w", err)
+		}
 
-	// Add all changes in worktree
-	if err := g.gitClient.AddAllInWorktree(worktreePath); err != nil {
-		log.Error("❌ Failed to add all changes in worktree: %v", err)
-		return nil, fmt.Errorf("failed to add all changes in worktree: %w", err)
-	}
+		log.Info("📝 Generated commit message: %s", commitMessage)
 
-	// Commit with message in worktree
-	if err := g.gitClient.CommitInWorktree(worktreePath, commitMessage); err != nil {
-		log.Error("❌ Failed to commit changes in worktree: %v", err)
-		return nil, fmt.Errorf("failed to commit changes in worktree: %w", err)
+		// Add all changes in worktree
+		if err := g.gitClient.AddAllInWorktree(worktreePath); err != nil {
+			log.Error("❌ Failed to add all changes in worktree: %v", err)
+			return nil, fmt.Errorf("failed to add all changes in worktree: %w", err)
+		}
+
+		// Commit with message in worktree
+		if err := g.gitClient.CommitInWorktree(worktreePath, commitMessage, commitTrailers(requester, threadLink)); err != nil {
+			log.Error("❌ Failed to commit changes in worktree: %v", err)
+			return nil, fmt.Errorf("failed to commit changes in worktree: %w", err)
+		}
 	}
 
 	// Get commit hash after successful commit
@@ -1523,6 +1996,9 @@ func (g *GitUseCase) AutoCommitChangesInWorktreeIfNeeded(
 		log.Error("❌ Failed to get commit hash in worktree: %v", err)
 		return nil, fmt.Errorf("failed to get commit hash in worktree: %w", err)
 	}
+	if commitRemaining && commitHashes != nil {
+		commitHashes = append(commitHashes, commitHash)
+	}
 
 	// Get repository URL for commit link
 	repositoryURL, err := g.gitClient.GetRemoteURLInWorktree(worktreePath)
@@ -1537,6 +2013,13 @@ func (g *GitUseCase) AutoCommitChangesInWorktreeIfNeeded(
 		return nil, fmt.Errorf("failed to push branch from worktree: %w", err)
 	}
 
+	if repoContext.PushOnly {
+		log.Info("✅ Push-only mode: pushed branch %s from worktree without a pull request", currentBranch)
+		result := pushOnlyResult(currentBranch, commitHash, repositoryURL)
+		result.CommitHashes = commitHashes
+		return result, nil
+	}
+
 	// Handle PR creation/update from worktree context
 	prResult, err := g.handlePRCreationOrUpdateInWorktree(sessionID, currentBranch, threadLink, worktreePath)
 	if err != nil {
@@ -1546,6 +2029,7 @@ func (g *GitUseCase) AutoCommitChangesInWorktreeIfNeeded(
 
 	// Update the result with commit information
 	prResult.CommitHash = commitHash
+	prResult.CommitHashes = commitHashes
 	prResult.RepositoryURL = repositoryURL
 
 	// Extract and store PR ID from the PR URL if available
@@ -1557,6 +2041,54 @@ func (g *GitUseCase) AutoCommitChangesInWorktreeIfNeeded(
 	return prResult, nil
 }
 
+// inFlightCommitMessage is the message of commits that save unfinished agent work on shutdown
+const inFlightCommitMessage = "chore: save in-progress agent work before shutdown"
+
+// CommitInFlightChanges commits the uncommitted changes of a job whose turn was stopped and
+// pushes its branch. The commit message is fixed and the PR is left alone, since the agent
+// session is unavailable while its turn is being stopped. worktreePath is empty for the main
+// checkout. Returns the commit hash, or "" if there was nothing to commit.
+func (g *GitUseCase) CommitInFlightChanges(worktreePath, threadLink string, requester CommitRequester) (string, error) {
+	repoContext := g.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		return "", nil
+	}
+
+	dir := worktreePath
+	if dir == "" {
+		dir = repoContext.RepoPath
+	}
+	hasChanges, err := g.gitClient.HasUncommittedChangesInWorktree(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if !hasChanges {
+		log.Info("ℹ️ No uncommitted changes to save in %s", dir)
+		return "", nil
+	}
+
+	currentBranch, err := g.gitClient.GetCurrentBranchInWorktree(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if err := g.gitClient.AddAllInWorktree(dir); err != nil {
+		return "", fmt.Errorf("failed to add all changes: %w", err)
+	}
+	if err := g.gitClient.CommitInWorktree(dir, inFlightCommitMessage, commitTrailers(requester, threadLink)); err != nil {
+		return "", fmt.Errorf("failed to commit changes: %w", err)
+	}
+	commitHash, err := g.gitClient.GetLatestCommitHashInWorktree(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit hash: %w", err)
+	}
+	if err := g.gitClient.PushBranchFromWorktree(dir, currentBranch); err != nil {
+		return "", fmt.Errorf("failed to push branch %s: %w", currentBranch, err)
+	}
+
+	log.Info("✅ Saved in-flight changes as %s on branch %s", shortHash(commitHash), currentBranch)
+	return commitHash, nil
+}
+
 func (g *GitUseCase) generateCommitMessageWithClaudeInWorktree(sessionID, branchName, worktreePath string) (string, error) {
 	log.Info("🤖 Asking Claude to generate commit message in worktree: %s", worktreePath)
 
@@ -1586,11 +2118,14 @@ func (g *GitUseCase) handlePRCreationOrUpdateInWorktree(
 	if hasExistingPR {
 		log.Info("✅ Existing PR found for branch %s - changes have been pushed", branchName)
 
-		// Get the PR URL for the existing PR
-		prURL, err := g.gitClient.GetPRURLInWorktree(worktreePath, branchName)
+		// Get the PR URL and draft state of the existing PR
+		var prURL string
+		var draft bool
+		pr, err := g.gitClient.GetPullRequestInWorktree(worktreePath, branchName)
 		if err != nil {
 			log.Error("❌ Failed to get PR URL for existing PR: %v", err)
-			prURL = ""
+		} else {
+			prURL, draft = pr.URL, pr.Draft
 		}
 
 		// Update PR title and description based on new changes
@@ -1606,6 +2141,8 @@ func (g *GitUseCase) handlePRCreationOrUpdateInWorktree(
 			CommitHash:      "",
 			RepositoryURL:   "",
 			BranchName:      branchName,
+			Draft:           draft,
+			DraftPRs:        g.appState.GetRepositoryContext().DraftPRs,
 		}, nil
 	}
 
@@ -1651,7 +2188,8 @@ func (g *GitUseCase) handlePRCreationOrUpdateInWorktree(
 	}
 
 	// Create pull request from worktree
-	prURL, err := g.gitClient.CreatePullRequestInWorktree(worktreePath, prTitle, prBody, defaultBranch)
+	draft := g.appState.GetRepositoryContext().DraftPRs
+	prURL, err := g.gitClient.CreatePullRequestInWorktree(worktreePath, prTitle, prBody, defaultBranch, draft)
 	if err != nil {
 		log.Error("❌ Failed to create pull request: %v", err)
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
@@ -1665,6 +2203,8 @@ func (g *GitUseCase) handlePRCreationOrUpdateInWorktree(
 		CommitHash:      "",
 		RepositoryURL:   "",
 		BranchName:      branchName,
+		Draft:           draft,
+		DraftPRs:        draft,
 	}, nil
 }
 
@@ -1839,6 +2379,10 @@ func (g *GitUseCase) ValidateAndRestorePRDescriptionFooterInWorktree(threadLink,
 		log.Info("📦 No-repo mode: Skipping PR description footer validation")
 		return nil
 	}
+	if repoContext.PushOnly {
+		log.Info("📦 Push-only mode: Skipping PR description footer validation")
+		return nil
+	}
 
 	// Get current branch in worktree
 	currentBranch, err := g.gitClient.GetCurrentBranchInWorktree(worktreePath)
@@ -0,0 +1,254 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"eksecd/clients"
+	"eksecd/core"
+	"eksecd/models"
+	"eksecd/services"
+)
+
+func TestCheckPRStatus_PushOnlyUsesBranchMergeStatus(t *testing.T) {
+	mainRepo, _, cleanup := setupTestGitRepoWithRemote(t)
+	defer cleanup()
+	remote := runGit(t, mainRepo, "remote", "get-url", "origin")
+	runGit(t, remote, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{RepoPath: mainRepo, IsRepoMode: true, PushOnly: true})
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return mainRepo })
+	gitUseCase := NewGitUseCase(gitClient, nil, appState)
+
+	checkStatus := func(expected string) {
+		t.Helper()
+		status, err := gitUseCase.CheckPRStatus("eksecd/feature")
+		if err != nil {
+			t.Fatalf("failed to check status: %v", err)
+		}
+		if status != expected {
+			t.Errorf("expected status %s, got %s", expected, status)
+		}
+	}
+
+	// The job branch exists only locally
+	runGit(t, mainRepo, "checkout", "-b", "eksecd/feature")
+	if err := os.WriteFile(filepath.Join(mainRepo, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, mainRepo, "add", "-A")
+	runGit(t, mainRepo, "commit", "-m", "Add feature")
+	checkStatus("no_pr")
+
+	// Pushed but not merged
+	runGit(t, mainRepo, "push", "-u", "origin", "eksecd/feature")
+	checkStatus("open")
+
+	// Merged into the default branch on the server
+	runGit(t, mainRepo, "checkout", "main")
+	runGit(t, mainRepo, "merge", "--no-ff", "-m", "Merge feature", "eksecd/feature")
+	runGit(t, mainRepo, "push", "origin", "main")
+	checkStatus("merged")
+}
+
+// fakeConflictResolver is a CLIAgent whose ContinueConversationInDir runs resolve in the
+// worktree instead of an agent turn
+type fakeConflictResolver struct {
+	services.CLIAgent
+	resolve func(workDir string) error
+	prompts []string
+}
+
+func (f *fakeConflictResolver) ContinueConversationInDir(sessionID, prompt, workDir string) (*services.CLIAgentResult, error) {
+	f.prompts = append(f.prompts, prompt)
+	if err := f.resolve(workDir); err != nil {
+		return nil, err
+	}
+	return &services.CLIAgentResult{Output: "Resolved", SessionID: sessionID}, nil
+}
+
+// setupRebaseTest creates a pushed job branch in a worktree that changes shared.txt, then
+// moves main on the remote ahead with mainContent written to mainFile
+func setupRebaseTest(t *testing.T, agent services.CLIAgent, mainFile, mainContent string) (*GitUseCase, string, string) {
+	t.Helper()
+	mainRepo, worktreeBase, cleanup := setupTestGitRepoWithRemote(t)
+	t.Cleanup(cleanup)
+	remote := runGit(t, mainRepo, "remote", "get-url", "origin")
+	runGit(t, remote, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	if err := os.WriteFile(filepath.Join(mainRepo, "shared.txt"), []byte("base\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, mainRepo, "add", "-A")
+	runGit(t, mainRepo, "commit", "-m", "Add shared file")
+	runGit(t, mainRepo, "push", "origin", "main")
+
+	worktreePath := filepath.Join(worktreeBase, "job")
+	runGit(t, mainRepo, "worktree", "add", "-b", "eksecd/job", worktreePath, "main")
+	if err := os.WriteFile(filepath.Join(worktreePath, "shared.txt"), []byte("job\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, worktreePath, "commit", "-am", "Change shared file on job branch")
+	runGit(t, worktreePath, "push", "-u", "origin", "eksecd/job")
+
+	if err := os.WriteFile(filepath.Join(mainRepo, mainFile), []byte(mainContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, mainRepo, "add", "-A")
+	runGit(t, mainRepo, "commit", "-m", "Move main ahead")
+	runGit(t, mainRepo, "push", "origin", "main")
+
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{RepoPath: mainRepo, IsRepoMode: true})
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return mainRepo })
+	return NewGitUseCase(gitClient, agent, appState), worktreePath, remote
+}
+
+func TestPrepareWorktreeForJob_RebasesOntoDefaultBranch(t *testing.T) {
+	gitUseCase, worktreePath, remote := setupRebaseTest(t, nil, "other.txt", "main\n")
+
+	result, err := gitUseCase.PrepareWorktreeForJob(worktreePath, "eksecd/job", "session", nil, true)
+	if err != nil {
+		t.Fatalf("failed to prepare worktree: %v", err)
+	}
+	if result == nil || !result.Rebased || !result.Pushed || result.Aborted {
+		t.Fatalf("expected a pushed rebase, got %+v", result)
+	}
+	if result.Onto != "origin/main" {
+		t.Errorf("expected rebase onto origin/main, got %s", result.Onto)
+	}
+
+	// The remote job branch now sits on top of main
+	runGit(t, remote, "merge-base", "--is-ancestor", "main", "eksecd/job")
+	if head := runGit(t, worktreePath, "rev-parse", "HEAD"); head != runGit(t, remote, "rev-parse", "eksecd/job") {
+		t.Errorf("expected remote job branch at %s", head)
+	}
+
+	// Nothing to do once the branch is current
+	result, err = gitUseCase.PrepareWorktreeForJob(worktreePath, "eksecd/job", "session", nil, true)
+	if err != nil {
+		t.Fatalf("failed to prepare worktree: %v", err)
+	}
+	if result != nil {
+		t.Errorf("expected no rebase for an up-to-date branch, got %+v", result)
+	}
+}
+
+func TestPrepareWorktreeForJob_AgentResolvesRebaseConflict(t *testing.T) {
+	agent := &fakeConflictResolver{resolve: func(workDir string) error {
+		return os.WriteFile(filepath.Join(workDir, "shared.txt"), []byte("main and job\n"), 0644)
+	}}
+	gitUseCase, worktreePath, remote := setupRebaseTest(t, agent, "shared.txt", "main\n")
+
+	result, err := gitUseCase.PrepareWorktreeForJob(worktreePath, "eksecd/job", "session", agent, true)
+	if err != nil {
+		t.Fatalf("failed to prepare worktree: %v", err)
+	}
+	if result == nil || !result.Rebased || !result.Pushed {
+		t.Fatalf("expected a pushed rebase, got %+v", result)
+	}
+	if len(result.ResolvedConflicts) != 1 || result.ResolvedConflicts[0] != "shared.txt" {
+		t.Errorf("expected resolved conflict in shared.txt, got %v", result.ResolvedConflicts)
+	}
+	if len(agent.prompts) != 1 || !strings.Contains(agent.prompts[0], "- shared.txt") {
+		t.Errorf("expected one resolution prompt listing shared.txt, got %v", agent.prompts)
+	}
+
+	if content := runGit(t, remote, "show", "eksecd/job:shared.txt"); content != "main and job" {
+		t.Errorf("expected resolved content on remote, got %q", content)
+	}
+	if subject := runGit(t, worktreePath, "log", "--format=%s", "-1"); subject != "Change shared file on job branch" {
+		t.Errorf("expected original commit message to be kept, got %q", subject)
+	}
+}
+
+func TestPrepareWorktreeForJob_AbortsUnresolvedRebase(t *testing.T) {
+	// The agent leaves the conflict markers in place
+	agent := &fakeConflictResolver{resolve: func(workDir string) error { return nil }}
+	gitUseCase, worktreePath, remote := setupRebaseTest(t, agent, "shared.txt", "main\n")
+	headBefore := runGit(t, worktreePath, "rev-parse", "HEAD")
+
+	result, err := gitUseCase.PrepareWorktreeForJob(worktreePath, "eksecd/job", "session", agent, true)
+	if err != nil {
+		t.Fatalf("failed to prepare worktree: %v", err)
+	}
+	if result == nil || !result.Aborted || result.Rebased {
+		t.Fatalf("expected an aborted rebase, got %+v", result)
+	}
+	if !strings.Contains(result.AbortReason, "shared.txt") {
+		t.Errorf("expected abort reason to name shared.txt, got %q", result.AbortReason)
+	}
+
+	if head := runGit(t, worktreePath, "rev-parse", "HEAD"); head != headBefore {
+		t.Errorf("expected branch restored to %s, got %s", headBefore, head)
+	}
+	if remoteHead := runGit(t, remote, "rev-parse", "eksecd/job"); remoteHead != headBefore {
+		t.Errorf("expected remote branch untouched, got %s", remoteHead)
+	}
+	if status := runGit(t, worktreePath, "status", "--porcelain"); status != "" {
+		t.Errorf("expected clean worktree, got %q", status)
+	}
+}
+
+func TestPrepareWorktreeForJob_InterruptedResolutionAbortsRebase(t *testing.T) {
+	// The job's agent is interrupted halfway through resolving the conflict
+	agent := &fakeConflictResolver{resolve: func(workDir string) error {
+		if err := os.WriteFile(filepath.Join(workDir, "shared.txt"), []byte("main and"), 0644); err != nil {
+			return err
+		}
+		return &core.ErrAgentInterrupted{SessionID: "session"}
+	}}
+	gitUseCase, worktreePath, remote := setupRebaseTest(t, nil, "shared.txt", "main\n")
+	headBefore := runGit(t, worktreePath, "rev-parse", "HEAD")
+
+	result, err := gitUseCase.PrepareWorktreeForJob(worktreePath, "eksecd/job", "session", agent, true)
+	if err != nil {
+		t.Fatalf("failed to prepare worktree: %v", err)
+	}
+	if len(agent.prompts) != 1 {
+		t.Errorf("expected the conflict resolution to run on the job's agent, got %d prompts", len(agent.prompts))
+	}
+	if result == nil || !result.Interrupted || !result.Aborted || result.Rebased {
+		t.Fatalf("expected an interrupted and aborted rebase, got %+v", result)
+	}
+
+	if head := runGit(t, worktreePath, "rev-parse", "HEAD"); head != headBefore {
+		t.Errorf("expected branch restored to %s, got %s", headBefore, head)
+	}
+	if remoteHead := runGit(t, remote, "rev-parse", "eksecd/job"); remoteHead != headBefore {
+		t.Errorf("expected remote branch untouched, got %s", remoteHead)
+	}
+	if status := runGit(t, worktreePath, "status", "--porcelain"); status != "" {
+		t.Errorf("expected clean worktree, got %q", status)
+	}
+}
+
+func TestCommitInFlightChanges(t *testing.T) {
+	// A nil agent proves the commit does not ask the agent for a message
+	gitUseCase, worktreePath, remote := setupRebaseTest(t, nil, "other.txt", "main\n")
+
+	if commitHash, err := gitUseCase.CommitInFlightChanges(worktreePath, "https://example.com/t", CommitRequester{}); err != nil || commitHash != "" {
+		t.Fatalf("expected nothing to commit, got %q, %v", commitHash, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktreePath, "partial.txt"), []byte("half done\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commitHash, err := gitUseCase.CommitInFlightChanges(worktreePath, "https://example.com/t", CommitRequester{})
+	if err != nil {
+		t.Fatalf("failed to commit in-flight changes: %v", err)
+	}
+	if subject := runGit(t, worktreePath, "log", "-1", "--format=%s"); subject != inFlightCommitMessage {
+		t.Errorf("expected the fixed commit message, got %q", subject)
+	}
+	if remoteHead := runGit(t, remote, "rev-parse", "eksecd/job"); remoteHead != commitHash {
+		t.Errorf("expected the branch pushed at %s, got %s", commitHash, remoteHead)
+	}
+}
@@ -0,0 +1,588 @@
+package usecases
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/core/log"
+	"eksecd/models"
+)
+
+// JobBundleFormatVersion is the version of the job bundles written by JobBundler.Export
+const JobBundleFormatVersion = 1
+
+const (
+	jobBundleManifestEntry = "manifest.json"
+	jobBundleBranchEntry   = "branch.bundle"
+	jobBundleChangesEntry  = "changes.patch"
+	jobBundleHomePrefix    = "home/" // Agent session files, relative to the agent user's home directory
+)
+
+// claudeProjectDirChars matches the characters Claude Code replaces with "-" when naming the
+// ~/.claude/projects directory of a working directory
+var claudeProjectDirChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// JobBundleManifest describes the contents of a job bundle
+type JobBundleManifest struct {
+	FormatVersion   int            `json:"format_version"`
+	ExportedAt      time.Time      `json:"exported_at"`
+	Job             models.JobData `json:"job"`
+	HeadCommit      string         `json:"head_commit,omitempty"`   // Tip of the job branch
+	HasBranchBundle bool           `json:"has_branch_bundle"`       // False when every commit of the branch is on origin
+	HasChanges      bool           `json:"has_changes"`             // Uncommitted changes, untracked files included
+	SessionFiles    []string       `json:"session_files,omitempty"` // Relative to the agent user's home directory
+}
+
+// JobBundler exports a job with its git work and agent session into a single file, and
+// imports such a file on another machine so the conversation continues there
+type JobBundler struct {
+	gitClient        *clients.GitClient
+	repoPath         string // Repository the agent works on, empty in no-repo mode
+	worktreeBasePath string // Where imported jobs get their worktree
+	homeDir          string // Home directory of the user the agent runs as
+	workDir          string // Directory the agent runs in, used for sessions of jobs without a worktree
+}
+
+// NewJobBundler creates a JobBundler. gitClient must run in repoPath.
+func NewJobBundler(gitClient *clients.GitClient, repoPath, worktreeBasePath, homeDir, workDir string) *JobBundler {
+	return &JobBundler{
+		gitClient:        gitClient,
+		repoPath:         repoPath,
+		worktreeBasePath: worktreeBasePath,
+		homeDir:          homeDir,
+		workDir:          workDir,
+	}
+}
+
+// AgentHomeDir returns the home directory of the user agent processes run as. In managed
+// mode that is AGENT_EXEC_USER's home, like for worktrees.
+func AgentHomeDir() (string, error) {
+	if execUser := clients.AgentExecUser(); execUser != "" {
+		return filepath.Join("/home", execUser), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return homeDir, nil
+}
+
+// Export writes job to a gzipped tar at bundlePath: the job data, a git bundle of the
+// branch commits that are not on origin, a patch of the uncommitted changes and the
+// agent's session files
+func (b *JobBundler) Export(job models.JobData, bundlePath string) (*JobBundleManifest, error) {
+	log.Info("📋 Starting to export job %s to %s", job.JobID, bundlePath)
+
+	tmpDir, err := os.MkdirTemp("", "eksecd-export-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest := &JobBundleManifest{
+		FormatVersion: JobBundleFormatVersion,
+		ExportedAt:    time.Now(),
+		Job:           job,
+	}
+
+	var changes []byte
+	if job.BranchName != "" {
+		if b.repoPath == "" {
+			return nil, fmt.Errorf("job %s works on branch %s but no repository is configured", job.JobID, job.BranchName)
+		}
+
+		if manifest.HeadCommit, err = b.gitClient.ResolveCommitInDir(b.repoPath, "refs/heads/"+job.BranchName); err != nil {
+			return nil, fmt.Errorf("failed to resolve job branch: %w", err)
+		}
+		unpushed, err := b.gitClient.CountCommitsNotOnOrigin(b.repoPath, job.BranchName)
+		if err != nil {
+			return nil, err
+		}
+		if unpushed > 0 {
+			if err := b.gitClient.CreateBranchBundle(b.repoPath, job.BranchName, filepath.Join(tmpDir, jobBundleBranchEntry)); err != nil {
+				return nil, err
+			}
+			manifest.HasBranchBundle = true
+		}
+
+		changesDir, err := b.changesDir(job)
+		if err != nil {
+			return nil, err
+		}
+		if changesDir != "" {
+			if changes, err = b.gitClient.DiffWorkingTree(changesDir); err != nil {
+				return nil, fmt.Errorf("failed to collect uncommitted changes: %w", err)
+			}
+			manifest.HasChanges = len(changes) > 0
+		}
+	}
+
+	if job.ClaudeSessionID != "" {
+		if manifest.SessionFiles, err = findAgentSessionFiles(b.homeDir, job.ClaudeSessionID); err != nil {
+			return nil, err
+		}
+		if len(manifest.SessionFiles) == 0 {
+			log.Warn("⚠️ No local session files found for session %s, the agent will not remember the conversation after import", job.ClaudeSessionID)
+		}
+	}
+
+	if err := b.writeBundle(bundlePath, manifest, tmpDir, changes); err != nil {
+		os.Remove(bundlePath)
+		return nil, err
+	}
+
+	log.Info("✅ Exported job %s (branch bundle: %t, changes: %t, session files: %d)",
+		job.JobID, manifest.HasBranchBundle, manifest.HasChanges, len(manifest.SessionFiles))
+	return manifest, nil
+}
+
+// changesDir returns the working tree holding the job's uncommitted changes, or "" when the
+// job's branch is not checked out anywhere
+func (b *JobBundler) changesDir(job models.JobData) (string, error) {
+	if job.WorktreePath != "" {
+		return job.WorktreePath, nil
+	}
+	currentBranch, err := b.gitClient.GetCurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if currentBranch != job.BranchName {
+		return "", nil
+	}
+	return b.repoPath, nil
+}
+
+// writeBundle writes the bundle archive
+func (b *JobBundler) writeBundle(bundlePath string, manifest *JobBundleManifest, tmpDir string, changes []byte) error {
+	file, err := os.OpenFile(bundlePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle file: %w", err)
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+	if err := writeTarEntry(tw, jobBundleManifestEntry, manifestData); err != nil {
+		return err
+	}
+	if manifest.HasBranchBundle {
+		data, err := os.ReadFile(filepath.Join(tmpDir, jobBundleBranchEntry))
+		if err != nil {
+			return fmt.Errorf("failed to read git bundle: %w", err)
+		}
+		if err := writeTarEntry(tw, jobBundleBranchEntry, data); err != nil {
+			return err
+		}
+	}
+	if manifest.HasChanges {
+		if err := writeTarEntry(tw, jobBundleChangesEntry, changes); err != nil {
+			return err
+		}
+	}
+	for _, relPath := range manifest.SessionFiles {
+		data, err := os.ReadFile(filepath.Join(b.homeDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			return fmt.Errorf("failed to read session file %s: %w", relPath, err)
+		}
+		if err := writeTarEntry(tw, jobBundleHomePrefix+relPath, data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync bundle file: %w", err)
+	}
+	return nil
+}
+
+// writeTarEntry adds a regular file to the archive
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write bundle entry %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write bundle entry %s: %w", name, err)
+	}
+	return nil
+}
+
+// Import recreates the job from the bundle at bundlePath: the branch is restored from
+// origin and the git bundle, checked out in a new worktree with the uncommitted changes
+// applied, and the session files are put where the agent looks for them. store is called
+// with the job data once everything is in place; if it fails, the worktree, branch and
+// session files are removed again.
+func (b *JobBundler) Import(bundlePath string, store func(job models.JobData) error) (job *models.JobData, err error) {
+	log.Info("📋 Starting to import job bundle %s", bundlePath)
+
+	tmpDir, err := os.MkdirTemp("", "eksecd-import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := extractBundle(bundlePath, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	imported := manifest.Job
+	imported.WorktreePath = ""
+	sessionDir := b.workDir
+
+	if imported.BranchName != "" {
+		worktreePath, restoreErr := b.restoreBranch(manifest, tmpDir)
+		if restoreErr != nil {
+			return nil, restoreErr
+		}
+		imported.WorktreePath = worktreePath
+		sessionDir = worktreePath
+
+		// Leave no half-imported worktree behind
+		defer func() {
+			if err != nil {
+				if cleanupErr := b.gitClient.RemoveWorktree(worktreePath); cleanupErr != nil {
+					log.Warn("⚠️ Failed to remove worktree of failed import: %v", cleanupErr)
+				}
+				if cleanupErr := b.gitClient.DeleteLocalBranch(imported.BranchName); cleanupErr != nil {
+					log.Warn("⚠️ Failed to delete branch of failed import: %v", cleanupErr)
+				}
+			}
+		}()
+
+		if manifest.HasChanges {
+			if err := b.gitClient.ApplyPatchInWorktree(worktreePath, filepath.Join(tmpDir, jobBundleChangesEntry)); err != nil {
+				return nil, fmt.Errorf("failed to restore uncommitted changes: %w", err)
+			}
+		}
+	}
+
+	// Session files that did not exist before are removed if the import fails
+	var createdSessionFiles []string
+	defer func() {
+		if err != nil {
+			for _, target := range createdSessionFiles {
+				if removeErr := os.Remove(target); removeErr != nil {
+					log.Warn("⚠️ Failed to remove session file of failed import: %v", removeErr)
+				}
+			}
+		}
+	}()
+
+	for _, relPath := range manifest.SessionFiles {
+		target := filepath.Join(b.homeDir, filepath.FromSlash(importedSessionPath(relPath, sessionDir)))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create session directory: %w", err)
+		}
+		data, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(jobBundleHomePrefix+relPath)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session file %s from bundle: %w", relPath, err)
+		}
+		if _, statErr := os.Stat(target); os.IsNotExist(statErr) {
+			createdSessionFiles = append(createdSessionFiles, target)
+		}
+		if err := os.WriteFile(target, data, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write session file %s: %w", target, err)
+		}
+	}
+
+	if err := store(imported); err != nil {
+		return nil, err
+	}
+
+	log.Info("✅ Imported job %s (worktree: %s, session files: %d)", imported.JobID, imported.WorktreePath, len(manifest.SessionFiles))
+	return &imported, nil
+}
+
+// restoreBranch creates the job branch from origin and the git bundle and checks it out in
+// a new worktree. Returns the worktree path.
+func (b *JobBundler) restoreBranch(manifest *JobBundleManifest, tmpDir string) (string, error) {
+	job := manifest.Job
+	if b.repoPath == "" {
+		return "", fmt.Errorf("job %s works on branch %s, import it into an agent running in that repository", job.JobID, job.BranchName)
+	}
+
+	if err := b.gitClient.CheckBranchName(job.BranchName); err != nil {
+		return "", err
+	}
+
+	worktreePath := filepath.Join(b.worktreeBasePath, job.JobID)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return "", fmt.Errorf("worktree %s already exists", worktreePath)
+	}
+	branches, err := b.gitClient.GetLocalBranches()
+	if err != nil {
+		return "", err
+	}
+	for _, branch := range branches {
+		if branch == job.BranchName {
+			return "", fmt.Errorf("branch %s already exists in %s", job.BranchName, b.repoPath)
+		}
+	}
+
+	// The git bundle only carries commits that are not on origin
+	if err := b.gitClient.FetchOrigin(); err != nil {
+		return "", err
+	}
+	if manifest.HasBranchBundle {
+		if err := b.gitClient.FetchBranchFromBundle(filepath.Join(tmpDir, jobBundleBranchEntry), job.BranchName); err != nil {
+			return "", err
+		}
+	} else if err := b.gitClient.CreateBranchAt(job.BranchName, manifest.HeadCommit); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(b.worktreeBasePath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create worktree base directory: %w", err)
+	}
+	if err := b.gitClient.AddWorktreeForBranch(worktreePath, job.BranchName); err != nil {
+		if deleteErr := b.gitClient.DeleteLocalBranch(job.BranchName); deleteErr != nil {
+			log.Warn("⚠️ Failed to delete branch of failed import: %v", deleteErr)
+		}
+		return "", err
+	}
+
+	remoteExists, err := b.gitClient.RemoteBranchExists(job.BranchName)
+	if err != nil {
+		log.Warn("⚠️ Failed to check remote branch %s: %v", job.BranchName, err)
+	} else if remoteExists {
+		if err := b.gitClient.SetUpstreamInWorktree(worktreePath, job.BranchName); err != nil {
+			log.Warn("⚠️ Failed to set upstream of %s: %v", job.BranchName, err)
+		}
+	}
+
+	return worktreePath, nil
+}
+
+// extractBundle unpacks the bundle archive into dir and returns its manifest
+func extractBundle(bundlePath, dir string) (*JobBundleManifest, error) {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("bundle contains invalid path %q", header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, fmt.Errorf("failed to extract bundle: %w", err)
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract bundle: %w", err)
+		}
+		_, copyErr := io.Copy(out, tr)
+		closeErr := out.Close()
+		if copyErr != nil || closeErr != nil {
+			return nil, fmt.Errorf("failed to extract bundle entry %s: %v %v", name, copyErr, closeErr)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, jobBundleManifestEntry))
+	if err != nil {
+		return nil, fmt.Errorf("bundle has no manifest: %w", err)
+	}
+	return parseJobBundleManifest(data)
+}
+
+// ReadJobBundleManifest returns the manifest of the bundle at bundlePath without
+// extracting anything else, so the job can be checked before it is imported
+func ReadJobBundleManifest(bundlePath string) (*JobBundleManifest, error) {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("bundle has no manifest")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || path.Clean(header.Name) != jobBundleManifestEntry {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+		}
+		return parseJobBundleManifest(data)
+	}
+}
+
+// parseJobBundleManifest parses and validates a bundle manifest
+func parseJobBundleManifest(data []byte) (*JobBundleManifest, error) {
+	var manifest JobBundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if manifest.FormatVersion > JobBundleFormatVersion {
+		return nil, fmt.Errorf("bundle format version %d is newer than supported version %d", manifest.FormatVersion, JobBundleFormatVersion)
+	}
+	if manifest.Job.JobID == "" {
+		return nil, fmt.Errorf("bundle manifest has no job")
+	}
+	// The job ID names the worktree directory, so it must stay a single path component
+	jobID := manifest.Job.JobID
+	if filepath.Base(jobID) != jobID || strings.ContainsAny(jobID, `/\`) || strings.HasPrefix(jobID, ".") {
+		return nil, fmt.Errorf("bundle manifest has invalid job ID %q", jobID)
+	}
+	if strings.HasPrefix(manifest.Job.BranchName, "-") {
+		return nil, fmt.Errorf("bundle manifest has invalid branch name %q", manifest.Job.BranchName)
+	}
+	if manifest.Job.BranchName != "" && manifest.HeadCommit == "" {
+		return nil, fmt.Errorf("bundle manifest has no head commit for branch %s", manifest.Job.BranchName)
+	}
+	for _, relPath := range manifest.SessionFiles {
+		if !isAgentSessionPath(relPath) {
+			return nil, fmt.Errorf("bundle contains invalid session file path %q", relPath)
+		}
+	}
+	return &manifest, nil
+}
+
+// isAgentSessionPath reports whether relPath, relative to the home directory, is inside
+// one of the directories agents keep their sessions in. Session files are written to the
+// agent user's home on import, so nothing else may be touched.
+func isAgentSessionPath(relPath string) bool {
+	if !fs.ValidPath(relPath) {
+		return false
+	}
+	parts := strings.Split(relPath, "/")
+	switch {
+	case len(parts) >= 4 && parts[0] == ".claude" && parts[1] == "projects":
+		return true
+	case len(parts) >= 3 && parts[0] == ".codex" && parts[1] == "sessions":
+		return true
+	}
+	return false
+}
+
+// findAgentSessionFiles returns the files agents keep for sessionID under homeDir, relative
+// to it: Claude Code transcripts in ~/.claude/projects and Codex rollouts in ~/.codex/sessions
+func findAgentSessionFiles(homeDir, sessionID string) ([]string, error) {
+	var files []string
+	addFile := func(absPath string) error {
+		relPath, err := filepath.Rel(homeDir, absPath)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	}
+
+	// Claude Code: <project>/<session>.jsonl plus an optional <project>/<session>/ directory
+	claudeProjects := filepath.Join(homeDir, ".claude", "projects")
+	projectDirs, err := os.ReadDir(claudeProjects)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read Claude projects: %w", err)
+	}
+	for _, projectDir := range projectDirs {
+		if !projectDir.IsDir() {
+			continue
+		}
+		projectPath := filepath.Join(claudeProjects, projectDir.Name())
+		transcript := filepath.Join(projectPath, sessionID+".jsonl")
+		if _, err := os.Stat(transcript); err == nil {
+			if err := addFile(transcript); err != nil {
+				return nil, err
+			}
+		}
+		sessionDir := filepath.Join(projectPath, sessionID)
+		if info, err := os.Stat(sessionDir); err == nil && info.IsDir() {
+			if err := filepath.WalkDir(sessionDir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				return addFile(p)
+			}); err != nil {
+				return nil, fmt.Errorf("failed to read Claude session directory: %w", err)
+			}
+		}
+	}
+
+	// Codex: sessions/YYYY/MM/DD/rollout-<timestamp>-<session>.jsonl
+	codexSessions := filepath.Join(homeDir, ".codex", "sessions")
+	if _, err := os.Stat(codexSessions); err == nil {
+		if err := filepath.WalkDir(codexSessions, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if strings.Contains(d.Name(), sessionID) {
+				return addFile(p)
+			}
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("failed to read Codex sessions: %w", err)
+		}
+	}
+
+	return files, nil
+}
+
+// importedSessionPath maps an exported session file to where the agent looks for it on this
+// machine. Claude Code keys transcripts by working directory, so they move to the project
+// directory of sessionDir; other files keep their path.
+func importedSessionPath(relPath, sessionDir string) string {
+	parts := strings.SplitN(relPath, "/", 4)
+	if len(parts) == 4 && parts[0] == ".claude" && parts[1] == "projects" && sessionDir != "" {
+		parts[2] = claudeProjectDirChars.ReplaceAllString(sessionDir, "-")
+		return strings.Join(parts, "/")
+	}
+	return relPath
+}
@@ -0,0 +1,307 @@
+package usecases
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"eksecd/clients"
+	"eksecd/models"
+)
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.email=test@example.com", "-c", "user.name=Test User"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\nOutput: %s", strings.Join(args, " "), err, string(output))
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newTestBundler returns a JobBundler for repoPath with its own worktree base and home
+func newTestBundler(t *testing.T, repoPath string) (*JobBundler, string, string) {
+	t.Helper()
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return repoPath })
+	worktreeBase := t.TempDir()
+	homeDir := t.TempDir()
+	return NewJobBundler(gitClient, repoPath, worktreeBase, homeDir, repoPath), worktreeBase, homeDir
+}
+
+func TestJobBundler_ExportImportRoundTrip(t *testing.T) {
+	sourceRepo, _, cleanup := setupTestGitRepoWithRemote(t)
+	defer cleanup()
+	remote := runGit(t, sourceRepo, "remote", "get-url", "origin")
+
+	// The job worktree has a pushed commit, an unpushed commit, and uncommitted changes
+	source, _, sourceHome := newTestBundler(t, sourceRepo)
+	sourceWorktree := filepath.Join(t.TempDir(), "job-1")
+	runGit(t, sourceRepo, "worktree", "add", sourceWorktree, "-b", "eksecd/feature", "origin/main")
+	if err := os.WriteFile(filepath.Join(sourceWorktree, "pushed.txt"), []byte("pushed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, sourceWorktree, "add", "-A")
+	runGit(t, sourceWorktree, "commit", "-m", "Pushed work")
+	runGit(t, sourceWorktree, "push", "-u", "origin", "eksecd/feature")
+	if err := os.WriteFile(filepath.Join(sourceWorktree, "unpushed.txt"), []byte("unpushed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, sourceWorktree, "add", "-A")
+	runGit(t, sourceWorktree, "commit", "-m", "Unpushed work")
+	headCommit := runGit(t, sourceWorktree, "rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(sourceWorktree, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceWorktree, "untracked.txt"), []byte("new file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Claude keeps the transcript in the project directory of the worktree
+	sessionID := "0b7c1c9e-5f7e-4a53-9d3b-3c5e2b6f4a11"
+	sourceProject := filepath.Join(sourceHome, ".claude", "projects", claudeProjectDirChars.ReplaceAllString(sourceWorktree, "-"))
+	if err := os.MkdirAll(sourceProject, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceProject, sessionID+".jsonl"), []byte("{\"type\":\"user\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	job := models.JobData{
+		JobID:           "job-1",
+		BranchName:      "eksecd/feature",
+		WorktreePath:    sourceWorktree,
+		ClaudeSessionID: sessionID,
+		PullRequestID:   "7",
+		Status:          models.JobStatusCompleted,
+		TurnCount:       2,
+	}
+	bundlePath := filepath.Join(t.TempDir(), "job-1.tar.gz")
+	manifest, err := source.Export(job, bundlePath)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !manifest.HasBranchBundle || !manifest.HasChanges || manifest.HeadCommit != headCommit || len(manifest.SessionFiles) != 1 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	// The source worktree and index are left alone
+	if status := runGit(t, sourceWorktree, "status", "--porcelain"); !strings.Contains(status, "?? untracked.txt") {
+		t.Errorf("expected untracked file to stay untracked in source, got %q", status)
+	}
+
+	// Import into a fresh clone on "another machine"
+	targetRepo := filepath.Join(t.TempDir(), "clone")
+	runGit(t, filepath.Dir(targetRepo), "clone", remote, targetRepo)
+	target, targetWorktreeBase, targetHome := newTestBundler(t, targetRepo)
+	expectedWorktree := filepath.Join(targetWorktreeBase, "job-1")
+	targetTranscript := filepath.Join(targetHome, ".claude", "projects", claudeProjectDirChars.ReplaceAllString(expectedWorktree, "-"), sessionID+".jsonl")
+
+	if peeked, err := ReadJobBundleManifest(bundlePath); err != nil || peeked.Job.JobID != "job-1" {
+		t.Fatalf("expected to read the manifest of job-1, got %+v, %v", peeked, err)
+	}
+
+	// A job that cannot be stored leaves nothing behind
+	if _, err := target.Import(bundlePath, func(models.JobData) error { return errors.New("state is full") }); err == nil {
+		t.Fatalf("expected import to fail when the job cannot be stored")
+	}
+	if _, err := os.Stat(expectedWorktree); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree to be removed, got %v", err)
+	}
+	if branches := runGit(t, targetRepo, "branch", "--list", "eksecd/feature"); branches != "" {
+		t.Errorf("expected the branch to be deleted, got %q", branches)
+	}
+	if _, err := os.Stat(targetTranscript); !os.IsNotExist(err) {
+		t.Errorf("expected the transcript to be removed, got %v", err)
+	}
+
+	var stored models.JobData
+	imported, err := target.Import(bundlePath, func(job models.JobData) error {
+		stored = job
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if stored.JobID != "job-1" || stored.WorktreePath != expectedWorktree {
+		t.Errorf("expected the imported job to be stored, got %+v", stored)
+	}
+	if imported.WorktreePath != expectedWorktree {
+		t.Errorf("expected worktree %s, got %s", expectedWorktree, imported.WorktreePath)
+	}
+	if imported.ClaudeSessionID != sessionID || imported.PullRequestID != "7" || imported.TurnCount != 2 {
+		t.Errorf("expected job data to be kept, got %+v", imported)
+	}
+	if head := runGit(t, expectedWorktree, "rev-parse", "HEAD"); head != headCommit {
+		t.Errorf("expected branch at %s, got %s", headCommit, head)
+	}
+	if upstream := runGit(t, expectedWorktree, "rev-parse", "--abbrev-ref", "@{upstream}"); upstream != "origin/eksecd/feature" {
+		t.Errorf("expected upstream origin/eksecd/feature, got %s", upstream)
+	}
+	for file, content := range map[string]string{"README.md": "# Changed\n", "untracked.txt": "new file\n", "unpushed.txt": "unpushed\n"} {
+		data, err := os.ReadFile(filepath.Join(expectedWorktree, file))
+		if err != nil || string(data) != content {
+			t.Errorf("expected %s to contain %q, got %q (%v)", file, content, string(data), err)
+		}
+	}
+	if _, err := os.Stat(targetTranscript); err != nil {
+		t.Errorf("expected transcript in the project directory of the new worktree: %v", err)
+	}
+
+	// Importing the same job again fails without touching the imported worktree
+	if _, err := target.Import(bundlePath, func(models.JobData) error { return nil }); err == nil {
+		t.Errorf("expected second import to fail")
+	}
+	if _, err := os.Stat(filepath.Join(expectedWorktree, "untracked.txt")); err != nil {
+		t.Errorf("expected imported worktree to survive a failed import: %v", err)
+	}
+}
+
+func TestJobBundler_ExportPushedBranchWithoutChanges(t *testing.T) {
+	sourceRepo, _, cleanup := setupTestGitRepoWithRemote(t)
+	defer cleanup()
+	remote := runGit(t, sourceRepo, "remote", "get-url", "origin")
+
+	// A branch-mode job whose branch is fully pushed and not checked out
+	runGit(t, sourceRepo, "branch", "eksecd/pushed")
+	runGit(t, sourceRepo, "push", "origin", "eksecd/pushed")
+	source, _, _ := newTestBundler(t, sourceRepo)
+
+	bundlePath := filepath.Join(t.TempDir(), "job-2.tar.gz")
+	manifest, err := source.Export(models.JobData{JobID: "job-2", BranchName: "eksecd/pushed"}, bundlePath)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if manifest.HasBranchBundle || manifest.HasChanges {
+		t.Errorf("expected no git bundle or changes for a pushed branch, got %+v", manifest)
+	}
+
+	targetRepo := filepath.Join(t.TempDir(), "clone")
+	runGit(t, filepath.Dir(targetRepo), "clone", remote, targetRepo)
+	target, _, _ := newTestBundler(t, targetRepo)
+	imported, err := target.Import(bundlePath, func(models.JobData) error { return nil })
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if head := runGit(t, imported.WorktreePath, "rev-parse", "HEAD"); head != manifest.HeadCommit {
+		t.Errorf("expected branch at %s, got %s", manifest.HeadCommit, head)
+	}
+}
+
+// writeTestBundle writes a bundle with the given manifest and session files, bypassing
+// the checks Export does
+func writeTestBundle(t *testing.T, manifest JobBundleManifest, files map[string]string) string {
+	t.Helper()
+	bundlePath := filepath.Join(t.TempDir(), "hostile.tar.gz")
+	file, err := os.Create(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[jobBundleManifestEntry] = string(data)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bundlePath
+}
+
+func TestJobBundler_RejectsHostileManifest(t *testing.T) {
+	repo, _, cleanup := setupTestGitRepoWithRemote(t)
+	defer cleanup()
+	headCommit := runGit(t, repo, "rev-parse", "HEAD")
+
+	tests := []struct {
+		name     string
+		manifest JobBundleManifest
+		files    map[string]string
+	}{
+		{
+			name:     "Session file outside the session directories",
+			manifest: JobBundleManifest{FormatVersion: 1, Job: models.JobData{JobID: "job-1"}, SessionFiles: []string{".ssh/authorized_keys"}},
+			files:    map[string]string{jobBundleHomePrefix + ".ssh/authorized_keys": "ssh-ed25519 AAAA attacker"},
+		},
+		{
+			name:     "Job ID escaping the worktree directory",
+			manifest: JobBundleManifest{FormatVersion: 1, Job: models.JobData{JobID: "../../x", BranchName: "eksecd/x"}, HeadCommit: headCommit},
+		},
+		{
+			name:     "Branch name taken as an option",
+			manifest: JobBundleManifest{FormatVersion: 1, Job: models.JobData{JobID: "job-1", BranchName: "--force"}, HeadCommit: headCommit},
+		},
+		{
+			name:     "Branch name git rejects",
+			manifest: JobBundleManifest{FormatVersion: 1, Job: models.JobData{JobID: "job-1", BranchName: "eksecd/bad..name"}, HeadCommit: headCommit},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.files == nil {
+				tt.files = map[string]string{}
+			}
+			bundlePath := writeTestBundle(t, tt.manifest, tt.files)
+			bundler, worktreeBase, homeDir := newTestBundler(t, repo)
+
+			stored := false
+			if _, err := bundler.Import(bundlePath, func(models.JobData) error { stored = true; return nil }); err == nil || stored {
+				t.Fatalf("expected the import to be refused, got %v (stored: %t)", err, stored)
+			}
+			if _, err := os.Stat(filepath.Join(homeDir, ".ssh")); !os.IsNotExist(err) {
+				t.Errorf("expected nothing written outside the session directories")
+			}
+			if entries, _ := os.ReadDir(worktreeBase); len(entries) != 0 {
+				t.Errorf("expected no worktree, got %v", entries)
+			}
+			if escaped := filepath.Join(worktreeBase, "..", "..", "x"); fileExists(escaped) {
+				t.Errorf("expected no worktree outside the base directory at %s", escaped)
+			}
+			if branches := runGit(t, repo, "branch", "--list", "--", tt.manifest.Job.BranchName); tt.manifest.Job.BranchName != "" && branches != "" {
+				t.Errorf("expected no branch to be created, got %q", branches)
+			}
+		})
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestImportedSessionPath(t *testing.T) {
+	tests := []struct {
+		relPath  string
+		expected string
+	}{
+		{".claude/projects/-old-worktree/abc.jsonl", ".claude/projects/-home-agent--eksec-worktrees-job-1/abc.jsonl"},
+		{".claude/projects/-old-worktree/abc/subagents/x.jsonl", ".claude/projects/-home-agent--eksec-worktrees-job-1/abc/subagents/x.jsonl"},
+		{".codex/sessions/2024/03/04/rollout-abc.jsonl", ".codex/sessions/2024/03/04/rollout-abc.jsonl"},
+	}
+	for _, tt := range tests {
+		if got := importedSessionPath(tt.relPath, "/home/agent/.eksec_worktrees/job-1"); got != tt.expected {
+			t.Errorf("importedSessionPath(%q) = %q, want %q", tt.relPath, got, tt.expected)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package usecases
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // CommitMessageGenerationPrompt creates a prompt for Claude to generate commit messages
 func CommitMessageGenerationPrompt(branchName string) string {
@@ -179,3 +183,82 @@ CRITICAL: Your response must contain ONLY the PR description in markdown format.
 
 Respond with ONLY the PR description in markdown format, nothing else.`, currentDescriptionClean)
 }
+
+// RebaseConflictResolutionPrompt asks the agent to resolve the conflicts of a rebase in progress
+func RebaseConflictResolutionPrompt(onto string, conflictedFiles []string) string {
+	return fmt.Sprintf(`Our branch is being rebased onto %s so it stays current with the default branch, and the rebase stopped on merge conflicts in these files:
+
+%s
+
+INSTRUCTIONS:
+- Resolve every conflict in the files above and remove all conflict markers (<<<<<<<, =======, >>>>>>>)
+- Keep the intent of our changes while incorporating the changes from %s
+- If a file was deleted on one side and our changes no longer apply, delete the file
+- Only edit the conflicted files; do not make unrelated changes
+
+IMPORTANT:
+- Do NOT run git commands (no add, commit, rebase --continue or rebase --abort); the rebase is continued for you
+- Do NOT create, update, or modify any pull requests
+
+When you are done, reply with a one-line summary of how you resolved the conflicts.`, onto, "- "+strings.Join(conflictedFiles, "\n- "), onto)
+}
+
+// CommitGroupingPrompt asks the agent to group the uncommitted changes into Conventional
+// Commits. units lists the changes by ID; rules summarizes the commitlint rules.
+func CommitGroupingPrompt(units, rules string) string {
+	return fmt.Sprintf(`Group the changes we just made into logical commits. The changes are split into units: hunks of modified files, or whole files. Each unit has an ID:
+
+%s
+
+INSTRUCTIONS:
+- Put every unit in exactly one commit; a commit holds one logical change (e.g. a feature, a fix, its tests, a refactoring it needs)
+- Order the commits so each one builds on the previous ones
+- Write each message in the Conventional Commits format: "type(scope): subject", optionally followed by a blank line and a body explaining why
+- Use the imperative mood and no trailing period in the subject
+- Don't mention "Claude" or "agent"
+
+RULES THE MESSAGES MUST PASS:
+%s
+
+IMPORTANT:
+- Do NOT run git commands; the commits are made for you
+- Do NOT modify any files
+
+Respond with ONLY a JSON array, nothing else:
+[{"message": "feat(api): add pagination to list endpoints", "units": ["U1", "U3"]}, {"message": "test(api): cover pagination", "units": ["U2"]}]`, units, rules)
+}
+
+// CommitGroupingRetryPrompt asks the agent to fix a rejected commit plan
+func CommitGroupingRetryPrompt(problems []string) string {
+	return fmt.Sprintf(`The commit plan was rejected:
+
+%s
+
+Fix these problems and respond with ONLY the complete corrected JSON array of commits.`, "- "+strings.Join(problems, "\n- "))
+}
+
+// VerificationFixPrompt asks the agent to fix the changes that failed the repository's
+// verification command. output is the tail of the command's output.
+func VerificationFixPrompt(command, output string, timedOut bool, timeout time.Duration) string {
+	outcome := "failed"
+	if timedOut {
+		outcome = fmt.Sprintf("did not finish within %s", timeout)
+	}
+	return fmt.Sprintf(`Your changes were verified with this command before committing, and it %s:
+
+    %s
+
+Output (last lines):
+%s
+
+INSTRUCTIONS:
+- Fix the problems reported above so the command passes
+- Keep the intent of your changes; do not delete or skip tests to make the command pass
+- You may run the command yourself to check your fix
+
+IMPORTANT:
+- Do NOT run git commands (no add, commit or push); your changes are committed for you once verification passes
+- Do NOT create, update, or modify any pull requests
+
+When you are done, reply with a one-line summary of what you fixed.`, outcome, command, "```\n"+output+"\n```")
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"eksecd/core/log"
+)
+
+const (
+	// maxVerificationOutputLines and maxVerificationOutputBytes limit the tail of the
+	// verification output that is fed back to the agent
+	maxVerificationOutputLines = 80
+	maxVerificationOutputBytes = 6000
+
+	// verificationWaitDelay bounds how long a timed-out command's leftover child processes
+	// may hold its output open
+	verificationWaitDelay = 5 * time.Second
+)
+
+// VerificationRun is the outcome of one run of the verification command
+type VerificationRun struct {
+	Passed   bool
+	TimedOut bool
+	Output   string // Tail of the combined output
+	Duration time.Duration
+}
+
+// VerificationResult is the final verification status of a turn's changes
+type VerificationResult struct {
+	Command     string
+	Passed      bool
+	TimedOut    bool // The last run was killed after the timeout
+	FixAttempts int  // Agent turns spent fixing failures
+}
+
+// VerifyChanges runs the verification command on the uncommitted changes of the job's
+// checkout; worktreePath is empty for the main checkout. Returns nil when there is nothing
+// to verify: no-repo mode or no uncommitted changes.
+func (g *GitUseCase) VerifyChanges(worktreePath, command string, timeout time.Duration) (*VerificationRun, error) {
+	repoContext := g.appState.GetRepositoryContext()
+	if !repoContext.IsRepoMode {
+		return nil, nil
+	}
+
+	dir := worktreePath
+	if dir == "" {
+		dir = repoContext.RepoPath
+	}
+	hasChanges, err := g.gitClient.HasUncommittedChangesInWorktree(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if !hasChanges {
+		log.Info("ℹ️ No uncommitted changes to verify")
+		return nil, nil
+	}
+
+	return RunVerification(dir, command, timeout), nil
+}
+
+// RunVerification runs command with the platform shell in dir, killing it and the processes
+// it started after timeout
+func RunVerification(dir, command string, timeout time.Duration) *VerificationRun {
+	log.Info("🔍 Running verification command in %s: %s", dir, command)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.WaitDelay = verificationWaitDelay
+	setVerificationProcessGroup(cmd)
+
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	run := &VerificationRun{
+		Passed:   err == nil,
+		TimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+		Output:   trimVerificationOutput(string(output)),
+		Duration: time.Since(start),
+	}
+	if run.TimedOut {
+		run.Passed = false
+		log.Warn("⚠️ Verification timed out after %s", timeout)
+	} else if err != nil {
+		log.Warn("⚠️ Verification failed after %s: %v", run.Duration.Round(time.Second), err)
+	} else {
+		log.Info("✅ Verification passed in %s", run.Duration.Round(time.Second))
+	}
+	return run
+}
+
+// trimVerificationOutput keeps the end of the output, where build and test tools report
+// the failures
+func trimVerificationOutput(output string) string {
+	output = strings.TrimRight(output, "\n")
+	lines := strings.Split(output, "\n")
+	if len(lines) > maxVerificationOutputLines {
+		output = fmt.Sprintf("... (%d lines omitted)\n", len(lines)-maxVerificationOutputLines) +
+			strings.Join(lines[len(lines)-maxVerificationOutputLines:], "\n")
+	}
+	if len(output) > maxVerificationOutputBytes {
+		output = "... (output truncated)\n" + strings.ToValidUTF8(output[len(output)-maxVerificationOutputBytes:], "")
+	}
+	return output
+}
@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"eksecd/clients"
+	"eksecd/models"
+)
+
+func TestRunVerification(t *testing.T) {
+	dir := t.TempDir()
+
+	run := RunVerification(dir, "echo building && echo ok", time.Minute)
+	if !run.Passed || run.TimedOut || run.Output != "building\nok" {
+		t.Errorf("expected a passing run, got %+v", run)
+	}
+
+	run = RunVerification(dir, "echo FAIL: TestLogin >&2; exit 1", time.Minute)
+	if run.Passed || run.TimedOut || run.Output != "FAIL: TestLogin" {
+		t.Errorf("expected a failing run with its output, got %+v", run)
+	}
+
+	// The background process keeps the output open, so it must be killed too
+	run = RunVerification(dir, "sleep 30 & echo $! > child.pid; echo started; exec sleep 5", 200*time.Millisecond)
+	if run.Passed || !run.TimedOut {
+		t.Errorf("expected a timed out run, got %+v", run)
+	}
+	if run.Duration > 4*time.Second {
+		t.Errorf("expected the command to be killed at the timeout, took %s", run.Duration)
+	}
+	if run.Output != "started" {
+		t.Errorf("expected the output before the timeout, got %q", run.Output)
+	}
+	childPID, err := os.ReadFile(filepath.Join(dir, "child.pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A killed child that was not reaped yet shows up as a zombie
+	status, _ := exec.Command("ps", "-o", "stat=", "-p", strings.TrimSpace(string(childPID))).Output()
+	if state := strings.TrimSpace(string(status)); state != "" && !strings.HasPrefix(state, "Z") {
+		t.Errorf("expected the background process to be killed, it is still running (%s)", state)
+	}
+}
+
+func TestTrimVerificationOutput(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	trimmed := trimVerificationOutput(strings.Join(lines, "\n") + "\n")
+	if !strings.HasPrefix(trimmed, "... (20 lines omitted)\nline 21\n") || !strings.HasSuffix(trimmed, "line 100") {
+		t.Errorf("expected the last 80 lines, got:\n%s", trimmed)
+	}
+
+	trimmed = trimVerificationOutput(strings.Repeat("é", maxVerificationOutputBytes))
+	if !strings.HasPrefix(trimmed, "... (output truncated)\n") || len(trimmed) > maxVerificationOutputBytes+30 {
+		t.Errorf("expected the output cut to %d bytes, got %d", maxVerificationOutputBytes, len(trimmed))
+	}
+	if !strings.HasSuffix(trimmed, "é") || strings.ContainsRune(trimmed, '�') {
+		t.Error("expected valid UTF-8 after truncation")
+	}
+
+	if trimmed := trimVerificationOutput("ok\n"); trimmed != "ok" {
+		t.Errorf("expected short output unchanged, got %q", trimmed)
+	}
+}
+
+func TestVerifyChanges(t *testing.T) {
+	mainRepo, _, cleanup := setupTestGitRepoWithRemote(t)
+	t.Cleanup(cleanup)
+
+	appState := models.NewAppState("test-agent", filepath.Join(t.TempDir(), models.StateFileName))
+	t.Cleanup(func() { appState.Close() })
+	appState.SetRepositoryContext(&models.RepositoryContext{RepoPath: mainRepo, IsRepoMode: true})
+	gitClient := clients.NewGitClient()
+	gitClient.SetRepoPathProvider(func() string { return mainRepo })
+	gitUseCase := NewGitUseCase(gitClient, nil, appState)
+
+	run, err := gitUseCase.VerifyChanges("", "exit 1", time.Minute)
+	if err != nil || run != nil {
+		t.Fatalf("expected nothing to verify without changes, got %+v, %v", run, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(mainRepo, "main.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run, err = gitUseCase.VerifyChanges("", "test -f main.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to verify changes: %v", err)
+	}
+	if run == nil || !run.Passed {
+		t.Errorf("expected the command to run in the repository, got %+v", run)
+	}
+
+	appState.SetRepositoryContext(&models.RepositoryContext{IsRepoMode: false})
+	if run, err := gitUseCase.VerifyChanges("", "exit 1", time.Minute); err != nil || run != nil {
+		t.Errorf("expected no verification outside repo mode, got %+v, %v", run, err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package usecases
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setVerificationProcessGroup starts cmd in its own process group and makes cancelling it
+// kill the whole group, so processes the command put in the background don't outlive it
+func setVerificationProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
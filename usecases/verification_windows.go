@@ -0,0 +1,9 @@
+//go:build windows
+
+package usecases
+
+import "os/exec"
+
+// setVerificationProcessGroup keeps the default cancellation on Windows, which kills
+// only the shell
+func setVerificationProcessGroup(cmd *exec.Cmd) {}
@@ -169,11 +169,30 @@ func (p *WorktreePool) GetPoolSize() int {
 	return len(p.ready)
 }
 
-// GetTargetSize returns the target pool size.
+// GetTargetSize returns the target pool size (thread-safe).
 func (p *WorktreePool) GetTargetSize() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.targetSize
 }
 
+// SetTargetSize changes the target pool size. Growing triggers the replenisher;
+// shrinking keeps already prepared worktrees until they are acquired.
+func (p *WorktreePool) SetTargetSize(targetSize int) {
+	p.mutex.Lock()
+	grew := targetSize > p.targetSize
+	p.targetSize = targetSize
+	p.mutex.Unlock()
+
+	log.Info("🏊 Worktree pool target size set to %d", targetSize)
+	if grew {
+		select {
+		case p.replenishChan <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // replenisherLoop is the background goroutine that maintains the pool.
 // It fills the pool on startup, responds to acquire signals, and periodically
 // refreshes stale worktrees.
@@ -181,7 +200,7 @@ func (p *WorktreePool) replenisherLoop(ctx context.Context) {
 	defer p.wg.Done()
 
 	// Initial fill on startup
-	log.Info("🔄 Worktree pool: starting initial fill (target size: %d)", p.targetSize)
+	log.Info("🔄 Worktree pool: starting initial fill (target size: %d)", p.GetTargetSize())
 	p.fillToTarget(ctx)
 	log.Info("✅ Worktree pool: initial fill complete (pool size: %d)", p.GetPoolSize())
 
@@ -207,7 +226,7 @@ func (p *WorktreePool) replenisherLoop(ctx context.Context) {
 // fillToTarget creates worktrees until the pool reaches target size.
 // It checks for context cancellation between each replenish attempt.
 func (p *WorktreePool) fillToTarget(ctx context.Context) {
-	for p.GetPoolSize() < p.targetSize {
+	for p.GetPoolSize() < p.GetTargetSize() {
 		// Check for cancellation before each replenish
 		select {
 		case <-ctx.Done():
@@ -236,7 +255,7 @@ func (p *WorktreePool) replenish() error {
 	// Generate unique ID
 	id := uuid.New().String()[:8]
 	wtPath := filepath.Join(p.basePath, fmt.Sprintf("pool-%s", id))
-	branchName := fmt.Sprintf("eksecd/pool-ready-%s", id)
+	branchName := poolBranchPrefix + id
 
 	// Ensure base directory exists
 	if err := os.MkdirAll(p.basePath, 0755); err != nil {
@@ -585,7 +604,7 @@ func (p *WorktreePool) ReclaimOrphanedPoolWorktrees() error {
 		}
 
 		// Only reclaim if it has a pool-ready branch
-		if !strings.HasPrefix(branchName, "eksecd/pool-ready-") {
+		if !strings.HasPrefix(branchName, poolBranchPrefix) {
 			log.Info("⚠️ Worktree %s has non-pool branch %s, removing", wtPath, branchName)
 			if err := p.gitClient.RemoveWorktree(wtPath); err != nil {
 				log.Warn("⚠️ Failed to remove worktree: %v", err)
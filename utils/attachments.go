@@ -236,35 +236,41 @@ func ExpandHomeDir(path string) (string, error) {
 	return path, nil
 }
 
-// FetchAndStoreArtifact downloads an artifact file and saves it to the specified location
-// The location path may contain ~ which will be expanded to the user's home directory
-func FetchAndStoreArtifact(client *clients.AgentsApiClient, attachmentID string, location string) error {
-	// Expand ~ in location path
-	expandedPath, err := ExpandHomeDir(location)
-	if err != nil {
-		return fmt.Errorf("failed to expand home directory in path %s: %w", location, err)
-	}
-
+// FetchArtifactContent downloads an artifact file and returns its decoded content
+// without writing anything to disk
+func FetchArtifactContent(client *clients.AgentsApiClient, attachmentID string) ([]byte, error) {
 	// Fetch attachment using existing mechanism (returns base64-encoded content)
 	attachmentResp, err := client.FetchAttachment(attachmentID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch artifact attachment %s: %w", attachmentID, err)
+		return nil, fmt.Errorf("failed to fetch artifact attachment %s: %w", attachmentID, err)
 	}
 
 	// Validate base64 data is not empty
 	if attachmentResp.Data == "" {
-		return fmt.Errorf("artifact attachment data is empty for ID %s", attachmentID)
+		return nil, fmt.Errorf("artifact attachment data is empty for ID %s", attachmentID)
 	}
 
 	// Decode base64 content
 	content, err := base64.StdEncoding.DecodeString(attachmentResp.Data)
 	if err != nil {
-		return fmt.Errorf("invalid base64 content in artifact attachment %s: %w", attachmentID, err)
+		return nil, fmt.Errorf("invalid base64 content in artifact attachment %s: %w", attachmentID, err)
 	}
 
 	// Check decoded content is not empty
 	if len(content) == 0 {
-		return fmt.Errorf("decoded artifact content is empty for ID %s", attachmentID)
+		return nil, fmt.Errorf("decoded artifact content is empty for ID %s", attachmentID)
+	}
+
+	return content, nil
+}
+
+// StoreArtifactContent writes artifact content to the specified location
+// The location path may contain ~ which will be expanded to the user's home directory
+func StoreArtifactContent(location string, content []byte) error {
+	// Expand ~ in location path
+	expandedPath, err := ExpandHomeDir(location)
+	if err != nil {
+		return fmt.Errorf("failed to expand home directory in path %s: %w", location, err)
 	}
 
 	// Create parent directory if it doesn't exist
@@ -280,3 +286,110 @@ func FetchAndStoreArtifact(client *clients.AgentsApiClient, attachmentID string,
 
 	return nil
 }
+
+// ArtifactFile is a downloaded artifact file and the location it is stored at
+type ArtifactFile struct {
+	Location string
+	Content  []byte
+}
+
+// ReplaceStoredArtifacts replaces the stored rules, MCP configs and skills with files. The
+// new set is written to a staging directory first and each managed directory is then swapped
+// in by rename, so a failure while writing leaves the previous artifacts in place. Files
+// outside the managed directories are written to their location afterwards.
+func ReplaceStoredArtifacts(files []ArtifactFile) error {
+	var managedDirs []string
+	for _, getDir := range []func() (string, error){GetCcagentRulesDir, GetCcagentMCPDir, GetCcagentSkillsDir} {
+		dir, err := getDir()
+		if err != nil {
+			return err
+		}
+		managedDirs = append(managedDirs, dir)
+	}
+
+	// The managed directories share a parent, so the staged ones can be renamed into place
+	parentDir := filepath.Dir(managedDirs[0])
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", parentDir, err)
+	}
+	stagingDir, err := os.MkdirTemp(parentDir, ".artifacts-staging-")
+	if err != nil {
+		return fmt.Errorf("failed to create artifact staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	stagedDir := func(dir string) string {
+		return filepath.Join(stagingDir, filepath.Base(dir))
+	}
+	for _, dir := range managedDirs {
+		if err := os.MkdirAll(stagedDir(dir), 0755); err != nil {
+			return fmt.Errorf("failed to create artifact staging directory: %w", err)
+		}
+	}
+
+	var unmanaged []ArtifactFile
+	for _, file := range files {
+		path, err := ExpandHomeDir(file.Location)
+		if err != nil {
+			return fmt.Errorf("failed to expand home directory in path %s: %w", file.Location, err)
+		}
+
+		staged := false
+		for _, dir := range managedDirs {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if err := StoreArtifactContent(filepath.Join(stagedDir(dir), rel), file.Content); err != nil {
+				return err
+			}
+			staged = true
+			break
+		}
+		if !staged {
+			unmanaged = append(unmanaged, file)
+		}
+	}
+
+	for _, dir := range managedDirs {
+		if err := swapDir(stagedDir(dir), dir); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range unmanaged {
+		if err := StoreArtifactContent(file.Location, file.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// swapDir replaces dir with newDir by rename, restoring dir if the rename fails
+func swapDir(newDir, dir string) error {
+	oldDir := dir + ".old"
+	if err := os.RemoveAll(oldDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", oldDir, err)
+	}
+
+	hadDir := true
+	if err := os.Rename(dir, oldDir); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to move %s aside: %w", dir, err)
+		}
+		hadDir = false
+	}
+	if err := os.Rename(newDir, dir); err != nil {
+		if hadDir {
+			_ = os.Rename(oldDir, dir)
+		}
+		return fmt.Errorf("failed to move new artifacts into %s: %w", dir, err)
+	}
+
+	if hadDir {
+		if err := os.RemoveAll(oldDir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", oldDir, err)
+		}
+	}
+	return nil
+}
@@ -449,7 +449,7 @@ func TestExpandHomeDir_TildeOnly(t *testing.T) {
 
 // Test artifact fetching and storage
 
-func TestFetchAndStoreArtifact_Success(t *testing.T) {
+func TestFetchAndStoreArtifactContent_Success(t *testing.T) {
 	// Create mock API server
 	markdownContent := "# Test Artifact\nThis is a test rule."
 	base64Content := base64.StdEncoding.EncodeToString([]byte(markdownContent))
@@ -482,7 +482,10 @@ func TestFetchAndStoreArtifact_Success(t *testing.T) {
 	location := filepath.Join(tempDir, "test-rule.md")
 
 	// Fetch and store artifact
-	err := FetchAndStoreArtifact(client, "test-attachment-id", location)
+	fetched, err := FetchArtifactContent(client, "test-attachment-id")
+	if err == nil {
+		err = StoreArtifactContent(location, fetched)
+	}
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -504,7 +507,7 @@ func TestFetchAndStoreArtifact_Success(t *testing.T) {
 	}
 }
 
-func TestFetchAndStoreArtifact_WithTilde(t *testing.T) {
+func TestFetchAndStoreArtifactContent_WithTilde(t *testing.T) {
 	// Create mock API server
 	markdownContent := "# Test Artifact with Tilde\nThis is a test."
 	base64Content := base64.StdEncoding.EncodeToString([]byte(markdownContent))
@@ -527,7 +530,10 @@ func TestFetchAndStoreArtifact_WithTilde(t *testing.T) {
 	location := "~/eksec_test_artifact.md"
 
 	// Fetch and store artifact
-	err := FetchAndStoreArtifact(client, "test-attachment-id", location)
+	fetched, err := FetchArtifactContent(client, "test-attachment-id")
+	if err == nil {
+		err = StoreArtifactContent(location, fetched)
+	}
 
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
@@ -543,7 +549,7 @@ func TestFetchAndStoreArtifact_WithTilde(t *testing.T) {
 	os.Remove(expandedPath)
 }
 
-func TestFetchAndStoreArtifact_APIError(t *testing.T) {
+func TestFetchArtifactContent_APIError(t *testing.T) {
 	// Create mock API server that returns error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Not Found", http.StatusNotFound)
@@ -552,19 +558,14 @@ func TestFetchAndStoreArtifact_APIError(t *testing.T) {
 
 	client := clients.NewAgentsApiClient("test-api-key", server.URL, "test-agent-id")
 
-	tempDir := filepath.Join(os.TempDir(), "eksec_test_artifacts_error")
-	defer os.RemoveAll(tempDir)
-
-	location := filepath.Join(tempDir, "test-rule.md")
-
-	err := FetchAndStoreArtifact(client, "nonexistent-id", location)
+	_, err := FetchArtifactContent(client, "nonexistent-id")
 
 	if err == nil {
 		t.Error("Expected error for API failure, got nil")
 	}
 }
 
-func TestFetchAndStoreArtifact_EmptyContent(t *testing.T) {
+func TestFetchArtifactContent_EmptyContent(t *testing.T) {
 	// Create mock API server that returns empty base64 data
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := map[string]string{
@@ -578,12 +579,7 @@ func TestFetchAndStoreArtifact_EmptyContent(t *testing.T) {
 
 	client := clients.NewAgentsApiClient("test-api-key", server.URL, "test-agent-id")
 
-	tempDir := filepath.Join(os.TempDir(), "eksec_test_artifacts_empty")
-	defer os.RemoveAll(tempDir)
-
-	location := filepath.Join(tempDir, "test-rule.md")
-
-	err := FetchAndStoreArtifact(client, "test-id", location)
+	_, err := FetchArtifactContent(client, "test-id")
 
 	if err == nil {
 		t.Error("Expected error for empty content, got nil")
@@ -593,3 +589,62 @@ func TestFetchAndStoreArtifact_EmptyContent(t *testing.T) {
 		t.Errorf("Expected 'empty' error, got: %v", err)
 	}
 }
+
+func TestReplaceStoredArtifacts(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	configDir := filepath.Join(homeDir, ".config", "eksecd")
+	oldRule := filepath.Join(configDir, "rules", "old.md")
+	if err := os.MkdirAll(filepath.Dir(oldRule), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldRule, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file that cannot be written leaves the previous artifacts in place
+	err := ReplaceStoredArtifacts([]ArtifactFile{
+		{Location: "~/.config/eksecd/rules/new.md", Content: []byte("new")},
+		{Location: "~/.config/eksecd/rules/new.md/nested.md", Content: []byte("nested")},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a file below another file")
+	}
+	if _, err := os.Stat(oldRule); err != nil {
+		t.Errorf("expected the previous rule to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "rules", "new.md")); !os.IsNotExist(err) {
+		t.Errorf("expected no new rule after the failure, got %v", err)
+	}
+
+	err = ReplaceStoredArtifacts([]ArtifactFile{
+		{Location: "~/.config/eksecd/rules/new.md", Content: []byte("new")},
+		{Location: "~/.config/eksecd/mcp/servers.json", Content: []byte("{}")},
+		{Location: "~/elsewhere/notes.md", Content: []byte("notes")},
+	})
+	if err != nil {
+		t.Fatalf("failed to replace artifacts: %v", err)
+	}
+	if _, err := os.Stat(oldRule); !os.IsNotExist(err) {
+		t.Errorf("expected the stale rule to be removed, got %v", err)
+	}
+	for path, expected := range map[string]string{
+		filepath.Join(configDir, "rules", "new.md"):     "new",
+		filepath.Join(configDir, "mcp", "servers.json"): "{}",
+		filepath.Join(homeDir, "elsewhere", "notes.md"): "notes",
+	} {
+		if content, err := os.ReadFile(path); err != nil || string(content) != expected {
+			t.Errorf("expected %q in %s, got %q, %v", expected, path, content, err)
+		}
+	}
+
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".artifacts-staging-") || strings.HasSuffix(entry.Name(), ".old") {
+			t.Errorf("expected no leftovers, found %s", entry.Name())
+		}
+	}
+}
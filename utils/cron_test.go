@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronExpression_Matches(t *testing.T) {
+	// Monday 2024-01-15 09:00
+	monday9am := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		at       time.Time
+		expected bool
+	}{
+		{"Every minute", "* * * * *", monday9am.Add(17 * time.Minute), true},
+		{"Mondays at 9", "0 9 * * 1", monday9am, true},
+		{"Mondays at 9 by name", "0 9 * * mon", monday9am, true},
+		{"Mondays at 9, wrong minute", "0 9 * * 1", monday9am.Add(time.Minute), false},
+		{"Weekdays range", "0 9 * * 1-5", monday9am.AddDate(0, 0, 5), false},
+		{"Step minutes", "*/15 * * * *", monday9am.Add(45 * time.Minute), true},
+		{"Step minutes off step", "*/15 * * * *", monday9am.Add(50 * time.Minute), false},
+		{"Start with step", "5/20 * * * *", monday9am.Add(25 * time.Minute), true},
+		{"List", "0 8,9,17 * * *", monday9am, true},
+		{"Sunday as 7", "0 9 * * 7", monday9am.AddDate(0, 0, 6), true},
+		{"Month by name", "0 9 15 jan *", monday9am, true},
+		{"Day of month or weekday", "0 9 1 * 1", monday9am, true},
+		{"Day of month only", "0 9 1 * *", monday9am, false},
+		{"Daily shorthand", "@daily", monday9am.Truncate(24 * time.Hour), true},
+		{"Hourly shorthand", "@hourly", monday9am.Add(30 * time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCronExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCronExpression(%q) returned error: %v", tt.expr, err)
+			}
+			if got := schedule.Matches(tt.at); got != tt.expected {
+				t.Errorf("Matches(%s) for %q = %v, want %v", tt.at, tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCronExpression_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		if _, err := ParseCronExpression(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	schedule, err := ParseCronExpression("30 9 * * 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Tuesday 2024-01-16 10:00 -> Monday 2024-01-22 09:30
+	next := schedule.Next(time.Date(2024, 1, 16, 10, 0, 0, 0, time.UTC))
+	expected := time.Date(2024, 1, 22, 9, 30, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Errorf("Expected next run %s, got %s", expected, next)
+	}
+
+	never, err := ParseCronExpression("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !never.Next(expected).IsZero() {
+		t.Errorf("Expected no next run for February 30th")
+	}
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	cgroupV2Root       = "/sys/fs/cgroup"
+	cgroupV1MemoryRoot = "/sys/fs/cgroup/memory"
+	procMeminfoPath    = "/proc/meminfo"
+
+	// cgroupV1UnlimitedThreshold catches the page-rounded max int64 that cgroup v1
+	// reports when no memory limit is set
+	cgroupV1UnlimitedThreshold = uint64(1) << 60
+)
+
+// MemoryUsage describes memory consumption against the limit that applies to this process
+type MemoryUsage struct {
+	UsedBytes  uint64
+	LimitBytes uint64
+	Source     string // "cgroup v2", "cgroup v1" or "meminfo"
+}
+
+// UsedFraction returns the used share of the limit, between 0 and 1
+func (m MemoryUsage) UsedFraction() float64 {
+	if m.LimitBytes == 0 {
+		return 0
+	}
+	fraction := float64(m.UsedBytes) / float64(m.LimitBytes)
+	if fraction > 1 {
+		return 1
+	}
+	return fraction
+}
+
+// ReadMemoryUsage reads memory usage from the container's cgroup when it has a memory
+// limit, falling back to system-wide figures from /proc/meminfo
+func ReadMemoryUsage() (MemoryUsage, error) {
+	return readMemoryUsage(cgroupV2Root, cgroupV1MemoryRoot, procMeminfoPath)
+}
+
+func readMemoryUsage(v2Root, v1Root, meminfoPath string) (MemoryUsage, error) {
+	if usage, ok := readCgroupMemoryUsage(
+		filepath.Join(v2Root, "memory.current"),
+		filepath.Join(v2Root, "memory.max"),
+		filepath.Join(v2Root, "memory.stat"),
+		"inactive_file",
+	); ok {
+		usage.Source = "cgroup v2"
+		return usage, nil
+	}
+
+	if usage, ok := readCgroupMemoryUsage(
+		filepath.Join(v1Root, "memory.usage_in_bytes"),
+		filepath.Join(v1Root, "memory.limit_in_bytes"),
+		filepath.Join(v1Root, "memory.stat"),
+		"total_inactive_file",
+	); ok {
+		usage.Source = "cgroup v1"
+		return usage, nil
+	}
+
+	return readMeminfo(meminfoPath)
+}
+
+// readCgroupMemoryUsage reads usage and limit from cgroup files. Reclaimable page cache
+// (inactive file pages) is not counted as used, matching how container runtimes decide
+// on OOM pressure. Returns false when the files are missing or no limit is set.
+func readCgroupMemoryUsage(usagePath, limitPath, statPath, inactiveFileKey string) (MemoryUsage, bool) {
+	limitRaw, err := os.ReadFile(limitPath)
+	if err != nil {
+		return MemoryUsage{}, false
+	}
+	limitStr := strings.TrimSpace(string(limitRaw))
+	if limitStr == "max" {
+		return MemoryUsage{}, false
+	}
+	limit, err := strconv.ParseUint(limitStr, 10, 64)
+	if err != nil || limit == 0 || limit >= cgroupV1UnlimitedThreshold {
+		return MemoryUsage{}, false
+	}
+
+	usageRaw, err := os.ReadFile(usagePath)
+	if err != nil {
+		return MemoryUsage{}, false
+	}
+	used, err := strconv.ParseUint(strings.TrimSpace(string(usageRaw)), 10, 64)
+	if err != nil {
+		return MemoryUsage{}, false
+	}
+
+	if inactiveFile, ok := readKeyedValue(statPath, inactiveFileKey); ok && inactiveFile < used {
+		used -= inactiveFile
+	}
+
+	return MemoryUsage{UsedBytes: used, LimitBytes: limit}, true
+}
+
+// readMeminfo reads system-wide memory usage as MemTotal minus MemAvailable
+func readMeminfo(path string) (MemoryUsage, error) {
+	total, okTotal := readKeyedValue(path, "MemTotal:")
+	available, okAvailable := readKeyedValue(path, "MemAvailable:")
+	if !okTotal || !okAvailable || total == 0 {
+		return MemoryUsage{}, fmt.Errorf("failed to read MemTotal and MemAvailable from %s", path)
+	}
+
+	// /proc/meminfo reports kB
+	used := uint64(0)
+	if available < total {
+		used = total - available
+	}
+	return MemoryUsage{
+		UsedBytes:  used * 1024,
+		LimitBytes: total * 1024,
+		Source:     "meminfo",
+	}, nil
+}
+
+// readKeyedValue returns the number following key in a "key value [unit]" formatted file
+func readKeyedValue(path, key string) (uint64, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != key {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return value, true
+	}
+	return 0, false
+}
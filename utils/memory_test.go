@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+const testMeminfo = `MemTotal:        8000000 kB
+MemFree:          500000 kB
+MemAvailable:    2000000 kB
+Buffers:          100000 kB
+`
+
+func TestReadMemoryUsage_CgroupV2(t *testing.T) {
+	root := t.TempDir()
+	v2 := filepath.Join(root, "v2")
+	writeTestFiles(t, v2, map[string]string{
+		"memory.current": "900\n",
+		"memory.max":     "1000\n",
+		"memory.stat":    "anon 700\ninactive_file 100\nactive_file 100\n",
+	})
+
+	usage, err := readMemoryUsage(v2, filepath.Join(root, "v1"), filepath.Join(root, "meminfo"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if usage.Source != "cgroup v2" {
+		t.Errorf("Expected cgroup v2 source, got %s", usage.Source)
+	}
+	if usage.UsedBytes != 800 || usage.LimitBytes != 1000 {
+		t.Errorf("Expected 800/1000 bytes (inactive file excluded), got %d/%d", usage.UsedBytes, usage.LimitBytes)
+	}
+	if usage.UsedFraction() != 0.8 {
+		t.Errorf("Expected used fraction 0.8, got %f", usage.UsedFraction())
+	}
+}
+
+func TestReadMemoryUsage_CgroupV2UnlimitedFallsBackToMeminfo(t *testing.T) {
+	root := t.TempDir()
+	v2 := filepath.Join(root, "v2")
+	writeTestFiles(t, v2, map[string]string{
+		"memory.current": "900\n",
+		"memory.max":     "max\n",
+	})
+	writeTestFiles(t, root, map[string]string{"meminfo": testMeminfo})
+
+	usage, err := readMemoryUsage(v2, filepath.Join(root, "v1"), filepath.Join(root, "meminfo"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if usage.Source != "meminfo" {
+		t.Errorf("Expected meminfo source, got %s", usage.Source)
+	}
+	if usage.UsedBytes != 6000000*1024 || usage.LimitBytes != 8000000*1024 {
+		t.Errorf("Unexpected meminfo usage: %+v", usage)
+	}
+}
+
+func TestReadMemoryUsage_CgroupV1(t *testing.T) {
+	root := t.TempDir()
+	v1 := filepath.Join(root, "v1")
+	writeTestFiles(t, v1, map[string]string{
+		"memory.usage_in_bytes": "500\n",
+		"memory.limit_in_bytes": "1000\n",
+		"memory.stat":           "cache 200\ntotal_inactive_file 100\n",
+	})
+
+	usage, err := readMemoryUsage(filepath.Join(root, "v2"), v1, filepath.Join(root, "meminfo"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if usage.Source != "cgroup v1" || usage.UsedBytes != 400 || usage.LimitBytes != 1000 {
+		t.Errorf("Unexpected cgroup v1 usage: %+v", usage)
+	}
+}
+
+func TestReadMemoryUsage_CgroupV1UnlimitedFallsBackToMeminfo(t *testing.T) {
+	root := t.TempDir()
+	v1 := filepath.Join(root, "v1")
+	writeTestFiles(t, v1, map[string]string{
+		"memory.usage_in_bytes": "500\n",
+		"memory.limit_in_bytes": "9223372036854771712\n",
+	})
+	writeTestFiles(t, root, map[string]string{"meminfo": testMeminfo})
+
+	usage, err := readMemoryUsage(filepath.Join(root, "v2"), v1, filepath.Join(root, "meminfo"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if usage.Source != "meminfo" {
+		t.Errorf("Expected meminfo source, got %s", usage.Source)
+	}
+}
+
+func TestReadMemoryUsage_NothingReadable(t *testing.T) {
+	root := t.TempDir()
+
+	if _, err := readMemoryUsage(filepath.Join(root, "v2"), filepath.Join(root, "v1"), filepath.Join(root, "meminfo")); err == nil {
+		t.Error("Expected error when no memory source is readable")
+	}
+}